@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/AkashGit21/ms-project/lib/backup"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	var output string
+	var compress bool
+	backupCmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Backs up the users and movies of the database into an archive",
+		Long: `Backs up the users and movies of the database into an archive.
+
+The archive is a tar file, compressed with gzip by --gzip or an output ending
+in .gz or .tgz, holding a manifest with the checksums of its files, the users
+with their password hashes and the movies. It restores into any backend.`,
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dbhandler, err := openDatabase()
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if output != "-" {
+				f, err := os.Create(output)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				out = f
+				compress = compress || strings.HasSuffix(output, ".gz") || strings.HasSuffix(output, ".tgz")
+			}
+			manifest, err := backup.Write(context.Background(), out, dbhandler, compress)
+			if err != nil {
+				return err
+			}
+			printManifest(cmd.ErrOrStderr(), "Backed up", manifest)
+			return nil
+		},
+	}
+	addDatabaseFlags(backupCmd.Flags())
+	backupCmd.Flags().StringVarP(&output, "output", "o", "-", "The archive to write, - for the standard output")
+	backupCmd.Flags().BoolVar(&compress, "gzip", false, "Compress the archive with gzip")
+
+	var skipExisting, verifyOnly bool
+	restoreCmd := &cobra.Command{
+		Use:   "restore ARCHIVE",
+		Short: "Restores the users and movies of an archive into the database",
+		Long: `Restores the users and movies of an archive of backup into the database.
+
+The archive is verified against its manifest first, so that a corrupt one
+restores nothing. A record already in the database stops the restore, unless
+--skip-existing is given.`,
+		Args:          cobra.ExactArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			f, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			if verifyOnly {
+				manifest, err := backup.Verify(f)
+				if err != nil {
+					return err
+				}
+				printManifest(cmd.OutOrStdout(), "Verified", manifest)
+				return nil
+			}
+
+			dbhandler, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			_, stats, err := backup.Restore(context.Background(), f, dbhandler, backup.RestoreOptions{SkipExisting: skipExisting})
+			fmt.Fprintf(cmd.OutOrStdout(), "Restored %d users and %d movies, skipped %d records\n", stats.Users, stats.Movies, stats.Skipped)
+			return err
+		},
+	}
+	addDatabaseFlags(restoreCmd.Flags())
+	restoreCmd.Flags().BoolVar(&skipExisting, "skip-existing", false, "Skip the records already in the database")
+	restoreCmd.Flags().BoolVar(&verifyOnly, "verify", false, "Only verify the archive, without restoring it")
+
+	rootCmd.AddCommand(backupCmd, restoreCmd)
+}
+
+func printManifest(w io.Writer, verb string, manifest backup.Manifest) {
+	fmt.Fprintf(w, "%s an archive of version %d, created at %v\n", verb, manifest.Version, manifest.CreateTime)
+	for _, f := range manifest.Files {
+		fmt.Fprintf(w, "  %s: %d records, sha256 %s\n", f.Name, f.Records, f.SHA256)
+	}
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"github.com/AkashGit21/ms-project/lib/configuration"
+	"github.com/AkashGit21/ms-project/lib/persistence"
+	"github.com/AkashGit21/ms-project/lib/persistence/dblayer"
+	"github.com/AkashGit21/ms-project/lib/persistence/sqlite"
+	"github.com/spf13/pflag"
+)
+
+// addDatabaseFlags adds the flags choosing the database to flags.
+func addDatabaseFlags(flags *pflag.FlagSet) {
+	flags.StringVar((*string)(&configuration.DBTypeDefault), "db-type",
+		string(configuration.DBTypeDefault), "The database backend to use, i.e. mongodb, postgres, sqlite or memory")
+	flags.StringVar(&configuration.DBConnectionDefault, "db-connection",
+		configuration.DBConnectionDefault, "The connection string of the database, "+
+			"by default "+dblayer.MongoDBConnectionDefault+" for mongodb, "+sqlite.DefaultFile+" for sqlite and the PG* environment variables for postgres")
+}
+
+// openDatabase connects to the database chosen by the flags of addDatabaseFlags.
+func openDatabase() (persistence.DatabaseHandler, error) {
+	return dblayer.NewPersistenceLayer(configuration.DBTypeDefault, configuration.DBConnectionDefault)
+}
@@ -3,10 +3,16 @@ package main
 import (
 	"testing"
 	"time"
+
+	"github.com/AkashGit21/ms-project/lib/configuration"
+	"github.com/AkashGit21/ms-project/lib/persistence/dblayer"
 )
 
 func TestServers(t *testing.T) {
 
+	// Run against the in-memory backend, so no database is needed
+	configuration.DBTypeDefault = dblayer.MEMORY
+
 	testServer := &Servers{}
 	conf := RuntimeConfig{
 		port:         8082,
@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -21,6 +22,9 @@ import (
 	"github.com/AkashGit21/ms-project/internal/server/interceptors"
 	"github.com/AkashGit21/ms-project/internal/server/services"
 	"github.com/AkashGit21/ms-project/lib/configuration"
+	"github.com/AkashGit21/ms-project/lib/events"
+	"github.com/AkashGit21/ms-project/lib/mail"
+	"github.com/AkashGit21/ms-project/lib/persistence"
 	"github.com/AkashGit21/ms-project/lib/persistence/dblayer"
 	fallback "github.com/googleapis/grpc-fallback-go/server"
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
@@ -53,33 +57,82 @@ func accessRoles() map[string][]string {
 	return map[string][]string{
 
 		// Roles for IdentityService
-		identityServicePath + "ListUsers":  {"ADMIN"},
-		identityServicePath + "GetUser":    {"ADMIN", "GUEST", "NORMAL", "SUBSCRIBED"},
-		identityServicePath + "CreateUser": {"ADMIN", "GUEST", "NORMAL", "SUBSCRIBED"},
-		identityServicePath + "UpdateUser": {"ADMIN", "NORMAL", "SUBSCRIBED"},
-		identityServicePath + "DeleteUser": {"ADMIN", "NORMAL", "SUBSCRIBED"},
+		identityServicePath + "ListUsers":          {"ADMIN"},
+		identityServicePath + "GetUser":            {"ADMIN", "GUEST", "NORMAL", "SUBSCRIBED"},
+		identityServicePath + "CreateUser":         {"ADMIN", "GUEST", "NORMAL", "SUBSCRIBED"},
+		identityServicePath + "UpdateUser":         {"ADMIN", "NORMAL", "SUBSCRIBED"},
+		identityServicePath + "DeleteUser":         {"ADMIN", "NORMAL", "SUBSCRIBED"},
+		identityServicePath + "UndeleteUser":       {"ADMIN"},
+		identityServicePath + "VerifyEmail":        {"ADMIN", "GUEST", "NORMAL", "SUBSCRIBED"},
+		identityServicePath + "ResendVerification": {"ADMIN", "GUEST", "NORMAL", "SUBSCRIBED"},
 
 		// Roles for AuthService
 		authServicePath + "Login": {"GUEST"},
 
 		// Roles for MovieService
-		movieServicePath + "ListMovies":  {"ADMIN", "GUEST", "NORMAL", "SUBSCRIBED"},
-		movieServicePath + "GetMovie":    {"ADMIN", "GUEST", "NORMAL", "SUBSCRIBED"},
-		movieServicePath + "CreateMovie": {"ADMIN", "SUBSCRIBED"},
-		movieServicePath + "UpdateMovie": {"ADMIN", "SUBSCRIBED"},
-		movieServicePath + "DeleteMovie": {"ADMIN", "SUBSCRIBED"},
+		movieServicePath + "ListMovies":         {"ADMIN", "GUEST", "NORMAL", "SUBSCRIBED"},
+		movieServicePath + "SearchMovies":       {"ADMIN", "GUEST", "NORMAL", "SUBSCRIBED"},
+		movieServicePath + "GetMovie":           {"ADMIN", "GUEST", "NORMAL", "SUBSCRIBED"},
+		movieServicePath + "CreateMovie":        {"ADMIN", "SUBSCRIBED"},
+		movieServicePath + "UpdateMovie":        {"ADMIN", "SUBSCRIBED"},
+		movieServicePath + "PartialUpdateMovie": {"ADMIN", "SUBSCRIBED"},
+		movieServicePath + "DeleteMovie":        {"ADMIN", "SUBSCRIBED"},
+		movieServicePath + "UndeleteMovie":      {"ADMIN", "SUBSCRIBED"},
+		movieServicePath + "WatchMovies":        {"ADMIN", "GUEST", "NORMAL", "SUBSCRIBED"},
+		movieServicePath + "ImportMovies":       {"ADMIN"},
 	}
 }
 
 // createBackends creates services used by both the gRPC and REST servers.
 func createBackends() *services.Backend {
 
-	dbhandler, _ := dblayer.NewPersistenceLayer(configuration.DBTypeDefault, configuration.DBConnectionDefault)
+	dbhandler, err := dblayer.NewPersistenceLayer(configuration.DBTypeDefault, configuration.DBConnectionDefault)
+	if err != nil {
+		log.Fatalf("Failed to set up the %v database: %v", configuration.DBTypeDefault, err)
+	}
+
+	// The relay reads the outbox of the backend itself, the wrapper of
+	// WithTimeout hides it
+	bus := events.NewBus()
+	if outbox, ok := dbhandler.(events.Outbox); ok && configuration.EventsIntervalDefault > 0 {
+		relay := events.NewRelay(outbox, eventSinks(bus)...)
+		if configuration.EventsDeadLetterFileDefault != "" {
+			file, err := events.NewFileSink(configuration.EventsDeadLetterFileDefault)
+			if err != nil {
+				log.Fatalf("Failed to open the dead letter file of the events: %v", err)
+			}
+			relay.DeadLetter = file
+		}
+		go relay.Run(context.Background(), configuration.EventsIntervalDefault)
+	} else if !ok {
+		log.Printf("[WARN] The %v database records no domain events", configuration.DBTypeDefault)
+	}
+
+	// The timeout only bounds the reads which miss the cache
+	var cache *persistence.CachingHandler
+	if configuration.CacheSizeDefault > 0 {
+		cache = persistence.NewCachingHandler(dbhandler, persistence.CacheOptions{
+			Size:        configuration.CacheSizeDefault,
+			TTL:         configuration.CacheTTLDefault,
+			NegativeTTL: configuration.CacheNegativeTTLDefault,
+		})
+		dbhandler = cache
+	}
+	dbhandler = persistence.WithTimeout(dbhandler, configuration.DBTimeoutDefault)
+
+	if configuration.PurgeIntervalDefault > 0 {
+		go persistence.RunPurger(context.Background(), dbhandler,
+			configuration.PurgeRetentionDefault, configuration.PurgeIntervalDefault)
+	}
 
 	identitySrv := services.NewIdentityServer(dbhandler)
-	authSrv := services.NewAuthServer(identitySrv)
-	movieSrv := services.NewMovieServer(authSrv)
+	authSrv := services.NewAuthServer(identitySrv, dbhandler)
+	movieSrv := services.NewMovieServer(authSrv, dbhandler)
 
+	identitySrv.Mailer = mailer()
+	identitySrv.EmailTokens = server.NewEmailTokenManager(services.SecretKey, configuration.VerifyEmailTTLDefault)
+	identitySrv.VerifyEmailURL = configuration.VerifyEmailURLDefault
+	authSrv.RequireVerifiedEmail = configuration.RequireVerifiedEmailDefault
 	authSrv.JWT = server.NewJWTManager(services.SecretKey, 5*time.Minute)
 	authI := interceptors.NewAuthInterceptor(authSrv.JWT, accessRoles())
 
@@ -94,6 +147,8 @@ func createBackends() *services.Backend {
 		AuthServer:      authSrv,
 		MovieServer:     movieSrv,
 		AuthInterceptor: authI,
+		Events:          bus,
+		Cache:           cache,
 
 		StdLog: stdLog,
 		ErrLog: errLog,
@@ -102,6 +157,46 @@ func createBackends() *services.Backend {
 	}
 }
 
+// eventSinks returns the sinks of the domain events: the subscribers of bus,
+// and the file and webhook of the configuration, if any.
+func eventSinks(bus *events.Bus) []events.Sink {
+	sinks := []events.Sink{bus}
+	if configuration.EventsFileDefault != "" {
+		file, err := events.NewFileSink(configuration.EventsFileDefault)
+		if err != nil {
+			log.Fatalf("Failed to open the events file: %v", err)
+		}
+		sinks = append(sinks, file)
+	}
+	if configuration.EventsWebhookDefault != "" {
+		sinks = append(sinks, events.NewWebhookSink(configuration.EventsWebhookDefault, configuration.EventsWebhookTimeout))
+	}
+	return sinks
+}
+
+// mailer returns the mailer of the configuration: the SMTP server if given,
+// else the mail file if given, else the log.
+func mailer() mail.Mailer {
+	switch {
+	case configuration.SMTPAddrDefault != "":
+		return mail.NewSMTPMailer(mail.SMTPConfig{
+			Addr:     configuration.SMTPAddrDefault,
+			Username: configuration.SMTPUsernameDefault,
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     configuration.MailFromDefault,
+			Timeout:  configuration.SMTPTimeout,
+		})
+	case configuration.MailFileDefault != "":
+		file, err := mail.NewFileMailer(configuration.MailFileDefault, configuration.MailFromDefault)
+		if err != nil {
+			log.Fatalf("Failed to open the mail file: %v", err)
+		}
+		return file
+	}
+	log.Println("[WARN] No SMTP server is given, the mails are only logged")
+	return mail.NewLogMailer(nil)
+}
+
 type Servers struct {
 	Backend        *services.Backend
 	gRPCServer     *grpc.Server
@@ -176,6 +271,7 @@ func (s *Servers) initiateGRPCServer(endpoint string, config RuntimeConfig) erro
 
 	opts := []grpc.ServerOption{
 		s.getUnaryInterceptors(),
+		s.getStreamInterceptors(),
 		// grpc.ChainUnaryInterceptor(
 		// 	s.Backend.AuthInterceptor.Unary(),
 		// 	s.Backend.ObserverRegistry.UnaryInterceptor,
@@ -233,9 +329,13 @@ func (s *Servers) initiateHTTPServer(endpoint string, config RuntimeConfig) erro
 	}
 	s.httpListener = lis
 
-	mux := runtime.NewServeMux()
+	// Writes with a stale etag are answered with 412, see server.GatewayErrorHandler
+	mux := runtime.NewServeMux(runtime.WithErrorHandler(server.GatewayErrorHandler))
 	dialAddr := fmt.Sprintf(":%d", config.port)
 	s.registerHTTPService(dialAddr, mux)
+	if s.Backend != nil && s.Backend.Cache != nil {
+		mux.HandlePath(http.MethodGet, "/debug/cache", cacheStatsHandler(s.Backend.Cache))
+	}
 
 	httpSrv := &http.Server{
 		Addr:         addr,
@@ -252,6 +352,14 @@ func (s *Servers) initiateHTTPServer(endpoint string, config RuntimeConfig) erro
 	return nil
 }
 
+// cacheStatsHandler serves the statistics of the cache as JSON, for monitoring.
+func cacheStatsHandler(cache *persistence.CachingHandler) runtime.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(cache.Stats())
+	}
+}
+
 // Register all the services required for gRPC server
 func (s *Servers) registerGRPCService() {
 
@@ -296,3 +404,14 @@ func (s *Servers) getUnaryInterceptors() grpc.ServerOption {
 		s.Backend.ObserverRegistry.UnaryInterceptor,
 	)
 }
+
+func (s *Servers) getStreamInterceptors() grpc.ServerOption {
+
+	rateLimit := interceptors.NewRateLimiter()
+
+	return grpc.ChainStreamInterceptor(
+		s.Backend.AuthInterceptor.Stream(),
+		rateLimit.StreamRateLimiter(rateLimit),
+		s.Backend.ObserverRegistry.StreamInterceptor,
+	)
+}
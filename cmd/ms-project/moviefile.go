@@ -0,0 +1,231 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	moviepb "github.com/AkashGit21/ms-project/internal/grpc/movie"
+	"github.com/golang/protobuf/jsonpb"
+)
+
+// The file formats of the movie catalogue. JSONL has a JSON movie per line,
+// CSV a header naming the columns of csvColumns, in any order, and a movie per
+// record. The lists of a CSV record are separated by csvListSep.
+const (
+	formatJSONL = "jsonl"
+	formatCSV   = "csv"
+
+	csvListSep = ";"
+)
+
+var csvColumns = []string{"id", "name", "summary", "cast", "tags", "director", "writers"}
+
+// movieFormat returns the given format, or the one of the extension of path if
+// none is given.
+func movieFormat(format, path string) (string, error) {
+	if format == "" {
+		if strings.EqualFold(filepath.Ext(path), ".csv") {
+			return formatCSV, nil
+		}
+		return formatJSONL, nil
+	}
+	if format != formatJSONL && format != formatCSV {
+		return "", fmt.Errorf("unknown format %q, want %s or %s", format, formatJSONL, formatCSV)
+	}
+	return format, nil
+}
+
+// recordError reports a malformed record of a movie file. The records after
+// it can still be read.
+type recordError struct {
+	line int
+	err  error
+}
+
+func (e *recordError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.line, e.err)
+}
+
+// movieReader reads the movies of a file one at a time.
+type movieReader interface {
+	// Read returns the next movie and the line it begins on, or io.EOF after
+	// the last one. A malformed record is reported with a *recordError.
+	Read() (*moviepb.Movie, int, error)
+}
+
+func newMovieReader(r io.Reader, format string) (movieReader, error) {
+	switch format {
+	case formatJSONL:
+		return &jsonlReader{r: bufio.NewReader(r)}, nil
+	case formatCSV:
+		return newCSVReader(r)
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+type jsonlReader struct {
+	r    *bufio.Reader
+	line int
+}
+
+func (jr *jsonlReader) Read() (*moviepb.Movie, int, error) {
+	for {
+		data, err := jr.r.ReadBytes('\n')
+		if err != nil && (err != io.EOF || len(data) == 0) {
+			return nil, 0, err
+		}
+		jr.line++
+
+		// Blank lines are skipped
+		if data = bytes.TrimSpace(data); len(data) == 0 {
+			continue
+		}
+		mv := &moviepb.Movie{}
+		if err := jsonpb.Unmarshal(bytes.NewReader(data), mv); err != nil {
+			return nil, jr.line, &recordError{line: jr.line, err: err}
+		}
+		return mv, jr.line, nil
+	}
+}
+
+type csvReader struct {
+	r       *csv.Reader
+	columns []string
+}
+
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading the CSV header: %v", err)
+	}
+	for _, col := range header {
+		if !contains(csvColumns, col) {
+			return nil, fmt.Errorf("unknown CSV column %q, want some of %s", col, strings.Join(csvColumns, ", "))
+		}
+	}
+	return &csvReader{r: cr, columns: header}, nil
+}
+
+func (cr *csvReader) Read() (*moviepb.Movie, int, error) {
+	record, err := cr.r.Read()
+	var perr *csv.ParseError
+	if errors.As(err, &perr) {
+		return nil, perr.StartLine, &recordError{line: perr.StartLine, err: perr.Err}
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	line, _ := cr.r.FieldPos(0)
+
+	mv := &moviepb.Movie{}
+	for i, value := range record {
+		switch cr.columns[i] {
+		case "id":
+			mv.Id = value
+		case "name":
+			mv.Name = value
+		case "summary":
+			mv.Summary = value
+		case "cast":
+			mv.Cast = splitList(value)
+		case "tags":
+			for _, name := range splitList(value) {
+				tag, ok := moviepb.Tag_value[name]
+				if !ok {
+					return nil, line, &recordError{line: line, err: fmt.Errorf("unknown tag %q", name)}
+				}
+				mv.Tags = append(mv.Tags, moviepb.Tag(tag))
+			}
+		case "director":
+			mv.Director = value
+		case "writers":
+			mv.Writers = splitList(value)
+		}
+	}
+	return mv, line, nil
+}
+
+func splitList(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, csvListSep) {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// movieWriter writes movies to a file one at a time. Flush must be called
+// after the last one.
+type movieWriter interface {
+	Write(*moviepb.Movie) error
+	Flush() error
+}
+
+func newMovieWriter(w io.Writer, format string) (movieWriter, error) {
+	switch format {
+	case formatJSONL:
+		return &jsonlWriter{w: bufio.NewWriter(w), m: &jsonpb.Marshaler{OrigName: true}}, nil
+	case formatCSV:
+		cw := csv.NewWriter(w)
+		return &csvWriter{w: cw}, cw.Write(csvColumns)
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+type jsonlWriter struct {
+	w *bufio.Writer
+	m *jsonpb.Marshaler
+}
+
+func (jw *jsonlWriter) Write(mv *moviepb.Movie) error {
+	if err := jw.m.Marshal(jw.w, mv); err != nil {
+		return err
+	}
+	return jw.w.WriteByte('\n')
+}
+
+func (jw *jsonlWriter) Flush() error {
+	return jw.w.Flush()
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func (cw *csvWriter) Write(mv *moviepb.Movie) error {
+	tags := make([]string, len(mv.GetTags()))
+	for i, tag := range mv.GetTags() {
+		tags[i] = tag.String()
+	}
+	return cw.w.Write([]string{
+		mv.GetId(),
+		mv.GetName(),
+		mv.GetSummary(),
+		strings.Join(mv.GetCast(), csvListSep),
+		strings.Join(tags, csvListSep),
+		mv.GetDirector(),
+		strings.Join(mv.GetWriters(), csvListSep),
+	})
+}
+
+func (cw *csvWriter) Flush() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
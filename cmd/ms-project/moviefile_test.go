@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	moviepb "github.com/AkashGit21/ms-project/internal/grpc/movie"
+	"github.com/AkashGit21/ms-project/internal/server/services"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// readAll returns the movies of r and the lines of its malformed records.
+func readAll(t *testing.T, r movieReader) ([]*moviepb.Movie, []int) {
+	t.Helper()
+	var movies []*moviepb.Movie
+	var bad []int
+	for {
+		mv, line, err := r.Read()
+		if err == io.EOF {
+			return movies, bad
+		}
+		var rerr *recordError
+		if errors.As(err, &rerr) {
+			bad = append(bad, line)
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Read: unexpected err %v", err)
+		}
+		movies = append(movies, mv)
+	}
+}
+
+func TestMovieReader(t *testing.T) {
+	want := []*moviepb.Movie{
+		{Id: "id_0", Name: "test_movie", Cast: []string{"test_actor1", "test_actor2"}, Tags: []moviepb.Tag{moviepb.Tag_Action}},
+		{Name: "test_movie2", Summary: "test, summary"},
+	}
+
+	tests := []struct {
+		name    string
+		format  string
+		input   string
+		wantBad []int
+	}{
+		{
+			name:   "jsonl",
+			format: formatJSONL,
+			input: `{"id": "id_0", "name": "test_movie", "cast": ["test_actor1", "test_actor2"], "tags": ["Action"]}
+
+{"name": "test_movie2", "unknown": 1}
+{"name": "test_movie2", "summary": "test, summary"}
+{"name": `,
+			wantBad: []int{3, 5},
+		},
+		{
+			name:   "csv",
+			format: formatCSV,
+			input: `name,id,cast,tags,summary
+test_movie,id_0,test_actor1; test_actor2,Action,
+test_movie2,,,Horror,
+test_movie2,,,,"test, summary"
+test_movie3,,,,"unterminated
+`,
+			wantBad: []int{3, 5},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := newMovieReader(strings.NewReader(tt.input), tt.format)
+			if err != nil {
+				t.Fatalf("newMovieReader: unexpected err %v", err)
+			}
+			got, bad := readAll(t, r)
+			if len(got) != len(want) {
+				t.Fatalf("Read: want %d movies, got %v", len(want), got)
+			}
+			for i := range want {
+				if !proto.Equal(got[i], want[i]) {
+					t.Errorf("Read: want %v, got %v", want[i], got[i])
+				}
+			}
+			if len(bad) != len(tt.wantBad) || bad[0] != tt.wantBad[0] || bad[1] != tt.wantBad[1] {
+				t.Errorf("Read: want malformed lines %v, got %v", tt.wantBad, bad)
+			}
+		})
+	}
+
+	if _, err := newMovieReader(strings.NewReader("name,year\n"), formatCSV); err == nil {
+		t.Error("newMovieReader: want an error for an unknown column")
+	}
+}
+
+func TestMovieWriter(t *testing.T) {
+	movies := []*moviepb.Movie{
+		{Id: "id_0", Name: "test_movie", Summary: "test, \"quoted\" summary", Cast: []string{"test_actor1", "test_actor2"},
+			Tags: []moviepb.Tag{moviepb.Tag_Action, moviepb.Tag_Comedy}, Director: "test_director", Writers: []string{"test_writer"}},
+		{Id: "id_1", Name: "test_movie2"},
+	}
+
+	for _, format := range []string{formatJSONL, formatCSV} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := newMovieWriter(&buf, format)
+			if err != nil {
+				t.Fatalf("newMovieWriter: unexpected err %v", err)
+			}
+			for _, mv := range movies {
+				if err := w.Write(mv); err != nil {
+					t.Fatalf("Write: unexpected err %v", err)
+				}
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("Flush: unexpected err %v", err)
+			}
+
+			// What is written reads back the same
+			r, err := newMovieReader(&buf, format)
+			if err != nil {
+				t.Fatalf("newMovieReader: unexpected err %v", err)
+			}
+			got, bad := readAll(t, r)
+			if len(got) != len(movies) || len(bad) != 0 {
+				t.Fatalf("Read: want %d movies, got %v and malformed lines %v", len(movies), got, bad)
+			}
+			for i := range movies {
+				if !proto.Equal(got[i], movies[i]) {
+					t.Errorf("Read: want %v, got %v", movies[i], got[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMovieFormat(t *testing.T) {
+	tests := []struct {
+		format, path, want string
+		wantErr            bool
+	}{
+		{path: "movies.csv", want: formatCSV},
+		{path: "MOVIES.CSV", want: formatCSV},
+		{path: "movies.jsonl", want: formatJSONL},
+		{path: "-", want: formatJSONL},
+		{format: formatCSV, path: "-", want: formatCSV},
+		{format: "xml", path: "movies.xml", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := movieFormat(tt.format, tt.path)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("movieFormat(%q, %q): want %q, got %q, %v", tt.format, tt.path, tt.want, got, err)
+		}
+	}
+}
+
+// fakeImporter rejects the movies without a name, and replaces those with an id.
+type fakeImporter struct {
+	imported []string
+}
+
+func (fi *fakeImporter) ImportMovie(ctx context.Context, mv *moviepb.Movie, run *services.ImportRun) (bool, error) {
+	if mv.GetName() == "" {
+		return false, status.Error(codes.InvalidArgument, "missing name")
+	}
+	if !run.DryRun {
+		fi.imported = append(fi.imported, mv.GetName())
+	}
+	return mv.GetId() == "", nil
+}
+
+func TestImportMovies(t *testing.T) {
+	input := `{"name": "test_movie"}
+{"id": "id_0", "name": "test_movie2"}
+{"summary": "test_summary"}
+not json
+`
+	for _, dryRun := range []bool{false, true} {
+		r, _ := newMovieReader(strings.NewReader(input), formatJSONL)
+		importer := &fakeImporter{}
+		var out, errOut bytes.Buffer
+		err := importMovies(context.Background(), r, importer, dryRun, true, &out, &errOut)
+		if err == nil {
+			t.Error("importMovies: want an error for the failed movies")
+		}
+
+		wantOut := "Created 1, updated 1 and failed 2 movies\n"
+		wantImported := 2
+		if dryRun {
+			wantOut = "Created 1, updated 1 and failed 2 movies (dry run)\n"
+			wantImported = 0
+		}
+		if out.String() != wantOut {
+			t.Errorf("importMovies: want summary %q, got %q", wantOut, out.String())
+		}
+		if len(importer.imported) != wantImported {
+			t.Errorf("importMovies: want %d movies imported, got %v", wantImported, importer.imported)
+		}
+		if lines := strings.Split(strings.TrimSpace(errOut.String()), "\n"); len(lines) != 2 ||
+			lines[0] != "line 3: missing name" || !strings.HasPrefix(lines[1], "line 4: ") {
+			t.Errorf("importMovies: want the errors of lines 3 and 4, got %q", errOut.String())
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	moviepb "github.com/AkashGit21/ms-project/internal/grpc/movie"
+	"github.com/AkashGit21/ms-project/internal/server/services"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/status"
+)
+
+func init() {
+	moviesCmd := &cobra.Command{
+		Use:   "movies",
+		Short: "Imports and exports the movie catalogue, directly in the database",
+	}
+	addDatabaseFlags(moviesCmd.PersistentFlags())
+
+	var format string
+	var dryRun, upsert bool
+	importCmd := &cobra.Command{
+		Use:   "import FILE",
+		Short: "Imports the movies of a JSONL or CSV file, - for the standard input",
+		Long: `Imports the movies of a JSONL or CSV file, - for the standard input.
+
+Every movie is validated like CreateMovie does, and the movies which fail are
+reported with their line. A movie without an id is created with a new one, a
+movie with an id keeps it. The columns of a CSV file are named by its header,
+among id, name, summary, cast, tags, director and writers, the lists being
+separated by semicolons.`,
+		Args:          cobra.ExactArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := movieFormat(format, args[0])
+			if err != nil {
+				return err
+			}
+			in := io.Reader(os.Stdin)
+			if args[0] != "-" {
+				f, err := os.Open(args[0])
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				in = f
+			}
+			r, err := newMovieReader(in, format)
+			if err != nil {
+				return err
+			}
+
+			dbhandler, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			movieSrv := services.NewMovieServer(nil, dbhandler)
+			return importMovies(context.Background(), r, movieSrv, dryRun, upsert, cmd.OutOrStdout(), cmd.ErrOrStderr())
+		},
+	}
+	importCmd.Flags().StringVar(&format, "format", "", "The format of the file, jsonl or csv, by default the one of its extension")
+	importCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Only validate the movies and check that their ids and names are free, without storing any")
+	importCmd.Flags().BoolVar(&upsert, "upsert", false, "Replace the stored movies with the ids of the imported ones")
+
+	var output string
+	var showDeleted bool
+	exportCmd := &cobra.Command{
+		Use:           "export",
+		Short:         "Exports the movies to a JSONL or CSV file",
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := movieFormat(format, output)
+			if err != nil {
+				return err
+			}
+			dbhandler, err := openDatabase()
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if output != "-" {
+				f, err := os.Create(output)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				out = f
+			}
+			w, err := newMovieWriter(out, format)
+			if err != nil {
+				return err
+			}
+			movieSrv := services.NewMovieServer(nil, dbhandler)
+			if err := movieSrv.ExportMovies(context.Background(), showDeleted, w.Write); err != nil {
+				return err
+			}
+			return w.Flush()
+		},
+	}
+	exportCmd.Flags().StringVar(&format, "format", "", "The format of the file, jsonl or csv, by default the one of its extension")
+	exportCmd.Flags().StringVarP(&output, "output", "o", "-", "The file to write, - for the standard output")
+	exportCmd.Flags().BoolVar(&showDeleted, "show-deleted", false, "Export the deleted movies too")
+
+	moviesCmd.AddCommand(importCmd, exportCmd)
+	rootCmd.AddCommand(moviesCmd)
+}
+
+// movieImporter imports a single movie, as done by the movie server.
+type movieImporter interface {
+	ImportMovie(ctx context.Context, mv *moviepb.Movie, run *services.ImportRun) (bool, error)
+}
+
+// importMovies imports the movies read from r one by one, reporting the ones
+// which fail to errOut with their line, and a summary to out. It fails if any
+// movie did.
+func importMovies(ctx context.Context, r movieReader, importer movieImporter, dryRun, upsert bool, out, errOut io.Writer) error {
+	run := services.NewImportRun(dryRun, upsert)
+	var created, updated, failed int
+	for {
+		mv, line, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		var rerr *recordError
+		if errors.As(err, &rerr) {
+			fmt.Fprintln(errOut, rerr)
+			failed++
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		ok, err := importer.ImportMovie(ctx, mv, run)
+		switch {
+		case err != nil:
+			fmt.Fprintf(errOut, "line %d: %s\n", line, status.Convert(err).Message())
+			failed++
+		case ok:
+			created++
+		default:
+			updated++
+		}
+	}
+
+	summary := fmt.Sprintf("Created %d, updated %d and failed %d movies", created, updated, failed)
+	if dryRun {
+		summary += " (dry run)"
+	}
+	fmt.Fprintln(out, summary)
+	if failed > 0 {
+		return fmt.Errorf("%d of the movies failed to import", failed)
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"github.com/AkashGit21/ms-project/lib/configuration"
 	"github.com/spf13/cobra"
 )
 
@@ -22,6 +23,42 @@ func init() {
 		},
 	}
 
+	addDatabaseFlags(runCmd.Flags())
+	runCmd.Flags().DurationVar(&configuration.DBTimeoutDefault, "db-timeout",
+		configuration.DBTimeoutDefault, "The maximum duration of a single database operation, 0 disables it")
+	runCmd.Flags().DurationVar(&configuration.PurgeRetentionDefault, "purge-retention",
+		configuration.PurgeRetentionDefault, "How long deleted movies and users can be restored before they are purged")
+	runCmd.Flags().DurationVar(&configuration.PurgeIntervalDefault, "purge-interval",
+		configuration.PurgeIntervalDefault, "How often the deleted movies and users are purged, 0 disables it")
+	runCmd.Flags().IntVar(&configuration.CacheSizeDefault, "cache-size",
+		configuration.CacheSizeDefault, "The most users, movies and lists cached in memory, 0 disables the cache")
+	runCmd.Flags().DurationVar(&configuration.CacheTTLDefault, "cache-ttl",
+		configuration.CacheTTLDefault, "How long a cached read is served, which bounds how stale it gets")
+	runCmd.Flags().DurationVar(&configuration.CacheNegativeTTLDefault, "cache-negative-ttl",
+		configuration.CacheNegativeTTLDefault, "How long a user or movie found missing is cached as missing, 0 disables it")
+	runCmd.Flags().DurationVar(&configuration.EventsIntervalDefault, "events-interval",
+		configuration.EventsIntervalDefault, "How often the pending domain events are delivered, 0 disables it")
+	runCmd.Flags().StringVar(&configuration.EventsFileDefault, "events-file",
+		configuration.EventsFileDefault, "A file to append the domain events to, one JSON object per line")
+	runCmd.Flags().StringVar(&configuration.EventsWebhookDefault, "events-webhook",
+		configuration.EventsWebhookDefault, "A URL to post every domain event to")
+	runCmd.Flags().StringVar(&configuration.EventsDeadLetterFileDefault, "events-dead-letter-file",
+		configuration.EventsDeadLetterFileDefault, "A file to append the domain events which could not be delivered to, one JSON object per line")
+	runCmd.Flags().StringVar(&configuration.VerifyEmailURLDefault, "verify-email-url",
+		configuration.VerifyEmailURLDefault, "The URL the users open to verify their email, given the token as the query parameter token")
+	runCmd.Flags().DurationVar(&configuration.VerifyEmailTTLDefault, "verify-email-ttl",
+		configuration.VerifyEmailTTLDefault, "How long the token verifying an email is valid")
+	runCmd.Flags().BoolVar(&configuration.RequireVerifiedEmailDefault, "require-verified-email",
+		configuration.RequireVerifiedEmailDefault, "Refuse the login of users whose email is not verified")
+	runCmd.Flags().StringVar(&configuration.MailFromDefault, "mail-from",
+		configuration.MailFromDefault, "The sender of the mails to the users")
+	runCmd.Flags().StringVar(&configuration.MailFileDefault, "mail-file",
+		configuration.MailFileDefault, "A file to append the mails to instead of sending them, when no SMTP server is given")
+	runCmd.Flags().StringVar(&configuration.SMTPAddrDefault, "smtp-addr",
+		configuration.SMTPAddrDefault, "The host:port of the SMTP server sending the mails, the password is read from SMTP_PASSWORD")
+	runCmd.Flags().StringVar(&configuration.SMTPUsernameDefault, "smtp-username",
+		configuration.SMTPUsernameDefault, "The user to authenticate to the SMTP server as, if any")
+
 	rootCmd.AddCommand(runCmd)
 }
 
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AkashGit21/ms-project/lib/fixtures"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	var reset bool
+	seedCmd := &cobra.Command{
+		Use:   "seed FIXTURES...",
+		Short: "Seeds the database with the users and movies of YAML or JSON fixtures",
+		Long: `Seeds the database with the users and movies of YAML or JSON fixtures.
+
+The records already in the database are left untouched, so seeding twice
+changes nothing. With --reset every user and movie of the database is removed
+first, so that it holds the fixtures only. The passwords of the fixtures are
+given in plain text and hashed when seeded.`,
+		Example:       "  ms-project seed --db-type sqlite --db-connection ./ms-project.db fixtures/dev.yaml",
+		Args:          cobra.MinimumNArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fx, err := fixtures.Load(args...)
+			if err != nil {
+				return err
+			}
+			dbhandler, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			stats, err := fixtures.Seed(context.Background(), dbhandler, fx, reset)
+			fmt.Fprintf(cmd.OutOrStdout(), "Removed %d, created %d and skipped %d records\n", stats.Removed, stats.Created, stats.Skipped)
+			return err
+		},
+	}
+	addDatabaseFlags(seedCmd.Flags())
+	seedCmd.Flags().BoolVar(&reset, "reset", false, "Remove every user and movie of the database first")
+
+	rootCmd.AddCommand(seedCmd)
+}
@@ -4,10 +4,10 @@ package moviepb
 
 import (
 	context "context"
-	empty "github.com/golang/protobuf/ptypes/empty"
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
 )
 
 // This is a compile-time assertion to ensure that this generated file
@@ -21,6 +21,9 @@ const _ = grpc.SupportPackageIsVersion7
 type MovieServiceClient interface {
 	// Lists all the movies
 	ListMovies(ctx context.Context, in *ListMoviesRequest, opts ...grpc.CallOption) (*ListMoviesResponse, error)
+	// Searches the movies by the words of their name, summary, cast, director
+	// and writers, best matches first
+	SearchMovies(ctx context.Context, in *SearchMoviesRequest, opts ...grpc.CallOption) (*SearchMoviesResponse, error)
 	// Fetches the movie with given ID
 	GetMovie(ctx context.Context, in *GetMovieRequest, opts ...grpc.CallOption) (*Movie, error)
 	// Inserts the movie with provided values, and returns the generated ID for movie
@@ -30,7 +33,15 @@ type MovieServiceClient interface {
 	// Update an already present Movie with new values
 	PartialUpdateMovie(ctx context.Context, in *PartialUpdateMovieRequest, opts ...grpc.CallOption) (*PartialUpdateMovieResponse, error)
 	// Delete an existing Record with given ID
-	DeleteMovie(ctx context.Context, in *DeleteMovieRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	DeleteMovie(ctx context.Context, in *DeleteMovieRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Restore a deleted Record with given ID, before it is purged
+	UndeleteMovie(ctx context.Context, in *UndeleteMovieRequest, opts ...grpc.CallOption) (*Movie, error)
+	// Streams the changes of the movies as they happen, beginning with the
+	// changes after the given resume token, if any
+	WatchMovies(ctx context.Context, in *WatchMoviesRequest, opts ...grpc.CallOption) (MovieService_WatchMoviesClient, error)
+	// Imports a stream of movies, validating each like CreateMovie does. The
+	// movies which fail are reported in the response, the others are stored.
+	ImportMovies(ctx context.Context, opts ...grpc.CallOption) (MovieService_ImportMoviesClient, error)
 }
 
 type movieServiceClient struct {
@@ -50,6 +61,15 @@ func (c *movieServiceClient) ListMovies(ctx context.Context, in *ListMoviesReque
 	return out, nil
 }
 
+func (c *movieServiceClient) SearchMovies(ctx context.Context, in *SearchMoviesRequest, opts ...grpc.CallOption) (*SearchMoviesResponse, error) {
+	out := new(SearchMoviesResponse)
+	err := c.cc.Invoke(ctx, "/movie.MovieService/SearchMovies", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *movieServiceClient) GetMovie(ctx context.Context, in *GetMovieRequest, opts ...grpc.CallOption) (*Movie, error) {
 	out := new(Movie)
 	err := c.cc.Invoke(ctx, "/movie.MovieService/GetMovie", in, out, opts...)
@@ -86,8 +106,8 @@ func (c *movieServiceClient) PartialUpdateMovie(ctx context.Context, in *Partial
 	return out, nil
 }
 
-func (c *movieServiceClient) DeleteMovie(ctx context.Context, in *DeleteMovieRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
-	out := new(empty.Empty)
+func (c *movieServiceClient) DeleteMovie(ctx context.Context, in *DeleteMovieRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
 	err := c.cc.Invoke(ctx, "/movie.MovieService/DeleteMovie", in, out, opts...)
 	if err != nil {
 		return nil, err
@@ -95,12 +115,90 @@ func (c *movieServiceClient) DeleteMovie(ctx context.Context, in *DeleteMovieReq
 	return out, nil
 }
 
+func (c *movieServiceClient) UndeleteMovie(ctx context.Context, in *UndeleteMovieRequest, opts ...grpc.CallOption) (*Movie, error) {
+	out := new(Movie)
+	err := c.cc.Invoke(ctx, "/movie.MovieService/UndeleteMovie", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *movieServiceClient) WatchMovies(ctx context.Context, in *WatchMoviesRequest, opts ...grpc.CallOption) (MovieService_WatchMoviesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &MovieService_ServiceDesc.Streams[0], "/movie.MovieService/WatchMovies", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &movieServiceWatchMoviesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type MovieService_WatchMoviesClient interface {
+	Recv() (*MovieEvent, error)
+	grpc.ClientStream
+}
+
+type movieServiceWatchMoviesClient struct {
+	grpc.ClientStream
+}
+
+func (x *movieServiceWatchMoviesClient) Recv() (*MovieEvent, error) {
+	m := new(MovieEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *movieServiceClient) ImportMovies(ctx context.Context, opts ...grpc.CallOption) (MovieService_ImportMoviesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &MovieService_ServiceDesc.Streams[1], "/movie.MovieService/ImportMovies", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &movieServiceImportMoviesClient{stream}
+	return x, nil
+}
+
+type MovieService_ImportMoviesClient interface {
+	Send(*ImportMoviesRequest) error
+	CloseAndRecv() (*ImportMoviesResponse, error)
+	grpc.ClientStream
+}
+
+type movieServiceImportMoviesClient struct {
+	grpc.ClientStream
+}
+
+func (x *movieServiceImportMoviesClient) Send(m *ImportMoviesRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *movieServiceImportMoviesClient) CloseAndRecv() (*ImportMoviesResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(ImportMoviesResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // MovieServiceServer is the server API for MovieService service.
 // All implementations must embed UnimplementedMovieServiceServer
 // for forward compatibility
 type MovieServiceServer interface {
 	// Lists all the movies
 	ListMovies(context.Context, *ListMoviesRequest) (*ListMoviesResponse, error)
+	// Searches the movies by the words of their name, summary, cast, director
+	// and writers, best matches first
+	SearchMovies(context.Context, *SearchMoviesRequest) (*SearchMoviesResponse, error)
 	// Fetches the movie with given ID
 	GetMovie(context.Context, *GetMovieRequest) (*Movie, error)
 	// Inserts the movie with provided values, and returns the generated ID for movie
@@ -110,7 +208,15 @@ type MovieServiceServer interface {
 	// Update an already present Movie with new values
 	PartialUpdateMovie(context.Context, *PartialUpdateMovieRequest) (*PartialUpdateMovieResponse, error)
 	// Delete an existing Record with given ID
-	DeleteMovie(context.Context, *DeleteMovieRequest) (*empty.Empty, error)
+	DeleteMovie(context.Context, *DeleteMovieRequest) (*emptypb.Empty, error)
+	// Restore a deleted Record with given ID, before it is purged
+	UndeleteMovie(context.Context, *UndeleteMovieRequest) (*Movie, error)
+	// Streams the changes of the movies as they happen, beginning with the
+	// changes after the given resume token, if any
+	WatchMovies(*WatchMoviesRequest, MovieService_WatchMoviesServer) error
+	// Imports a stream of movies, validating each like CreateMovie does. The
+	// movies which fail are reported in the response, the others are stored.
+	ImportMovies(MovieService_ImportMoviesServer) error
 	mustEmbedUnimplementedMovieServiceServer()
 }
 
@@ -121,6 +227,9 @@ type UnimplementedMovieServiceServer struct {
 func (UnimplementedMovieServiceServer) ListMovies(context.Context, *ListMoviesRequest) (*ListMoviesResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListMovies not implemented")
 }
+func (UnimplementedMovieServiceServer) SearchMovies(context.Context, *SearchMoviesRequest) (*SearchMoviesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchMovies not implemented")
+}
 func (UnimplementedMovieServiceServer) GetMovie(context.Context, *GetMovieRequest) (*Movie, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetMovie not implemented")
 }
@@ -133,9 +242,18 @@ func (UnimplementedMovieServiceServer) UpdateMovie(context.Context, *UpdateMovie
 func (UnimplementedMovieServiceServer) PartialUpdateMovie(context.Context, *PartialUpdateMovieRequest) (*PartialUpdateMovieResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method PartialUpdateMovie not implemented")
 }
-func (UnimplementedMovieServiceServer) DeleteMovie(context.Context, *DeleteMovieRequest) (*empty.Empty, error) {
+func (UnimplementedMovieServiceServer) DeleteMovie(context.Context, *DeleteMovieRequest) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteMovie not implemented")
 }
+func (UnimplementedMovieServiceServer) UndeleteMovie(context.Context, *UndeleteMovieRequest) (*Movie, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UndeleteMovie not implemented")
+}
+func (UnimplementedMovieServiceServer) WatchMovies(*WatchMoviesRequest, MovieService_WatchMoviesServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchMovies not implemented")
+}
+func (UnimplementedMovieServiceServer) ImportMovies(MovieService_ImportMoviesServer) error {
+	return status.Errorf(codes.Unimplemented, "method ImportMovies not implemented")
+}
 func (UnimplementedMovieServiceServer) mustEmbedUnimplementedMovieServiceServer() {}
 
 // UnsafeMovieServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -167,6 +285,24 @@ func _MovieService_ListMovies_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MovieService_SearchMovies_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchMoviesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MovieServiceServer).SearchMovies(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/movie.MovieService/SearchMovies",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MovieServiceServer).SearchMovies(ctx, req.(*SearchMoviesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _MovieService_GetMovie_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetMovieRequest)
 	if err := dec(in); err != nil {
@@ -257,6 +393,71 @@ func _MovieService_DeleteMovie_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MovieService_UndeleteMovie_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UndeleteMovieRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MovieServiceServer).UndeleteMovie(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/movie.MovieService/UndeleteMovie",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MovieServiceServer).UndeleteMovie(ctx, req.(*UndeleteMovieRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MovieService_WatchMovies_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchMoviesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MovieServiceServer).WatchMovies(m, &movieServiceWatchMoviesServer{stream})
+}
+
+type MovieService_WatchMoviesServer interface {
+	Send(*MovieEvent) error
+	grpc.ServerStream
+}
+
+type movieServiceWatchMoviesServer struct {
+	grpc.ServerStream
+}
+
+func (x *movieServiceWatchMoviesServer) Send(m *MovieEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _MovieService_ImportMovies_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(MovieServiceServer).ImportMovies(&movieServiceImportMoviesServer{stream})
+}
+
+type MovieService_ImportMoviesServer interface {
+	SendAndClose(*ImportMoviesResponse) error
+	Recv() (*ImportMoviesRequest, error)
+	grpc.ServerStream
+}
+
+type movieServiceImportMoviesServer struct {
+	grpc.ServerStream
+}
+
+func (x *movieServiceImportMoviesServer) SendAndClose(m *ImportMoviesResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *movieServiceImportMoviesServer) Recv() (*ImportMoviesRequest, error) {
+	m := new(ImportMoviesRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // MovieService_ServiceDesc is the grpc.ServiceDesc for MovieService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -268,6 +469,10 @@ var MovieService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ListMovies",
 			Handler:    _MovieService_ListMovies_Handler,
 		},
+		{
+			MethodName: "SearchMovies",
+			Handler:    _MovieService_SearchMovies_Handler,
+		},
 		{
 			MethodName: "GetMovie",
 			Handler:    _MovieService_GetMovie_Handler,
@@ -288,7 +493,22 @@ var MovieService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteMovie",
 			Handler:    _MovieService_DeleteMovie_Handler,
 		},
+		{
+			MethodName: "UndeleteMovie",
+			Handler:    _MovieService_UndeleteMovie_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchMovies",
+			Handler:       _MovieService_WatchMovies_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ImportMovies",
+			Handler:       _MovieService_ImportMovies_Handler,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "internal/proto-files/movie.proto",
 }
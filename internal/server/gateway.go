@@ -0,0 +1,41 @@
+package server
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+)
+
+// EtagViolation is the type of the precondition failure of a write with a
+// stale etag.
+const EtagViolation = "ETAG"
+
+// GatewayErrorHandler writes the errors of the REST gateway like the default
+// handler does, except for the writes with a stale etag, which are answered
+// with 412 Precondition Failed instead of 400 as HTTP clients expect of If-Match.
+func GatewayErrorHandler(ctx context.Context, mux *runtime.ServeMux, marshaler runtime.Marshaler,
+	w http.ResponseWriter, r *http.Request, err error) {
+	if isEtagMismatch(err) {
+		err = &runtime.HTTPStatusError{HTTPStatus: http.StatusPreconditionFailed, Err: err}
+	}
+	runtime.DefaultHTTPErrorHandler(ctx, mux, marshaler, w, r, err)
+}
+
+// isEtagMismatch reports whether err has a precondition failure of type EtagViolation.
+func isEtagMismatch(err error) bool {
+	for _, detail := range status.Convert(err).Details() {
+		failure, ok := detail.(*errdetails.PreconditionFailure)
+		if !ok {
+			continue
+		}
+		for _, v := range failure.GetViolations() {
+			if v.GetType() == EtagViolation {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGatewayErrorHandler(t *testing.T) {
+	etagFailure, _ := status.New(codes.FailedPrecondition, "stale etag").WithDetails(&errdetails.PreconditionFailure{
+		Violations: []*errdetails.PreconditionFailure_Violation{{Type: EtagViolation, Subject: "movie/id_0"}},
+	})
+	otherFailure, _ := status.New(codes.FailedPrecondition, "not ready").WithDetails(&errdetails.PreconditionFailure{
+		Violations: []*errdetails.PreconditionFailure_Violation{{Type: "STATE"}},
+	})
+
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{"etag_mismatch", etagFailure.Err(), http.StatusPreconditionFailed},
+		{"other_precondition", otherFailure.Err(), http.StatusBadRequest},
+		{"not_found", status.Error(codes.NotFound, "not found"), http.StatusNotFound},
+	}
+
+	mux := runtime.NewServeMux()
+	for _, tcase := range tests {
+		t.Run(tcase.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodDelete, "/v1/movies/id_0", nil)
+			GatewayErrorHandler(context.Background(), mux, &runtime.JSONPb{}, w, r, tcase.err)
+			if w.Code != tcase.expected {
+				t.Errorf("GatewayErrorHandler: want status %d, got %d", tcase.expected, w.Code)
+			}
+		})
+	}
+}
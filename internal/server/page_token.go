@@ -2,11 +2,14 @@ package server
 
 import (
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"hash/fnv"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/AkashGit21/ms-project/lib/persistence"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -22,10 +25,17 @@ func TokenGeneratorWithSalt(salt string) TokenGenerator {
 	return &tokenGenerator{salt}
 }
 
-// TokenGenerator generates a page token for a given index.
+// TokenGenerator generates a page token for a given index, or for the
+// position of the last record of a page. A cursor or offset token only
+// applies to the query it was generated for, which describes e.g. the filter
+// and order of the list, or the terms of a search.
 type TokenGenerator interface {
 	ForIndex(int) string
 	GetIndex(string) (int, error)
+	ForCursor(query string, c persistence.Cursor) string
+	GetCursor(token string, query string) (persistence.Cursor, error)
+	ForOffset(query string, i int) string
+	GetOffset(token string, query string) (int, error)
 }
 
 // InvalidTokenErr is the error returned if the token provided is not
@@ -34,6 +44,12 @@ var InvalidTokenErr = status.Errorf(
 	codes.InvalidArgument,
 	"The field `page_token` is invalid.")
 
+// TokenQueryMismatchErr is the error returned if the token provided was
+// generated for another query, e.g. with another `filter` or `order_by`.
+var TokenQueryMismatchErr = status.Errorf(
+	codes.InvalidArgument,
+	"The field `page_token` does not match the other parameters of the request, e.g. `filter` or `order_by`.")
+
 type tokenGenerator struct {
 	salt string
 }
@@ -59,8 +75,129 @@ func (t *tokenGenerator) GetIndex(s string) (int, error) {
 	}
 
 	i, err := strconv.Atoi(strings.TrimPrefix(string(bs), t.salt))
-	if err != nil {
+	if err != nil || i < 0 {
 		return -1, InvalidTokenErr
 	}
 	return i, nil
 }
+
+// cursorToken is the content of a page token for a persistence.Cursor.
+type cursorToken struct {
+	// Query is a hash of the query which the token applies to
+	Query  string       `json:"q"`
+	Values []tokenValue `json:"v,omitempty"`
+	Key    string       `json:"k"`
+}
+
+// tokenValue is a value of a cursor: either a string, or a time in RFC 3339
+// format which is empty for the zero time.
+type tokenValue struct {
+	String *string `json:"s,omitempty"`
+	Time   *string `json:"t,omitempty"`
+}
+
+func (t *tokenGenerator) ForCursor(query string, c persistence.Cursor) string {
+	tok := cursorToken{Query: queryHash(query), Key: c.Key}
+	for _, v := range c.Values {
+		var tv tokenValue
+		switch v := v.(type) {
+		case string:
+			tv.String = &v
+		case time.Time:
+			s := ""
+			if !v.IsZero() {
+				s = v.Format(time.RFC3339Nano)
+			}
+			tv.Time = &s
+		}
+		tok.Values = append(tok.Values, tv)
+	}
+	bs, _ := json.Marshal(tok)
+	return base64.StdEncoding.EncodeToString(append([]byte(t.salt), bs...))
+}
+
+func (t *tokenGenerator) GetCursor(s string, query string) (persistence.Cursor, error) {
+	if s == "" {
+		return persistence.Cursor{}, nil
+	}
+
+	bs, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return persistence.Cursor{}, InvalidTokenErr
+	}
+
+	if !strings.HasPrefix(string(bs), t.salt) {
+		return persistence.Cursor{}, InvalidTokenErr
+	}
+
+	var tok cursorToken
+	if err := json.Unmarshal(bs[len(t.salt):], &tok); err != nil || tok.Key == "" {
+		return persistence.Cursor{}, InvalidTokenErr
+	}
+	if tok.Query != queryHash(query) {
+		return persistence.Cursor{}, TokenQueryMismatchErr
+	}
+
+	c := persistence.Cursor{Key: tok.Key}
+	for _, tv := range tok.Values {
+		switch {
+		case tv.String != nil:
+			c.Values = append(c.Values, *tv.String)
+		case tv.Time != nil && *tv.Time == "":
+			c.Values = append(c.Values, time.Time{})
+		case tv.Time != nil:
+			ts, err := time.Parse(time.RFC3339Nano, *tv.Time)
+			if err != nil {
+				return persistence.Cursor{}, InvalidTokenErr
+			}
+			c.Values = append(c.Values, ts)
+		default:
+			return persistence.Cursor{}, InvalidTokenErr
+		}
+	}
+	return c, nil
+}
+
+// offsetToken is the content of a page token for an offset into the results
+// of a query.
+type offsetToken struct {
+	// Query is a hash of the query which the token applies to
+	Query  string `json:"q"`
+	Offset *int   `json:"o"`
+}
+
+func (t *tokenGenerator) ForOffset(query string, i int) string {
+	bs, _ := json.Marshal(offsetToken{Query: queryHash(query), Offset: &i})
+	return base64.StdEncoding.EncodeToString(append([]byte(t.salt), bs...))
+}
+
+func (t *tokenGenerator) GetOffset(s string, query string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+
+	bs, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return -1, InvalidTokenErr
+	}
+
+	if !strings.HasPrefix(string(bs), t.salt) {
+		return -1, InvalidTokenErr
+	}
+
+	var tok offsetToken
+	if err := json.Unmarshal(bs[len(t.salt):], &tok); err != nil || tok.Offset == nil || *tok.Offset < 0 {
+		return -1, InvalidTokenErr
+	}
+	if tok.Query != queryHash(query) {
+		return -1, TokenQueryMismatchErr
+	}
+	return *tok.Offset, nil
+}
+
+// queryHash keeps tokens short whatever the length of the query.
+func queryHash(query string) string {
+	h := fnv.New64a()
+	h.Write([]byte(query))
+	return strconv.FormatUint(h.Sum64(), 36)
+}
@@ -2,7 +2,11 @@ package server
 
 import (
 	"encoding/base64"
+	"reflect"
 	"testing"
+	"time"
+
+	"github.com/AkashGit21/ms-project/lib/persistence"
 )
 
 func Test_tokenGenerator_ForIndex(t *testing.T) {
@@ -39,6 +43,14 @@ func Test_tokenGenerator_GetIndex_invalidIndex(t *testing.T) {
 	}
 }
 
+func Test_tokenGenerator_GetIndex_negativeIndex(t *testing.T) {
+	tok := TokenGeneratorWithSalt("salt")
+	_, err := tok.GetIndex(base64.StdEncoding.EncodeToString([]byte("salt-1")))
+	if err != InvalidTokenErr {
+		t.Errorf("GetIndex: want %v for a negative index, got %v", InvalidTokenErr, err)
+	}
+}
+
 func Test_tokenGenerator_GetIndex(t *testing.T) {
 	tok := TokenGeneratorWithSalt("salt")
 	i, err := tok.GetIndex(base64.StdEncoding.EncodeToString([]byte("salt1")))
@@ -49,3 +61,56 @@ func Test_tokenGenerator_GetIndex(t *testing.T) {
 		t.Errorf("GetIndex: want 1, got %d", i)
 	}
 }
+
+func Test_tokenGenerator_GetCursor(t *testing.T) {
+	tok := TokenGeneratorWithSalt("salt")
+	cursors := []persistence.Cursor{
+		{Values: []interface{}{time.Unix(1633046400, 123).UTC()}, Key: "id_1"},
+		{Values: []interface{}{"Nolan", time.Time{}}, Key: "id_2"},
+		{Key: "harry_potter"},
+	}
+	for _, want := range cursors {
+		got, err := tok.GetCursor(tok.ForCursor("name desc", want), "name desc")
+		if err != nil {
+			t.Fatalf("GetCursor: unexpected err %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("GetCursor: want %v, got %v", want, got)
+		}
+	}
+
+	if c, err := tok.GetCursor("", "name desc"); err != nil || !c.IsZero() {
+		t.Errorf("GetCursor: want zero cursor for empty token, got %v, %v", c, err)
+	}
+	if _, err := tok.GetCursor(TokenGeneratorWithSalt("other").ForCursor("", cursors[0]), ""); err == nil {
+		t.Error("GetCursor: want error for token of other salt.")
+	}
+	if _, err := tok.GetCursor(tok.ForIndex(1), ""); err == nil {
+		t.Error("GetCursor: want error for index token.")
+	}
+	if _, err := tok.GetCursor(tok.ForCursor("name desc", cursors[0]), "name"); err != TokenQueryMismatchErr {
+		t.Errorf("GetCursor: want TokenQueryMismatchErr for token of other query, got %v", err)
+	}
+}
+
+func Test_tokenGenerator_GetOffset(t *testing.T) {
+	tok := TokenGeneratorWithSalt("salt")
+	if i, err := tok.GetOffset(tok.ForOffset("nolan dreams", 12), "nolan dreams"); err != nil || i != 12 {
+		t.Errorf("GetOffset: want 12, got %d, %v", i, err)
+	}
+	if i, err := tok.GetOffset("", "nolan dreams"); err != nil || i != 0 {
+		t.Errorf("GetOffset: want 0 for empty token, got %d, %v", i, err)
+	}
+	if _, err := tok.GetOffset(tok.ForIndex(1), "nolan dreams"); err != InvalidTokenErr {
+		t.Errorf("GetOffset: want InvalidTokenErr for index token, got %v", err)
+	}
+	if _, err := tok.GetOffset(tok.ForCursor("nolan dreams", persistence.Cursor{Key: "id_1"}), "nolan dreams"); err != InvalidTokenErr {
+		t.Errorf("GetOffset: want InvalidTokenErr for cursor token, got %v", err)
+	}
+	if _, err := tok.GetOffset(tok.ForOffset("nolan dreams", -1), "nolan dreams"); err != InvalidTokenErr {
+		t.Errorf("GetOffset: want InvalidTokenErr for a negative offset, got %v", err)
+	}
+	if _, err := tok.GetOffset(tok.ForOffset("nolan dreams", 12), "magicians"); err != TokenQueryMismatchErr {
+		t.Errorf("GetOffset: want TokenQueryMismatchErr for token of other query, got %v", err)
+	}
+}
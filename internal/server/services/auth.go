@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"errors"
 	"log"
 
 	authpb "github.com/AkashGit21/ms-project/internal/grpc/auth"
@@ -21,16 +22,20 @@ var (
 type authServer struct {
 	authpb.UnimplementedAuthServiceServer
 
-	dbhandler     persistence.DatabaseHandler
+	credentials   persistence.CredentialStore
 	identityStore ReadOnlyIdentityServer
 	JWT           *server.JWTManager
+
+	// RequireVerifiedEmail refuses the login of the users whose email is not
+	// verified yet
+	RequireVerifiedEmail bool
 }
 
-// NewAuthServer returns a new auth server
-func NewAuthServer(is *identityServer) *authServer {
+// NewAuthServer returns a new auth server, which checks the passwords with the given store
+func NewAuthServer(is *identityServer, credentials persistence.CredentialStore) *authServer {
 	return &authServer{
 		identityStore: is,
-		dbhandler:     is.dbhandler,
+		credentials:   credentials,
 	}
 }
 
@@ -40,15 +45,28 @@ func (as *authServer) Login(ctx context.Context, req *authpb.LoginRequest) (*aut
 	log.Println("Beginning of Login! ", req)
 	interceptors.CURRENT_USERNAME = req.GetUsername()
 	user, err := as.identityStore.GetUser(ctx, &identitypb.GetUserRequest{Username: req.GetUsername()})
-	if err != nil {
+	if status.Code(err) == codes.NotFound {
 		log.Println("Error: ", err)
 		return nil, status.Errorf(codes.InvalidArgument, "incorrect username/password!")
+	} else if err != nil {
+		return nil, err
 	}
 
-	if !as.dbhandler.Authenticate(req.GetUsername(), req.GetPassword()) {
+	// An unknown user is reported like a wrong password, not to reveal which usernames exist
+	ok, err := as.credentials.Authenticate(ctx, req.GetUsername(), req.GetPassword())
+	if err != nil && !errors.Is(err, persistence.ErrNotFound) {
+		return nil, toStatus(err)
+	}
+	if !ok {
 		return nil, status.Errorf(codes.InvalidArgument, "incorrect username/password!")
 	}
 
+	// Checked once the password matched, so that others learn nothing of the email
+	if as.RequireVerifiedEmail && user.GetEmailVerifyTime() == nil {
+		return nil, status.Errorf(codes.FailedPrecondition,
+			"the email of the user is not verified! Open the link mailed to them, or ask for a new one")
+	}
+
 	token, err := as.JWT.GenerateToken(user)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "cannot generate access token! %v", err)
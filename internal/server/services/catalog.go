@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+
+	moviepb "github.com/AkashGit21/ms-project/internal/grpc/movie"
+	"github.com/AkashGit21/ms-project/internal/server"
+	"github.com/AkashGit21/ms-project/lib/persistence"
+	"github.com/golang/protobuf/ptypes"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ImportMovies stores the streamed movies one by one, so that a failing movie
+// does not stop the others. The options are those of the first request, a
+// later request setting other options is rejected.
+func (ms *movieServer) ImportMovies(stream moviepb.MovieService_ImportMoviesServer) error {
+	log.Println("[DEBUG] Beginning ImportMovies!")
+
+	var run *ImportRun
+	resp := &moviepb.ImportMoviesResponse{}
+	for index := int32(1); ; index++ {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			log.Println("[DEBUG] End ImportMovies!")
+			return stream.SendAndClose(resp)
+		}
+		if err != nil {
+			return err
+		}
+		if index == 1 {
+			run = NewImportRun(req.GetDryRun(), req.GetUpsert())
+		}
+
+		var created bool
+		if (req.GetDryRun() && !run.DryRun) || (req.GetUpsert() && !run.Upsert) {
+			err = status.Errorf(codes.InvalidArgument, "The options `dry_run` and `upsert` differ from those of the first request!")
+		} else {
+			created, err = ms.ImportMovie(stream.Context(), req.GetMovie(), run)
+		}
+		switch {
+		case status.Code(err) == codes.Canceled || status.Code(err) == codes.DeadlineExceeded:
+			return err
+		case err != nil:
+			resp.Errors = append(resp.Errors, &moviepb.ImportError{
+				Index:   index,
+				Id:      req.GetMovie().GetId(),
+				Message: status.Convert(err).Message(),
+			})
+		case created:
+			resp.Created++
+		default:
+			resp.Updated++
+		}
+	}
+}
+
+// ImportRun holds the options of an import, and the movies a dry run accepted
+// so far, so that it reports the IDs and names repeated within the import as
+// the database would.
+type ImportRun struct {
+	DryRun bool
+	Upsert bool
+
+	// ids maps the IDs of the movies accepted to the MovieNameKey of their
+	// name, names the other way round
+	ids   map[string]string
+	names map[string]string
+}
+
+// NewImportRun returns the run of an import with the given options.
+func NewImportRun(dryRun, upsert bool) *ImportRun {
+	return &ImportRun{
+		DryRun: dryRun,
+		Upsert: upsert,
+		ids:    map[string]string{},
+		names:  map[string]string{},
+	}
+}
+
+// ImportMovie validates mv like CreateMovie does and stores it, unless the
+// run is a dry run, which only checks that the ID and name of mv are free.
+// A movie without an ID is created with a new one. A movie with an ID
+// is created with it, or replaces the stored movie of that ID if the run
+// upserts. The output only fields other than the ID are ignored.
+//
+// It returns whether the movie was created rather than replaced, and fails
+// with a status error.
+func (ms *movieServer) ImportMovie(ctx context.Context, mv *moviepb.Movie, run *ImportRun) (bool, error) {
+	if mv == nil {
+		return false, status.Errorf(codes.InvalidArgument, "Input is not valid! The movie is missing.")
+	}
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	objID := mv.GetId()
+	if valid, err := ms.isValidMovie(mv); !valid {
+		return false, status.Errorf(codes.InvalidArgument, "Input is not valid! %v", err.Error())
+	}
+
+	_, exists := run.ids[objID]
+	if exists && !run.Upsert {
+		return false, status.Errorf(codes.AlreadyExists, "Movie Record with ID:%v already exists!", objID)
+	}
+	if objID != "" && !exists {
+		res, err := ms.movies.FindMovieByID(ctx, objID)
+		if err != nil && !errors.Is(err, persistence.ErrNotFound) {
+			return false, toStatus(err)
+		}
+		if err == nil {
+			if !run.Upsert {
+				return false, status.Errorf(codes.AlreadyExists, "Movie Record with ID:%v already exists!", objID)
+			}
+			if res.DeleteTime != nil {
+				return false, status.Errorf(codes.FailedPrecondition, "Movie Record with ID:%v is deleted!", objID)
+			}
+			exists = true
+		}
+	} else if objID == "" {
+		objID = server.GenerateUUID()
+	}
+
+	if run.DryRun {
+		// The name is only checked ahead for a dry run, the database checks
+		// it as the movie is stored
+		return !exists, ms.checkImportName(ctx, run, objID, persistence.NormalizeMovieName(mv.GetName()))
+	}
+
+	now := ptypes.TimestampNow()
+	movieObject := persistence.Movie{
+		Id:         objID,
+		Name:       persistence.NormalizeMovieName(mv.GetName()),
+		Summary:    mv.GetSummary(),
+		Cast:       mv.GetCast(),
+		Tags:       toPersistenceTags(mv.GetTags()),
+		Director:   mv.GetDirector(),
+		Writers:    mv.GetWriters(),
+		Active:     true,
+		CreateTime: now,
+		UpdateTime: now,
+	}
+
+	if exists {
+		if _, err := ms.movies.UpdateMovieByID(ctx, objID, "", movieObject); err != nil {
+			return false, toStatus(err)
+		}
+		return false, nil
+	}
+	if _, err := ms.movies.AddMovie(ctx, movieObject); err != nil {
+		return false, toStatus(err)
+	}
+	return true, nil
+}
+
+// checkImportName fails unless name is free for the movie with ID id, neither
+// accepted earlier in the dry run nor stored for another movie, and records
+// the movie in the run.
+func (ms *movieServer) checkImportName(ctx context.Context, run *ImportRun, id, name string) error {
+	key := persistence.MovieNameKey(name)
+	holder, ok := run.names[key]
+	if !ok {
+		stored, err := ms.movies.FindMovieByName(ctx, name)
+		if err != nil && !errors.Is(err, persistence.ErrNotFound) {
+			return toStatus(err)
+		}
+		// A stored movie renamed earlier in the run frees its name
+		if _, renamed := run.ids[stored.Id]; err == nil && !renamed {
+			holder, ok = stored.Id, true
+		}
+	}
+	if ok && holder != id {
+		return toStatus(persistence.AlreadyExistsAs("movie", "name", name, holder))
+	}
+
+	if previous, ok := run.ids[id]; ok {
+		delete(run.names, previous)
+	}
+	run.ids[id], run.names[key] = key, id
+	return nil
+}
+
+// exportPageSize is the number of movies read from the database at once by ExportMovies.
+const exportPageSize = 100
+
+// ExportMovies passes every stored movie to fn, in the default order, reading
+// them a page at a time so that a large catalogue is never held in memory.
+// Deleted movies are passed too if showDeleted is set. It stops at the first
+// error of fn, and returns it.
+func (ms *movieServer) ExportMovies(ctx context.Context, showDeleted bool, fn func(*moviepb.Movie) error) error {
+	order := persistence.DefaultOrder
+	var after persistence.Cursor
+	for {
+		movies, err := ms.movies.FindAllMovies(ctx, nil, showDeleted, order, after, exportPageSize)
+		if err != nil {
+			return toStatus(err)
+		}
+		for _, mv := range movies {
+			if err := fn(toMoviePB(mv)); err != nil {
+				return err
+			}
+		}
+		if len(movies) < exportPageSize {
+			return nil
+		}
+		after = order.MovieCursor(movies[len(movies)-1])
+	}
+}
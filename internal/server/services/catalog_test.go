@@ -0,0 +1,214 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"testing"
+
+	moviepb "github.com/AkashGit21/ms-project/internal/grpc/movie"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// importStream is a MovieService_ImportMoviesServer receiving the given requests.
+type importStream struct {
+	ctx  context.Context
+	reqs []*moviepb.ImportMoviesRequest
+	resp *moviepb.ImportMoviesResponse
+
+	grpc.ServerStream
+}
+
+func (is *importStream) Context() context.Context {
+	return is.ctx
+}
+
+func (is *importStream) Recv() (*moviepb.ImportMoviesRequest, error) {
+	if len(is.reqs) == 0 {
+		return nil, io.EOF
+	}
+	req := is.reqs[0]
+	is.reqs = is.reqs[1:]
+	return req, nil
+}
+
+func (is *importStream) SendAndClose(resp *moviepb.ImportMoviesResponse) error {
+	is.resp = resp
+	return nil
+}
+
+func TestImportMovies(t *testing.T) {
+	ms := getMovieServer()
+	ctx := context.Background()
+
+	existing, err := ms.CreateMovie(ctx, &moviepb.CreateMovieRequest{Movie: &moviepb.Movie{
+		Name: "test_import_existing", Summary: "test_import_summary", Tags: []moviepb.Tag{moviepb.Tag_Action}}})
+	if err != nil {
+		t.Fatalf("Failed to create pre-requisite object! %v", err)
+	}
+
+	movies := []*moviepb.Movie{
+		{Name: "test_import_movie", Summary: "test_import_summary", Tags: []moviepb.Tag{moviepb.Tag_Action}},
+		{Id: "test_import_id", Name: "test_import_movie2", Summary: "test_import_summary", Tags: []moviepb.Tag{moviepb.Tag_Action}},
+		{Id: existing.GetId(), Name: "test_import_replaced", Summary: "test_import_summary", Tags: []moviepb.Tag{moviepb.Tag_Action}},
+		{Name: "", Summary: "test_import_summary", Tags: []moviepb.Tag{moviepb.Tag_Action}},
+		nil,
+	}
+	tests := []struct {
+		name        string
+		dryRun      bool
+		upsert      bool
+		wantCreated int32
+		wantUpdated int32
+		wantErrors  []int32
+	}{
+		{name: "dry run", dryRun: true, upsert: true, wantCreated: 2, wantUpdated: 1, wantErrors: []int32{4, 5}},
+		{name: "without upsert", wantCreated: 2, wantErrors: []int32{3, 4, 5}},
+		// The movie without an id is created again, with a name already taken
+		{name: "upsert", upsert: true, wantUpdated: 2, wantErrors: []int32{1, 4, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The options are read from the first request, the others may repeat them
+			var reqs []*moviepb.ImportMoviesRequest
+			for _, mv := range movies {
+				reqs = append(reqs, &moviepb.ImportMoviesRequest{Movie: mv})
+			}
+			reqs[0].DryRun, reqs[0].Upsert = tt.dryRun, tt.upsert
+			reqs[1].DryRun, reqs[1].Upsert = tt.dryRun, tt.upsert
+
+			stream := &importStream{ctx: ctx, reqs: reqs}
+			if err := ms.ImportMovies(stream); err != nil {
+				t.Fatalf("ImportMovies: unexpected err %v", err)
+			}
+			resp := stream.resp
+			if resp.GetCreated() != tt.wantCreated || resp.GetUpdated() != tt.wantUpdated {
+				t.Errorf("ImportMovies: want %d created and %d updated, got %v", tt.wantCreated, tt.wantUpdated, resp)
+			}
+			var gotErrors []int32
+			for _, e := range resp.GetErrors() {
+				gotErrors = append(gotErrors, e.GetIndex())
+			}
+			if len(gotErrors) != len(tt.wantErrors) {
+				t.Fatalf("ImportMovies: want errors at %v, got %v", tt.wantErrors, resp.GetErrors())
+			}
+			for i := range gotErrors {
+				if gotErrors[i] != tt.wantErrors[i] {
+					t.Errorf("ImportMovies: want errors at %v, got %v", tt.wantErrors, resp.GetErrors())
+				}
+			}
+		})
+	}
+
+	// The movie with an id kept it, the existing one was replaced
+	if mv, err := ms.GetMovie(ctx, &moviepb.GetMovieRequest{Id: "test_import_id"}); err != nil || mv.GetName() != "test_import_movie2" {
+		t.Errorf("GetMovie: want the imported movie, got %v, %v", mv, err)
+	}
+	if mv, err := ms.GetMovie(ctx, &moviepb.GetMovieRequest{Id: existing.GetId()}); err != nil || mv.GetName() != "test_import_replaced" {
+		t.Errorf("GetMovie: want the replaced movie, got %v, %v", mv, err)
+	}
+
+	// A later request setting other options is rejected
+	stream := &importStream{ctx: ctx, reqs: []*moviepb.ImportMoviesRequest{
+		{Movie: &moviepb.Movie{Name: "test_import_options", Summary: "test_import_summary", Tags: []moviepb.Tag{moviepb.Tag_Action}}},
+		{Movie: &moviepb.Movie{Id: "test_import_id", Name: "test_import_options2", Summary: "test_import_summary", Tags: []moviepb.Tag{moviepb.Tag_Action}}, Upsert: true},
+	}}
+	if err := ms.ImportMovies(stream); err != nil {
+		t.Fatalf("ImportMovies: unexpected err %v", err)
+	}
+	if resp := stream.resp; resp.GetCreated() != 1 || len(resp.GetErrors()) != 1 || resp.GetErrors()[0].GetIndex() != 2 {
+		t.Errorf("ImportMovies: want the second movie rejected, got %v", resp)
+	}
+	if mv, _ := ms.GetMovie(ctx, &moviepb.GetMovieRequest{Id: "test_import_id"}); mv.GetName() != "test_import_movie2" {
+		t.Errorf("GetMovie: want the movie kept, got %v", mv)
+	}
+
+	// A dry run reports the names already taken, but by the replaced movie itself
+	taken := &moviepb.Movie{Name: "TEST_IMPORT_MOVIE2", Summary: "test_import_summary", Tags: []moviepb.Tag{moviepb.Tag_Action}}
+	if _, err := ms.ImportMovie(ctx, taken, NewImportRun(true, false)); status.Code(err) != codes.AlreadyExists {
+		t.Errorf("ImportMovie: want AlreadyExists for a name taken, got %v", err)
+	}
+	taken.Id = "test_import_id"
+	if created, err := ms.ImportMovie(ctx, taken, NewImportRun(true, true)); err != nil || created {
+		t.Errorf("ImportMovie: want the movie replaced, got %v, %v", created, err)
+	}
+
+	// A dry run reports the ids and names repeated within the import, like the
+	// database would, but by the movies replaced
+	tags := []moviepb.Tag{moviepb.Tag_Action}
+	stream = &importStream{ctx: ctx, reqs: []*moviepb.ImportMoviesRequest{
+		{Movie: &moviepb.Movie{Name: "test_import_twice", Summary: "test_import_summary", Tags: tags}, DryRun: true},
+		{Movie: &moviepb.Movie{Name: "TEST_IMPORT_Twice", Summary: "test_import_summary", Tags: tags}},
+		{Movie: &moviepb.Movie{Id: "test_import_new", Name: "test_import_new", Summary: "test_import_summary", Tags: tags}},
+		{Movie: &moviepb.Movie{Id: "test_import_new", Name: "test_import_new2", Summary: "test_import_summary", Tags: tags}},
+		{Movie: &moviepb.Movie{Id: "test_import_id", Name: "test_import_renamed", Summary: "test_import_summary", Tags: tags}},
+	}}
+	if err := ms.ImportMovies(stream); err != nil {
+		t.Fatalf("ImportMovies: unexpected err %v", err)
+	}
+	if resp := stream.resp; resp.GetCreated() != 2 || len(resp.GetErrors()) != 3 || resp.GetErrors()[0].GetIndex() != 2 ||
+		resp.GetErrors()[1].GetIndex() != 4 || resp.GetErrors()[2].GetIndex() != 5 {
+		t.Errorf("ImportMovies: want the repeated name and id rejected, got %v", resp)
+	}
+	run := NewImportRun(true, true)
+	for _, mv := range []*moviepb.Movie{
+		{Id: "test_import_new", Name: "test_import_new", Summary: "test_import_summary", Tags: tags},
+		{Id: "test_import_new", Name: "test_import_new2", Summary: "test_import_summary", Tags: tags},
+		// The stored movie renamed frees its name
+		{Id: "test_import_id", Name: "test_import_renamed", Summary: "test_import_summary", Tags: tags},
+		{Name: "test_import_movie2", Summary: "test_import_summary", Tags: tags},
+		{Name: "Test_Import_New", Summary: "test_import_summary", Tags: tags},
+	} {
+		if _, err := ms.ImportMovie(ctx, mv, run); err != nil {
+			t.Errorf("ImportMovie(%v): unexpected err %v", mv.GetName(), err)
+		}
+	}
+	if _, err := ms.ImportMovie(ctx, &moviepb.Movie{Name: "TEST_IMPORT_NEW2", Summary: "test_import_summary", Tags: tags}, run); status.Code(err) != codes.AlreadyExists {
+		t.Errorf("ImportMovie: want AlreadyExists for a name taken in the run, got %v", err)
+	}
+
+	// A deleted movie is not replaced
+	if _, err := ms.DeleteMovie(ctx, &moviepb.DeleteMovieRequest{Id: existing.GetId()}); err != nil {
+		t.Fatalf("DeleteMovie: unexpected err %v", err)
+	}
+	if _, err := ms.ImportMovie(ctx, movies[2], NewImportRun(false, true)); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("ImportMovie: want FailedPrecondition for a deleted movie, got %v", err)
+	}
+}
+
+func TestExportMovies(t *testing.T) {
+	ms := getMovieServer()
+	ctx := context.Background()
+
+	// More movies than a page
+	want := exportPageSize + 2
+	for i := 0; i < want; i++ {
+		mv := &moviepb.Movie{Name: "test_export_movie_" + strconv.Itoa(i), Summary: "test_export_summary", Tags: []moviepb.Tag{moviepb.Tag_Action}}
+		if _, err := ms.ImportMovie(ctx, mv, NewImportRun(false, false)); err != nil {
+			t.Fatalf("Failed to create pre-requisite object! %v", err)
+		}
+	}
+
+	ids := map[string]bool{}
+	err := ms.ExportMovies(ctx, false, func(mv *moviepb.Movie) error {
+		ids[mv.GetId()] = true
+		return nil
+	})
+	if err != nil || len(ids) != want {
+		t.Errorf("ExportMovies: want %d distinct movies, got %d, %v", want, len(ids), err)
+	}
+
+	// An error of fn stops the export
+	errStop := errors.New("stop")
+	count := 0
+	err = ms.ExportMovies(ctx, false, func(mv *moviepb.Movie) error {
+		count++
+		return errStop
+	})
+	if err != errStop || count != 1 {
+		t.Errorf("ExportMovies: want the error of fn after 1 movie, got %v after %d", err, count)
+	}
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/AkashGit21/ms-project/internal/server"
+	"github.com/AkashGit21/ms-project/lib/persistence"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// retryDelay is suggested to clients when the database is temporarily unavailable
+const retryDelay = 1 * time.Second
+
+// ifMatchKey is the metadata key of the If-Match header forwarded by the gateway
+const ifMatchKey = "grpcgateway-if-match"
+
+// toStatus translates an error of the persistence layer into a gRPC status
+// error carrying google.rpc error details. Errors which already are a status
+// are returned as they are.
+func toStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	var perr *persistence.Error
+	errors.As(err, &perr)
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, "the database did not respond in time")
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, "the request was cancelled")
+	case errors.Is(err, persistence.ErrNotFound):
+		return withDetails(status.New(codes.NotFound, err.Error()), resourceInfo(perr))
+	case errors.Is(err, persistence.ErrAlreadyExists):
+		return withDetails(status.New(codes.AlreadyExists, err.Error()), resourceInfo(perr))
+	case errors.Is(err, persistence.ErrEtagMismatch):
+		return etagMismatch(perr)
+	case errors.Is(err, persistence.ErrConflict):
+		return withDetails(status.New(codes.Aborted, "the operation conflicted with a concurrent one, please retry"),
+			&errdetails.RetryInfo{RetryDelay: ptypes.DurationProto(retryDelay)})
+	case errors.Is(err, persistence.ErrUnavailable):
+		log.Println("[ERROR] database unavailable: ", err)
+		return withDetails(status.New(codes.Unavailable, "the database is unavailable, please retry"),
+			&errdetails.RetryInfo{RetryDelay: ptypes.DurationProto(retryDelay)})
+	}
+
+	// Do not leak internal details of the database to the client
+	log.Println("[ERROR] unexpected database error: ", err)
+	return status.Error(codes.Internal, "internal error")
+}
+
+// invalidArgument returns an InvalidArgument status for the request field,
+// describing err as a violation of it.
+func invalidArgument(field string, err error) error {
+	return withDetails(status.Newf(codes.InvalidArgument, "invalid %s: %v", field, err),
+		&errdetails.BadRequest{FieldViolations: []*errdetails.BadRequest_FieldViolation{{
+			Field:       field,
+			Description: err.Error(),
+		}}})
+}
+
+// etagMismatch returns a FailedPrecondition status for a write with a stale
+// etag. Its violation of type EtagViolation lets the gateway answer with 412.
+func etagMismatch(perr *persistence.Error) error {
+	violation := &errdetails.PreconditionFailure_Violation{
+		Type:        server.EtagViolation,
+		Description: "the etag is stale, read the record again and retry with its current etag",
+	}
+	msg := "the etag does not match the current one"
+	if perr != nil {
+		violation.Subject = perr.Resource + "/" + perr.Value
+		msg = perr.Error()
+	}
+	return withDetails(status.New(codes.FailedPrecondition, msg),
+		&errdetails.PreconditionFailure{Violations: []*errdetails.PreconditionFailure_Violation{violation}})
+}
+
+// requestEtag returns the etag a write request was made with: the etag field
+// of the request if set, or else the If-Match header forwarded by the gateway.
+// The wildcard If-Match matches any etag, like an empty one.
+func requestEtag(ctx context.Context, etag string) string {
+	if etag != "" {
+		return etag
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, v := range md.Get(ifMatchKey) {
+		v = strings.TrimPrefix(strings.TrimSpace(v), "W/")
+		if v = strings.Trim(v, `"`); v != "*" {
+			return v
+		}
+	}
+	return ""
+}
+
+// resourceInfo describes the record a persistence error is about, if known.
+// A value already taken is described by the ID of the record holding it, when
+// the database tells it, so that clients can e.g. link to the existing movie.
+func resourceInfo(perr *persistence.Error) proto.Message {
+	if perr == nil || perr.Resource == "" {
+		return nil
+	}
+	if perr.ID != "" {
+		return &errdetails.ResourceInfo{
+			ResourceType: perr.Resource,
+			ResourceName: perr.ID,
+			Description:  fmt.Sprintf("%s `%s` is taken", perr.Field, perr.Value),
+		}
+	}
+	return &errdetails.ResourceInfo{
+		ResourceType: perr.Resource,
+		ResourceName: perr.Value,
+		Description:  perr.Field,
+	}
+}
+
+// withDetails attaches detail to st, if any.
+func withDetails(st *status.Status, detail proto.Message) error {
+	if detail == nil {
+		return st.Err()
+	}
+	if withDetail, err := st.WithDetails(detail); err == nil {
+		st = withDetail
+	}
+	return st.Err()
+}
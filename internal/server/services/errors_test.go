@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/AkashGit21/ms-project/internal/server"
+	"github.com/AkashGit21/ms-project/lib/persistence"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestToStatus(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		err      error
+		expected codes.Code
+	}{
+		{"nil", nil, codes.OK},
+		{"status", status.Error(codes.PermissionDenied, "denied"), codes.PermissionDenied},
+		{"not_found", persistence.NotFound("movie", "id", "test_id"), codes.NotFound},
+		{"already_exists", persistence.AlreadyExists("user", "email", "test_email@domain.com"), codes.AlreadyExists},
+		{"etag_mismatch", persistence.EtagMismatch("movie", "id", "test_id"), codes.FailedPrecondition},
+		{"conflict", persistence.Conflict("movie", errors.New("write conflict")), codes.Aborted},
+		{"unavailable", persistence.Unavailable(errors.New("connection refused")), codes.Unavailable},
+		{"deadline", persistence.Unavailable(fmt.Errorf("timed out: %w", context.DeadlineExceeded)), codes.DeadlineExceeded},
+		{"cancelled", context.Canceled, codes.Canceled},
+		{"unknown", errors.New("disk full"), codes.Internal},
+	}
+
+	for _, tcase := range tests {
+		t.Run(tcase.name, func(t *testing.T) {
+			if actual := status.Code(toStatus(tcase.err)); actual != tcase.expected {
+				t.Errorf("\n\texpected: %v \n\tactual: %v", tcase.expected, actual)
+			}
+		})
+	}
+}
+
+func TestToStatus_details(t *testing.T) {
+
+	st := status.Convert(toStatus(persistence.AlreadyExists("user", "email", "test_email@domain.com")))
+	if len(st.Details()) != 1 {
+		t.Fatalf("expected a single detail, got %v", st.Details())
+	}
+	info, ok := st.Details()[0].(*errdetails.ResourceInfo)
+	if !ok || info.GetResourceType() != "user" || info.GetResourceName() != "test_email@domain.com" || info.GetDescription() != "email" {
+		t.Errorf("unexpected detail %v", st.Details()[0])
+	}
+
+	// A value already taken is described by the record holding it
+	st = status.Convert(toStatus(persistence.AlreadyExistsAs("movie", "name", "Inception", "test_id")))
+	if len(st.Details()) != 1 {
+		t.Fatalf("expected a single detail, got %v", st.Details())
+	}
+	info, ok = st.Details()[0].(*errdetails.ResourceInfo)
+	if !ok || info.GetResourceType() != "movie" || info.GetResourceName() != "test_id" || info.GetDescription() != "name `Inception` is taken" {
+		t.Errorf("unexpected detail %v", st.Details()[0])
+	}
+
+	st = status.Convert(toStatus(persistence.Unavailable(errors.New("connection refused"))))
+	if len(st.Details()) != 1 {
+		t.Fatalf("expected a single detail, got %v", st.Details())
+	}
+	if _, ok := st.Details()[0].(*errdetails.RetryInfo); !ok {
+		t.Errorf("expected RetryInfo, got %v", st.Details()[0])
+	}
+}
+
+func TestToStatus_etagMismatch(t *testing.T) {
+
+	st := status.Convert(toStatus(persistence.EtagMismatch("movie", "id", "test_id")))
+	if len(st.Details()) != 1 {
+		t.Fatalf("expected a single detail, got %v", st.Details())
+	}
+	failure, ok := st.Details()[0].(*errdetails.PreconditionFailure)
+	if !ok || len(failure.GetViolations()) != 1 || failure.GetViolations()[0].GetType() != server.EtagViolation ||
+		failure.GetViolations()[0].GetSubject() != "movie/test_id" {
+		t.Errorf("unexpected detail %v", st.Details()[0])
+	}
+}
+
+func TestRequestEtag(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		etag     string
+		ifMatch  []string
+		expected string
+	}{
+		{"none", "", nil, ""},
+		{"field", "e1", []string{`"e2"`}, "e1"},
+		{"if_match", "", []string{`"e2"`}, "e2"},
+		{"weak_if_match", "", []string{`W/"e2"`}, "e2"},
+		{"wildcard", "", []string{"*"}, ""},
+	}
+
+	for _, tcase := range tests {
+		t.Run(tcase.name, func(t *testing.T) {
+			ctx := context.Background()
+			for _, v := range tcase.ifMatch {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(ifMatchKey, v))
+			}
+			if actual := requestEtag(ctx, tcase.etag); actual != tcase.expected {
+				t.Errorf("\n\texpected: %v \n\tactual: %v", tcase.expected, actual)
+			}
+		})
+	}
+}
+
+func TestInvalidArgument(t *testing.T) {
+
+	st := status.Convert(invalidArgument("filter", errors.New("unknown field")))
+	if st.Code() != codes.InvalidArgument || st.Message() != "invalid filter: unknown field" {
+		t.Errorf("unexpected status %v", st)
+	}
+	if len(st.Details()) != 1 {
+		t.Fatalf("expected a single detail, got %v", st.Details())
+	}
+	req, ok := st.Details()[0].(*errdetails.BadRequest)
+	if !ok || len(req.GetFieldViolations()) != 1 || req.GetFieldViolations()[0].GetField() != "filter" {
+		t.Errorf("unexpected detail %v", st.Details()[0])
+	}
+}
@@ -2,25 +2,36 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"strconv"
 	"sync"
+	"time"
 
 	identitypb "github.com/AkashGit21/ms-project/internal/grpc/identity"
 	"github.com/AkashGit21/ms-project/internal/server"
 	"github.com/AkashGit21/ms-project/internal/server/interceptors"
+	"github.com/AkashGit21/ms-project/lib/mail"
 	"github.com/AkashGit21/ms-project/lib/persistence"
+	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes"
 	"github.com/golang/protobuf/ptypes/empty"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
-// NewIdentityServer returns a new instance of application identity server.
-func NewIdentityServer(dbHandler persistence.DatabaseHandler) *identityServer {
+// NewIdentityServer returns a new instance of application identity server,
+// which keeps the users in the given repository. It logs the mails verifying
+// the emails of the users until given a Mailer.
+func NewIdentityServer(users persistence.UserRepository) *identityServer {
 	return &identityServer{
 		token: server.NewTokenGenerator(),
 		// keys:      map[string]int{},
-		dbhandler: dbHandler,
+		users:       users,
+		resent:      map[string]time.Time{},
+		Mailer:      mail.NewLogMailer(nil),
+		EmailTokens: server.NewEmailTokenManager(SecretKey, verifyEmailTTL),
 	}
 }
 
@@ -36,7 +47,16 @@ type identityServer struct {
 	// keys        map[string]int
 	// userEntries []userEntry
 
-	dbhandler persistence.DatabaseHandler
+	users persistence.UserRepository
+	// resent holds when a verification was last resent to each user
+	resent map[string]time.Time
+
+	// Mailer mails the users the tokens of EmailTokens verifying their
+	// email, as links to VerifyEmailURL if set
+	Mailer         mail.Mailer
+	EmailTokens    *server.EmailTokenManager
+	VerifyEmailURL string
+
 	identitypb.UnimplementedIdentityServiceServer
 }
 
@@ -47,26 +67,46 @@ type ReadOnlyIdentityServer interface {
 }
 
 // Creates a user.
-func (is *identityServer) CreateUser(_ context.Context,
+func (is *identityServer) CreateUser(ctx context.Context,
 	req *identitypb.CreateUserRequest) (*identitypb.CreateUserResponse, error) {
 	log.Println("Beginning CreateUser request: ", req)
+
+	user, err := is.createUser(ctx, req.GetUser())
+	if err != nil {
+		return nil, err
+	}
+
+	// The user may ask for the mail again, hence is created regardless. The
+	// mail is sent unlocked, not to stall the other requests on the mail server
+	if err := is.sendVerification(ctx, user); err != nil {
+		log.Printf("[ERROR] Cannot mail the verification to %s: %v", user.Username, err)
+	}
+	log.Println("End of CreateUser!")
+
+	return &identitypb.CreateUserResponse{
+		Username: user.Username,
+	}, nil
+}
+
+// createUser validates and stores the user u, and returns it as stored.
+func (is *identityServer) createUser(ctx context.Context, u *identitypb.User) (persistence.User, error) {
 	is.mu.Lock()
 	defer is.mu.Unlock()
 
-	u := req.GetUser()
-
 	// Check if Object already exists -
 	// codes.AlreadyExists
-	_, err := is.dbhandler.FindByUsername(u.GetUsername())
+	_, err := is.users.FindByUsername(ctx, u.GetUsername())
 	if err == nil {
-		return nil, status.Errorf(codes.AlreadyExists,
+		return persistence.User{}, status.Errorf(codes.AlreadyExists,
 			"A user with username `%s` already exists!", u.GetUsername())
+	} else if !errors.Is(err, persistence.ErrNotFound) {
+		return persistence.User{}, toStatus(err)
 	} else {
 
 		// Validate format of Input and store the data
 		err := is.validate(u)
 		if err != nil {
-			return nil, err
+			return persistence.User{}, err
 		}
 
 		// Assign server generated info.
@@ -74,7 +114,7 @@ func (is *identityServer) CreateUser(_ context.Context,
 
 		pwd, err := server.HashPassword(u.GetPassword())
 		if err != nil {
-			return nil, status.Errorf(codes.InvalidArgument, err.Error())
+			return persistence.User{}, status.Errorf(codes.InvalidArgument, err.Error())
 		}
 
 		user := persistence.User{
@@ -92,17 +132,17 @@ func (is *identityServer) CreateUser(_ context.Context,
 			Nickname:    u.Nickname,
 		}
 
-		is.dbhandler.AddUser(user)
+		// The database has the final say, since a concurrent request may
+		// have taken the username or email in the meantime
+		if _, err := is.users.AddUser(ctx, user); err != nil {
+			return persistence.User{}, toStatus(err)
+		}
+		return user, nil
 	}
-	log.Println("End of CreateUser!")
-
-	return &identitypb.CreateUserResponse{
-		Username: u.GetUsername(),
-	}, nil
 }
 
 // Retrieves the User with the given uri.
-func (is *identityServer) GetUser(_ context.Context,
+func (is *identityServer) GetUser(ctx context.Context,
 	req *identitypb.GetUserRequest) (*identitypb.User, error) {
 	log.Println("Beginning GetUser request: ", req)
 	is.mu.Lock()
@@ -116,38 +156,100 @@ func (is *identityServer) GetUser(_ context.Context,
 			"not allowed to perform this operation!")
 	}
 
-	res, err := is.dbhandler.FindByUsername(uname)
-	if err != nil || !res.Active {
+	res, err := is.users.FindByUsername(ctx, uname)
+	if err != nil && !errors.Is(err, persistence.ErrNotFound) {
+		return nil, toStatus(err)
+	}
+	if err != nil || !res.Active || res.DeleteTime != nil {
 		return nil, status.Errorf(
 			codes.NotFound, "A user with username `%s` not found!",
 			uname)
 	}
 
-	user := &identitypb.User{
-		Username:    res.Username,
-		Email:       res.Email,
-		Role:        identitypb.Role(res.Role),
-		FirstName:   res.FirstName,
-		LastName:    res.LastName,
-		CreateTime:  res.CreateTime,
-		UpdateTime:  res.UpdateTime,
-		Age:         res.Age,
-		HeightInCms: res.HeightInCms,
-		Nickname:    res.Nickname,
-		Active:      res.Active,
-	}
-	return user, nil
+	return toUserPB(res), nil
 }
 
-// Updates a user.
-func (is *identityServer) UpdateUser(_ context.Context,
+// Updates the fields of a user named by the update mask, or all of its
+// mutable fields if the mask is empty. Only ADMIN may change the role.
+func (is *identityServer) UpdateUser(ctx context.Context,
 	req *identitypb.UpdateUserRequest) (*identitypb.User, error) {
-	// TODO: Add the working for UpdateUser
-	return &identitypb.User{}, nil
+	log.Println("Beginning UpdateUser request: ", req)
+
+	res, emailChanged, err := is.updateUser(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sent unlocked, like in CreateUser
+	if emailChanged && res.EmailVerifyTime == nil {
+		if err := is.sendVerification(ctx, res); err != nil {
+			log.Printf("[ERROR] Cannot mail the verification to %s: %v", res.Username, err)
+		}
+	}
+	log.Println("[DEBUG] End UpdateUser!")
+	return toUserPB(res), nil
 }
 
-// Deletes a user, their profile, and all of their authored messages.
-func (is *identityServer) DeleteUser(_ context.Context,
+// updateUser applies the update of req, and returns the user as updated and
+// whether its email changed.
+func (is *identityServer) updateUser(ctx context.Context,
+	req *identitypb.UpdateUserRequest) (persistence.User, bool, error) {
+	is.mu.Lock()
+	defer is.mu.Unlock()
+
+	uname := req.GetUsername()
+	u := req.GetUser()
+
+	// Only ADMIN or the user itself can update his/her information
+	if interceptors.CURRENT_ROLE != "ADMIN" && interceptors.CURRENT_USERNAME != uname {
+		return persistence.User{}, false, status.Error(codes.PermissionDenied,
+			"not allowed to perform this operation!")
+	}
+
+	// Check if object already exists or not
+	// codes.NotFound
+	stored, err := is.users.FindByUsername(ctx, uname)
+	if err != nil && !errors.Is(err, persistence.ErrNotFound) {
+		return persistence.User{}, false, toStatus(err)
+	}
+	if err != nil || !stored.Active || stored.DeleteTime != nil {
+		return persistence.User{}, false, status.Errorf(
+			codes.NotFound, "A user with username `%s` not found!",
+			uname)
+	}
+
+	// Verify that the username is not updated
+	if u.GetUsername() != "" && u.GetUsername() != uname {
+		return persistence.User{}, false, status.Errorf(codes.InvalidArgument, "Cannot update the username of a user!")
+	}
+
+	paths, err := userUpdatePaths(req.GetUpdateMask().GetPaths(), u, stored)
+	if err != nil {
+		return persistence.User{}, false, invalidArgument("update_mask", err)
+	}
+	updated, err := applyUserUpdate(stored, u, paths)
+	if err != nil {
+		return persistence.User{}, false, err
+	}
+	if updated.Role != stored.Role && interceptors.CURRENT_ROLE != "ADMIN" {
+		return persistence.User{}, false, status.Error(codes.PermissionDenied,
+			"only ADMIN may change the role of a user!")
+	}
+	updated.UpdateTime = ptypes.TimestampNow()
+
+	if _, err := is.users.UpdateByUsername(ctx, uname, requestEtag(ctx, u.GetEtag()), updated); err != nil {
+		return persistence.User{}, false, toStatus(err)
+	}
+	res, err := is.users.FindByUsername(ctx, uname)
+	if err != nil {
+		return persistence.User{}, false, toStatus(err)
+	}
+	return res, res.Email != stored.Email, nil
+}
+
+// Deletes a user, their profile, and all of their authored messages. The user
+// is kept until purged, so that an admin may restore them.
+func (is *identityServer) DeleteUser(ctx context.Context,
 	req *identitypb.DeleteUserRequest) (*empty.Empty, error) {
 	log.Println("Beginning DeleteUser request: ", req)
 
@@ -158,52 +260,226 @@ func (is *identityServer) DeleteUser(_ context.Context,
 			"not allowed to perform this operation!")
 	}
 
-	// Check if object already exists or not
+	// Check if object already exists or not, a deleted one is gone already
 	// codes.NotFound
-	if _, err := is.dbhandler.FindByUsername(uname); err != nil {
+	if res, err := is.users.FindByUsername(ctx, uname); errors.Is(err, persistence.ErrNotFound) ||
+		err == nil && res.DeleteTime != nil {
 		return nil, status.Errorf(codes.NotFound, "A user with username `%s` does not exist!", uname)
+	} else if err != nil {
+		return nil, toStatus(err)
 	}
 
-	if err := is.dbhandler.RemoveByUsername(uname); err != nil {
-		return nil, status.Errorf(codes.InvalidArgument, "some error while deleting user!")
+	if err := is.users.SetUserDeleteTime(ctx, uname, requestEtag(ctx, req.GetEtag()), ptypes.TimestampNow()); err != nil {
+		return nil, toStatus(err)
 	}
 	log.Println("[DEBUG] End DeleteUser!")
 	return &empty.Empty{}, nil
 }
 
+// Restores a deleted user, before they are purged.
+func (is *identityServer) UndeleteUser(ctx context.Context,
+	req *identitypb.UndeleteUserRequest) (*identitypb.User, error) {
+	log.Println("Beginning UndeleteUser request: ", req)
+
+	uname := req.GetUsername()
+
+	// A deleted user cannot log in, hence only ADMIN may restore them
+	if interceptors.CURRENT_ROLE != "ADMIN" {
+		return nil, status.Error(codes.PermissionDenied,
+			"not allowed to perform this operation!")
+	}
+
+	// Check if object exists and is deleted
+	// codes.NotFound, codes.AlreadyExists
+	res, err := is.users.FindByUsername(ctx, uname)
+	if errors.Is(err, persistence.ErrNotFound) {
+		return nil, status.Errorf(codes.NotFound, "A user with username `%s` does not exist!", uname)
+	} else if err != nil {
+		return nil, toStatus(err)
+	}
+	if res.DeleteTime == nil {
+		return nil, status.Errorf(codes.AlreadyExists, "A user with username `%s` is not deleted!", uname)
+	}
+
+	if err := is.users.SetUserDeleteTime(ctx, uname, requestEtag(ctx, req.GetEtag()), nil); err != nil {
+		return nil, toStatus(err)
+	}
+	// Read the user again for its new etag
+	if res, err = is.users.FindByUsername(ctx, uname); err != nil {
+		return nil, toStatus(err)
+	}
+	log.Println("[DEBUG] End UndeleteUser!")
+	return toUserPB(res), nil
+}
+
 // Lists all users.
-func (is *identityServer) ListUsers(_ context.Context,
+func (is *identityServer) ListUsers(ctx context.Context,
 	in *identitypb.ListUsersRequest) (*identitypb.ListUsersResponse, error) {
-	start, err := is.token.GetIndex(in.GetPageToken())
+	order, err := persistence.ParseOrder(in.GetOrderBy(), persistence.UserSortFields)
+	if err != nil {
+		return nil, invalidArgument("order_by", err)
+	}
+	if in.GetShowDeleted() && interceptors.CURRENT_ROLE != "ADMIN" {
+		return nil, status.Error(codes.PermissionDenied,
+			"only admins may list the deleted users!")
+	}
+
+	// A page token only continues the list it was generated for
+	query := order.String() + "|" + strconv.FormatBool(in.GetShowDeleted())
+	after, err := is.token.GetCursor(in.GetPageToken(), query)
 	if err != nil {
 		return nil, err
 	}
+	if err := order.CheckCursor(after); err != nil {
+		log.Println("[DEBUG] rejected page token: ", err)
+		return nil, server.InvalidTokenErr
+	}
 
 	// Default page size is 12
 	var pageSz int32
-	if pageSz = in.GetPageSize(); pageSz == 0 || pageSz > 12 {
+	if pageSz = in.GetPageSize(); pageSz <= 0 || pageSz > 12 {
 		pageSz = 12
 	}
-	// offset := 0
-
-	numOfUsers := is.dbhandler.CountUsers()
 
-	users, err := is.dbhandler.FindAllUsers(start, pageSz)
+	// Fetch one more user than requested to know whether another page follows
+	users, err := is.users.FindAllUsers(ctx, in.GetShowDeleted(), order, after, pageSz+1)
 	if err != nil {
-		return nil, err
+		return nil, toStatus(err)
 	}
 
 	nextToken := ""
-	if start+int(pageSz) < numOfUsers {
-		nextToken = is.token.ForIndex(start + int(pageSz))
+	if len(users) > int(pageSz) {
+		users = users[:pageSz]
+		last := users[pageSz-1]
+		nextToken = is.token.ForCursor(query, order.UserCursor(last))
 	}
 
+	results := make([]*identitypb.User, 0, len(users))
+	for _, u := range users {
+		results = append(results, toUserPB(u))
+	}
 	return &identitypb.ListUsersResponse{
-		Users:         users,
+		Users:         results,
 		NextPageToken: nextToken,
 	}, nil
 }
 
+// toUserPB converts a stored user to the API type, leaving out its password hash.
+func toUserPB(u persistence.User) *identitypb.User {
+	return &identitypb.User{
+		Username:            u.Username,
+		Email:               u.Email,
+		Role:                identitypb.Role(u.Role),
+		Active:              u.Active,
+		FirstName:           u.FirstName,
+		LastName:            u.LastName,
+		CreateTime:          u.CreateTime,
+		UpdateTime:          u.UpdateTime,
+		Age:                 u.Age,
+		HeightInCms:         u.HeightInCms,
+		Nickname:            u.Nickname,
+		EnableNotifications: u.EnableNotifications,
+		DeleteTime:          u.DeleteTime,
+		Etag:                u.Etag,
+		EmailVerifyTime:     u.EmailVerifyTime,
+	}
+}
+
+// userMutableFields are the paths of an update mask a user update may set.
+var userMutableFields = []string{"email", "password", "role", "first_name", "last_name",
+	"age", "height_in_cms", "nickname", "enable_notifications"}
+
+// userOutputOnlyFields are the fields of a user only the server sets.
+var userOutputOnlyFields = map[string]bool{"username": true, "Active": true, "create_time": true,
+	"update_time": true, "delete_time": true, "etag": true, "email_verify_time": true}
+
+// userUpdatePaths returns the fields an update of stored with u sets: those of
+// the mask, or all the mutable ones if the mask is empty. Output only fields
+// may not be set, hence neither masked nor changed by u without a mask.
+// Without a mask a missing password is kept, since the users read carry none.
+func userUpdatePaths(mask []string, u *identitypb.User, stored persistence.User) ([]string, error) {
+	if len(mask) == 0 || len(mask) == 1 && mask[0] == "*" {
+		switch {
+		case u.GetCreateTime() != nil && !proto.Equal(u.GetCreateTime(), stored.CreateTime):
+			return nil, fmt.Errorf("the field `create_time` is output only")
+		case u.GetUpdateTime() != nil && !proto.Equal(u.GetUpdateTime(), stored.UpdateTime):
+			return nil, fmt.Errorf("the field `update_time` is output only")
+		case u.GetDeleteTime() != nil && !proto.Equal(u.GetDeleteTime(), stored.DeleteTime):
+			return nil, fmt.Errorf("the field `delete_time` is output only")
+		case u.GetEmailVerifyTime() != nil && !proto.Equal(u.GetEmailVerifyTime(), stored.EmailVerifyTime):
+			return nil, fmt.Errorf("the field `email_verify_time` is output only")
+		}
+		var paths []string
+		for _, path := range userMutableFields {
+			if path != "password" || u.GetPassword() != "" {
+				paths = append(paths, path)
+			}
+		}
+		return paths, nil
+	}
+
+	for _, path := range mask {
+		if userOutputOnlyFields[path] {
+			return nil, fmt.Errorf("the field `%s` is output only", path)
+		}
+		if !isUserMutableField(path) {
+			return nil, fmt.Errorf("unknown field `%s`", path)
+		}
+	}
+	return mask, nil
+}
+
+func isUserMutableField(path string) bool {
+	for _, field := range userMutableFields {
+		if field == path {
+			return true
+		}
+	}
+	return false
+}
+
+// applyUserUpdate returns stored with the given fields set from u, hashing
+// the password.
+func applyUserUpdate(stored persistence.User, u *identitypb.User, paths []string) (persistence.User, error) {
+	updated := stored
+	for _, path := range paths {
+		switch path {
+		case "email":
+			if u.GetEmail() == "" {
+				return updated, invalidArgument("user.email", errors.New("the field `email` is required"))
+			}
+			updated.Email = u.GetEmail()
+		case "password":
+			if u.GetPassword() == "" {
+				return updated, invalidArgument("user.password", errors.New("the field `password` is required"))
+			}
+			pwd, err := server.HashPassword(u.GetPassword())
+			if err != nil {
+				return updated, status.Errorf(codes.InvalidArgument, err.Error())
+			}
+			updated.Password = pwd
+		case "role":
+			if _, ok := identitypb.Role_name[int32(u.GetRole())]; !ok {
+				return updated, invalidArgument("user.role", fmt.Errorf("unknown role %d", u.GetRole()))
+			}
+			updated.Role = persistence.Role(u.GetRole())
+		case "first_name":
+			updated.FirstName = u.GetFirstName()
+		case "last_name":
+			updated.LastName = u.LastName
+		case "age":
+			updated.Age = u.Age
+		case "height_in_cms":
+			updated.HeightInCms = u.HeightInCms
+		case "nickname":
+			updated.Nickname = u.Nickname
+		case "enable_notifications":
+			updated.EnableNotifications = u.EnableNotifications
+		}
+	}
+	return updated, nil
+}
+
 // TODO: Add Validation for similar email in DB
 func (is *identityServer) validate(u *identitypb.User) error {
 	// Validate Required Fields.
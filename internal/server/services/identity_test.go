@@ -8,8 +8,15 @@ import (
 
 	identitypb "github.com/AkashGit21/ms-project/internal/grpc/identity"
 	"github.com/AkashGit21/ms-project/internal/server/interceptors"
+	"github.com/AkashGit21/ms-project/lib/persistence"
+	"github.com/AkashGit21/ms-project/lib/persistence/dblayer"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
 	"github.com/golang/protobuf/ptypes/empty"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 )
 
 func TestCreateUser(t *testing.T) {
@@ -63,18 +70,18 @@ func TestCreateUser(t *testing.T) {
 			expected:    "",
 			expectedErr: "rpc error: code = AlreadyExists desc = A user with username `test_username` already exists!",
 		},
-		// {
-		// 	name: "bad_email",
-		// 	args: &identitypb.User{
-		// 		Username:  "test_username2",
-		// 		Email:     "test_email@domain.com",
-		// 		Password:  "test_pwd",
-		// 		Role:      identitypb.Role_NORMAL,
-		// 		FirstName: "test_first",
-		// 	},
-		// 	expected:    "",
-		// 	expectedErr: "rpc error: code = AlreadyExists desc = A user with email `test_email@domain.com` already exists.",
-		// },
+		{
+			name: "bad_email",
+			args: &identitypb.User{
+				Username:  "test_username2",
+				Email:     "test_email@domain.com",
+				Password:  "test_pwd",
+				Role:      identitypb.Role_NORMAL,
+				FirstName: "test_first",
+			},
+			expected:    "",
+			expectedErr: "rpc error: code = AlreadyExists desc = user with email `test_email@domain.com` already exists",
+		},
 	}
 
 	// Mock server using Client
@@ -268,53 +275,99 @@ func TestListUsers(t *testing.T) {
 	}
 }
 
-// TODO: Tests for UpdateUser is not written
-// func TestUpdateUser(t *testing.T) {
-// 	dbhandler, _ := dblayer.NewPersistenceLayer(configuration.DBTypeDefault, configuration.DBConnectionDefault)
-
-// 	TestIdentitySrv = NewIdentityServer(dbhandler)
-
-// 	userObj := &identitypb.User{
-// 		Username:  "test_list_user1",
-// 		Email:     "test_list_email1@domain.in",
-// 		Password:  "test_list_pwd",
-// 		Role:      identitypb.Role_NORMAL,
-// 		FirstName: "test_first",
-// 	}
-// 	_, err := TestIdentitySrv.CreateUser(
-// 		context.Background(),
-// 		&identitypb.CreateUserRequest{
-// 			User: userObj,
-// 		},
-// 	)
-// 	if err != nil {
-// 		t.Fatalf("Failed to create pre-requisite object!")
-// 	}
-
-// 	tests := []TestCase{
-// 		{
-// 			name:        "demo",
-// 			args:        &identitypb.UpdateUserRequest{},
-// 			expected:    &identitypb.User{},
-// 			expectedErr: "",
-// 		},
-// 	}
-
-// 	for _, tcase := range tests {
-// 		t.Run(tcase.name, func(t *testing.T) {
-// 			actual, err := TestIdentitySrv.UpdateUser(
-// 				context.Background(),
-// 				tcase.args.(*identitypb.UpdateUserRequest))
-
-// 			if (err == nil || (err.Error() != tcase.expectedErr)) && tcase.expectedErr != "" {
-// 				t.Errorf("\n\texpected: %v \n\tactual: %v", tcase.expectedErr, err)
-// 			}
-// 			if (tcase.expected != nil || (actual != nil)) && !reflect.DeepEqual(actual, tcase.expected) {
-// 				t.Errorf("\n\texpected: %v \n\tactual: %v", tcase.expected, actual)
-// 			}
-// 		})
-// 	}
-// }
+func TestUpdateUser(t *testing.T) {
+	dbhandler, _ := dblayer.NewPersistenceLayer(dblayer.MEMORY, "")
+	is := NewIdentityServer(dbhandler)
+	ctx := context.Background()
+
+	userObj := &identitypb.User{
+		Username:  "test_update_username",
+		Email:     "test_update_email@domain.in",
+		Password:  "test_update_pwd",
+		Role:      identitypb.Role_NORMAL,
+		FirstName: "test_first",
+	}
+	if _, err := is.CreateUser(ctx, &identitypb.CreateUserRequest{User: userObj}); err != nil {
+		t.Fatalf("Failed to create pre-requisite object! %v", err)
+	}
+	uname := userObj.GetUsername()
+	interceptors.CURRENT_ROLE = "NORMAL"
+	interceptors.CURRENT_USERNAME = uname
+	defer func() { interceptors.CURRENT_USERNAME = "" }()
+
+	stored, err := is.GetUser(ctx, &identitypb.GetUserRequest{Username: uname})
+	if err != nil {
+		t.Fatalf("GetUser: unexpected err %v", err)
+	}
+
+	// Only the masked fields change, and a changed password is hashed
+	nickname := "test_nick"
+	updated, err := is.UpdateUser(ctx, &identitypb.UpdateUserRequest{
+		Username:   uname,
+		User:       &identitypb.User{Email: "ignored@domain.in", Password: "test_new_pwd", Nickname: &nickname},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"password", "nickname"}},
+	})
+	if err != nil {
+		t.Fatalf("UpdateUser: unexpected err %v", err)
+	}
+	if updated.GetEmail() != userObj.GetEmail() || updated.GetNickname() != nickname || updated.GetPassword() != "" ||
+		updated.GetEtag() == stored.GetEtag() || !updated.GetUpdateTime().AsTime().After(stored.GetUpdateTime().AsTime()) {
+		t.Errorf("UpdateUser: want the masked fields and update time changed, got %v", updated)
+	}
+	if ok, _ := dbhandler.Authenticate(ctx, uname, "test_new_pwd"); !ok {
+		t.Error("Authenticate: want the new password hashed")
+	}
+
+	// Without a mask every mutable field is replaced, the missing password kept
+	replaced := proto.Clone(updated).(*identitypb.User)
+	replaced.Email = "test_update_email2@domain.in"
+	replaced.FirstName = "test_first2"
+	replaced.Nickname = nil
+	updated, err = is.UpdateUser(ctx, &identitypb.UpdateUserRequest{Username: uname, User: replaced})
+	if err != nil || updated.GetEmail() != replaced.GetEmail() || updated.GetFirstName() != "test_first2" || updated.Nickname != nil {
+		t.Errorf("UpdateUser: want every mutable field replaced, got %v, %v", updated, err)
+	}
+	if ok, _ := dbhandler.Authenticate(ctx, uname, "test_new_pwd"); !ok {
+		t.Error("Authenticate: want the password kept without a mask")
+	}
+
+	tests := []struct {
+		name     string
+		role     string
+		req      *identitypb.UpdateUserRequest
+		wantCode codes.Code
+	}{
+		{"output_only_masked", "NORMAL", &identitypb.UpdateUserRequest{Username: uname, User: &identitypb.User{CreateTime: ptypes.TimestampNow()},
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"create_time"}}}, codes.InvalidArgument},
+		{"output_only_changed", "NORMAL", &identitypb.UpdateUserRequest{Username: uname, User: &identitypb.User{Email: replaced.GetEmail(),
+			Role: identitypb.Role_NORMAL, CreateTime: ptypes.TimestampNow()}}, codes.InvalidArgument},
+		{"unknown_field", "NORMAL", &identitypb.UpdateUserRequest{Username: uname, User: &identitypb.User{},
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"middle_name"}}}, codes.InvalidArgument},
+		{"username_changed", "NORMAL", &identitypb.UpdateUserRequest{Username: uname, User: &identitypb.User{Username: "other_username"},
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"first_name"}}}, codes.InvalidArgument},
+		{"missing_email", "NORMAL", &identitypb.UpdateUserRequest{Username: uname, User: &identitypb.User{},
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"email"}}}, codes.InvalidArgument},
+		{"role_by_user", "NORMAL", &identitypb.UpdateUserRequest{Username: uname, User: &identitypb.User{Role: identitypb.Role_ADMIN},
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"role"}}}, codes.PermissionDenied},
+		{"stale_etag", "NORMAL", &identitypb.UpdateUserRequest{Username: uname, User: &identitypb.User{Etag: stored.GetEtag()},
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"first_name"}}}, codes.FailedPrecondition},
+		{"other_user", "NORMAL", &identitypb.UpdateUserRequest{Username: "other_username", User: &identitypb.User{}}, codes.PermissionDenied},
+		{"not_exists", "ADMIN", &identitypb.UpdateUserRequest{Username: "other_username", User: &identitypb.User{}}, codes.NotFound},
+		{"role_by_admin", "ADMIN", &identitypb.UpdateUserRequest{Username: uname, User: &identitypb.User{Role: identitypb.Role_SUBSCRIBED},
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"role"}}}, codes.OK},
+	}
+	for _, tcase := range tests {
+		t.Run(tcase.name, func(t *testing.T) {
+			interceptors.CURRENT_ROLE = tcase.role
+			if _, err := is.UpdateUser(ctx, tcase.req); status.Code(err) != tcase.wantCode {
+				t.Errorf("UpdateUser: want %v, got %v", tcase.wantCode, err)
+			}
+		})
+	}
+	if u, _ := dbhandler.FindByUsername(ctx, uname); u.Role != persistence.Role_SUBSCRIBED {
+		t.Errorf("FindByUsername: want the role changed by the admin, got %v", u.Role)
+	}
+}
 
 func TestDeleteUser(t *testing.T) {
 
@@ -356,6 +409,12 @@ func TestDeleteUser(t *testing.T) {
 			expected:    &empty.Empty{},
 			expectedErr: "",
 		},
+		{
+			name:        "already_removed",
+			args:        uname,
+			expected:    nil,
+			expectedErr: "rpc error: code = NotFound desc = A user with username `" + uname + "` does not exist!",
+		},
 	}
 
 	// To allow the deletion, assigned ADMIN role temporarily
@@ -381,3 +440,58 @@ func TestDeleteUser(t *testing.T) {
 		})
 	}
 }
+
+func TestUndeleteUser(t *testing.T) {
+	dbhandler, _ := dblayer.NewPersistenceLayer(dblayer.MEMORY, "")
+	is := NewIdentityServer(dbhandler)
+	ctx := context.Background()
+
+	userObj := &identitypb.User{
+		Username:  "test_undelete_username",
+		Email:     "test_undelete_email@domain.in",
+		Password:  "test_undelete_pwd",
+		Role:      identitypb.Role_NORMAL,
+		FirstName: "test_first",
+	}
+	if _, err := is.CreateUser(ctx, &identitypb.CreateUserRequest{User: userObj}); err != nil {
+		t.Fatalf("Failed to create pre-requisite object! %v", err)
+	}
+	uname := userObj.GetUsername()
+
+	interceptors.CURRENT_ROLE = "ADMIN"
+	if _, err := is.UndeleteUser(ctx, &identitypb.UndeleteUserRequest{Username: uname}); status.Code(err) != codes.AlreadyExists {
+		t.Errorf("UndeleteUser: want AlreadyExists for a user who is not deleted, got %v", err)
+	}
+	if _, err := is.DeleteUser(ctx, &identitypb.DeleteUserRequest{Username: uname}); err != nil {
+		t.Fatalf("DeleteUser: unexpected err %v", err)
+	}
+
+	// A deleted user is hidden, except from admins listing the deleted ones
+	if _, err := is.GetUser(ctx, &identitypb.GetUserRequest{Username: uname}); status.Code(err) != codes.NotFound {
+		t.Errorf("GetUser: want NotFound for a deleted user, got %v", err)
+	}
+	if list, err := is.ListUsers(ctx, &identitypb.ListUsersRequest{}); err != nil || len(list.GetUsers()) != 0 {
+		t.Errorf("ListUsers: want no deleted user, got %v, %v", list, err)
+	}
+	list, err := is.ListUsers(ctx, &identitypb.ListUsersRequest{ShowDeleted: true})
+	if err != nil || len(list.GetUsers()) != 1 || list.GetUsers()[0].GetDeleteTime() == nil {
+		t.Errorf("ListUsers: want the deleted user, got %v, %v", list, err)
+	}
+
+	interceptors.CURRENT_ROLE = "NORMAL"
+	if _, err := is.UndeleteUser(ctx, &identitypb.UndeleteUserRequest{Username: uname}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("UndeleteUser: want PermissionDenied for a non admin, got %v", err)
+	}
+	interceptors.CURRENT_ROLE = "ADMIN"
+	restored, err := is.UndeleteUser(ctx, &identitypb.UndeleteUserRequest{Username: uname})
+	if err != nil || restored.GetUsername() != uname {
+		t.Fatalf("UndeleteUser: unexpected result %v, %v", restored, err)
+	}
+	if _, err := is.GetUser(ctx, &identitypb.GetUserRequest{Username: uname}); err != nil {
+		t.Errorf("GetUser: unexpected err %v for a restored user", err)
+	}
+
+	if _, err := is.UndeleteUser(ctx, &identitypb.UndeleteUserRequest{Username: "test_missing_user"}); status.Code(err) != codes.NotFound {
+		t.Errorf("UndeleteUser: want NotFound for a missing user, got %v", err)
+	}
+}
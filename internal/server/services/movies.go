@@ -2,14 +2,20 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"reflect"
+	"strconv"
+	"strings"
 	"sync"
 
 	moviepb "github.com/AkashGit21/ms-project/internal/grpc/movie"
 	"github.com/AkashGit21/ms-project/internal/server"
+	"github.com/AkashGit21/ms-project/internal/server/interceptors"
+	"github.com/AkashGit21/ms-project/lib/filter"
 	"github.com/AkashGit21/ms-project/lib/persistence"
+	"github.com/AkashGit21/ms-project/lib/search"
 	"github.com/golang/protobuf/ptypes"
 	"github.com/golang/protobuf/ptypes/empty"
 	"google.golang.org/grpc/codes"
@@ -20,60 +26,149 @@ type movieServer struct {
 	token   server.TokenGenerator
 	authSrv *authServer
 
-	mu        sync.Mutex
-	dbhandler persistence.DatabaseHandler
-	keys      map[string]int
-	Store     []movieEntry
+	mu     sync.Mutex
+	movies persistence.MovieRepository
+	keys   map[string]int
 
 	moviepb.UnimplementedMovieServiceServer
 }
 
-type movieEntry struct {
-	movie  *moviepb.Movie
-	active bool
-}
-
-func NewMovieServer(as *authServer) *movieServer {
+func NewMovieServer(as *authServer, movies persistence.MovieRepository) *movieServer {
 	return &movieServer{
-		token:     server.NewTokenGenerator(),
-		authSrv:   as,
-		keys:      map[string]int{},
-		dbhandler: as.dbhandler,
+		token:   server.NewTokenGenerator(),
+		authSrv: as,
+		keys:    map[string]int{},
+		movies:  movies,
 	}
 }
 
 func (ms *movieServer) ListMovies(ctx context.Context,
 	req *moviepb.ListMoviesRequest) (*moviepb.ListMoviesResponse, error) {
 
-	start, err := ms.token.GetIndex(req.GetPageToken())
+	expr, err := filter.Parse(req.GetFilter(), persistence.MovieFilterSchema)
+	if err != nil {
+		return nil, invalidArgument("filter", err)
+	}
+	order, err := persistence.ParseOrder(req.GetOrderBy(), persistence.MovieSortFields)
+	if err != nil {
+		return nil, invalidArgument("order_by", err)
+	}
+	if req.GetShowDeleted() && interceptors.CURRENT_ROLE != "ADMIN" {
+		return nil, status.Error(codes.PermissionDenied,
+			"only admins may list the deleted movies!")
+	}
+
+	// A page token only continues the list it was generated for
+	query := order.String() + "|" + req.GetFilter() + "|" + strconv.FormatBool(req.GetShowDeleted())
+	after, err := ms.token.GetCursor(req.GetPageToken(), query)
 	if err != nil {
 		return nil, err
 	}
+	if err := order.CheckCursor(after); err != nil {
+		log.Println("[DEBUG] rejected page token: ", err)
+		return nil, server.InvalidTokenErr
+	}
 
 	// Default page size is 12
 	var pageSz int32
-	if pageSz = req.GetPageSize(); pageSz == 0 || pageSz > 12 {
+	if pageSz = req.GetPageSize(); pageSz <= 0 || pageSz > 12 {
 		pageSz = 12
 	}
 
-	numOfRecords := ms.dbhandler.CountMovieRecords()
+	// Fetch one more movie than requested to know whether another page follows
+	movies, err := ms.movies.FindAllMovies(ctx, expr, req.GetShowDeleted(), order, after, pageSz+1)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	nextToken := ""
+	if len(movies) > int(pageSz) {
+		movies = movies[:pageSz]
+		last := movies[pageSz-1]
+		nextToken = ms.token.ForCursor(query, order.MovieCursor(last))
+	}
+
+	results := make([]*moviepb.Movie, 0, len(movies))
+	for _, mv := range movies {
+		results = append(results, toMoviePB(mv))
+	}
+	return &moviepb.ListMoviesResponse{
+		Movies:        results,
+		NextPageToken: nextToken,
+	}, nil
+}
+
+// snippetWidth is the length in characters of the highlights of a search.
+const snippetWidth = 80
+
+func (ms *movieServer) SearchMovies(ctx context.Context,
+	req *moviepb.SearchMoviesRequest) (*moviepb.SearchMoviesResponse, error) {
+
+	terms := search.Tokenize(req.GetQ())
+	if len(terms) == 0 {
+		return nil, invalidArgument("q", errors.New("should contain a word to search"))
+	}
 
-	movies, err := ms.dbhandler.FindAllMovies(start, pageSz)
+	// Results are ranked, hence paged by their position, which a page token
+	// only continues for the same terms
+	query := strings.Join(terms, " ")
+	offset, err := ms.token.GetOffset(req.GetPageToken(), query)
 	if err != nil {
 		return nil, err
 	}
 
+	// Default page size is 12
+	var pageSz int32
+	if pageSz = req.GetPageSize(); pageSz <= 0 || pageSz > 12 {
+		pageSz = 12
+	}
+
+	// Fetch one more movie than requested to know whether another page follows
+	hits, err := ms.movies.SearchMovies(ctx, req.GetQ(), int32(offset), pageSz+1)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
 	nextToken := ""
-	if start+int(pageSz) < numOfRecords {
-		nextToken = ms.token.ForIndex(start + int(pageSz))
+	if len(hits) > int(pageSz) {
+		hits = hits[:pageSz]
+		nextToken = ms.token.ForOffset(query, offset+int(pageSz))
 	}
 
-	return &moviepb.ListMoviesResponse{
-		Movies:        movies,
+	results := make([]*moviepb.SearchResult, 0, len(hits))
+	for _, hit := range hits {
+		results = append(results, &moviepb.SearchResult{
+			Movie:      toMoviePB(hit.Movie),
+			Score:      hit.Score,
+			Highlights: highlights(hit.Movie, req.GetQ()),
+		})
+	}
+	return &moviepb.SearchMoviesResponse{
+		Results:       results,
 		NextPageToken: nextToken,
 	}, nil
 }
 
+// highlights returns the snippets of the searchable fields of mv which
+// contain a word of the query.
+func highlights(mv persistence.Movie, query string) map[string]string {
+	fields := map[string]string{
+		"name":     mv.Name,
+		"summary":  mv.Summary,
+		"cast":     strings.Join(mv.Cast, ", "),
+		"director": mv.Director,
+		"writers":  strings.Join(mv.Writers, ", "),
+	}
+
+	result := map[string]string{}
+	for field, text := range fields {
+		if snippet := search.Highlight(text, query, snippetWidth); snippet != "" {
+			result[field] = snippet
+		}
+	}
+	return result
+}
+
 func (ms *movieServer) GetMovie(ctx context.Context,
 	req *moviepb.GetMovieRequest) (*moviepb.Movie, error) {
 	log.Println("[DEBUG] Beginning GetMovieRequest: ", req)
@@ -85,29 +180,18 @@ func (ms *movieServer) GetMovie(ctx context.Context,
 
 	// Check if Object exists or not
 	// codes.NotFound
-	res, err := ms.dbhandler.FindMovieByID(objID)
-	if err != nil || !res.Active {
+	res, err := ms.movies.FindMovieByID(ctx, objID)
+	if err != nil && !errors.Is(err, persistence.ErrNotFound) {
+		return nil, toStatus(err)
+	}
+	if err != nil || !res.Active || res.DeleteTime != nil {
 
 		return nil, status.Errorf(
 			codes.NotFound, "Movie Record with ID:%v does not exist!",
 			objID)
 	}
 
-	mv := &moviepb.Movie{
-		Id:      res.Id,
-		Name:    res.Name,
-		Summary: res.Summary,
-		Cast:    res.Cast,
-		// TODO: Add the Tags here
-		// Tags: res.Tags,
-		Director:   res.Director,
-		Writers:    res.Writers,
-		Active:     res.Active,
-		CreateTime: res.CreateTime,
-		UpdateTime: res.UpdateTime,
-	}
-
-	return mv, nil
+	return toMoviePB(res), nil
 }
 
 func (ms *movieServer) CreateMovie(ctx context.Context,
@@ -118,43 +202,39 @@ func (ms *movieServer) CreateMovie(ctx context.Context,
 	defer ms.mu.Unlock()
 	objID := server.GenerateUUID()
 
-	// Check if Object already exists -
-	// codes.AlreadyExists
-	_, err := ms.dbhandler.FindMovieByID(objID)
-	if err == nil {
-		return nil, status.Errorf(codes.AlreadyExists,
-			"Movie Record with ID: %v already exists!", objID)
-	} else {
-		mvObject := req.GetMovie()
-		if mvObject.GetId() != "" {
-			return nil, status.Errorf(codes.InvalidArgument,
-				"Input is not valid! ID is auto-generated...")
-		}
-		mvObject.Id = objID
-
-		// Validate format of Input and store the data
-		if valid, err := ms.isValidMovie(mvObject); !valid {
-			return nil, status.Errorf(codes.InvalidArgument,
-				"Input is not valid! %v", err.Error())
-		}
+	mvObject := req.GetMovie()
+	if mvObject.GetId() != "" {
+		return nil, status.Errorf(codes.InvalidArgument,
+			"Input is not valid! ID is auto-generated...")
+	}
+	mvObject.Id = objID
 
-		// Assign server generated info.
-		now := ptypes.TimestampNow()
+	// Validate format of Input and store the data
+	if valid, err := ms.isValidMovie(mvObject); !valid {
+		return nil, status.Errorf(codes.InvalidArgument,
+			"Input is not valid! %v", err.Error())
+	}
 
-		movieObject := persistence.Movie{
-			Id:      objID,
-			Name:    mvObject.Name,
-			Summary: mvObject.Summary,
-			Cast:    mvObject.Cast,
-			// TODO: Add Tags here
-			Director:   mvObject.Director,
-			Writers:    mvObject.Writers,
-			Active:     true,
-			CreateTime: now,
-			UpdateTime: now,
-		}
+	// Assign server generated info.
+	now := ptypes.TimestampNow()
+
+	movieObject := persistence.Movie{
+		Id:         objID,
+		Name:       persistence.NormalizeMovieName(mvObject.Name),
+		Summary:    mvObject.Summary,
+		Cast:       mvObject.Cast,
+		Tags:       toPersistenceTags(mvObject.Tags),
+		Director:   mvObject.Director,
+		Writers:    mvObject.Writers,
+		Active:     true,
+		CreateTime: now,
+		UpdateTime: now,
+	}
 
-		ms.dbhandler.AddMovie(movieObject)
+	// A clash of the generated ID is reported by AddMovie as ALREADY_EXISTS,
+	// without an extra round trip to look the ID up first
+	if _, err := ms.movies.AddMovie(ctx, movieObject); err != nil {
+		return nil, toStatus(err)
 	}
 
 	log.Println("[DEBUG] End CreateMovieRequest!")
@@ -175,9 +255,11 @@ func (ms *movieServer) UpdateMovie(ctx context.Context,
 	// Check if object already exists or not
 	// codes.NotFound
 
-	_, err := ms.dbhandler.FindMovieByID(objID)
-	if err != nil {
+	res, err := ms.movies.FindMovieByID(ctx, objID)
+	if errors.Is(err, persistence.ErrNotFound) || err == nil && res.DeleteTime != nil {
 		return nil, status.Errorf(codes.NotFound, "Movie Record with ID:%v does not exist!", objID)
+	} else if err != nil {
+		return nil, toStatus(err)
 	} else {
 
 		// Validate and update the whole object
@@ -196,15 +278,17 @@ func (ms *movieServer) UpdateMovie(ctx context.Context,
 		}
 
 		updatedMv := persistence.Movie{
-			Name:    mvObject.Name,
-			Summary: mvObject.Summary,
-			Cast:    mvObject.Cast,
-			// Tags: mvObject.Tags,
+			Name:       persistence.NormalizeMovieName(mvObject.Name),
+			Summary:    mvObject.Summary,
+			Cast:       mvObject.Cast,
+			Tags:       toPersistenceTags(mvObject.Tags),
 			Director:   mvObject.Director,
 			Writers:    mvObject.Writers,
 			UpdateTime: ptypes.TimestampNow(),
 		}
-		ms.dbhandler.UpdateMovieByID(objID, updatedMv)
+		if _, err := ms.movies.UpdateMovieByID(ctx, objID, requestEtag(ctx, mvObject.GetEtag()), updatedMv); err != nil {
+			return nil, toStatus(err)
+		}
 	}
 
 	log.Println("[DEBUG] End UpdateMovieRequest!")
@@ -213,56 +297,95 @@ func (ms *movieServer) UpdateMovie(ctx context.Context,
 	}, nil
 }
 
-// func (ms *movieServer) PartialUpdateMovie(ctx context.Context, req *moviepb.PartialUpdateMovieRequest) (*moviepb.PartialUpdateMovieResponse, error) {
-
-// 	log.Println("[DEBUG] Beginning PartialUpdateMovieRequest: ", req)
-
-// 	objID := req.GetId()
-
-// 	// Check if object already exists or not
-// 	// codes.NotFound
-// 	if index, ok := ms.keys[objID]; ok && ms.Store[index].active {
-
-// 		// Validate each field individually and Update the fields at a go afterwards
-// 		ms.mu.Lock()
-// 		defer ms.mu.Unlock()
-// 		mvObject := ms.Store[index].movie
+// Updates the fields of a movie set in the request, and those named by its
+// update mask, leaving the others as they are.
+func (ms *movieServer) PartialUpdateMovie(ctx context.Context,
+	req *moviepb.PartialUpdateMovieRequest) (*moviepb.PartialUpdateMovieResponse, error) {
+	log.Println("[DEBUG] Beginning PartialUpdateMovieRequest: ", req)
 
-// 		// Verify that the ID is not updated
-// 		newID := req.GetId()
-// 		if newID != "" && newID != objID {
-// 			return nil, status.Errorf(codes.InvalidArgument, "Cannot update the ID of object!")
-// 		}
+	objID := req.GetId()
 
-// 		if req.GetSummary() != "" {
-// 			mvObject.Summary = req.GetSummary()
-// 		}
+	fields, err := moviePatchFields(req)
+	if err != nil {
+		return nil, invalidArgument("update_mask", err)
+	}
+	if len(fields) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "No field of the movie to update!")
+	}
 
-// 		if req.GetDirector() != "" {
-// 			mvObject.Director = req.GetDirector()
-// 		}
+	// Validate each field individually, the others are as validated before
+	patch := &moviepb.Movie{
+		Id:       objID,
+		Name:     req.GetName(),
+		Summary:  req.GetSummary(),
+		Cast:     req.GetCast(),
+		Tags:     req.GetTags(),
+		Director: req.GetDirector(),
+		Writers:  req.GetWriters(),
+	}
+	for _, field := range fields {
+		if err := validateMovieField(patch, field); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "Input is not valid! %v", err.Error())
+		}
+	}
 
-// 		if req.GetCast() != nil {
-// 			mvObject.Cast = req.GetCast()
-// 		}
+	// Check if object already exists or not
+	// codes.NotFound
+	if res, err := ms.movies.FindMovieByID(ctx, objID); errors.Is(err, persistence.ErrNotFound) ||
+		err == nil && res.DeleteTime != nil {
+		return nil, status.Errorf(codes.NotFound, "Movie Record with ID:%v does not exist!", objID)
+	} else if err != nil {
+		return nil, toStatus(err)
+	}
 
-// 		if req.GetTags() != nil {
-// 			mvObject.Tags = req.GetTags()
-// 		}
+	patchedMv := persistence.Movie{
+		Name:       persistence.NormalizeMovieName(patch.Name),
+		Summary:    patch.Summary,
+		Cast:       patch.Cast,
+		Tags:       toPersistenceTags(patch.Tags),
+		Director:   patch.Director,
+		Writers:    patch.Writers,
+		UpdateTime: ptypes.TimestampNow(),
+	}
+	// The fields are set at once in the database, so that the concurrent
+	// updates of the other fields are kept
+	if _, err := ms.movies.PatchMovieByID(ctx, objID, requestEtag(ctx, req.GetEtag()), patchedMv, fields); err != nil {
+		return nil, toStatus(err)
+	}
 
-// 		if req.GetWriters() != nil {
-// 			mvObject.Writers = req.GetWriters()
-// 		}
+	log.Println("[DEBUG] End PartialUpdateMovieRequest!")
+	return &moviepb.PartialUpdateMovieResponse{
+		Id: objID,
+	}, nil
+}
 
-// 	} else {
-// 		return nil, status.Errorf(codes.NotFound, "Movie Record with ID:%v does not exist!", objID)
-// 	}
+// moviePatchFields returns the fields a partial update of a movie sets: those
+// present in the request, the repeated ones if not empty, and those named by
+// its update mask.
+func moviePatchFields(req *moviepb.PartialUpdateMovieRequest) ([]string, error) {
+	set := map[string]bool{
+		"name":     req.Name != nil,
+		"summary":  req.Summary != nil,
+		"cast":     len(req.GetCast()) > 0,
+		"tags":     len(req.GetTags()) > 0,
+		"director": req.Director != nil,
+		"writers":  len(req.GetWriters()) > 0,
+	}
+	for _, path := range req.GetUpdateMask().GetPaths() {
+		if _, ok := set[path]; !ok {
+			return nil, fmt.Errorf("the field `%s` cannot be updated", path)
+		}
+		set[path] = true
+	}
 
-// 	log.Println("[DEBUG] End PartialUpdateMovieRequest!")
-// 	return &moviepb.PartialUpdateMovieResponse{
-// 		Id: objID,
-// 	}, nil
-// }
+	var fields []string
+	for _, field := range persistence.MoviePatchFields {
+		if set[field] {
+			fields = append(fields, field)
+		}
+	}
+	return fields, nil
+}
 
 func (ms *movieServer) DeleteMovie(ctx context.Context,
 	req *moviepb.DeleteMovieRequest) (*empty.Empty, error) {
@@ -270,63 +393,154 @@ func (ms *movieServer) DeleteMovie(ctx context.Context,
 
 	objID := req.GetId()
 
-	// Check if object already exists or not
+	// Check if object already exists or not, a deleted one is gone already
 	// codes.NotFound
-	if _, err := ms.dbhandler.FindMovieByID(objID); err != nil {
+	if res, err := ms.movies.FindMovieByID(ctx, objID); errors.Is(err, persistence.ErrNotFound) ||
+		err == nil && res.DeleteTime != nil {
 		return nil, status.Errorf(
 			codes.NotFound, "Movie Record with ID:%v does not exist!",
 			objID)
+	} else if err != nil {
+		return nil, toStatus(err)
 	}
 
-	if err := ms.dbhandler.RemoveMovieByID(objID); err != nil {
-		return nil, status.Errorf(codes.InvalidArgument, "some error while deleting movie!")
+	// The movie is kept until purged, so that it can be restored
+	if err := ms.movies.SetMovieDeleteTime(ctx, objID, requestEtag(ctx, req.GetEtag()), ptypes.TimestampNow()); err != nil {
+		return nil, toStatus(err)
 	}
 
 	log.Println("[DEBUG] End DeleteMovieRequest!")
 	return &empty.Empty{}, nil
 }
 
-func (ms *movieServer) isValidMovie(mv *moviepb.Movie) (bool, error) {
+func (ms *movieServer) UndeleteMovie(ctx context.Context,
+	req *moviepb.UndeleteMovieRequest) (*moviepb.Movie, error) {
+	log.Println("[DEBUG] Beginning UndeleteMovieRequest: ", req)
 
-	if !isValidName(mv.Name) {
-		return false, fmt.Errorf("The name should be between 1 and 120 characters.")
-	}
+	objID := req.GetId()
 
-	if err := isAllowedSummary(mv.Summary); err != nil {
-		return false, err
+	// Check if object exists and is deleted
+	// codes.NotFound, codes.AlreadyExists
+	res, err := ms.movies.FindMovieByID(ctx, objID)
+	if errors.Is(err, persistence.ErrNotFound) {
+		return nil, status.Errorf(
+			codes.NotFound, "Movie Record with ID:%v does not exist!",
+			objID)
+	} else if err != nil {
+		return nil, toStatus(err)
+	}
+	if res.DeleteTime == nil {
+		return nil, status.Errorf(
+			codes.AlreadyExists, "Movie Record with ID:%v is not deleted!",
+			objID)
 	}
 
-	if len(mv.Cast) > 0 {
-		for _, crew := range mv.Cast {
-			if !isValidName(crew) {
-				return false, fmt.Errorf("Name of Cast members should be between 1 and 120 characters.")
-			}
-		}
+	if err := ms.movies.SetMovieDeleteTime(ctx, objID, requestEtag(ctx, req.GetEtag()), nil); err != nil {
+		return nil, toStatus(err)
 	}
+	// Read the movie again for its new etag
+	if res, err = ms.movies.FindMovieByID(ctx, objID); err != nil {
+		return nil, toStatus(err)
+	}
+
+	log.Println("[DEBUG] End UndeleteMovieRequest!")
+	return toMoviePB(res), nil
+}
 
-	if mv.Director != "" && !isValidName(mv.Director) {
-		return false, fmt.Errorf("Director field should be between 1 and 120 characters.")
+// WatchMovies streams the changes of the movies until the client goes away.
+// A client reconnecting passes the resume token of the last event it got.
+func (ms *movieServer) WatchMovies(req *moviepb.WatchMoviesRequest,
+	stream moviepb.MovieService_WatchMoviesServer) error {
+	log.Println("[DEBUG] Beginning WatchMoviesRequest: ", req)
+
+	ctx := stream.Context()
+	events, err := ms.movies.WatchMovies(ctx, req.GetResumeToken())
+	if errors.Is(err, persistence.ErrInvalidResumeToken) {
+		return invalidArgument("resume_token", err)
+	} else if err != nil {
+		return toStatus(err)
 	}
+	defer events.Close()
 
-	if len(mv.Cast) > 0 {
-		for _, wr := range mv.Cast {
-			if !isValidName(wr) {
-				return false, fmt.Errorf("Writers' name should be between 1 and 120 characters.")
-			}
+	for {
+		ev, err := events.Next(ctx)
+		if err != nil {
+			log.Println("[DEBUG] End WatchMoviesRequest: ", err)
+			return toStatus(err)
 		}
-	}
 
-	// Verify no duplicate entries are made for Movie name
-	for _, mvObject := range ms.Store {
-		if !mvObject.active {
-			continue
+		eventTime, _ := ptypes.TimestampProto(ev.Time)
+		err = stream.Send(&moviepb.MovieEvent{
+			Type:        moviepb.MovieEvent_Type(ev.Type),
+			Movie:       toMoviePB(ev.Movie),
+			EventTime:   eventTime,
+			ResumeToken: ev.ResumeToken,
+		})
+		if err != nil {
+			return err
 		}
+	}
+}
 
-		// Check whether Name is Unique or not. Also, ignore if update call is happening
-		if mvObject.movie.GetName() == mv.GetName() && mv.GetId() == "" {
-			return false, fmt.Errorf("Name should be unique!")
+func toMoviePB(mv persistence.Movie) *moviepb.Movie {
+	return &moviepb.Movie{
+		Id:         mv.Id,
+		Name:       mv.Name,
+		Summary:    mv.Summary,
+		Cast:       mv.Cast,
+		Tags:       toMoviePBTags(mv.Tags),
+		Director:   mv.Director,
+		Writers:    mv.Writers,
+		Active:     mv.Active,
+		CreateTime: mv.CreateTime,
+		UpdateTime: mv.UpdateTime,
+		DeleteTime: mv.DeleteTime,
+		Etag:       mv.Etag,
+	}
+}
+
+// isValidMovie checks the fields of a movie. The name being free is checked by
+// the database as the movie is stored, see persistence.MovieRepository.
+func (ms *movieServer) isValidMovie(mv *moviepb.Movie) (bool, error) {
+
+	for _, field := range persistence.MoviePatchFields {
+		if err := validateMovieField(mv, field); err != nil {
+			return false, err
 		}
 	}
 
 	return true, nil
 }
+
+// validateMovieField checks the given field of a movie, one of
+// persistence.MoviePatchFields, so that a partial update validates the fields
+// it sets like isValidMovie does the whole movie.
+func validateMovieField(mv *moviepb.Movie, field string) error {
+	switch field {
+	case "name":
+		if !isValidName(mv.Name) {
+			return fmt.Errorf("The name should be between 1 and 120 characters.")
+		}
+	case "summary":
+		return isAllowedSummary(mv.Summary)
+	case "tags":
+		return validateTags(mv.Tags)
+	case "cast":
+		for _, crew := range mv.Cast {
+			if !isValidName(crew) {
+				return fmt.Errorf("Name of Cast members should be between 1 and 120 characters.")
+			}
+		}
+	case "director":
+		if mv.Director != "" && !isValidName(mv.Director) {
+			return fmt.Errorf("Director field should be between 1 and 120 characters.")
+		}
+	case "writers":
+		for _, wr := range mv.Writers {
+			if !isValidName(wr) {
+				return fmt.Errorf("Writers' name should be between 1 and 120 characters.")
+			}
+		}
+	}
+	return nil
+}
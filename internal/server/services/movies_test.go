@@ -2,23 +2,32 @@ package services
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"reflect"
 	"strconv"
 	"testing"
+	"time"
 
 	moviepb "github.com/AkashGit21/ms-project/internal/grpc/movie"
-	"github.com/AkashGit21/ms-project/lib/configuration"
+	"github.com/AkashGit21/ms-project/internal/server"
+	"github.com/AkashGit21/ms-project/internal/server/interceptors"
+	"github.com/AkashGit21/ms-project/lib/persistence"
 	"github.com/AkashGit21/ms-project/lib/persistence/dblayer"
 	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 )
 
 func getMovieServer() *movieServer {
-	dbhandler, _ := dblayer.NewPersistenceLayer(configuration.DBTypeDefault, configuration.DBConnectionDefault)
+	dbhandler, _ := dblayer.NewPersistenceLayer(dblayer.MEMORY, "")
 
-	authSrv := NewAuthServer(NewIdentityServer(dbhandler))
-	return NewMovieServer(authSrv)
+	authSrv := NewAuthServer(NewIdentityServer(dbhandler), dbhandler)
+	return NewMovieServer(authSrv, dbhandler)
 }
 
 func TestCreateMovie(t *testing.T) {
@@ -51,7 +60,7 @@ func TestCreateMovie(t *testing.T) {
 			expectedErr: "rpc error: code = InvalidArgument desc = Input is not valid! The name should be between 1 and 120 characters.",
 		},
 		{
-			name: "created_movie",
+			name: "undefined_tag_error",
 			args: &moviepb.Movie{
 				Name:    "test_create_movie",
 				Summary: "test_summary",
@@ -59,6 +68,18 @@ func TestCreateMovie(t *testing.T) {
 				Tags:    []moviepb.Tag{moviepb.Tag(0)},
 				Writers: []string{"test_writer1", "test_writer2"},
 			},
+			expected:    "",
+			expectedErr: "rpc error: code = InvalidArgument desc = Input is not valid! The tag UNDEFINED_TAG is not allowed.",
+		},
+		{
+			name: "created_movie",
+			args: &moviepb.Movie{
+				Name:    "test_create_movie",
+				Summary: "test_summary",
+				Cast:    []string{"test_cast1"},
+				Tags:    []moviepb.Tag{moviepb.Tag_Action, moviepb.Tag_Comedy},
+				Writers: []string{"test_writer1", "test_writer2"},
+			},
 			expected:    "9e6f9248-e147-4cbe-9c4f-e3d06c79e361",
 			expectedErr: "",
 		},
@@ -92,6 +113,32 @@ func TestCreateMovie(t *testing.T) {
 	}
 }
 
+func TestCreateMovie_duplicateName(t *testing.T) {
+	ms := getMovieServer()
+	ctx := context.Background()
+
+	mv := &moviepb.Movie{Name: "  Test   Unique Movie ", Summary: "test_summary", Tags: []moviepb.Tag{moviepb.Tag_Action}}
+	resp, err := ms.CreateMovie(ctx, &moviepb.CreateMovieRequest{Movie: mv})
+	if err != nil {
+		t.Fatalf("Failed to create pre-requisite object! %v", err)
+	}
+	if found, _ := ms.movies.FindMovieByID(ctx, resp.GetId()); found.Name != "Test Unique Movie" {
+		t.Errorf("CreateMovie: want the name normalized, got %q", found.Name)
+	}
+
+	// The names differing by case and spacing only are the same, and the
+	// error tells the movie holding the name
+	dup := &moviepb.Movie{Name: "test unique  MOVIE", Summary: "test_summary", Tags: []moviepb.Tag{moviepb.Tag_Action}}
+	_, err = ms.CreateMovie(ctx, &moviepb.CreateMovieRequest{Movie: dup})
+	st := status.Convert(err)
+	if st.Code() != codes.AlreadyExists || len(st.Details()) != 1 {
+		t.Fatalf("CreateMovie: want ALREADY_EXISTS with a detail, got %v", err)
+	}
+	if info, ok := st.Details()[0].(*errdetails.ResourceInfo); !ok || info.GetResourceName() != resp.GetId() {
+		t.Errorf("CreateMovie: want the ID %v of the existing movie, got %v", resp.GetId(), st.Details()[0])
+	}
+}
+
 func TestGetMovie(t *testing.T) {
 
 	// Mock server using Client
@@ -109,6 +156,7 @@ func TestGetMovie(t *testing.T) {
 	mvObj := &moviepb.Movie{
 		Name:    "test_get_movie",
 		Summary: "test_get_movie_summary",
+		Tags:    []moviepb.Tag{moviepb.Tag_Action},
 		Cast:    []string{"test_cast1", "test_cast2"},
 	}
 	resp, err := movieClient.CreateMovie(context.Background(),
@@ -159,6 +207,9 @@ func TestGetMovie(t *testing.T) {
 				if !reflect.DeepEqual(actual.Cast, gotMovie.Cast) {
 					t.Errorf("\n\texpected: %v \n\tactual: %v", gotMovie.Cast, actual.Cast)
 				}
+				if !reflect.DeepEqual(actual.Tags, gotMovie.Tags) {
+					t.Errorf("\n\texpected: %v \n\tactual: %v", gotMovie.Tags, actual.Tags)
+				}
 			}
 		})
 	}
@@ -181,6 +232,7 @@ func TestListMovies(t *testing.T) {
 	mvObj1 := &moviepb.Movie{
 		Name:    "test_list_movie1",
 		Summary: "test_list_movies_summary1",
+		Tags:    []moviepb.Tag{moviepb.Tag_Action},
 		Cast:    []string{"test_cast1", "test_cast2"},
 	}
 	_, err = movieClient.CreateMovie(
@@ -196,6 +248,7 @@ func TestListMovies(t *testing.T) {
 	mvObj2 := &moviepb.Movie{
 		Name:    "test_list_movie2",
 		Summary: "test_list_movies_summary2",
+		Tags:    []moviepb.Tag{moviepb.Tag_Action},
 		Cast:    []string{"test_cast1", "test_cast2"},
 	}
 	_, err = movieClient.CreateMovie(
@@ -245,6 +298,234 @@ func TestListMovies(t *testing.T) {
 	}
 }
 
+func TestListMovies_pageToken(t *testing.T) {
+	ms := getMovieServer()
+	ctx := context.Background()
+
+	create := func(name string) string {
+		resp, err := ms.CreateMovie(ctx, &moviepb.CreateMovieRequest{
+			Movie: &moviepb.Movie{Name: name, Summary: "test_page_movie_summary", Tags: []moviepb.Tag{moviepb.Tag_Action}, Cast: []string{"test_cast1"}},
+		})
+		if err != nil {
+			t.Fatalf("Failed to create pre-requisite object! %v", err)
+		}
+		return resp.GetId()
+	}
+	for i := 0; i < 5; i++ {
+		create("test_page_movie" + strconv.Itoa(i))
+	}
+
+	// Records added or removed while paging must neither shift nor repeat pages
+	seen := map[string]bool{}
+	token := ""
+	for page := 0; ; page++ {
+		resp, err := ms.ListMovies(ctx, &moviepb.ListMoviesRequest{PageSize: 2, PageToken: token})
+		if err != nil {
+			t.Fatalf("ListMovies: unexpected err %v", err)
+		}
+		for _, mv := range resp.GetMovies() {
+			if seen[mv.GetName()] {
+				t.Errorf("ListMovies: movie %s returned twice", mv.GetName())
+			}
+			seen[mv.GetName()] = true
+		}
+		if page == 0 {
+			firstID := resp.GetMovies()[0].GetId()
+			if _, err := ms.DeleteMovie(ctx, &moviepb.DeleteMovieRequest{Id: firstID}); err != nil {
+				t.Fatalf("DeleteMovie: unexpected err %v", err)
+			}
+			create("test_page_movie_late")
+		}
+		if token = resp.GetNextPageToken(); token == "" {
+			break
+		}
+	}
+	if len(seen) != 6 || !seen["test_page_movie_late"] {
+		t.Errorf("ListMovies: want all 6 movies once, got %v", seen)
+	}
+
+	if _, err := ms.ListMovies(ctx, &moviepb.ListMoviesRequest{PageToken: "invalid"}); err == nil {
+		t.Error("ListMovies: want error for invalid page token")
+	}
+	// A token of the right query, but whose values do not match its order
+	forged := ms.token.ForCursor("create_time||false", persistence.Cursor{Values: []interface{}{"a", "b"}, Key: "id_0"})
+	if _, err := ms.ListMovies(ctx, &moviepb.ListMoviesRequest{PageToken: forged}); err != server.InvalidTokenErr {
+		t.Errorf("ListMovies: want %v for a cursor not matching the order, got %v", server.InvalidTokenErr, err)
+	}
+}
+
+func TestListMovies_filter(t *testing.T) {
+	ms := getMovieServer()
+	ctx := context.Background()
+
+	for _, mv := range []*moviepb.Movie{
+		{Name: "test_filter_movie1", Summary: "test_filter_summary", Tags: []moviepb.Tag{moviepb.Tag_Action, moviepb.Tag_Comedy},
+			Director: "test_director1", Cast: []string{"test_cast1"}},
+		{Name: "test_filter_movie2", Summary: "test_filter_summary", Tags: []moviepb.Tag{moviepb.Tag_Fantasy},
+			Director: "test_director2", Cast: []string{"test_cast2"}},
+	} {
+		if _, err := ms.CreateMovie(ctx, &moviepb.CreateMovieRequest{Movie: mv}); err != nil {
+			t.Fatalf("Failed to create pre-requisite object! %v", err)
+		}
+	}
+
+	tests := []TestCase{
+		{
+			name:        "found_movies",
+			args:        `cast:test_cast1 OR director = "test_director2"`,
+			expected:    2,
+			expectedErr: "",
+		},
+		{
+			name:        "found_movie1",
+			args:        `cast:test_cast1 AND create_time > "2021-01-01"`,
+			expected:    1,
+			expectedErr: "",
+		},
+		{
+			name:        "found_by_tag",
+			args:        `tags:Comedy OR tags:Fantasy`,
+			expected:    2,
+			expectedErr: "",
+		},
+		{
+			name:        "found_movie1_by_tag",
+			args:        `tags:Comedy AND NOT tags:Fantasy`,
+			expected:    1,
+			expectedErr: "",
+		},
+		{
+			name:        "bad_tag",
+			args:        `tags:Horror`,
+			expected:    0,
+			expectedErr: "rpc error: code = InvalidArgument desc = invalid filter: invalid value for field \"tags\": want one of UNDEFINED_TAG, Action, Adventure, Fantasy, Comedy at position 6 near `Horror`",
+		},
+		{
+			name:        "bad_field",
+			args:        `directer = "test_director1"`,
+			expected:    0,
+			expectedErr: "rpc error: code = InvalidArgument desc = invalid filter: unknown field \"directer\" at position 1 near `directer`",
+		},
+	}
+
+	for _, tcase := range tests {
+		t.Run(tcase.name, func(t *testing.T) {
+			req := &moviepb.ListMoviesRequest{Filter: tcase.args.(string)}
+			actual, err := ms.ListMovies(ctx, req)
+
+			if (err != nil || tcase.expectedErr != "") && (err == nil || err.Error() != tcase.expectedErr) {
+				t.Errorf("\n\texpected: %v \n\tactual: %v", tcase.expectedErr, err)
+			}
+			if len(actual.GetMovies()) != tcase.expected.(int) {
+				t.Errorf("\n\texpected: %v \n\tactual: %v", tcase.expected, len(actual.GetMovies()))
+			}
+		})
+	}
+}
+
+func TestListMovies_orderBy(t *testing.T) {
+	ms := getMovieServer()
+	ctx := context.Background()
+
+	for _, director := range []string{"test_director2", "test_director3", "test_director1"} {
+		mv := &moviepb.Movie{Name: "test_order_movie_" + director, Summary: "test_order_summary", Tags: []moviepb.Tag{moviepb.Tag_Action}, Director: director, Cast: []string{"test_cast1"}}
+		if _, err := ms.CreateMovie(ctx, &moviepb.CreateMovieRequest{Movie: mv}); err != nil {
+			t.Fatalf("Failed to create pre-requisite object! %v", err)
+		}
+	}
+
+	var directors []string
+	req := &moviepb.ListMoviesRequest{PageSize: 1, Filter: `summary = "test_order_summary"`, OrderBy: "director desc"}
+	for {
+		resp, err := ms.ListMovies(ctx, req)
+		if err != nil {
+			t.Fatalf("ListMovies: unexpected err %v", err)
+		}
+		for _, mv := range resp.GetMovies() {
+			directors = append(directors, mv.GetDirector())
+		}
+		if req.PageToken = resp.GetNextPageToken(); req.PageToken == "" {
+			break
+		}
+	}
+	if want := "[test_director3 test_director2 test_director1]"; fmt.Sprint(directors) != want {
+		t.Errorf("ListMovies: want directors %v, got %v", want, directors)
+	}
+
+	// The order cannot change while paging
+	resp, _ := ms.ListMovies(ctx, &moviepb.ListMoviesRequest{PageSize: 1, OrderBy: "director desc"})
+	_, err := ms.ListMovies(ctx, &moviepb.ListMoviesRequest{PageSize: 1, OrderBy: "director", PageToken: resp.GetNextPageToken()})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("ListMovies: want InvalidArgument for page token of another order, got %v", err)
+	}
+
+	_, err = ms.ListMovies(ctx, &moviepb.ListMoviesRequest{OrderBy: "summary"})
+	if want := "rpc error: code = InvalidArgument desc = invalid order_by: cannot sort by `summary`, " +
+		"sortable fields are name, director, create_time, update_time"; err == nil || err.Error() != want {
+		t.Errorf("\n\texpected: %v \n\tactual: %v", want, err)
+	}
+}
+
+func TestSearchMovies(t *testing.T) {
+	ms := getMovieServer()
+	ctx := context.Background()
+
+	for _, mv := range []*moviepb.Movie{
+		{Name: "test_search_movie1", Summary: "A thief who steals secrets through dreams", Tags: []moviepb.Tag{moviepb.Tag_Action}, Director: "Nolan", Cast: []string{"test_cast1"}},
+		{Name: "test_search_movie2", Summary: "Two rival magicians", Tags: []moviepb.Tag{moviepb.Tag_Action}, Director: "Nolan", Cast: []string{"test_cast2"}},
+		{Name: "test_search_movie3", Summary: "A trio of singers and their dreams", Tags: []moviepb.Tag{moviepb.Tag_Action}, Cast: []string{"test_cast3"}},
+	} {
+		if _, err := ms.CreateMovie(ctx, &moviepb.CreateMovieRequest{Movie: mv}); err != nil {
+			t.Fatalf("Failed to create pre-requisite object! %v", err)
+		}
+	}
+
+	var names []string
+	req := &moviepb.SearchMoviesRequest{Q: "nolan dreams", PageSize: 2}
+	for {
+		resp, err := ms.SearchMovies(ctx, req)
+		if err != nil {
+			t.Fatalf("SearchMovies: unexpected err %v", err)
+		}
+		for _, res := range resp.GetResults() {
+			names = append(names, res.GetMovie().GetName())
+		}
+		if req.PageToken = resp.GetNextPageToken(); req.PageToken == "" {
+			break
+		}
+	}
+	if want := "[test_search_movie1 test_search_movie2 test_search_movie3]"; fmt.Sprint(names) != want {
+		t.Errorf("SearchMovies: want %v, got %v", want, names)
+	}
+
+	// A page token continues the search of the same terms only
+	resp, err := ms.SearchMovies(ctx, &moviepb.SearchMoviesRequest{Q: "nolan dreams", PageSize: 1})
+	if err != nil || resp.GetNextPageToken() == "" {
+		t.Fatalf("SearchMovies: unexpected result %v, %v", resp, err)
+	}
+	if _, err := ms.SearchMovies(ctx, &moviepb.SearchMoviesRequest{Q: "the NOLAN, dreams", PageToken: resp.GetNextPageToken()}); err != nil {
+		t.Errorf("SearchMovies: want the token to continue the same terms, got %v", err)
+	}
+	if _, err := ms.SearchMovies(ctx, &moviepb.SearchMoviesRequest{Q: "magicians", PageToken: resp.GetNextPageToken()}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("SearchMovies: want InvalidArgument for a token of other terms, got %v", err)
+	}
+
+	resp, err = ms.SearchMovies(ctx, &moviepb.SearchMoviesRequest{Q: "magicians"})
+	if err != nil || len(resp.GetResults()) != 1 {
+		t.Fatalf("SearchMovies: unexpected result %v, %v", resp, err)
+	}
+	want := map[string]string{"summary": "Two rival <em>magicians</em>"}
+	if got := resp.GetResults()[0].GetHighlights(); !reflect.DeepEqual(got, want) {
+		t.Errorf("SearchMovies: want highlights %v, got %v", want, got)
+	}
+
+	for _, q := range []string{"", "  the  "} {
+		if _, err := ms.SearchMovies(ctx, &moviepb.SearchMoviesRequest{Q: q}); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("SearchMovies: want InvalidArgument for query %q, got %v", q, err)
+		}
+	}
+}
+
 func TestUpdateMovie(t *testing.T) {
 
 	// Mock server using Client
@@ -262,6 +543,7 @@ func TestUpdateMovie(t *testing.T) {
 	mvObj := &moviepb.Movie{
 		Name:    "test_update_movie",
 		Summary: "test_update_movie_summary",
+		Tags:    []moviepb.Tag{moviepb.Tag_Action},
 		Cast:    []string{"test_cast1", "test_cast2"},
 	}
 	resp, err := movieClient.CreateMovie(context.Background(),
@@ -289,6 +571,7 @@ func TestUpdateMovie(t *testing.T) {
 					Id:      "9e6f9248-e147-4cbe-9c4f-e3d06c79e361",
 					Name:    "test_update_movie",
 					Summary: "test_update_movie_summary",
+					Tags:    []moviepb.Tag{moviepb.Tag_Action},
 					Cast:    []string{"test_cast1", "test_cast2"},
 				},
 			},
@@ -302,6 +585,7 @@ func TestUpdateMovie(t *testing.T) {
 				Movie: &moviepb.Movie{
 					Name:    "test_update_movie",
 					Summary: "summary",
+					Tags:    []moviepb.Tag{moviepb.Tag_Action},
 					Cast:    []string{"test_cast1", "test_cast2"},
 				},
 			},
@@ -315,6 +599,7 @@ func TestUpdateMovie(t *testing.T) {
 				Movie: &moviepb.Movie{
 					Name:    "test_update_movie",
 					Summary: "test_update_movie_summary_updated",
+					Tags:    []moviepb.Tag{moviepb.Tag_Action},
 					Cast:    []string{"test_cast1", "test_cast2"},
 				},
 			},
@@ -340,6 +625,69 @@ func TestUpdateMovie(t *testing.T) {
 	}
 }
 
+func TestPartialUpdateMovie(t *testing.T) {
+	ms := getMovieServer()
+	ctx := context.Background()
+
+	mv := &moviepb.Movie{Name: "test_patch_movie", Summary: "test_patch_summary", Tags: []moviepb.Tag{moviepb.Tag_Action}, Cast: []string{"test_cast1"},
+		Director: "test_director"}
+	resp, err := ms.CreateMovie(ctx, &moviepb.CreateMovieRequest{Movie: mv})
+	if err != nil {
+		t.Fatalf("Failed to create pre-requisite object! %v", err)
+	}
+	movieID := resp.GetId()
+	if _, err := ms.CreateMovie(ctx, &moviepb.CreateMovieRequest{Movie: &moviepb.Movie{Name: "test_patch_movie2",
+		Summary: "test_patch_summary", Tags: []moviepb.Tag{moviepb.Tag_Action}}}); err != nil {
+		t.Fatalf("Failed to create pre-requisite object! %v", err)
+	}
+	stored, _ := ms.movies.FindMovieByID(ctx, movieID)
+
+	// Only the fields given change, tags included
+	summary := "test_patch_summary_updated"
+	req := &moviepb.PartialUpdateMovieRequest{Id: movieID, Summary: &summary, Tags: []moviepb.Tag{moviepb.Tag_Comedy}}
+	if resp, err := ms.PartialUpdateMovie(ctx, req); err != nil || resp.GetId() != movieID {
+		t.Fatalf("PartialUpdateMovie: unexpected result %v, %v", resp, err)
+	}
+	found, _ := ms.movies.FindMovieByID(ctx, movieID)
+	if found.Name != mv.Name || found.Summary != summary || len(found.Cast) != 1 || found.Director != mv.Director ||
+		len(found.Tags) != 1 || found.Tags[0] != persistence.Tag_Comedy || found.Etag == stored.Etag ||
+		!found.UpdateTime.AsTime().After(stored.UpdateTime.AsTime()) {
+		t.Errorf("PartialUpdateMovie: want the summary and tags updated only, got %+v", found)
+	}
+
+	// An update mask clears the fields it names
+	req = &moviepb.PartialUpdateMovieRequest{Id: movieID, UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"cast"}}}
+	if _, err := ms.PartialUpdateMovie(ctx, req); err != nil {
+		t.Fatalf("PartialUpdateMovie: unexpected err %v", err)
+	}
+	if found, _ := ms.movies.FindMovieByID(ctx, movieID); len(found.Cast) != 0 || found.Summary != summary {
+		t.Errorf("PartialUpdateMovie: want the cast cleared only, got %+v", found)
+	}
+
+	short, taken := "summary", "test_patch_movie2"
+	tests := []struct {
+		name     string
+		req      *moviepb.PartialUpdateMovieRequest
+		wantCode codes.Code
+	}{
+		{"no_field", &moviepb.PartialUpdateMovieRequest{Id: movieID}, codes.InvalidArgument},
+		{"invalid_summary", &moviepb.PartialUpdateMovieRequest{Id: movieID, Summary: &short}, codes.InvalidArgument},
+		{"invalid_cast", &moviepb.PartialUpdateMovieRequest{Id: movieID, Cast: []string{""}}, codes.InvalidArgument},
+		{"masked_name", &moviepb.PartialUpdateMovieRequest{Id: movieID, UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name"}}}, codes.InvalidArgument},
+		{"output_only", &moviepb.PartialUpdateMovieRequest{Id: movieID, UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"create_time"}}}, codes.InvalidArgument},
+		{"duplicate_name", &moviepb.PartialUpdateMovieRequest{Id: movieID, Name: &taken}, codes.AlreadyExists},
+		{"stale_etag", &moviepb.PartialUpdateMovieRequest{Id: movieID, Summary: &summary, Etag: stored.Etag}, codes.FailedPrecondition},
+		{"not_exists", &moviepb.PartialUpdateMovieRequest{Id: "missing_id", Summary: &summary}, codes.NotFound},
+	}
+	for _, tcase := range tests {
+		t.Run(tcase.name, func(t *testing.T) {
+			if _, err := ms.PartialUpdateMovie(ctx, tcase.req); status.Code(err) != tcase.wantCode {
+				t.Errorf("PartialUpdateMovie: want %v, got %v", tcase.wantCode, err)
+			}
+		})
+	}
+}
+
 func TestDeleteMovie(t *testing.T) {
 
 	// Mock server using Client
@@ -357,6 +705,7 @@ func TestDeleteMovie(t *testing.T) {
 	mvObj := &moviepb.Movie{
 		Name:    "test_delete_movie",
 		Summary: "test_delete_movie_summary",
+		Tags:    []moviepb.Tag{moviepb.Tag_Action},
 		Cast:    []string{"test_cast1", "test_cast2"},
 	}
 	resp, err := movieClient.CreateMovie(context.Background(),
@@ -380,6 +729,12 @@ func TestDeleteMovie(t *testing.T) {
 			expected:    &empty.Empty{},
 			expectedErr: "",
 		},
+		{
+			name:        "already_removed",
+			args:        movieID,
+			expected:    nil,
+			expectedErr: "rpc error: code = NotFound desc = Movie Record with ID:" + movieID + " does not exist!",
+		},
 	}
 
 	// Start checking tests
@@ -403,6 +758,179 @@ func TestDeleteMovie(t *testing.T) {
 	}
 }
 
+func TestUndeleteMovie(t *testing.T) {
+	ms := getMovieServer()
+	ctx := context.Background()
+
+	mv := &moviepb.Movie{Name: "test_undelete_movie", Summary: "test_undelete_summary", Tags: []moviepb.Tag{moviepb.Tag_Action}, Cast: []string{"test_cast1"}}
+	resp, err := ms.CreateMovie(ctx, &moviepb.CreateMovieRequest{Movie: mv})
+	if err != nil {
+		t.Fatalf("Failed to create pre-requisite object! %v", err)
+	}
+	movieID := resp.GetId()
+
+	if _, err := ms.UndeleteMovie(ctx, &moviepb.UndeleteMovieRequest{Id: movieID}); status.Code(err) != codes.AlreadyExists {
+		t.Errorf("UndeleteMovie: want AlreadyExists for a movie which is not deleted, got %v", err)
+	}
+	if _, err := ms.DeleteMovie(ctx, &moviepb.DeleteMovieRequest{Id: movieID}); err != nil {
+		t.Fatalf("DeleteMovie: unexpected err %v", err)
+	}
+
+	// A deleted movie is hidden, except from admins listing the deleted ones
+	if _, err := ms.GetMovie(ctx, &moviepb.GetMovieRequest{Id: movieID}); status.Code(err) != codes.NotFound {
+		t.Errorf("GetMovie: want NotFound for a deleted movie, got %v", err)
+	}
+	if search, err := ms.SearchMovies(ctx, &moviepb.SearchMoviesRequest{Q: "test_undelete_movie"}); err != nil || len(search.GetResults()) != 0 {
+		t.Errorf("SearchMovies: want no deleted movie, got %v, %v", search, err)
+	}
+	req := &moviepb.ListMoviesRequest{Filter: `summary = "test_undelete_summary"`}
+	if list, err := ms.ListMovies(ctx, req); err != nil || len(list.GetMovies()) != 0 {
+		t.Errorf("ListMovies: want no deleted movie, got %v, %v", list, err)
+	}
+	req.ShowDeleted = true
+	interceptors.CURRENT_ROLE = "NORMAL"
+	if _, err := ms.ListMovies(ctx, req); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("ListMovies: want PermissionDenied to show deleted movies, got %v", err)
+	}
+	interceptors.CURRENT_ROLE = "ADMIN"
+	list, err := ms.ListMovies(ctx, req)
+	if err != nil || len(list.GetMovies()) != 1 || list.GetMovies()[0].GetDeleteTime() == nil {
+		t.Errorf("ListMovies: want the deleted movie, got %v, %v", list, err)
+	}
+
+	restored, err := ms.UndeleteMovie(ctx, &moviepb.UndeleteMovieRequest{Id: movieID})
+	if err != nil || restored.GetName() != mv.GetName() || restored.GetDeleteTime() != nil {
+		t.Fatalf("UndeleteMovie: unexpected result %v, %v", restored, err)
+	}
+	if _, err := ms.GetMovie(ctx, &moviepb.GetMovieRequest{Id: movieID}); err != nil {
+		t.Errorf("GetMovie: unexpected err %v for a restored movie", err)
+	}
+	if search, err := ms.SearchMovies(ctx, &moviepb.SearchMoviesRequest{Q: "test_undelete_movie"}); err != nil || len(search.GetResults()) != 1 {
+		t.Errorf("SearchMovies: want the restored movie, got %v, %v", search, err)
+	}
+
+	if _, err := ms.UndeleteMovie(ctx, &moviepb.UndeleteMovieRequest{Id: "9e6f9248-e147-4cbe-9c4f-e3d06c79e361"}); status.Code(err) != codes.NotFound {
+		t.Errorf("UndeleteMovie: want NotFound for a missing movie, got %v", err)
+	}
+}
+
+func TestMovieEtag(t *testing.T) {
+	ms := getMovieServer()
+	ctx := context.Background()
+
+	mv := &moviepb.Movie{Name: "test_etag_movie", Summary: "test_etag_summary", Tags: []moviepb.Tag{moviepb.Tag_Action}, Cast: []string{"test_cast1"}}
+	resp, err := ms.CreateMovie(ctx, &moviepb.CreateMovieRequest{Movie: mv})
+	if err != nil {
+		t.Fatalf("Failed to create pre-requisite object! %v", err)
+	}
+	movieID := resp.GetId()
+
+	read, err := ms.GetMovie(ctx, &moviepb.GetMovieRequest{Id: movieID})
+	if err != nil || read.GetEtag() == "" {
+		t.Fatalf("GetMovie: want the movie with its etag, got %v, %v", read, err)
+	}
+
+	update := &moviepb.Movie{Name: "test_etag_movie", Summary: "test_etag_summary_2", Tags: []moviepb.Tag{moviepb.Tag_Action}, Etag: read.GetEtag()}
+	if _, err := ms.UpdateMovie(ctx, &moviepb.UpdateMovieRequest{Id: movieID, Movie: update}); err != nil {
+		t.Fatalf("UpdateMovie: unexpected err %v", err)
+	}
+
+	// The etag read before the update is stale now
+	update.Summary = "test_etag_summary_3"
+	_, err = ms.UpdateMovie(ctx, &moviepb.UpdateMovieRequest{Id: movieID, Movie: update})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("UpdateMovie: want FailedPrecondition for a stale etag, got %v", err)
+	}
+	if _, err := ms.DeleteMovie(ctx, &moviepb.DeleteMovieRequest{Id: movieID, Etag: read.GetEtag()}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("DeleteMovie: want FailedPrecondition for a stale etag, got %v", err)
+	}
+
+	// The If-Match header forwarded by the gateway stands in for the etag field
+	current, _ := ms.GetMovie(ctx, &moviepb.GetMovieRequest{Id: movieID})
+	stale := metadata.NewIncomingContext(ctx, metadata.Pairs(ifMatchKey, `"`+read.GetEtag()+`"`))
+	if _, err := ms.DeleteMovie(stale, &moviepb.DeleteMovieRequest{Id: movieID}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("DeleteMovie: want FailedPrecondition for a stale If-Match, got %v", err)
+	}
+	matching := metadata.NewIncomingContext(ctx, metadata.Pairs(ifMatchKey, `"`+current.GetEtag()+`"`))
+	if _, err := ms.DeleteMovie(matching, &moviepb.DeleteMovieRequest{Id: movieID}); err != nil {
+		t.Errorf("DeleteMovie: unexpected err %v", err)
+	}
+}
+
+// watchStream is a MovieService_WatchMoviesServer passing the events sent to a channel.
+type watchStream struct {
+	ctx    context.Context
+	events chan *moviepb.MovieEvent
+
+	grpc.ServerStream
+}
+
+func (ws *watchStream) Context() context.Context {
+	return ws.ctx
+}
+
+func (ws *watchStream) Send(ev *moviepb.MovieEvent) error {
+	ws.events <- ev
+	return nil
+}
+
+func TestWatchMovies(t *testing.T) {
+	ms := getMovieServer()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream := &watchStream{ctx: ctx, events: make(chan *moviepb.MovieEvent, 10)}
+	done := make(chan error)
+	go func() {
+		done <- ms.WatchMovies(&moviepb.WatchMoviesRequest{}, stream)
+	}()
+
+	// The watch begins with the changes from now on, hence wait for it
+	var movieID string
+	var created *moviepb.MovieEvent
+	for i := 0; created == nil; i++ {
+		resp, err := ms.CreateMovie(ctx, &moviepb.CreateMovieRequest{Movie: &moviepb.Movie{
+			Name: "test_watch_movie_" + strconv.Itoa(i), Summary: "test_watch_summary", Tags: []moviepb.Tag{moviepb.Tag_Action}, Cast: []string{"test_cast1"}}})
+		if err != nil {
+			t.Fatalf("Failed to create pre-requisite object! %v", err)
+		}
+		movieID = resp.GetId()
+		select {
+		case created = <-stream.events:
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	if created.GetType() != moviepb.MovieEvent_CREATED || created.GetMovie().GetId() != movieID || created.GetResumeToken() == "" {
+		t.Errorf("WatchMovies: want the created movie, got %v", created)
+	}
+
+	if _, err := ms.DeleteMovie(ctx, &moviepb.DeleteMovieRequest{Id: movieID}); err != nil {
+		t.Fatalf("DeleteMovie: unexpected err %v", err)
+	}
+	if deleted := <-stream.events; deleted.GetType() != moviepb.MovieEvent_DELETED || deleted.GetMovie().GetDeleteTime() == nil {
+		t.Errorf("WatchMovies: want the deleted movie, got %v", deleted)
+	}
+
+	cancel()
+	if err := <-done; status.Code(err) != codes.Canceled {
+		t.Errorf("WatchMovies: want Canceled once the client is gone, got %v", err)
+	}
+
+	// A resumed watch gets the events after the token
+	resumedCtx, cancelResumed := context.WithCancel(context.Background())
+	defer cancelResumed()
+	resumed := &watchStream{ctx: resumedCtx, events: make(chan *moviepb.MovieEvent, 10)}
+	go ms.WatchMovies(&moviepb.WatchMoviesRequest{ResumeToken: created.GetResumeToken()}, resumed)
+	if deleted := <-resumed.events; deleted.GetType() != moviepb.MovieEvent_DELETED {
+		t.Errorf("WatchMovies: want the deleted movie after resuming, got %v", deleted)
+	}
+
+	err := ms.WatchMovies(&moviepb.WatchMoviesRequest{ResumeToken: "not_a_token"}, resumed)
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("WatchMovies: want InvalidArgument for a bad resume token, got %v", err)
+	}
+}
+
 func BenchmarkCreateMovie(b *testing.B) {
 
 	TestMovieSrv = getMovieServer()
@@ -425,6 +953,7 @@ func BenchmarkGetMovie(b *testing.B) {
 	mvObj := &moviepb.Movie{
 		Name:    "test_get_movie",
 		Summary: "test_get_movie_summary",
+		Tags:    []moviepb.Tag{moviepb.Tag_Action},
 		Cast:    []string{"test_cast1", "test_cast2"},
 	}
 	resp, err := TestMovieSrv.CreateMovie(context.Background(),
@@ -451,6 +980,7 @@ func BenchmarkDeleteMovie(b *testing.B) {
 		mvObj := &moviepb.Movie{
 			Name:    "test_delete_movie",
 			Summary: "test_delete_movie_summary",
+			Tags:    []moviepb.Tag{moviepb.Tag_Action},
 			Cast:    []string{"test_cast1", "test_cast2"},
 		}
 		resp, err := TestMovieSrv.CreateMovie(context.Background(),
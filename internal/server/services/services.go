@@ -8,6 +8,8 @@ import (
 	moviepb "github.com/AkashGit21/ms-project/internal/grpc/movie"
 	"github.com/AkashGit21/ms-project/internal/server"
 	"github.com/AkashGit21/ms-project/internal/server/interceptors"
+	"github.com/AkashGit21/ms-project/lib/events"
+	"github.com/AkashGit21/ms-project/lib/persistence"
 )
 
 // Backend contains the various service backends that will be
@@ -20,6 +22,11 @@ type Backend struct {
 
 	AuthInterceptor *interceptors.AuthInterceptor
 
+	// Events has the subscribers to the domain events within this process
+	Events *events.Bus
+	// Cache of the database, nil if disabled
+	Cache *persistence.CachingHandler
+
 	// Supporting protos
 
 	// Other supporting data structures
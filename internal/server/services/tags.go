@@ -0,0 +1,47 @@
+package services
+
+import (
+	"fmt"
+
+	moviepb "github.com/AkashGit21/ms-project/internal/grpc/movie"
+	"github.com/AkashGit21/ms-project/lib/persistence"
+)
+
+// toPersistenceTags converts the tags of the API to the stored ones. They are
+// matched by name, so that the two enums need not number them alike. A tag
+// unknown to the database becomes UNDEFINED_TAG, which validateTags rejects.
+func toPersistenceTags(tags []moviepb.Tag) []persistence.Tag {
+	var result []persistence.Tag
+	for _, t := range tags {
+		result = append(result, persistence.Tag(persistence.Tag_value[t.String()]))
+	}
+	return result
+}
+
+// toMoviePBTags converts the stored tags to those of the API, by name.
+func toMoviePBTags(tags []persistence.Tag) []moviepb.Tag {
+	var result []moviepb.Tag
+	for _, t := range tags {
+		result = append(result, moviepb.Tag(moviepb.Tag_value[persistence.Tag_name[int32(t)]]))
+	}
+	return result
+}
+
+// validateTags checks that a movie has at least one tag, each of them
+// defined and given once.
+func validateTags(tags []moviepb.Tag) error {
+	if len(tags) == 0 {
+		return fmt.Errorf("At least one tag is required.")
+	}
+	seen := map[moviepb.Tag]bool{}
+	for _, t := range tags {
+		if _, ok := persistence.Tag_value[t.String()]; !ok || t == moviepb.Tag_UNDEFINED_TAG {
+			return fmt.Errorf("The tag %v is not allowed.", t)
+		}
+		if seen[t] {
+			return fmt.Errorf("The tag %v is repeated.", t)
+		}
+		seen[t] = true
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	moviepb "github.com/AkashGit21/ms-project/internal/grpc/movie"
+	"github.com/AkashGit21/ms-project/lib/persistence"
+)
+
+func TestTagConversion(t *testing.T) {
+	// Every tag of the API is stored under the same name, and read back
+	for value, name := range moviepb.Tag_name {
+		tag := moviepb.Tag(value)
+		stored := toPersistenceTags([]moviepb.Tag{tag})
+		if len(stored) != 1 || persistence.Tag_name[int32(stored[0])] != name {
+			t.Errorf("toPersistenceTags: want the stored tag %s, got %v", name, stored)
+		}
+		if got := toMoviePBTags(stored); !reflect.DeepEqual(got, []moviepb.Tag{tag}) {
+			t.Errorf("toMoviePBTags: want %v, got %v", tag, got)
+		}
+	}
+
+	tags := []moviepb.Tag{moviepb.Tag_Comedy, moviepb.Tag_Action}
+	want := []persistence.Tag{persistence.Tag_Comedy, persistence.Tag_Action}
+	if got := toPersistenceTags(tags); !reflect.DeepEqual(got, want) {
+		t.Errorf("toPersistenceTags: want the order kept %v, got %v", want, got)
+	}
+	if got := toPersistenceTags([]moviepb.Tag{moviepb.Tag(99)}); !reflect.DeepEqual(got, []persistence.Tag{persistence.Tag_UNDEFINED_TAG}) {
+		t.Errorf("toPersistenceTags: want an unknown tag undefined, got %v", got)
+	}
+	if toPersistenceTags(nil) != nil || toMoviePBTags(nil) != nil {
+		t.Error("want no tags converted to none")
+	}
+}
+
+func TestValidateTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		tags    []moviepb.Tag
+		wantErr string
+	}{
+		{name: "valid", tags: []moviepb.Tag{moviepb.Tag_Action, moviepb.Tag_Fantasy}},
+		{name: "missing", tags: nil, wantErr: "At least one tag is required."},
+		{name: "undefined", tags: []moviepb.Tag{moviepb.Tag_Action, moviepb.Tag_UNDEFINED_TAG}, wantErr: "The tag UNDEFINED_TAG is not allowed."},
+		{name: "unknown", tags: []moviepb.Tag{moviepb.Tag(99)}, wantErr: "The tag 99 is not allowed."},
+		{name: "repeated", tags: []moviepb.Tag{moviepb.Tag_Comedy, moviepb.Tag_Action, moviepb.Tag_Comedy}, wantErr: "The tag Comedy is repeated."},
+	}
+	for _, tcase := range tests {
+		t.Run(tcase.name, func(t *testing.T) {
+			err := validateTags(tcase.tags)
+			if tcase.wantErr == "" && err != nil || tcase.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tcase.wantErr)) {
+				t.Errorf("validateTags: want an error %q, got %v", tcase.wantErr, err)
+			}
+		})
+	}
+}
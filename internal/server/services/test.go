@@ -10,7 +10,6 @@ import (
 	identitypb "github.com/AkashGit21/ms-project/internal/grpc/identity"
 	moviepb "github.com/AkashGit21/ms-project/internal/grpc/movie"
 	"github.com/AkashGit21/ms-project/internal/server"
-	"github.com/AkashGit21/ms-project/lib/configuration"
 	"github.com/AkashGit21/ms-project/lib/persistence/dblayer"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/test/bufconn"
@@ -33,12 +32,12 @@ func dialer() func(context.Context, string) (net.Conn, error) {
 	listener := bufconn.Listen(1024 * 1024)
 
 	grpcServer := grpc.NewServer()
-	dbhandler, _ := dblayer.NewPersistenceLayer(configuration.DBTypeDefault, configuration.DBConnectionDefault)
+	dbhandler, _ := dblayer.NewPersistenceLayer(dblayer.MEMORY, "")
 
 	TestIdentitySrv = NewIdentityServer(dbhandler)
-	TestAuthSrv = NewAuthServer(TestIdentitySrv)
+	TestAuthSrv = NewAuthServer(TestIdentitySrv, dbhandler)
 	TestAuthSrv.JWT = server.NewJWTManager(SecretKey, 2*time.Minute)
-	TestMovieSrv = NewMovieServer(TestAuthSrv)
+	TestMovieSrv = NewMovieServer(TestAuthSrv, dbhandler)
 
 	identitypb.RegisterIdentityServiceServer(grpcServer, TestIdentitySrv)
 	authpb.RegisterAuthServiceServer(grpcServer, TestAuthSrv)
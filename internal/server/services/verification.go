@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	identitypb "github.com/AkashGit21/ms-project/internal/grpc/identity"
+	"github.com/AkashGit21/ms-project/internal/server"
+	"github.com/AkashGit21/ms-project/lib/mail"
+	"github.com/AkashGit21/ms-project/lib/persistence"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// verifyEmailTTL is how long the verification tokens are valid by default
+	verifyEmailTTL = 24 * time.Hour
+	// resendInterval is the least time between two verifications resent to a user
+	resendInterval = time.Minute
+)
+
+const verifyEmailBody = `Hello %s,
+
+Please verify your email by opening the link below, before %s:
+
+%s
+
+If you did not sign up, you can ignore this email.
+`
+
+// Verifies the email of a user with the token mailed to them. The token is
+// used once: it is rejected once the email is verified, or changed.
+func (is *identityServer) VerifyEmail(ctx context.Context,
+	req *identitypb.VerifyEmailRequest) (*identitypb.VerifyEmailResponse, error) {
+	log.Println("Beginning VerifyEmail request")
+
+	claims, err := is.EmailTokens.Verify(req.GetToken())
+	if errors.Is(err, server.ErrTokenExpired) {
+		return nil, invalidArgument("token", errors.New("the token expired, please ask for a new one"))
+	} else if err != nil {
+		log.Println("[DEBUG] rejected email token: ", err)
+		return nil, invalidArgument("token", errors.New("the token is not valid"))
+	}
+
+	uname := claims.Username
+	stored, err := is.users.FindByUsername(ctx, uname)
+	if err != nil && !errors.Is(err, persistence.ErrNotFound) {
+		return nil, toStatus(err)
+	}
+	if err != nil || !stored.Active || stored.DeleteTime != nil {
+		return nil, status.Errorf(codes.NotFound, "A user with username `%s` not found!", uname)
+	}
+	if stored.Email != claims.Email {
+		return nil, emailNotVerifiable("the token was sent to another email than the one of the user")
+	}
+	if stored.EmailVerifyTime != nil {
+		return nil, emailNotVerifiable("the email of the user is already verified")
+	}
+
+	// The etag read makes a concurrent change of the email fail the write
+	err = is.users.SetUserEmailVerifyTime(ctx, uname, stored.Etag, ptypes.TimestampNow())
+	if errors.Is(err, persistence.ErrEtagMismatch) {
+		return nil, withDetails(status.New(codes.Aborted, "the user changed meanwhile, please retry"),
+			&errdetails.RetryInfo{RetryDelay: ptypes.DurationProto(retryDelay)})
+	} else if err != nil {
+		return nil, toStatus(err)
+	}
+
+	log.Println("[DEBUG] End VerifyEmail!")
+	return &identitypb.VerifyEmailResponse{Username: uname}, nil
+}
+
+// Mails a new verification token to a user whose email is not verified yet.
+// Like Login, it does not tell whether the user exists: nothing is mailed to
+// unknown users, nor to those already verified.
+func (is *identityServer) ResendVerification(ctx context.Context,
+	req *identitypb.ResendVerificationRequest) (*empty.Empty, error) {
+	log.Println("Beginning ResendVerification request: ", req)
+
+	uname := req.GetUsername()
+	if uname == "" {
+		return nil, invalidArgument("username", errors.New("the field `username` is required"))
+	}
+
+	// Every request counts, so that the limit tells nothing either
+	if wait := is.reserveResend(uname, time.Now()); wait > 0 {
+		return nil, withDetails(status.New(codes.ResourceExhausted, "a verification was sent recently, please retry later"),
+			&errdetails.RetryInfo{RetryDelay: ptypes.DurationProto(wait)})
+	}
+
+	// The mail is sent unlocked, like in CreateUser
+	stored, err := is.users.FindByUsername(ctx, uname)
+	if err != nil && !errors.Is(err, persistence.ErrNotFound) {
+		return nil, toStatus(err)
+	}
+	if err == nil && stored.Active && stored.DeleteTime == nil && stored.EmailVerifyTime == nil {
+		if err := is.sendVerification(ctx, stored); err != nil {
+			log.Printf("[ERROR] Cannot mail the verification to %s: %v", uname, err)
+			return nil, status.Error(codes.Unavailable, "cannot send the mail, please retry later")
+		}
+	}
+
+	log.Println("[DEBUG] End ResendVerification!")
+	return &empty.Empty{}, nil
+}
+
+// reserveResend records a verification resent to uname at now, unless one was
+// within resendInterval, in which case it returns how long to wait for the next.
+func (is *identityServer) reserveResend(uname string, now time.Time) time.Duration {
+	is.mu.Lock()
+	defer is.mu.Unlock()
+
+	for name, last := range is.resent {
+		if now.Sub(last) >= resendInterval {
+			delete(is.resent, name)
+		}
+	}
+	if last, ok := is.resent[uname]; ok {
+		return last.Add(resendInterval).Sub(now)
+	}
+	is.resent[uname] = now
+	return 0
+}
+
+// sendVerification mails u a token verifying their email, as a link to the
+// verification URL if there is one.
+func (is *identityServer) sendVerification(ctx context.Context, u persistence.User) error {
+	token, err := is.EmailTokens.GenerateToken(u.Username, u.Email)
+	if err != nil {
+		return fmt.Errorf("cannot generate the token: %w", err)
+	}
+
+	link := token
+	if is.VerifyEmailURL != "" {
+		verifyURL, err := url.Parse(is.VerifyEmailURL)
+		if err != nil {
+			return fmt.Errorf("invalid verification URL: %w", err)
+		}
+		query := verifyURL.Query()
+		query.Set("token", token)
+		verifyURL.RawQuery = query.Encode()
+		link = verifyURL.String()
+	}
+
+	name := u.FirstName
+	if name == "" {
+		name = u.Username
+	}
+	expiry := time.Now().Add(is.EmailTokens.TokenDuration()).UTC().Format(time.RFC1123)
+	return is.Mailer.Send(ctx, mail.Message{
+		To:      u.Email,
+		Subject: "Verify your email",
+		Body:    fmt.Sprintf(verifyEmailBody, name, expiry, link),
+	})
+}
+
+// emailNotVerifiable returns a FailedPrecondition status for a verification
+// token which cannot be used, with a violation of type EMAIL_VERIFICATION.
+func emailNotVerifiable(description string) error {
+	return withDetails(status.New(codes.FailedPrecondition, description),
+		&errdetails.PreconditionFailure{Violations: []*errdetails.PreconditionFailure_Violation{{
+			Type:        "EMAIL_VERIFICATION",
+			Subject:     "token",
+			Description: description,
+		}}})
+}
@@ -0,0 +1,302 @@
+package services
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	authpb "github.com/AkashGit21/ms-project/internal/grpc/auth"
+	identitypb "github.com/AkashGit21/ms-project/internal/grpc/identity"
+	"github.com/AkashGit21/ms-project/internal/server"
+	"github.com/AkashGit21/ms-project/internal/server/interceptors"
+	"github.com/AkashGit21/ms-project/lib/mail"
+	"github.com/AkashGit21/ms-project/lib/persistence"
+	"github.com/AkashGit21/ms-project/lib/persistence/dblayer"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// mailbox is a Mailer keeping the messages it is sent.
+type mailbox struct {
+	mu   sync.Mutex
+	msgs []mail.Message
+}
+
+func (mb *mailbox) Send(ctx context.Context, msg mail.Message) error {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+	mb.msgs = append(mb.msgs, msg)
+	return nil
+}
+
+func (mb *mailbox) count() int {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+	return len(mb.msgs)
+}
+
+var tokenParam = regexp.MustCompile(`\?token=(\S+)`)
+
+// lastToken returns the recipient and the token of the link of the last mail.
+func (mb *mailbox) lastToken(t *testing.T) (string, string) {
+	t.Helper()
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+	if len(mb.msgs) == 0 {
+		t.Fatal("no mail was sent")
+	}
+	msg := mb.msgs[len(mb.msgs)-1]
+	match := tokenParam.FindStringSubmatch(msg.Body)
+	if match == nil {
+		t.Fatalf("no verification link in the mail %q", msg.Body)
+	}
+	token, err := url.QueryUnescape(match[1])
+	if err != nil {
+		t.Fatalf("bad token in the mail %q: %v", msg.Body, err)
+	}
+	return msg.To, token
+}
+
+// newVerifyingIdentityServer returns an identity server on a memory database,
+// mailing to the returned mailbox.
+func newVerifyingIdentityServer() (*identityServer, *mailbox, persistence.DatabaseHandler) {
+	dbhandler, _ := dblayer.NewPersistenceLayer(dblayer.MEMORY, "")
+	is := NewIdentityServer(dbhandler)
+	mb := &mailbox{}
+	is.Mailer = mb
+	is.VerifyEmailURL = "http://localhost:8081/v1/users:verifyEmail"
+	return is, mb, dbhandler
+}
+
+func TestVerifyEmail(t *testing.T) {
+	is, mb, _ := newVerifyingIdentityServer()
+	ctx := context.Background()
+
+	userObj := &identitypb.User{
+		Username:  "test_verify_username",
+		Email:     "test_verify_email@domain.in",
+		Password:  "test_verify_pwd",
+		Role:      identitypb.Role_NORMAL,
+		FirstName: "test_first",
+	}
+	if _, err := is.CreateUser(ctx, &identitypb.CreateUserRequest{User: userObj}); err != nil {
+		t.Fatalf("Failed to create pre-requisite object! %v", err)
+	}
+	uname := userObj.GetUsername()
+	to, token := mb.lastToken(t)
+	if to != userObj.GetEmail() {
+		t.Errorf("CreateUser: want the verification mailed to %s, got %s", userObj.GetEmail(), to)
+	}
+
+	interceptors.CURRENT_ROLE = "ADMIN"
+	if u, err := is.GetUser(ctx, &identitypb.GetUserRequest{Username: uname}); err != nil || u.GetEmailVerifyTime() != nil {
+		t.Errorf("GetUser: want a user not verified yet, got %v, %v", u, err)
+	}
+
+	if _, err := is.VerifyEmail(ctx, &identitypb.VerifyEmailRequest{Token: "garbage"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("VerifyEmail: want InvalidArgument for a bad token, got %v", err)
+	}
+	res, err := is.VerifyEmail(ctx, &identitypb.VerifyEmailRequest{Token: token})
+	if err != nil || res.GetUsername() != uname {
+		t.Fatalf("VerifyEmail: unexpected result %v, %v", res, err)
+	}
+	if u, err := is.GetUser(ctx, &identitypb.GetUserRequest{Username: uname}); err != nil || u.GetEmailVerifyTime() == nil {
+		t.Errorf("GetUser: want a verified user, got %v, %v", u, err)
+	}
+
+	// A token is used once
+	if _, err := is.VerifyEmail(ctx, &identitypb.VerifyEmailRequest{Token: token}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("VerifyEmail: want FailedPrecondition for a used token, got %v", err)
+	}
+
+	// Changing the email unverifies it, and mails the new one
+	u, err := is.UpdateUser(ctx, &identitypb.UpdateUserRequest{
+		Username:   uname,
+		User:       &identitypb.User{Email: "test_verify_new_email@domain.in"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"email"}},
+	})
+	if err != nil || u.GetEmailVerifyTime() != nil {
+		t.Fatalf("UpdateUser: want the new email not verified, got %v, %v", u, err)
+	}
+	to, newToken := mb.lastToken(t)
+	if to != u.GetEmail() {
+		t.Errorf("UpdateUser: want the verification mailed to %s, got %s", u.GetEmail(), to)
+	}
+	if _, err := is.VerifyEmail(ctx, &identitypb.VerifyEmailRequest{Token: token}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("VerifyEmail: want FailedPrecondition for the token of the old email, got %v", err)
+	}
+	if _, err := is.VerifyEmail(ctx, &identitypb.VerifyEmailRequest{Token: newToken}); err != nil {
+		t.Errorf("VerifyEmail: unexpected err %v for the new email", err)
+	}
+
+	// The email verification time cannot be set by an update
+	u, _ = is.GetUser(ctx, &identitypb.GetUserRequest{Username: uname})
+	u.EmailVerifyTime.Seconds++
+	if _, err := is.UpdateUser(ctx, &identitypb.UpdateUserRequest{Username: uname, User: u}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("UpdateUser: want InvalidArgument when setting email_verify_time, got %v", err)
+	}
+}
+
+func TestVerifyEmail_expired(t *testing.T) {
+	is, mb, _ := newVerifyingIdentityServer()
+	is.EmailTokens = server.NewEmailTokenManager(SecretKey, -time.Minute)
+	ctx := context.Background()
+
+	userObj := &identitypb.User{
+		Username: "test_expired_username",
+		Email:    "test_expired_email@domain.in",
+		Password: "test_expired_pwd",
+	}
+	if _, err := is.CreateUser(ctx, &identitypb.CreateUserRequest{User: userObj}); err != nil {
+		t.Fatalf("Failed to create pre-requisite object! %v", err)
+	}
+	_, token := mb.lastToken(t)
+	if _, err := is.VerifyEmail(ctx, &identitypb.VerifyEmailRequest{Token: token}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("VerifyEmail: want InvalidArgument for an expired token, got %v", err)
+	}
+}
+
+func TestResendVerification(t *testing.T) {
+	is, mb, _ := newVerifyingIdentityServer()
+	ctx := context.Background()
+
+	userObj := &identitypb.User{
+		Username: "test_resend_username",
+		Email:    "test_resend_email@domain.in",
+		Password: "test_resend_pwd",
+	}
+	if _, err := is.CreateUser(ctx, &identitypb.CreateUserRequest{User: userObj}); err != nil {
+		t.Fatalf("Failed to create pre-requisite object! %v", err)
+	}
+	uname := userObj.GetUsername()
+
+	if _, err := is.ResendVerification(ctx, &identitypb.ResendVerificationRequest{Username: uname}); err != nil {
+		t.Fatalf("ResendVerification: unexpected err %v", err)
+	}
+	if mb.count() != 2 {
+		t.Errorf("ResendVerification: want a second mail, got %d mails", mb.count())
+	}
+	_, err := is.ResendVerification(ctx, &identitypb.ResendVerificationRequest{Username: uname})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("ResendVerification: want ResourceExhausted when resent too soon, got %v", err)
+	}
+
+	// Unknown users are answered alike, with no mail
+	if _, err := is.ResendVerification(ctx, &identitypb.ResendVerificationRequest{Username: "test_missing_user"}); err != nil {
+		t.Errorf("ResendVerification: unexpected err %v for a missing user", err)
+	}
+	if mb.count() != 2 {
+		t.Errorf("ResendVerification: want no mail for a missing user, got %d mails", mb.count())
+	}
+
+	// Nor are the verified users mailed again
+	_, token := mb.lastToken(t)
+	if _, err := is.VerifyEmail(ctx, &identitypb.VerifyEmailRequest{Token: token}); err != nil {
+		t.Fatalf("VerifyEmail: unexpected err %v", err)
+	}
+	delete(is.resent, uname)
+	if _, err := is.ResendVerification(ctx, &identitypb.ResendVerificationRequest{Username: uname}); err != nil || mb.count() != 2 {
+		t.Errorf("ResendVerification: want no mail for a verified user, got %d mails, %v", mb.count(), err)
+	}
+}
+
+// blockingMailer is a Mailer whose sends wait until release is closed, telling
+// sending when they start.
+type blockingMailer struct {
+	sending chan struct{}
+	release chan struct{}
+}
+
+func (bm *blockingMailer) Send(ctx context.Context, msg mail.Message) error {
+	bm.sending <- struct{}{}
+	<-bm.release
+	return nil
+}
+
+func TestVerification_slowMailer(t *testing.T) {
+	is, _, _ := newVerifyingIdentityServer()
+	bm := &blockingMailer{sending: make(chan struct{}), release: make(chan struct{})}
+	is.Mailer = bm
+	defer close(bm.release)
+	ctx := context.Background()
+
+	// A mail server which does not answer stalls the request sending the
+	// mail, but none of the others
+	userObj := &identitypb.User{
+		Username: "test_slow_username",
+		Email:    "test_slow_email@domain.in",
+		Password: "test_slow_pwd",
+	}
+	created := make(chan error, 1)
+	go func() {
+		_, err := is.CreateUser(ctx, &identitypb.CreateUserRequest{User: userObj})
+		created <- err
+	}()
+	<-bm.sending
+
+	interceptors.CURRENT_ROLE = "ADMIN"
+	getUser := func(while string) {
+		got := make(chan error, 1)
+		go func() {
+			_, err := is.GetUser(ctx, &identitypb.GetUserRequest{Username: userObj.GetUsername()})
+			got <- err
+		}()
+		select {
+		case err := <-got:
+			if err != nil {
+				t.Errorf("GetUser: unexpected err %v while %s", err, while)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("GetUser: blocked by the mail of %s", while)
+		}
+	}
+	getUser("creating")
+
+	go is.ResendVerification(ctx, &identitypb.ResendVerificationRequest{Username: userObj.GetUsername()})
+	<-bm.sending
+	getUser("resending")
+
+	bm.release <- struct{}{}
+	if err := <-created; err != nil {
+		t.Errorf("CreateUser: unexpected err %v", err)
+	}
+}
+
+func TestLogin_requireVerifiedEmail(t *testing.T) {
+	is, mb, dbhandler := newVerifyingIdentityServer()
+	as := NewAuthServer(is, dbhandler)
+	as.JWT = server.NewJWTManager(SecretKey, 2*time.Minute)
+	as.RequireVerifiedEmail = true
+	ctx := context.Background()
+
+	userObj := &identitypb.User{
+		Username: "test_unverified_username",
+		Email:    "test_unverified_email@domain.in",
+		Password: "test_unverified_pwd",
+		Role:     identitypb.Role_NORMAL,
+	}
+	if _, err := is.CreateUser(ctx, &identitypb.CreateUserRequest{User: userObj}); err != nil {
+		t.Fatalf("Failed to create pre-requisite object! %v", err)
+	}
+
+	req := &authpb.LoginRequest{Username: userObj.GetUsername(), Password: "test_wrong_pwd"}
+	if _, err := as.Login(ctx, req); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Login: want InvalidArgument for a wrong password, got %v", err)
+	}
+	req.Password = userObj.GetPassword()
+	if _, err := as.Login(ctx, req); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Login: want FailedPrecondition for an unverified email, got %v", err)
+	}
+
+	_, token := mb.lastToken(t)
+	if _, err := is.VerifyEmail(ctx, &identitypb.VerifyEmailRequest{Token: token}); err != nil {
+		t.Fatalf("VerifyEmail: unexpected err %v", err)
+	}
+	if res, err := as.Login(ctx, req); err != nil || res.GetAccessToken() == "" {
+		t.Errorf("Login: want a token once verified, got %v, %v", res, err)
+	}
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+)
+
+// emailTokenAudience is the audience of the email verification tokens, which
+// tells them apart from any other token signed with the same secret.
+const emailTokenAudience = "email_verification"
+
+// ErrTokenExpired is returned when verifying a token which is past its expiry.
+var ErrTokenExpired = errors.New("the token expired")
+
+// EmailTokenManager signs the tokens mailed to the users to verify that they
+// own their email. The tokens are signed with a key derived from the secret
+// key, hence never accepted as access tokens by a JWTManager.
+type EmailTokenManager struct {
+	key           []byte
+	tokenDuration time.Duration
+}
+
+// EmailClaims are the claims of an email verification token: the user and the
+// email the token was sent to.
+type EmailClaims struct {
+	jwt.StandardClaims
+	Username string
+	Email    string
+}
+
+// NewEmailTokenManager returns a manager of email verification tokens signed
+// with sk and valid for td.
+func NewEmailTokenManager(sk string, td time.Duration) *EmailTokenManager {
+	mac := hmac.New(sha256.New, []byte(sk))
+	mac.Write([]byte(emailTokenAudience))
+	return &EmailTokenManager{
+		key:           mac.Sum(nil),
+		tokenDuration: td,
+	}
+}
+
+// TokenDuration returns how long the tokens are valid after they are issued.
+func (em *EmailTokenManager) TokenDuration() time.Duration {
+	return em.tokenDuration
+}
+
+// GenerateToken returns a token verifying that username owns email.
+func (em *EmailTokenManager) GenerateToken(username, email string) (string, error) {
+	now := time.Now()
+	claims := EmailClaims{
+		StandardClaims: jwt.StandardClaims{
+			Audience:  emailTokenAudience,
+			Id:        uuid.NewString(),
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(em.tokenDuration).Unix(),
+		},
+		Username: username,
+		Email:    email,
+	}
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(em.key)
+}
+
+// Verify verifies the token and returns its claims if it is valid. It returns
+// ErrTokenExpired for a genuine token past its expiry.
+func (em *EmailTokenManager) Verify(token string) (*EmailClaims, error) {
+	parsed, err := jwt.ParseWithClaims(
+		token,
+		&EmailClaims{},
+		func(token *jwt.Token) (interface{}, error) {
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, fmt.Errorf("unexpected token signing method")
+			}
+			return em.key, nil
+		},
+	)
+
+	var verr *jwt.ValidationError
+	if errors.As(err, &verr) && verr.Errors == jwt.ValidationErrorExpired {
+		return nil, ErrTokenExpired
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
+
+	claims, ok := parsed.Claims.(*EmailClaims)
+	if !ok || !claims.VerifyAudience(emailTokenAudience, true) || claims.Username == "" {
+		return nil, fmt.Errorf("invalid token claims")
+	}
+	return claims, nil
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	identitypb "github.com/AkashGit21/ms-project/internal/grpc/identity"
+)
+
+func TestEmailTokenManager(t *testing.T) {
+	em := NewEmailTokenManager(SecretKey, time.Hour)
+
+	token, err := em.GenerateToken("usrname1", "user1@example.com")
+	if err != nil {
+		t.Fatalf("GenerateToken() failed: %v", err)
+	}
+	claims, err := em.Verify(token)
+	if err != nil {
+		t.Fatalf("Verify() failed: %v", err)
+	}
+	if claims.Username != "usrname1" || claims.Email != "user1@example.com" {
+		t.Errorf("Verify() = %+v, want the user and email of the token", claims)
+	}
+	if other, _ := em.GenerateToken("usrname1", "user1@example.com"); other == token {
+		t.Error("GenerateToken() returned the same token twice")
+	}
+
+	// Tokens of another secret, tampered ones or access tokens are rejected
+	foreign, _ := NewEmailTokenManager("other", time.Hour).GenerateToken("usrname1", "user1@example.com")
+	access, _ := NewJWTManager(SecretKey, time.Hour).GenerateToken(&identitypb.User{Username: "usrname1"})
+	for _, bad := range []string{"", "garbage", foreign, token + "x", access[len("Basic "):]} {
+		if _, err := em.Verify(bad); err == nil || errors.Is(err, ErrTokenExpired) {
+			t.Errorf("Verify(%q) = %v, want an invalid token", bad, err)
+		}
+	}
+
+	// Nor are email tokens accepted as access tokens
+	if _, err := NewJWTManager(SecretKey, time.Hour).Verify(token); err == nil {
+		t.Error("JWTManager.Verify() accepted an email token")
+	}
+}
+
+func TestEmailTokenManager_expired(t *testing.T) {
+	em := NewEmailTokenManager(SecretKey, -time.Minute)
+
+	token, err := em.GenerateToken("usrname1", "user1@example.com")
+	if err != nil {
+		t.Fatalf("GenerateToken() failed: %v", err)
+	}
+	if _, err := em.Verify(token); !errors.Is(err, ErrTokenExpired) {
+		t.Errorf("Verify() = %v, want %v", err, ErrTokenExpired)
+	}
+	// An expired token is still rejected for a bad signature first
+	if _, err := NewEmailTokenManager("other", time.Hour).Verify(token); err == nil || errors.Is(err, ErrTokenExpired) {
+		t.Errorf("Verify() with another secret = %v, want an invalid token", err)
+	}
+}
@@ -0,0 +1,210 @@
+// Package backup snapshots the users and movies of a database into an archive,
+// and restores them into any database, of the same backend or another.
+//
+// An archive is a tar file, optionally compressed with gzip, holding a
+// manifest followed by a file of JSON lines for the users, with their password
+// hashes, and one for the movies. The manifest records the version of the
+// format and the number of records and SHA-256 checksum of every file.
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/AkashGit21/ms-project/lib/persistence"
+)
+
+// FormatVersion is the version of the archives written by Write. Restore
+// reads the archives of this version and the older ones.
+const FormatVersion = 1
+
+// The files of an archive, in the order they are written.
+const (
+	ManifestFile = "manifest.json"
+	UsersFile    = "users.jsonl"
+	MoviesFile   = "movies.jsonl"
+)
+
+// pageSize is the number of records read from the database at once.
+const pageSize = 100
+
+// Database holds the records backed up and restored.
+type Database interface {
+	persistence.UserRepository
+	persistence.MovieRepository
+}
+
+// Manifest describes the contents of an archive.
+type Manifest struct {
+	Version    int       `json:"version"`
+	CreateTime time.Time `json:"create_time"`
+	Files      []File    `json:"files"`
+}
+
+// File describes a file of records of an archive.
+type File struct {
+	Name    string `json:"name"`
+	Records int    `json:"records"`
+	SHA256  string `json:"sha256"`
+}
+
+// file returns the description of the file of the given name.
+func (m Manifest) file(name string) (File, bool) {
+	for _, f := range m.Files {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return File{}, false
+}
+
+// Write writes an archive of every user and movie of db to w, the deleted
+// ones included, and returns its manifest. The records are staged in
+// temporary files, so that they are never all held in memory.
+func Write(ctx context.Context, w io.Writer, db Database, compress bool) (Manifest, error) {
+	manifest := Manifest{Version: FormatVersion, CreateTime: time.Now().UTC()}
+
+	users, err := stage(UsersFile, func(enc *json.Encoder) (int, error) { return writeUsers(ctx, db, enc) })
+	if err != nil {
+		return manifest, err
+	}
+	defer users.remove()
+	movies, err := stage(MoviesFile, func(enc *json.Encoder) (int, error) { return writeMovies(ctx, db, enc) })
+	if err != nil {
+		return manifest, err
+	}
+	defer movies.remove()
+	manifest.Files = []File{users.File, movies.File}
+
+	var gz *gzip.Writer
+	if compress {
+		gz = gzip.NewWriter(w)
+		w = gz
+	}
+	tw := tar.NewWriter(w)
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return manifest, err
+	}
+	if err := writeEntry(tw, ManifestFile, int64(len(data)), manifest.CreateTime, bytes.NewReader(data)); err != nil {
+		return manifest, err
+	}
+	for _, sf := range []*stagedFile{users, movies} {
+		if _, err := sf.f.Seek(0, io.SeekStart); err != nil {
+			return manifest, err
+		}
+		if err := writeEntry(tw, sf.Name, sf.size, manifest.CreateTime, sf.f); err != nil {
+			return manifest, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return manifest, err
+	}
+	if gz != nil {
+		return manifest, gz.Close()
+	}
+	return manifest, nil
+}
+
+func writeUsers(ctx context.Context, db Database, enc *json.Encoder) (int, error) {
+	order := persistence.DefaultOrder
+	var after persistence.Cursor
+	count := 0
+	for {
+		users, err := db.FindAllUsers(ctx, true, order, after, pageSize)
+		if err != nil {
+			return count, err
+		}
+		for _, u := range users {
+			// The lists leave out the password hashes
+			full, err := db.FindByUsername(ctx, u.Username)
+			if err != nil {
+				return count, err
+			}
+			if err := enc.Encode(full); err != nil {
+				return count, err
+			}
+			count++
+		}
+		if len(users) < pageSize {
+			return count, nil
+		}
+		after = order.UserCursor(users[len(users)-1])
+	}
+}
+
+func writeMovies(ctx context.Context, db Database, enc *json.Encoder) (int, error) {
+	order := persistence.DefaultOrder
+	var after persistence.Cursor
+	count := 0
+	for {
+		movies, err := db.FindAllMovies(ctx, nil, true, order, after, pageSize)
+		if err != nil {
+			return count, err
+		}
+		for _, mv := range movies {
+			if err := enc.Encode(mv); err != nil {
+				return count, err
+			}
+			count++
+		}
+		if len(movies) < pageSize {
+			return count, nil
+		}
+		after = order.MovieCursor(movies[len(movies)-1])
+	}
+}
+
+// stagedFile is a file of records written to a temporary file.
+type stagedFile struct {
+	File
+	f    *os.File
+	size int64
+}
+
+// stage writes the records encoded by write to a temporary file, counting and
+// summing them.
+func stage(name string, write func(*json.Encoder) (int, error)) (*stagedFile, error) {
+	f, err := ioutil.TempFile("", "ms-project-backup-*")
+	if err != nil {
+		return nil, err
+	}
+	sf := &stagedFile{File: File{Name: name}, f: f}
+
+	sum := sha256.New()
+	if sf.Records, err = write(json.NewEncoder(io.MultiWriter(f, sum))); err != nil {
+		sf.remove()
+		return nil, fmt.Errorf("backing up %s: %w", name, err)
+	}
+	if sf.size, err = f.Seek(0, io.SeekCurrent); err != nil {
+		sf.remove()
+		return nil, err
+	}
+	sf.SHA256 = hex.EncodeToString(sum.Sum(nil))
+	return sf, nil
+}
+
+func (sf *stagedFile) remove() {
+	sf.f.Close()
+	os.Remove(sf.f.Name())
+}
+
+func writeEntry(tw *tar.Writer, name string, size int64, modTime time.Time, r io.Reader) error {
+	hdr := &tar.Header{Name: name, Mode: 0600, Size: size, ModTime: modTime, Typeflag: tar.TypeReg}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := io.Copy(tw, r)
+	return err
+}
@@ -0,0 +1,155 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/AkashGit21/ms-project/lib/persistence"
+	"github.com/AkashGit21/ms-project/lib/persistence/memlayer"
+	"github.com/golang/protobuf/ptypes"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func newTestDatabase(t *testing.T) persistence.DatabaseHandler {
+	db, err := memlayer.NewMemoryLayer()
+	if err != nil {
+		t.Fatalf("NewMemoryLayer: unexpected err %v", err)
+	}
+	return db
+}
+
+// seed adds a user and more movies than a page to db, one of them deleted.
+func seed(t *testing.T, db persistence.DatabaseHandler) int {
+	ctx := context.Background()
+	hash, _ := bcrypt.GenerateFromPassword([]byte("test_pwd"), bcrypt.MinCost)
+	user := persistence.User{Username: "test_username", Email: "test_email@domain.com", Password: string(hash),
+		Role: persistence.Role_ADMIN, Active: true, CreateTime: ptypes.TimestampNow()}
+	if _, err := db.AddUser(ctx, user); err != nil {
+		t.Fatalf("AddUser: unexpected err %v", err)
+	}
+
+	movies := pageSize + 1
+	for i := 0; i < movies; i++ {
+		mv := persistence.Movie{Id: fmt.Sprintf("id_%d", i), Name: fmt.Sprintf("test_movie_%d", i),
+			Tags: []persistence.Tag{persistence.Tag_Action}, Active: true, CreateTime: ptypes.TimestampNow()}
+		if _, err := db.AddMovie(ctx, mv); err != nil {
+			t.Fatalf("AddMovie: unexpected err %v", err)
+		}
+	}
+	if err := db.SetMovieDeleteTime(ctx, "id_0", "", ptypes.TimestampNow()); err != nil {
+		t.Fatalf("SetMovieDeleteTime: unexpected err %v", err)
+	}
+	return movies
+}
+
+func TestBackupRestore(t *testing.T) {
+	ctx := context.Background()
+	src := newTestDatabase(t)
+	movies := seed(t, src)
+
+	for _, compress := range []bool{false, true} {
+		t.Run(fmt.Sprintf("compress=%v", compress), func(t *testing.T) {
+			var buf bytes.Buffer
+			manifest, err := Write(ctx, &buf, src, compress)
+			if err != nil {
+				t.Fatalf("Write: unexpected err %v", err)
+			}
+			if manifest.Version != FormatVersion || len(manifest.Files) != 2 ||
+				manifest.Files[0].Records != 1 || manifest.Files[1].Records != movies {
+				t.Errorf("Write: unexpected manifest %+v", manifest)
+			}
+
+			dst := newTestDatabase(t)
+			_, stats, err := Restore(ctx, bytes.NewReader(buf.Bytes()), dst, RestoreOptions{})
+			if err != nil || stats != (RestoreStats{Users: 1, Movies: movies}) {
+				t.Fatalf("Restore: want 1 user and %d movies, got %+v, %v", movies, stats, err)
+			}
+
+			// The password hashes, deletions and etags are kept
+			if ok, err := dst.Authenticate(ctx, "test_username", "test_pwd"); !ok || err != nil {
+				t.Errorf("Authenticate: want the restored password, got %v, %v", ok, err)
+			}
+			want, _ := src.FindMovieByID(ctx, "id_0")
+			if got, err := dst.FindMovieByID(ctx, "id_0"); err != nil || got.DeleteTime == nil || got.Etag != want.Etag {
+				t.Errorf("FindMovieByID: want %+v, got %+v, %v", want, got, err)
+			}
+
+			// The records restored again already exist
+			_, _, err = Restore(ctx, bytes.NewReader(buf.Bytes()), dst, RestoreOptions{})
+			if !errors.Is(err, persistence.ErrAlreadyExists) {
+				t.Errorf("Restore: want %v, got %v", persistence.ErrAlreadyExists, err)
+			}
+			_, stats, err = Restore(ctx, bytes.NewReader(buf.Bytes()), dst, RestoreOptions{SkipExisting: true})
+			if err != nil || stats != (RestoreStats{Skipped: movies + 1}) {
+				t.Errorf("Restore: want every record skipped, got %+v, %v", stats, err)
+			}
+		})
+	}
+}
+
+// archive writes a tar of the given files, in order.
+func archive(t *testing.T, files ...string) []byte {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for i := 0; i < len(files); i += 2 {
+		tw.WriteHeader(&tar.Header{Name: files[i], Mode: 0600, Size: int64(len(files[i+1]))})
+		tw.Write([]byte(files[i+1]))
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("Close: unexpected err %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestRestore_invalid(t *testing.T) {
+	ctx := context.Background()
+	var buf bytes.Buffer
+	if _, err := Write(ctx, &buf, newTestDatabase(t), false); err != nil {
+		t.Fatalf("Write: unexpected err %v", err)
+	}
+	manifest, err := Verify(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Verify: unexpected err %v", err)
+	}
+	manifestJSON := func(m Manifest) string {
+		data, _ := json.Marshal(m)
+		return string(data)
+	}
+	newer := manifest
+	newer.Version = FormatVersion + 1
+
+	user := `{"username":"test_username"}` + "\n"
+	tampered := manifest
+	tampered.Files = []File{{Name: UsersFile, Records: 1, SHA256: manifest.Files[0].SHA256}, manifest.Files[1]}
+
+	tests := []struct {
+		name    string
+		archive []byte
+		wantErr string
+	}{
+		{name: "not an archive", archive: []byte("not an archive"), wantErr: "manifest is missing"},
+		{name: "newer version", archive: archive(t, ManifestFile, manifestJSON(newer)), wantErr: "unsupported archive version"},
+		{name: "missing file", archive: archive(t, ManifestFile, manifestJSON(manifest), UsersFile, ""), wantErr: MoviesFile + " is missing"},
+		{name: "unknown file", archive: archive(t, ManifestFile, manifestJSON(manifest), "other.jsonl", ""), wantErr: "not in the manifest"},
+		{name: "checksum", archive: archive(t, ManifestFile, manifestJSON(tampered), UsersFile, user, MoviesFile, ""), wantErr: "checksum"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDatabase(t)
+			_, _, err := Restore(ctx, bytes.NewReader(tt.archive), db, RestoreOptions{})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Restore: want an error containing %q, got %v", tt.wantErr, err)
+			}
+			// Nothing is restored from an invalid archive
+			if n, _ := db.CountUsers(ctx); n != 0 {
+				t.Errorf("Restore: want no user restored, got %d", n)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/AkashGit21/ms-project/lib/persistence"
+)
+
+// ErrCorrupt reports an archive which does not match its manifest, or has none.
+var ErrCorrupt = errors.New("corrupt archive")
+
+// RestoreOptions are the options of Restore.
+type RestoreOptions struct {
+	// SkipExisting skips the records already in the database, which fail the
+	// restore otherwise.
+	SkipExisting bool
+}
+
+// RestoreStats counts the records of a restore.
+type RestoreStats struct {
+	Users   int
+	Movies  int
+	Skipped int
+}
+
+// Verify checks the archive read from r against its manifest, which it
+// returns. Compressed archives are recognised by their header.
+func Verify(r io.Reader) (Manifest, error) {
+	return readArchive(r, func(f File, data io.Reader) error {
+		sum := sha256.New()
+		lines := &lineCounter{}
+		if _, err := io.Copy(io.MultiWriter(sum, lines), data); err != nil {
+			return err
+		}
+		if got := hex.EncodeToString(sum.Sum(nil)); got != f.SHA256 {
+			return fmt.Errorf("%w: %s has checksum %s, want %s", ErrCorrupt, f.Name, got, f.SHA256)
+		}
+		if lines.n != f.Records {
+			return fmt.Errorf("%w: %s has %d records, want %d", ErrCorrupt, f.Name, lines.n, f.Records)
+		}
+		return nil
+	})
+}
+
+// Restore adds the users and movies of the archive read from r to db, as they
+// were backed up. The archive is verified first, so that a corrupt one
+// restores nothing. A record already in db fails the restore, unless skipped
+// by the options; the records restored until then are kept.
+func Restore(ctx context.Context, r io.ReadSeeker, db Database, opts RestoreOptions) (Manifest, RestoreStats, error) {
+	var stats RestoreStats
+	if manifest, err := Verify(r); err != nil {
+		return manifest, stats, err
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return Manifest{}, stats, err
+	}
+
+	manifest, err := readArchive(r, func(f File, data io.Reader) error {
+		dec := json.NewDecoder(data)
+		for dec.More() {
+			var err error
+			switch f.Name {
+			case UsersFile:
+				var u persistence.User
+				if err = dec.Decode(&u); err != nil {
+					return err
+				}
+				if _, err = db.AddUser(ctx, u); err == nil {
+					stats.Users++
+				}
+			case MoviesFile:
+				var mv persistence.Movie
+				if err = dec.Decode(&mv); err != nil {
+					return err
+				}
+				if _, err = db.AddMovie(ctx, mv); err == nil {
+					stats.Movies++
+				}
+			}
+			if errors.Is(err, persistence.ErrAlreadyExists) && opts.SkipExisting {
+				stats.Skipped++
+			} else if err != nil {
+				return fmt.Errorf("restoring %s: %w", f.Name, err)
+			}
+		}
+		return nil
+	})
+	return manifest, stats, err
+}
+
+// readArchive reads the manifest of the archive of r, then passes every file
+// of records to fn, along with its description in the manifest.
+func readArchive(r io.Reader, fn func(File, io.Reader) error) (Manifest, error) {
+	var manifest Manifest
+
+	br := bufio.NewReader(r)
+	if magic, _ := br.Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return manifest, err
+		}
+		defer gz.Close()
+		r = gz
+	} else {
+		r = br
+	}
+	tr := tar.NewReader(r)
+
+	hdr, err := tr.Next()
+	if err != nil || hdr.Name != ManifestFile {
+		return manifest, fmt.Errorf("%w: the manifest is missing", ErrCorrupt)
+	}
+	if err := json.NewDecoder(tr).Decode(&manifest); err != nil {
+		return manifest, fmt.Errorf("%w: reading the manifest: %v", ErrCorrupt, err)
+	}
+	if manifest.Version < 1 || manifest.Version > FormatVersion {
+		return manifest, fmt.Errorf("unsupported archive version %d, want at most %d", manifest.Version, FormatVersion)
+	}
+
+	seen := map[string]bool{}
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return manifest, fmt.Errorf("%w: %v", ErrCorrupt, err)
+		}
+		f, ok := manifest.file(hdr.Name)
+		if !ok {
+			return manifest, fmt.Errorf("%w: %s is not in the manifest", ErrCorrupt, hdr.Name)
+		}
+		seen[f.Name] = true
+		if err := fn(f, tr); err != nil {
+			return manifest, err
+		}
+	}
+	for _, f := range manifest.Files {
+		if !seen[f.Name] {
+			return manifest, fmt.Errorf("%w: %s is missing", ErrCorrupt, f.Name)
+		}
+	}
+	return manifest, nil
+}
+
+// lineCounter counts the lines written to it.
+type lineCounter struct {
+	n int
+}
+
+func (lc *lineCounter) Write(p []byte) (int, error) {
+	lc.n += bytes.Count(p, []byte{'\n'})
+	return len(p), nil
+}
@@ -3,14 +3,48 @@ package configuration
 import (
 	"encoding/json"
 	"os"
+	"time"
 
 	"github.com/AkashGit21/ms-project/lib/persistence/dblayer"
 )
 
 var (
+	// An empty connection picks the default of the database backend
 	DBTypeDefault       = dblayer.DBTYPE("mongodb")
-	DBConnectionDefault = "mongodb://127.0.0.1"
+	DBConnectionDefault = ""
+	DBTimeoutDefault    = 5 * time.Second
 	RestfulEPDefault    = "localhost"
+
+	// Deleted records are purged every PurgeIntervalDefault, once deleted
+	// longer than PurgeRetentionDefault ago
+	PurgeRetentionDefault = 30 * 24 * time.Hour
+	PurgeIntervalDefault  = time.Hour
+
+	// The domain events are delivered every EventsIntervalDefault to the
+	// JSONL file and the webhook, if given. Those given up on are appended
+	// to the dead letter file, if given
+	EventsIntervalDefault       = time.Second
+	EventsFileDefault           = ""
+	EventsWebhookDefault        = ""
+	EventsWebhookTimeout        = 10 * time.Second
+	EventsDeadLetterFileDefault = ""
+
+	// Reads are cached in up to CacheSizeDefault entries, 0 disables the cache
+	CacheSizeDefault        = 0
+	CacheTTLDefault         = 10 * time.Second
+	CacheNegativeTTLDefault = 2 * time.Second
+
+	// The users are mailed a link to VerifyEmailURLDefault verifying their
+	// email, valid for VerifyEmailTTLDefault. The mails go through the SMTP
+	// server if given, else to MailFileDefault if given, else to the log
+	VerifyEmailURLDefault       = "http://localhost:8081/v1/users:verifyEmail"
+	VerifyEmailTTLDefault       = 24 * time.Hour
+	RequireVerifiedEmailDefault = false
+	MailFromDefault             = "ms-project <no-reply@localhost>"
+	MailFileDefault             = ""
+	SMTPAddrDefault             = ""
+	SMTPUsernameDefault         = ""
+	SMTPTimeout                 = 30 * time.Second
 )
 
 type ServiceConfig struct {
@@ -0,0 +1,60 @@
+package events
+
+import (
+	"context"
+	"sync"
+)
+
+// Handler handles the events of a Bus.
+type Handler func(context.Context, Event) error
+
+// Bus is a Sink for the subscribers within this process. A handler which
+// fails has the event delivered again, to every handler. It is safe for
+// concurrent use.
+type Bus struct {
+	mu            sync.RWMutex
+	subscriptions []subscription
+}
+
+type subscription struct {
+	// types is nil for the subscriptions to every event
+	types   map[Type]bool
+	handler Handler
+}
+
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe has h called with the events of the given types, or with every
+// event if no type is given. The handlers are called one at a time, in the
+// order they subscribed.
+func (b *Bus) Subscribe(h Handler, types ...Type) {
+	sub := subscription{handler: h}
+	if len(types) > 0 {
+		sub.types = map[Type]bool{}
+		for _, typ := range types {
+			sub.types[typ] = true
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscriptions = append(b.subscriptions, sub)
+}
+
+func (b *Bus) Publish(ctx context.Context, ev Event) error {
+	b.mu.RLock()
+	subscriptions := b.subscriptions
+	b.mu.RUnlock()
+
+	for _, sub := range subscriptions {
+		if sub.types != nil && !sub.types[ev.Type] {
+			continue
+		}
+		if err := sub.handler(ctx, ev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
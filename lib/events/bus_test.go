@@ -0,0 +1,41 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestBus(t *testing.T) {
+	ctx := context.Background()
+	bus := NewBus()
+
+	var all, movies []Type
+	bus.Subscribe(func(ctx context.Context, ev Event) error {
+		all = append(all, ev.Type)
+		return nil
+	})
+	bus.Subscribe(func(ctx context.Context, ev Event) error {
+		movies = append(movies, ev.Type)
+		return nil
+	}, MovieCreated, MovieDeleted)
+
+	for _, typ := range []Type{UserCreated, MovieCreated, MovieUpdated, MovieDeleted} {
+		if err := bus.Publish(ctx, Event{Type: typ}); err != nil {
+			t.Fatalf("Publish: unexpected err %v", err)
+		}
+	}
+	if want := []Type{UserCreated, MovieCreated, MovieUpdated, MovieDeleted}; !reflect.DeepEqual(all, want) {
+		t.Errorf("Subscribe: want %v, got %v", want, all)
+	}
+	if want := []Type{MovieCreated, MovieDeleted}; !reflect.DeepEqual(movies, want) {
+		t.Errorf("Subscribe with types: want %v, got %v", want, movies)
+	}
+
+	wantErr := errors.New("test_error")
+	bus.Subscribe(func(ctx context.Context, ev Event) error { return wantErr })
+	if err := bus.Publish(ctx, Event{Type: UserCreated}); err != wantErr {
+		t.Errorf("Publish: want err %v, got %v", wantErr, err)
+	}
+}
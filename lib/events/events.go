@@ -0,0 +1,111 @@
+// Package events carries the domain events of the users and movies to other
+// services. The storage backends record the events in an outbox, in the same
+// transaction as the writes they announce, and a Relay delivers them from
+// there to the sinks, at least once. Only the MongoDB and memory backends
+// implement Outbox: the PostgreSQL and SQLite ones record no events yet.
+package events
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrPermanent marks the failures of a Sink which no retry would fix, e.g. an
+// event a webhook rejects as invalid. A Relay gives up on such events at once.
+var ErrPermanent = errors.New("permanent failure")
+
+// Permanent wraps err as a failure which no retry would fix, see ErrPermanent.
+func Permanent(err error) error {
+	return permanentError{err}
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string        { return e.err.Error() }
+func (e permanentError) Unwrap() error        { return e.err }
+func (e permanentError) Is(target error) bool { return target == ErrPermanent }
+
+// Type is the kind of an Event.
+type Type string
+
+const (
+	UserCreated   Type = "UserCreated"
+	UserUpdated   Type = "UserUpdated"
+	UserDeleted   Type = "UserDeleted"
+	UserUndeleted Type = "UserUndeleted"
+	// UserEmailVerified is published once a user proved to own their email
+	UserEmailVerified Type = "UserEmailVerified"
+
+	MovieCreated   Type = "MovieCreated"
+	MovieUpdated   Type = "MovieUpdated"
+	MovieDeleted   Type = "MovieDeleted"
+	MovieUndeleted Type = "MovieUndeleted"
+)
+
+// Event is a change of a user or a movie.
+type Event struct {
+	// ID is unique, and the IDs of the events of a process sort in the order
+	// they were made. Sinks may see an event more than once, consumers should
+	// ignore the IDs they already handled.
+	ID   string    `json:"id"`
+	Type Type      `json:"type"`
+	Time time.Time `json:"time"`
+	// Subject is the key of the record changed, the username of a user or
+	// the ID of a movie
+	Subject string `json:"subject"`
+	// Data is the record as of the change in JSON, without the password of a
+	// user. It is empty for the records removed for good.
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// New returns an event of the given type about the record with key subject,
+// whose data is the JSON encoding of data unless nil.
+func New(typ Type, subject string, data interface{}) (Event, error) {
+	now := time.Now().UTC()
+	ev := Event{
+		ID:      newID(now),
+		Type:    typ,
+		Time:    now,
+		Subject: subject,
+	}
+	if data != nil {
+		b, err := json.Marshal(data)
+		if err != nil {
+			return Event{}, fmt.Errorf("encoding the data of the %s event: %v", typ, err)
+		}
+		ev.Data = b
+	}
+	return ev, nil
+}
+
+// newID returns the time in hex, which sorts like the time itself, followed by
+// random bytes against the collisions of events made at once.
+func newID(t time.Time) string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return fmt.Sprintf("%016x%s", t.UnixNano(), hex.EncodeToString(b))
+}
+
+// Outbox is implemented by the storage backends which record the events of
+// their writes.
+type Outbox interface {
+	// PendingEvents returns the oldest events not acknowledged yet, at most
+	// limit of them, in the order they were recorded.
+	PendingEvents(ctx context.Context, limit int) ([]Event, error)
+	// AckEvents removes the events with the given IDs from the outbox.
+	AckEvents(ctx context.Context, ids []string) error
+}
+
+// Sink receives the events of a Relay.
+type Sink interface {
+	// Publish delivers the event, or fails and gets it again later, unless
+	// the failure is permanent.
+	Publish(ctx context.Context, ev Event) error
+}
@@ -0,0 +1,50 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// FileSink appends the events to a file, one JSON object per line. Every
+// event is synced to disk before it is acknowledged.
+type FileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileSink opens the file at path for appending, and creates it if needed.
+func NewFileSink(path string) (*FileSink, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &FileSink{file: f}, nil
+}
+
+func (s *FileSink) Publish(ctx context.Context, ev Event) error {
+	line, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.file.Write(line); err != nil {
+		return fmt.Errorf("writing event %s to %s: %v", ev.ID, s.file.Name(), err)
+	}
+	if err := s.file.Sync(); err != nil {
+		return fmt.Errorf("syncing %s: %v", s.file.Name(), err)
+	}
+	return nil
+}
+
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
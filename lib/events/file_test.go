@@ -0,0 +1,50 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileSink(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+
+	// Reopening the file appends to it
+	for _, subject := range []string{"id_0", "id_1"} {
+		sink, err := NewFileSink(path)
+		if err != nil {
+			t.Fatalf("NewFileSink: unexpected err %v", err)
+		}
+		ev, _ := New(MovieCreated, subject, map[string]string{"id": subject})
+		if err := sink.Publish(ctx, ev); err != nil {
+			t.Errorf("Publish: unexpected err %v", err)
+		}
+		sink.Close()
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Open: unexpected err %v", err)
+	}
+	defer f.Close()
+
+	var subjects []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var ev Event
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil {
+			t.Fatalf("Unmarshal: unexpected err %v", err)
+		}
+		if ev.Type != MovieCreated || string(ev.Data) != `{"id":"`+ev.Subject+`"}` {
+			t.Errorf("Publish: unexpected event %+v", ev)
+		}
+		subjects = append(subjects, ev.Subject)
+	}
+	if len(subjects) != 2 || subjects[0] != "id_0" || subjects[1] != "id_1" {
+		t.Errorf("Publish: want id_0 and id_1, got %v", subjects)
+	}
+}
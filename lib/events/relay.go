@@ -0,0 +1,173 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+const (
+	// DefaultBatchSize is the number of events a Relay reads from the outbox at once.
+	DefaultBatchSize = 100
+	// DefaultMaxAttempts is how many times a Relay tries to deliver an event
+	// before it gives up on it.
+	DefaultMaxAttempts = 10
+	// MaxBackoff bounds the wait of a Relay between two failed flushes.
+	MaxBackoff = 5 * time.Minute
+)
+
+// Relay delivers the events of an outbox to sinks. An event is acknowledged
+// once every sink took it, so an event a sink fails on is delivered again to
+// all of them.
+//
+// The relay gives up on an event which a sink fails on permanently, see
+// ErrPermanent, or MaxAttempts times in a row, so that it does not hold back
+// the events after it forever. Such an event is logged, handed to the
+// DeadLetter sink if any, and acknowledged.
+type Relay struct {
+	// MaxAttempts is how many times an event is tried, 0 tries forever
+	MaxAttempts int
+	// DeadLetter gets the events the relay gave up on, if set
+	DeadLetter Sink
+
+	outbox    Outbox
+	sinks     []Sink
+	batchSize int
+
+	// attempts counts the failed deliveries of the event with ID headID,
+	// the oldest one pending
+	headID   string
+	attempts int
+}
+
+// NewRelay returns a Relay from outbox to the given sinks.
+func NewRelay(outbox Outbox, sinks ...Sink) *Relay {
+	return &Relay{
+		MaxAttempts: DefaultMaxAttempts,
+		outbox:      outbox,
+		sinks:       sinks,
+		batchSize:   DefaultBatchSize,
+	}
+}
+
+// Flush delivers the pending events in the order they were recorded, and
+// returns how many it acknowledged. It stops at the first event a sink fails
+// on, which keeps the order of the events for every sink, unless it gives up
+// on the event. Flush must not be called concurrently.
+func (r *Relay) Flush(ctx context.Context) (int, error) {
+	acked := 0
+	for {
+		pending, err := r.outbox.PendingEvents(ctx, r.batchSize)
+		if err != nil {
+			return acked, err
+		}
+
+		var ids []string
+		var publishErr error
+		for _, ev := range pending {
+			if publishErr = r.publish(ctx, ev); publishErr != nil {
+				if !r.giveUp(ctx, ev, publishErr) {
+					break
+				}
+				if publishErr = r.deadLetter(ctx, ev, publishErr); publishErr != nil {
+					break
+				}
+			}
+			ids = append(ids, ev.ID)
+		}
+		if len(ids) > 0 {
+			if err := r.outbox.AckEvents(ctx, ids); err != nil {
+				return acked, err
+			}
+			acked += len(ids)
+		}
+		if publishErr != nil || len(pending) < r.batchSize {
+			return acked, publishErr
+		}
+	}
+}
+
+func (r *Relay) publish(ctx context.Context, ev Event) error {
+	for _, sink := range r.sinks {
+		if err := sink.Publish(ctx, ev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// giveUp counts a failed delivery of ev, and reports whether the relay should
+// give up on ev. The failures of a flush being canceled do not count.
+func (r *Relay) giveUp(ctx context.Context, ev Event, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if errors.Is(err, ErrPermanent) {
+		return true
+	}
+	if r.headID != ev.ID {
+		r.headID, r.attempts = ev.ID, 0
+	}
+	r.attempts++
+	return r.MaxAttempts > 0 && r.attempts >= r.MaxAttempts
+}
+
+// deadLetter logs ev, which failed with cause, and hands it to the DeadLetter
+// sink if any. A failure of the sink keeps ev pending.
+func (r *Relay) deadLetter(ctx context.Context, ev Event, cause error) error {
+	log.Printf("[ERROR] Gave up on the %s event %s of %s: %v", ev.Type, ev.ID, ev.Subject, cause)
+	if r.DeadLetter == nil {
+		return nil
+	}
+	if err := r.DeadLetter.Publish(ctx, ev); err != nil {
+		return fmt.Errorf("dead-lettering event %s: %v", ev.ID, err)
+	}
+	return nil
+}
+
+// Run flushes the relay every interval until ctx is done. Failures are logged,
+// the events they left are delivered by a later flush, which is delayed twice
+// as long after every failure in a row, up to MaxBackoff.
+func (r *Relay) Run(ctx context.Context, interval time.Duration) {
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+
+	failures := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		n, err := r.Flush(ctx)
+		if err != nil {
+			failures++
+		} else {
+			failures = 0
+		}
+		delay := backoff(interval, failures)
+		if err != nil {
+			log.Printf("[WARN] Could not deliver the pending events, retrying in %v: %v", delay, err)
+		}
+		if n > 0 {
+			log.Printf("[DEBUG] Delivered %d events", n)
+		}
+		timer.Reset(delay)
+	}
+}
+
+// backoff returns the wait before the next flush after the given number of
+// failed flushes in a row: interval, doubled for every failure up to MaxBackoff.
+func backoff(interval time.Duration, failures int) time.Duration {
+	delay := interval
+	for i := 0; i < failures && delay < MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > MaxBackoff && interval <= MaxBackoff {
+		return MaxBackoff
+	}
+	return delay
+}
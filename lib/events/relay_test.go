@@ -0,0 +1,186 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memOutbox is an Outbox holding the events in a slice.
+type memOutbox struct {
+	mu      sync.Mutex
+	pending []Event
+}
+
+func (o *memOutbox) PendingEvents(ctx context.Context, limit int) ([]Event, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if limit > 0 && len(o.pending) > limit {
+		return append([]Event(nil), o.pending[:limit]...), nil
+	}
+	return append([]Event(nil), o.pending...), nil
+}
+
+func (o *memOutbox) AckEvents(ctx context.Context, ids []string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	acked := map[string]bool{}
+	for _, id := range ids {
+		acked[id] = true
+	}
+	var pending []Event
+	for _, ev := range o.pending {
+		if !acked[ev.ID] {
+			pending = append(pending, ev)
+		}
+	}
+	o.pending = pending
+	return nil
+}
+
+// recorder is a Sink which records the subjects of the events, and fails on
+// the subjects of failing, permanently on those of rejected.
+type recorder struct {
+	subjects []string
+	failing  map[string]bool
+	rejected map[string]bool
+}
+
+func (r *recorder) Publish(ctx context.Context, ev Event) error {
+	if r.failing[ev.Subject] {
+		return errors.New("failed " + ev.Subject)
+	}
+	if r.rejected[ev.Subject] {
+		return Permanent(errors.New("rejected " + ev.Subject))
+	}
+	r.subjects = append(r.subjects, ev.Subject)
+	return nil
+}
+
+func TestRelay(t *testing.T) {
+	ctx := context.Background()
+
+	outbox := &memOutbox{}
+	for i := 0; i < 5; i++ {
+		ev, err := New(MovieCreated, fmt.Sprintf("id_%d", i), nil)
+		if err != nil {
+			t.Fatalf("New: unexpected err %v", err)
+		}
+		outbox.pending = append(outbox.pending, ev)
+	}
+
+	first := &recorder{}
+	second := &recorder{failing: map[string]bool{"id_3": true}}
+	relay := NewRelay(outbox, first, second)
+	relay.batchSize = 2
+
+	// The second sink stops the relay at id_3, which both sinks get again
+	n, err := relay.Flush(ctx)
+	if err == nil || n != 3 {
+		t.Errorf("Flush: want 3 events and an err, got %d, %v", n, err)
+	}
+	if len(outbox.pending) != 2 {
+		t.Errorf("Flush: want 2 pending events, got %d", len(outbox.pending))
+	}
+
+	second.failing = nil
+	n, err = relay.Flush(ctx)
+	if err != nil || n != 2 {
+		t.Errorf("Flush: want 2 events, got %d, %v", n, err)
+	}
+	if len(outbox.pending) != 0 {
+		t.Errorf("Flush: want no pending events, got %d", len(outbox.pending))
+	}
+
+	want := []string{"id_0", "id_1", "id_2", "id_3", "id_3", "id_4"}
+	if !reflect.DeepEqual(first.subjects, want) {
+		t.Errorf("first sink: want %v, got %v", want, first.subjects)
+	}
+	want = []string{"id_0", "id_1", "id_2", "id_3", "id_4"}
+	if !reflect.DeepEqual(second.subjects, want) {
+		t.Errorf("second sink: want %v, got %v", want, second.subjects)
+	}
+}
+
+func TestRelay_giveUp(t *testing.T) {
+	ctx := context.Background()
+
+	outbox := &memOutbox{}
+	for i := 0; i < 3; i++ {
+		ev, _ := New(MovieCreated, fmt.Sprintf("id_%d", i), nil)
+		outbox.pending = append(outbox.pending, ev)
+	}
+
+	sink := &recorder{failing: map[string]bool{"id_0": true}, rejected: map[string]bool{"id_1": true}}
+	deadLetter := &recorder{}
+	relay := NewRelay(outbox, sink)
+	relay.MaxAttempts = 2
+	relay.DeadLetter = deadLetter
+
+	// id_0 is retried once, then given up on like id_1 which is rejected
+	if n, err := relay.Flush(ctx); err == nil || n != 0 {
+		t.Errorf("Flush: want no events and an err, got %d, %v", n, err)
+	}
+	if n, err := relay.Flush(ctx); err != nil || n != 3 {
+		t.Errorf("Flush: want 3 events, got %d, %v", n, err)
+	}
+	if len(outbox.pending) != 0 {
+		t.Errorf("Flush: want no pending events, got %d", len(outbox.pending))
+	}
+	if want := []string{"id_2"}; !reflect.DeepEqual(sink.subjects, want) {
+		t.Errorf("sink: want %v, got %v", want, sink.subjects)
+	}
+	if want := []string{"id_0", "id_1"}; !reflect.DeepEqual(deadLetter.subjects, want) {
+		t.Errorf("dead letter sink: want %v, got %v", want, deadLetter.subjects)
+	}
+
+	// The events stay pending while the dead letter sink fails
+	ev, _ := New(MovieCreated, "id_3", nil)
+	outbox.pending = append(outbox.pending, ev)
+	sink.rejected["id_3"] = true
+	deadLetter.failing = map[string]bool{"id_3": true}
+	if n, err := relay.Flush(ctx); err == nil || n != 0 || len(outbox.pending) != 1 {
+		t.Errorf("Flush: want the event kept and an err, got %d, %v", n, err)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		interval time.Duration
+		failures int
+		expected time.Duration
+	}{
+		{time.Second, 0, time.Second},
+		{time.Second, 1, 2 * time.Second},
+		{time.Second, 3, 8 * time.Second},
+		{time.Second, 100, MaxBackoff},
+		{time.Hour, 2, time.Hour},
+	}
+	for _, tcase := range tests {
+		if got := backoff(tcase.interval, tcase.failures); got != tcase.expected {
+			t.Errorf("backoff(%v, %d): want %v, got %v", tcase.interval, tcase.failures, tcase.expected, got)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	ev, err := New(UserCreated, "test_username", map[string]string{"username": "test_username"})
+	if err != nil {
+		t.Fatalf("New: unexpected err %v", err)
+	}
+	if string(ev.Data) != `{"username":"test_username"}` {
+		t.Errorf("New: unexpected data %s", ev.Data)
+	}
+
+	later, _ := New(UserDeleted, "test_username", nil)
+	if later.ID <= ev.ID {
+		t.Errorf("New: want IDs in the order of the events, got %s after %s", later.ID, ev.ID)
+	}
+	if later.Data != nil {
+		t.Errorf("New: want no data, got %s", later.Data)
+	}
+}
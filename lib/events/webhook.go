@@ -0,0 +1,71 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// WebhookSink posts every event as JSON to a URL. The headers X-Event-Id and
+// X-Event-Type repeat the ID and type of the event. Any answer but a 2xx
+// status fails the delivery, permanently for a 4xx status other than 408 or
+// 429, since the webhook would reject the event again.
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookSink returns a sink posting to url, which gives up on a request
+// after timeout.
+func NewWebhookSink(url string, timeout time.Duration) *WebhookSink {
+	return &WebhookSink{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (s *WebhookSink) Publish(ctx context.Context, ev Event) error {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return Permanent(err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-Id", ev.ID)
+	req.Header.Set("X-Event-Type", string(ev.Type))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("posting event %s: %v", ev.ID, err)
+	}
+	// Drain the body so that the connection can be reused
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		err := fmt.Errorf("posting event %s: webhook answered %s", ev.ID, resp.Status)
+		if permanentStatus(resp.StatusCode) {
+			return Permanent(err)
+		}
+		return err
+	}
+	return nil
+}
+
+// permanentStatus reports whether a webhook answering code rejects the event
+// itself, rather than being overloaded or failing for a while.
+func permanentStatus(code int) bool {
+	switch code {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return false
+	}
+	return code >= 400 && code <= 499
+}
@@ -0,0 +1,61 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWebhookSink(t *testing.T) {
+	ctx := context.Background()
+
+	var got Event
+	status := http.StatusNoContent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("webhook: unexpected request %s %s", r.Method, r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("webhook: unexpected err %v", err)
+		}
+		if r.Header.Get("X-Event-Id") != got.ID || r.Header.Get("X-Event-Type") != string(got.Type) {
+			t.Errorf("webhook: headers do not match the event %+v", got)
+		}
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	sink := NewWebhookSink(srv.URL, time.Second)
+	ev, _ := New(UserCreated, "test_username", nil)
+
+	if err := sink.Publish(ctx, ev); err != nil {
+		t.Errorf("Publish: unexpected err %v", err)
+	}
+	if got.ID != ev.ID || got.Subject != "test_username" {
+		t.Errorf("Publish: want event %+v, got %+v", ev, got)
+	}
+
+	// Only the rejections of the event itself are permanent
+	tests := []struct {
+		status    int
+		permanent bool
+	}{
+		{http.StatusInternalServerError, false},
+		{http.StatusServiceUnavailable, false},
+		{http.StatusTooManyRequests, false},
+		{http.StatusRequestTimeout, false},
+		{http.StatusBadRequest, true},
+		{http.StatusNotFound, true},
+	}
+	for _, tcase := range tests {
+		status = tcase.status
+		err := sink.Publish(ctx, ev)
+		if err == nil || errors.Is(err, ErrPermanent) != tcase.permanent {
+			t.Errorf("Publish: want err permanent %v for status %d, got %v", tcase.permanent, status, err)
+		}
+	}
+}
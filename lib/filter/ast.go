@@ -0,0 +1,95 @@
+// Package filter parses the filter expressions of list requests, as described
+// by https://google.aip.dev/160, into an abstract syntax tree. Each storage
+// backend translates the tree into a query of its own.
+package filter
+
+import (
+	"fmt"
+	"time"
+)
+
+// Expr is a node of the syntax tree: And, Or, Not or Restriction.
+type Expr interface {
+	String() string
+	isExpr()
+}
+
+// And matches the records which match both sides.
+type And struct {
+	Left, Right Expr
+}
+
+// Or matches the records which match either side.
+type Or struct {
+	Left, Right Expr
+}
+
+// Not matches the records which do not match Expr.
+type Not struct {
+	Expr Expr
+}
+
+// Restriction compares a field of the record with a value.
+type Restriction struct {
+	Field string
+	Op    Operator
+	// Value has the Go type of the field: a string for String, List and Enum
+	// fields, a bool for Bool fields and a time.Time for Timestamp fields.
+	Value interface{}
+}
+
+func (And) isExpr()         {}
+func (Or) isExpr()          {}
+func (Not) isExpr()         {}
+func (Restriction) isExpr() {}
+
+func (e And) String() string { return fmt.Sprintf("(%v AND %v)", e.Left, e.Right) }
+func (e Or) String() string  { return fmt.Sprintf("(%v OR %v)", e.Left, e.Right) }
+func (e Not) String() string { return fmt.Sprintf("NOT %v", e.Expr) }
+
+func (e Restriction) String() string {
+	value := e.Value
+	if t, ok := value.(time.Time); ok {
+		value = t.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprintf("%s %s %q", e.Field, e.Op, fmt.Sprint(value))
+}
+
+// Operator is the comparator of a Restriction.
+type Operator string
+
+const (
+	Equals        Operator = "="
+	NotEquals     Operator = "!="
+	Less          Operator = "<"
+	LessEquals    Operator = "<="
+	Greater       Operator = ">"
+	GreaterEquals Operator = ">="
+	Has           Operator = ":"
+)
+
+// Type is the type of a field, which determines the operators and values
+// allowed in its restrictions.
+type Type int
+
+const (
+	// String fields allow = and !=
+	String Type = iota
+	// Bool fields allow = and != with true or false
+	Bool
+	// Timestamp fields allow all comparisons, with RFC 3339 times or dates
+	// like "2021-01-01", which stand for midnight UTC
+	Timestamp
+	// List fields are repeated strings, which allow : only
+	List
+)
+
+// Field declares a field which filters may restrict.
+type Field struct {
+	Type Type
+	// Enum lists the allowed values of a String or List field, if not empty
+	Enum []string
+}
+
+// Schema declares the fields of a resource by their name.
+type Schema map[string]Field
@@ -0,0 +1,66 @@
+package filter
+
+import (
+	"time"
+)
+
+// Match evaluates expr for a record whose fields are returned by value: a
+// string for String and Enum fields, a []string for List fields, a bool for
+// Bool fields and a time.Time for Timestamp fields, the zero time if unset.
+// A nil expr matches every record.
+func Match(expr Expr, value func(field string) interface{}) bool {
+	switch e := expr.(type) {
+	case nil:
+		return true
+	case And:
+		return Match(e.Left, value) && Match(e.Right, value)
+	case Or:
+		return Match(e.Left, value) || Match(e.Right, value)
+	case Not:
+		return !Match(e.Expr, value)
+	case Restriction:
+		return e.matches(value(e.Field))
+	}
+	return false
+}
+
+func (e Restriction) matches(actual interface{}) bool {
+	switch actual := actual.(type) {
+	case []string:
+		for _, v := range actual {
+			if v == e.Value {
+				return true
+			}
+		}
+		return false
+	case time.Time:
+		want, _ := e.Value.(time.Time)
+		// Records without the timestamp match no comparison
+		if actual.IsZero() {
+			return false
+		}
+		switch e.Op {
+		case Equals:
+			return actual.Equal(want)
+		case NotEquals:
+			return !actual.Equal(want)
+		case Less:
+			return actual.Before(want)
+		case LessEquals:
+			return !actual.After(want)
+		case Greater:
+			return actual.After(want)
+		case GreaterEquals:
+			return !actual.Before(want)
+		}
+		return false
+	}
+
+	switch e.Op {
+	case Equals:
+		return actual == e.Value
+	case NotEquals:
+		return actual != e.Value
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package filter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMatch(t *testing.T) {
+	record := map[string]interface{}{
+		"name":        "Inception",
+		"director":    "Nolan",
+		"active":      true,
+		"cast":        []string{"DiCaprio", "Page"},
+		"tags":        []string{"Action"},
+		"create_time": time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC),
+	}
+	value := func(field string) interface{} { return record[field] }
+
+	tests := []struct {
+		filter   string
+		expected bool
+	}{
+		{``, true},
+		{`director = "Nolan" AND tags:Action`, true},
+		{`director != "Nolan"`, false},
+		{`cast:Page`, true},
+		{`cast:Bale OR tags:Comedy`, false},
+		{`NOT tags:Comedy`, true},
+		{`active = false`, false},
+		{`create_time > "2021-01-01" AND create_time < "2021-06-01T00:00:01Z"`, true},
+		{`create_time >= "2021-06-01"`, true},
+		{`create_time != "2021-06-01"`, false},
+	}
+	for _, tcase := range tests {
+		t.Run(tcase.filter, func(t *testing.T) {
+			expr, err := Parse(tcase.filter, testSchema)
+			if err != nil {
+				t.Fatalf("Parse: unexpected err %v", err)
+			}
+			if got := Match(expr, value); got != tcase.expected {
+				t.Errorf("Match: want %v, got %v", tcase.expected, got)
+			}
+		})
+	}
+
+	// Records without the timestamp match no comparison of it
+	expr, _ := Parse(`create_time != "2021-06-01"`, testSchema)
+	if Match(expr, func(string) interface{} { return time.Time{} }) {
+		t.Error("Match: want no match for missing timestamp")
+	}
+}
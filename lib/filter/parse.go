@@ -0,0 +1,343 @@
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Error describes a filter which cannot be parsed, pointing at the offending token.
+type Error struct {
+	// Pos is the position of the token in the filter, starting at 1
+	Pos   int
+	Token string
+	Msg   string
+}
+
+func (e *Error) Error() string {
+	if e.Token == "" {
+		return fmt.Sprintf("%s at position %d", e.Msg, e.Pos)
+	}
+	return fmt.Sprintf("%s at position %d near `%s`", e.Msg, e.Pos, e.Token)
+}
+
+const (
+	// MaxLength is the longest filter accepted, in bytes
+	MaxLength = 4096
+	// MaxDepth is the most parentheses a filter may nest, which bounds the
+	// recursion of the parser
+	MaxDepth = 32
+)
+
+// Parse parses the filter expression s. Every restriction must name a field
+// of schema with an operator and value suitable for its type. An empty filter
+// returns a nil Expr, which matches every record.
+//
+// The grammar is a subset of AIP-160, where OR binds tighter than AND:
+//
+//	expression  = sequence { "AND" sequence }
+//	sequence    = factor { factor }           (implicit AND)
+//	factor      = term { "OR" term }
+//	term        = [ "NOT" | "-" ] simple
+//	simple      = restriction | "(" expression ")"
+//	restriction = field comparator value
+func Parse(s string, schema Schema) (Expr, error) {
+	if len(s) > MaxLength {
+		return nil, &Error{Pos: MaxLength + 1, Msg: fmt.Sprintf("the filter is longer than %d bytes", MaxLength)}
+	}
+	tokens, err := lex(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 1 {
+		return nil, nil
+	}
+
+	p := &parser{tokens: tokens, schema: schema}
+	expr, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, p.errorAt(tok, "unexpected token")
+	}
+	return expr, nil
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokText
+	tokString
+	tokComparator
+	tokLParen
+	tokRParen
+	tokMinus
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	// pos is the byte offset of the token in the filter
+	pos int
+}
+
+// lex splits s into tokens, ending with a tokEOF.
+func lex(s string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{tokLParen, "(", i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{tokRParen, ")", i})
+			i++
+		case c == '"' || c == '\'':
+			text, n, err := unquote(s[i:])
+			if err != nil {
+				return nil, &Error{Pos: i + 1, Token: s[i:], Msg: err.Error()}
+			}
+			tokens = append(tokens, token{tokString, text, i})
+			i += n
+		case strings.HasPrefix(s[i:], "!=") || strings.HasPrefix(s[i:], "<=") || strings.HasPrefix(s[i:], ">="):
+			tokens = append(tokens, token{tokComparator, s[i : i+2], i})
+			i += 2
+		case c == '=' || c == '<' || c == '>' || c == ':':
+			tokens = append(tokens, token{tokComparator, s[i : i+1], i})
+			i++
+		case c == '-' && i+1 < len(s) && (s[i+1] == '(' || isTextChar(rune(s[i+1]))) &&
+			(i == 0 || !isTextChar(rune(s[i-1]))):
+			// A leading minus negates the following term, like NOT
+			tokens = append(tokens, token{tokMinus, "-", i})
+			i++
+		case isTextChar(rune(c)) || c >= 0x80:
+			start := i
+			for i < len(s) && (isTextChar(rune(s[i])) || s[i] >= 0x80) {
+				i++
+			}
+			tokens = append(tokens, token{tokText, s[start:i], start})
+		default:
+			return nil, &Error{Pos: i + 1, Token: string(c), Msg: "unexpected character"}
+		}
+	}
+	return append(tokens, token{tokEOF, "", len(s)}), nil
+}
+
+func isTextChar(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_.-*", r)
+}
+
+// unquote reads the string literal at the start of s and returns its value and length.
+func unquote(s string) (string, int, error) {
+	quote := s[0]
+	var sb strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case quote:
+			return sb.String(), i + 1, nil
+		case '\\':
+			if i+1 == len(s) {
+				return "", 0, fmt.Errorf("unterminated string")
+			}
+			i++
+			sb.WriteByte(s[i])
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string")
+}
+
+type parser struct {
+	tokens []token
+	next   int
+	schema Schema
+	// depth is the number of parentheses open at the next token
+	depth int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.next]
+}
+
+func (p *parser) advance() token {
+	tok := p.tokens[p.next]
+	if tok.kind != tokEOF {
+		p.next++
+	}
+	return tok
+}
+
+func (p *parser) errorAt(tok token, format string, args ...interface{}) error {
+	text := tok.text
+	if tok.kind == tokString {
+		text = strconv.Quote(tok.text)
+	}
+	return &Error{Pos: tok.pos + 1, Token: text, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) isKeyword(keyword string) bool {
+	tok := p.peek()
+	return tok.kind == tokText && tok.text == keyword
+}
+
+func (p *parser) expression() (Expr, error) {
+	left, err := p.sequence()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("AND") {
+		p.advance()
+		right, err := p.sequence()
+		if err != nil {
+			return nil, err
+		}
+		left = And{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) sequence() (Expr, error) {
+	left, err := p.factor()
+	if err != nil {
+		return nil, err
+	}
+	// Factors separated by blanks only are implicitly joined by AND
+	for {
+		tok := p.peek()
+		if tok.kind == tokEOF || tok.kind == tokRParen || p.isKeyword("AND") {
+			return left, nil
+		}
+		right, err := p.factor()
+		if err != nil {
+			return nil, err
+		}
+		left = And{left, right}
+	}
+}
+
+func (p *parser) factor() (Expr, error) {
+	left, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("OR") {
+		p.advance()
+		right, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		left = Or{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) term() (Expr, error) {
+	if p.isKeyword("NOT") || p.peek().kind == tokMinus {
+		p.advance()
+		expr, err := p.simple()
+		if err != nil {
+			return nil, err
+		}
+		return Not{expr}, nil
+	}
+	return p.simple()
+}
+
+func (p *parser) simple() (Expr, error) {
+	tok := p.peek()
+	switch {
+	case tok.kind == tokLParen:
+		if p.depth == MaxDepth {
+			return nil, p.errorAt(tok, "parentheses are nested deeper than %d levels", MaxDepth)
+		}
+		p.advance()
+		p.depth++
+		expr, err := p.expression()
+		p.depth--
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.advance(); closing.kind != tokRParen {
+			return nil, p.errorAt(closing, "expected `)`")
+		}
+		return expr, nil
+	case tok.kind == tokEOF:
+		return nil, p.errorAt(tok, "unexpected end of filter")
+	case tok.kind != tokText || tok.text == "AND" || tok.text == "OR" || tok.text == "NOT":
+		return nil, p.errorAt(tok, "expected a field name")
+	}
+	return p.restriction()
+}
+
+func (p *parser) restriction() (Expr, error) {
+	name := p.advance()
+	field, ok := p.schema[name.text]
+	if !ok {
+		return nil, p.errorAt(name, "unknown field %q", name.text)
+	}
+
+	opTok := p.advance()
+	if opTok.kind != tokComparator {
+		return nil, p.errorAt(opTok, "expected a comparator after field %q", name.text)
+	}
+	op := Operator(opTok.text)
+	if !field.allows(op) {
+		return nil, p.errorAt(opTok, "operator %s is not supported for field %q", op, name.text)
+	}
+
+	valueTok := p.advance()
+	if valueTok.kind != tokText && valueTok.kind != tokString {
+		return nil, p.errorAt(valueTok, "expected a value for field %q", name.text)
+	}
+	value, err := field.parseValue(valueTok.text)
+	if err != nil {
+		return nil, p.errorAt(valueTok, "invalid value for field %q: %v", name.text, err)
+	}
+
+	return Restriction{Field: name.text, Op: op, Value: value}, nil
+}
+
+func (f Field) allows(op Operator) bool {
+	switch f.Type {
+	case String, Bool:
+		return op == Equals || op == NotEquals
+	case Timestamp:
+		return op != Has
+	case List:
+		return op == Has
+	}
+	return false
+}
+
+func (f Field) parseValue(text string) (interface{}, error) {
+	switch f.Type {
+	case Bool:
+		return strconv.ParseBool(text)
+	case Timestamp:
+		if t, err := time.Parse(time.RFC3339Nano, text); err == nil {
+			return t, nil
+		}
+		if t, err := time.Parse("2006-01-02", text); err == nil {
+			return t, nil
+		}
+		return nil, fmt.Errorf("want an RFC 3339 time or a date like 2021-01-01")
+	}
+
+	if len(f.Enum) > 0 {
+		for _, v := range f.Enum {
+			if v == text {
+				return text, nil
+			}
+		}
+		return nil, fmt.Errorf("want one of %s", strings.Join(f.Enum, ", "))
+	}
+	return text, nil
+}
@@ -0,0 +1,89 @@
+package filter
+
+import (
+	"strings"
+	"testing"
+)
+
+var testSchema = Schema{
+	"name":        {Type: String},
+	"director":    {Type: String},
+	"active":      {Type: Bool},
+	"cast":        {Type: List},
+	"tags":        {Type: List, Enum: []string{"Action", "Comedy"}},
+	"create_time": {Type: Timestamp},
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   string
+		expected string
+	}{
+		{"empty", "  ", "<nil>"},
+		{"restriction", `director = "Nolan"`, `director = "Nolan"`},
+		{"bare_value", `director=Nolan`, `director = "Nolan"`},
+		{"escaped_quote", `name = "The \"Prestige\""`, `name = "The \"Prestige\""`},
+		{"and", `director = "Nolan" AND tags:Action`, `(director = "Nolan" AND tags : "Action")`},
+		{"implicit_and", `director = "Nolan" tags:Action`, `(director = "Nolan" AND tags : "Action")`},
+		{"or_binds_tighter", `active = true AND tags:Action OR tags:Comedy`,
+			`(active = "true" AND (tags : "Action" OR tags : "Comedy"))`},
+		{"parentheses", `(active = true AND tags:Action) OR cast:Bale`,
+			`((active = "true" AND tags : "Action") OR cast : "Bale")`},
+		{"deepest", strings.Repeat("(", MaxDepth) + "active = true" + strings.Repeat(")", MaxDepth), `active = "true"`},
+		{"not", `NOT director = "Nolan"`, `NOT director = "Nolan"`},
+		{"minus", `-cast:Bale`, `NOT cast : "Bale"`},
+		{"date", `create_time > "2021-01-01"`, `create_time > "2021-01-01T00:00:00Z"`},
+		{"rfc3339", `create_time <= "2021-01-01T10:00:00+02:00"`, `create_time <= "2021-01-01T10:00:00+02:00"`},
+	}
+	for _, tcase := range tests {
+		t.Run(tcase.name, func(t *testing.T) {
+			expr, err := Parse(tcase.filter, testSchema)
+			if err != nil {
+				t.Fatalf("Parse: unexpected err %v", err)
+			}
+			got := "<nil>"
+			if expr != nil {
+				got = expr.String()
+			}
+			if got != tcase.expected {
+				t.Errorf("Parse:\n\texpected: %v \n\tactual: %v", tcase.expected, got)
+			}
+		})
+	}
+}
+
+func TestParse_errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		filter      string
+		expectedErr string
+	}{
+		{"unknown_field", `directer = "Nolan"`, "unknown field \"directer\" at position 1 near `directer`"},
+		{"bad_character", `director ~ "Nolan"`, "unexpected character at position 10 near `~`"},
+		{"missing_comparator", `director "Nolan"`, "expected a comparator after field \"director\" at position 10 near `\"Nolan\"`"},
+		{"missing_value", `director =`, "expected a value for field \"director\" at position 11"},
+		{"bad_operator", `cast = "Bale"`, "operator = is not supported for field \"cast\" at position 6 near `=`"},
+		{"bad_enum", `tags:Horror`, "invalid value for field \"tags\": want one of Action, Comedy at position 6 near `Horror`"},
+		{"bad_time", `create_time > "yesterday"`, "invalid value for field \"create_time\": want an RFC 3339 time or a date like 2021-01-01 at position 15 near `\"yesterday\"`"},
+		{"bad_bool", `active = maybe`, "invalid value for field \"active\": strconv.ParseBool: parsing \"maybe\": invalid syntax at position 10 near `maybe`"},
+		{"unterminated_string", `director = "Nolan`, "unterminated string at position 12 near `\"Nolan`"},
+		{"unclosed_parenthesis", `(active = true`, "expected `)` at position 15"},
+		{"dangling_and", `active = true AND`, "unexpected end of filter at position 18"},
+		{"stray_parenthesis", `active = true)`, "unexpected token at position 14 near `)`"},
+		{"too_deep", strings.Repeat("(", MaxDepth+1) + "active = true" + strings.Repeat(")", MaxDepth+1),
+			"parentheses are nested deeper than 32 levels at position 33 near `(`"},
+		{"too_long", strings.Repeat("(", 3<<20), "the filter is longer than 4096 bytes at position 4097"},
+	}
+	for _, tcase := range tests {
+		t.Run(tcase.name, func(t *testing.T) {
+			_, err := Parse(tcase.filter, testSchema)
+			if err == nil || err.Error() != tcase.expectedErr {
+				t.Errorf("Parse:\n\texpected: %v \n\tactual: %v", tcase.expectedErr, err)
+			}
+			if _, ok := err.(*Error); !ok {
+				t.Errorf("Parse: want *Error, got %T", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,276 @@
+// Package fixtures seeds a database with a declarative dataset of users and
+// movies, so that every development environment starts from the same one.
+//
+// Fixtures are written in YAML, or in JSON, which is read as YAML:
+//
+//	users:
+//	  - username: admin_user
+//	    email: admin@example.com
+//	    password: admin_pwd
+//	    role: ADMIN
+//	movies:
+//	  - id: movie-inception
+//	    name: Inception
+//	    tags: [Action, Adventure]
+//
+// Passwords are given in plain text and hashed when seeded. Movies are
+// identified by their id, so that seeding again finds them.
+package fixtures
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/AkashGit21/ms-project/lib/persistence"
+	"github.com/golang/protobuf/ptypes"
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/yaml.v3"
+)
+
+// Fixtures is a dataset of users and movies.
+type Fixtures struct {
+	Users  []User  `yaml:"users"`
+	Movies []Movie `yaml:"movies"`
+}
+
+// User is the fixture of a user, identified by its username.
+type User struct {
+	Username  string `yaml:"username"`
+	Email     string `yaml:"email"`
+	Password  string `yaml:"password"`
+	Role      string `yaml:"role"`
+	FirstName string `yaml:"first_name"`
+	LastName  string `yaml:"last_name"`
+	Nickname  string `yaml:"nickname"`
+}
+
+// Movie is the fixture of a movie, identified by its id.
+type Movie struct {
+	Id       string   `yaml:"id"`
+	Name     string   `yaml:"name"`
+	Summary  string   `yaml:"summary"`
+	Cast     []string `yaml:"cast"`
+	Tags     []string `yaml:"tags"`
+	Director string   `yaml:"director"`
+	Writers  []string `yaml:"writers"`
+}
+
+// Database holds the records seeded.
+type Database interface {
+	persistence.UserRepository
+	persistence.MovieRepository
+}
+
+// Stats counts the records of a seeding.
+type Stats struct {
+	Removed int
+	Created int
+	// Skipped counts the records already in the database
+	Skipped int
+}
+
+// Load reads the fixtures of the given files, in order, as a single dataset.
+func Load(paths ...string) (Fixtures, error) {
+	var all Fixtures
+	for _, path := range paths {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return all, err
+		}
+		fx, err := Parse(data)
+		if err != nil {
+			return all, fmt.Errorf("%s: %w", path, err)
+		}
+		all.Users = append(all.Users, fx.Users...)
+		all.Movies = append(all.Movies, fx.Movies...)
+	}
+	return all, all.Validate()
+}
+
+// Parse reads fixtures written in YAML or JSON. Unknown fields are rejected,
+// so that a misspelled one is not silently ignored.
+func Parse(data []byte) (Fixtures, error) {
+	var fx Fixtures
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&fx); err != nil && err != io.EOF {
+		return fx, err
+	}
+	return fx, nil
+}
+
+// Validate checks that every record has its identity and required fields,
+// that no identity is repeated and that the roles and tags exist. Movie names
+// are unique regardless of case and spacing, like in the database.
+func (fx Fixtures) Validate() error {
+	usernames := map[string]bool{}
+	for i, u := range fx.Users {
+		switch {
+		case u.Username == "":
+			return fmt.Errorf("user %d: the username is required", i+1)
+		case usernames[u.Username]:
+			return fmt.Errorf("user %s: the username is repeated", u.Username)
+		case u.Password == "":
+			return fmt.Errorf("user %s: the password is required", u.Username)
+		}
+		if _, ok := persistence.Role_value[u.Role]; !ok && u.Role != "" {
+			return fmt.Errorf("user %s: unknown role %q", u.Username, u.Role)
+		}
+		usernames[u.Username] = true
+	}
+
+	ids := map[string]bool{}
+	// names holds the id of the movie of every name key
+	names := map[string]string{}
+	for i, mv := range fx.Movies {
+		key := persistence.MovieNameKey(mv.Name)
+		switch {
+		case mv.Id == "":
+			return fmt.Errorf("movie %d: the id is required", i+1)
+		case ids[mv.Id]:
+			return fmt.Errorf("movie %s: the id is repeated", mv.Id)
+		case key == "":
+			return fmt.Errorf("movie %s: the name is required", mv.Id)
+		case names[key] != "":
+			return fmt.Errorf("movie %s: the name is repeated by movie %s", mv.Id, names[key])
+		}
+		for _, tag := range mv.Tags {
+			if t, ok := persistence.Tag_value[tag]; !ok || persistence.Tag(t) == persistence.Tag_UNDEFINED_TAG {
+				return fmt.Errorf("movie %s: unknown tag %q", mv.Id, tag)
+			}
+		}
+		ids[mv.Id] = true
+		names[key] = mv.Id
+	}
+	return nil
+}
+
+// Seed adds the records of fx missing from db, leaving those already there
+// untouched, so that seeding twice changes nothing. With reset, every user
+// and movie of db is removed first, so that db holds the fixtures only.
+func Seed(ctx context.Context, db Database, fx Fixtures, reset bool) (Stats, error) {
+	var stats Stats
+	if err := fx.Validate(); err != nil {
+		return stats, err
+	}
+	if reset {
+		removed, err := removeAll(ctx, db)
+		stats.Removed = removed
+		if err != nil {
+			return stats, err
+		}
+	}
+
+	now := ptypes.TimestampNow()
+	for _, u := range fx.Users {
+		if _, err := db.FindByUsername(ctx, u.Username); err == nil {
+			stats.Skipped++
+			continue
+		} else if !errors.Is(err, persistence.ErrNotFound) {
+			return stats, err
+		}
+
+		hash, err := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return stats, fmt.Errorf("user %s: unable to hash password! %v", u.Username, err)
+		}
+		// The emails of the fixtures are trusted, there is no one to verify them
+		user := persistence.User{
+			Username:        u.Username,
+			Email:           u.Email,
+			Password:        string(hash),
+			Role:            persistence.Role(persistence.Role_value[u.Role]),
+			Active:          true,
+			FirstName:       u.FirstName,
+			LastName:        optional(u.LastName),
+			Nickname:        optional(u.Nickname),
+			CreateTime:      now,
+			UpdateTime:      now,
+			EmailVerifyTime: now,
+		}
+		if _, err := db.AddUser(ctx, user); err != nil {
+			return stats, fmt.Errorf("user %s: %w", u.Username, err)
+		}
+		stats.Created++
+	}
+
+	for _, mv := range fx.Movies {
+		if _, err := db.FindMovieByID(ctx, mv.Id); err == nil {
+			stats.Skipped++
+			continue
+		} else if !errors.Is(err, persistence.ErrNotFound) {
+			return stats, err
+		}
+
+		var tags []persistence.Tag
+		for _, tag := range mv.Tags {
+			tags = append(tags, persistence.Tag(persistence.Tag_value[tag]))
+		}
+		movie := persistence.Movie{
+			Id:         mv.Id,
+			Name:       persistence.NormalizeMovieName(mv.Name),
+			Summary:    mv.Summary,
+			Cast:       mv.Cast,
+			Tags:       tags,
+			Director:   mv.Director,
+			Writers:    mv.Writers,
+			Active:     true,
+			CreateTime: now,
+			UpdateTime: now,
+		}
+		if _, err := db.AddMovie(ctx, movie); err != nil {
+			return stats, fmt.Errorf("movie %s: %w", mv.Id, err)
+		}
+		stats.Created++
+	}
+	return stats, nil
+}
+
+// removeAll removes every user and movie of db, the deleted ones included,
+// and returns how many it removed.
+func removeAll(ctx context.Context, db Database) (int, error) {
+	removed := 0
+	for {
+		// Every page is removed, hence the next one is the first again
+		users, err := db.FindAllUsers(ctx, true, persistence.DefaultOrder, persistence.Cursor{}, 100)
+		if err != nil {
+			return removed, err
+		}
+		for _, u := range users {
+			if err := db.RemoveByUsername(ctx, u.Username); err != nil && !errors.Is(err, persistence.ErrNotFound) {
+				return removed, err
+			}
+			removed++
+		}
+		if len(users) == 0 {
+			break
+		}
+	}
+	for {
+		movies, err := db.FindAllMovies(ctx, nil, true, persistence.DefaultOrder, persistence.Cursor{}, 100)
+		if err != nil {
+			return removed, err
+		}
+		for _, mv := range movies {
+			if err := db.RemoveMovieByID(ctx, mv.Id); err != nil && !errors.Is(err, persistence.ErrNotFound) {
+				return removed, err
+			}
+			removed++
+		}
+		if len(movies) == 0 {
+			return removed, nil
+		}
+	}
+}
+
+// optional returns a pointer to s, or nil if it is empty.
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,150 @@
+package fixtures
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AkashGit21/ms-project/lib/persistence"
+	"github.com/AkashGit21/ms-project/lib/persistence/memlayer"
+)
+
+const testYAML = `
+users:
+  - username: test_admin
+    email: test_admin@domain.com
+    password: test_pwd
+    role: ADMIN
+    last_name: test_last_name
+movies:
+  - id: test_movie_id
+    name: test_movie
+    tags: [Action, Comedy]
+    cast: [test_actor]
+`
+
+const testJSON = `{
+  "users": [{"username": "test_user", "password": "test_pwd2"}],
+  "movies": [{"id": "test_movie_id2", "name": "test_movie2"}]
+}`
+
+func TestParse(t *testing.T) {
+	fx, err := Parse([]byte(testYAML))
+	if err != nil {
+		t.Fatalf("Parse: unexpected err %v", err)
+	}
+	if len(fx.Users) != 1 || fx.Users[0].Role != "ADMIN" || fx.Users[0].LastName != "test_last_name" ||
+		len(fx.Movies) != 1 || len(fx.Movies[0].Tags) != 2 {
+		t.Errorf("Parse: unexpected fixtures %+v", fx)
+	}
+
+	if fx, err := Parse([]byte(testJSON)); err != nil || len(fx.Users) != 1 || len(fx.Movies) != 1 {
+		t.Errorf("Parse: want the JSON fixtures, got %+v, %v", fx, err)
+	}
+	if _, err := Parse([]byte("users:\n  - usrname: test_user\n")); err == nil {
+		t.Error("Parse: want an error for an unknown field")
+	}
+	if fx, err := Parse(nil); err != nil || len(fx.Users)+len(fx.Movies) != 0 {
+		t.Errorf("Parse: want no fixtures for an empty file, got %+v, %v", fx, err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	user := User{Username: "test_user", Password: "test_pwd"}
+	movie := Movie{Id: "test_movie_id", Name: "test_movie"}
+
+	tests := []struct {
+		name    string
+		fx      Fixtures
+		wantErr string
+	}{
+		{name: "valid", fx: Fixtures{Users: []User{user}, Movies: []Movie{movie}}},
+		{name: "missing username", fx: Fixtures{Users: []User{{Password: "test_pwd"}}}, wantErr: "username is required"},
+		{name: "missing password", fx: Fixtures{Users: []User{{Username: "test_user"}}}, wantErr: "password is required"},
+		{name: "repeated username", fx: Fixtures{Users: []User{user, user}}, wantErr: "username is repeated"},
+		{name: "unknown role", fx: Fixtures{Users: []User{{Username: "test_user", Password: "test_pwd", Role: "ROOT"}}}, wantErr: "unknown role"},
+		{name: "missing id", fx: Fixtures{Movies: []Movie{{Name: "test_movie"}}}, wantErr: "id is required"},
+		{name: "missing name", fx: Fixtures{Movies: []Movie{{Id: "test_movie_id"}}}, wantErr: "name is required"},
+		{name: "blank name", fx: Fixtures{Movies: []Movie{{Id: "test_movie_id", Name: "  "}}}, wantErr: "name is required"},
+		{name: "repeated id", fx: Fixtures{Movies: []Movie{movie, movie}}, wantErr: "id is repeated"},
+		{name: "repeated name", fx: Fixtures{Movies: []Movie{movie, {Id: "other_id", Name: " TEST_movie "}}}, wantErr: "name is repeated by movie test_movie_id"},
+		{name: "undefined tag", fx: Fixtures{Movies: []Movie{{Id: "test_movie_id", Name: "test_movie", Tags: []string{"UNDEFINED_TAG"}}}}, wantErr: "unknown tag"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.fx.Validate()
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("Validate: want an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	yamlPath := filepath.Join(dir, "users.yaml")
+	jsonPath := filepath.Join(dir, "movies.json")
+	os.WriteFile(yamlPath, []byte(testYAML), 0600)
+	os.WriteFile(jsonPath, []byte(testJSON), 0600)
+
+	fx, err := Load(yamlPath, jsonPath)
+	if err != nil || len(fx.Users) != 2 || len(fx.Movies) != 2 {
+		t.Errorf("Load: want the fixtures of both files, got %+v, %v", fx, err)
+	}
+	// The files form a single dataset, hence may not repeat each other
+	if _, err := Load(yamlPath, yamlPath); err == nil {
+		t.Error("Load: want an error for a repeated username")
+	}
+}
+
+func TestSeed(t *testing.T) {
+	ctx := context.Background()
+	db, _ := memlayer.NewMemoryLayer()
+	fx, _ := Parse([]byte(testYAML))
+
+	if stats, err := Seed(ctx, db, fx, false); err != nil || stats != (Stats{Created: 2}) {
+		t.Fatalf("Seed: want 2 records created, got %+v, %v", stats, err)
+	}
+	u, err := db.FindByUsername(ctx, "test_admin")
+	if err != nil || u.Role != persistence.Role_ADMIN || !u.Active || u.EmailVerifyTime == nil || u.LastName == nil || *u.LastName != "test_last_name" {
+		t.Errorf("FindByUsername: unexpected user %+v, %v", u, err)
+	}
+	if ok, err := db.Authenticate(ctx, "test_admin", "test_pwd"); !ok || err != nil {
+		t.Errorf("Authenticate: want the seeded password hashed, got %v, %v", ok, err)
+	}
+	mv, err := db.FindMovieByID(ctx, "test_movie_id")
+	if err != nil || len(mv.Tags) != 2 || mv.Tags[0] != persistence.Tag_Action || mv.Tags[1] != persistence.Tag_Comedy {
+		t.Errorf("FindMovieByID: unexpected movie %+v, %v", mv, err)
+	}
+
+	// Seeding again changes nothing
+	if stats, err := Seed(ctx, db, fx, false); err != nil || stats != (Stats{Skipped: 2}) {
+		t.Errorf("Seed: want 2 records skipped, got %+v, %v", stats, err)
+	}
+
+	// A reset removes the records which are not fixtures
+	if _, err := db.AddMovie(ctx, persistence.Movie{Id: "other_id", Name: "other_movie"}); err != nil {
+		t.Fatalf("AddMovie: unexpected err %v", err)
+	}
+	if stats, err := Seed(ctx, db, fx, true); err != nil || stats != (Stats{Removed: 3, Created: 2}) {
+		t.Errorf("Seed: want 3 records removed and 2 created, got %+v, %v", stats, err)
+	}
+	if _, err := db.FindMovieByID(ctx, "other_id"); err == nil {
+		t.Error("FindMovieByID: want the other movie removed by the reset")
+	}
+}
+
+func TestSeed_normalizedNames(t *testing.T) {
+	ctx := context.Background()
+	db, _ := memlayer.NewMemoryLayer()
+	fx := Fixtures{Movies: []Movie{{Id: "test_movie_id", Name: "  The   Spaced  Movie "}}}
+
+	if _, err := Seed(ctx, db, fx, false); err != nil {
+		t.Fatalf("Seed: unexpected err %v", err)
+	}
+	if mv, err := db.FindMovieByID(ctx, "test_movie_id"); err != nil || mv.Name != "The Spaced Movie" {
+		t.Errorf("FindMovieByID: want the name normalized, got %q, %v", mv.Name, err)
+	}
+}
@@ -0,0 +1,70 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// FileMailer appends the messages to a file, as they would be sent, each one
+// followed by a blank line. It is meant for development, so that the links of
+// the messages are at hand without an SMTP server.
+type FileMailer struct {
+	mu   sync.Mutex
+	file *os.File
+	from string
+}
+
+// NewFileMailer opens the file at path for appending, and creates it if needed.
+// The messages are written as sent by from.
+func NewFileMailer(path, from string) (*FileMailer, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &FileMailer{file: f, from: from}, nil
+}
+
+func (m *FileMailer) Send(ctx context.Context, msg Message) error {
+	data, err := msg.format(m.from, time.Now())
+	if err != nil {
+		return err
+	}
+	data = append(data, '\r', '\n')
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, err := m.file.Write(data); err != nil {
+		return fmt.Errorf("writing mail to %s: %v", m.file.Name(), err)
+	}
+	return nil
+}
+
+func (m *FileMailer) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.file.Close()
+}
+
+// LogMailer logs the messages instead of sending them.
+type LogMailer struct {
+	logger *log.Logger
+}
+
+// NewLogMailer returns a mailer printing to logger, or to the standard logger
+// if nil.
+func NewLogMailer(logger *log.Logger) *LogMailer {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &LogMailer{logger: logger}
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	m.logger.Printf("[INFO] Mail to %s: %s\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
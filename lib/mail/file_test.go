@@ -0,0 +1,48 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileMailer(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "mails.txt")
+
+	// Reopening the file appends to it
+	for _, to := range []string{"harry@hogwarts.edu", "ron@hogwarts.edu"} {
+		m, err := NewFileMailer(path, "noreply@domain.com")
+		if err != nil {
+			t.Fatalf("NewFileMailer: unexpected err %v", err)
+		}
+		if err := m.Send(ctx, Message{To: to, Subject: "test_subject", Body: "test_body"}); err != nil {
+			t.Errorf("Send: unexpected err %v", err)
+		}
+		m.Close()
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: unexpected err %v", err)
+	}
+	if strings.Count(string(data), "From: noreply@domain.com\r\n") != 2 || !strings.Contains(string(data), "To: harry@hogwarts.edu\r\n") ||
+		!strings.Contains(string(data), "To: ron@hogwarts.edu\r\n") || strings.Count(string(data), "test_body\r\n\r\n") != 2 {
+		t.Errorf("Send: want both messages written, got %q", data)
+	}
+}
+
+func TestLogMailer(t *testing.T) {
+	var buf bytes.Buffer
+	m := NewLogMailer(log.New(&buf, "", 0))
+	if err := m.Send(context.Background(), Message{To: "harry@hogwarts.edu", Subject: "test_subject", Body: "test_body"}); err != nil {
+		t.Errorf("Send: unexpected err %v", err)
+	}
+	if got := buf.String(); got != "[INFO] Mail to harry@hogwarts.edu: test_subject\ntest_body\n" {
+		t.Errorf("Send: unexpected log %q", got)
+	}
+}
@@ -0,0 +1,27 @@
+package mail
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMessageFormat(t *testing.T) {
+	msg := Message{To: "harry@hogwarts.edu", Subject: "Vérifiez", Body: "line 1\nline 2"}
+	data, err := msg.format("noreply@domain.com", time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("format: unexpected err %v", err)
+	}
+	want := "From: noreply@domain.com\r\nTo: harry@hogwarts.edu\r\nSubject: =?utf-8?q?V=C3=A9rifiez?=\r\n" +
+		"Date: Fri, 01 Jan 2021 00:00:00 +0000\r\nMIME-Version: 1.0\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n" +
+		"line 1\r\nline 2\r\n"
+	if string(data) != want {
+		t.Errorf("format: want\n%q\ngot\n%q", want, data)
+	}
+
+	// A header may not smuggle in others
+	msg.To = "harry@hogwarts.edu\r\nBcc: ron@hogwarts.edu"
+	if _, err := msg.format("noreply@domain.com", time.Now()); err == nil || !strings.Contains(err.Error(), "line breaks") {
+		t.Errorf("format: want an error for a line break, got %v", err)
+	}
+}
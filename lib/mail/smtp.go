@@ -0,0 +1,122 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+	"time"
+)
+
+// SMTPConfig is the server an SMTPMailer delivers through.
+type SMTPConfig struct {
+	// Addr is the host and port of the server, e.g. `smtp.example.com:587`
+	Addr string
+	// Username and Password authenticate with the PLAIN mechanism, which is
+	// only used over TLS or to localhost. No authentication if empty.
+	Username string
+	Password string
+	// From is the sender of the messages, e.g. `MS Project <noreply@example.com>`
+	From string
+	// Timeout bounds the delivery of a message, 0 leaves it to the context
+	Timeout time.Duration
+}
+
+// SMTPMailer delivers every message through an SMTP server, on a connection of
+// its own. The connection is upgraded with STARTTLS when the server offers it.
+type SMTPMailer struct {
+	config SMTPConfig
+}
+
+// NewSMTPMailer returns a mailer delivering through the server of config.
+func NewSMTPMailer(config SMTPConfig) *SMTPMailer {
+	return &SMTPMailer{config: config}
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	from, err := netmail.ParseAddress(m.config.From)
+	if err != nil {
+		return fmt.Errorf("invalid sender %q: %v", m.config.From, err)
+	}
+	to, err := netmail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("invalid recipient %q: %v", msg.To, err)
+	}
+	data, err := msg.format(m.config.From, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if m.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.config.Timeout)
+		defer cancel()
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", m.config.Addr)
+	if err != nil {
+		return fmt.Errorf("connecting to %s: %v", m.config.Addr, err)
+	}
+	defer conn.Close()
+	// The SMTP client has no context, closing the connection interrupts it
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	if err := m.deliver(conn, from.Address, to.Address, data); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("sending mail to %s: %w", to.Address, ctx.Err())
+		}
+		return fmt.Errorf("sending mail to %s: %v", to.Address, err)
+	}
+	return nil
+}
+
+// deliver runs the SMTP exchange sending data from one address to another.
+func (m *SMTPMailer) deliver(conn net.Conn, from, to string, data []byte) error {
+	host, _, err := net.SplitHostPort(m.config.Addr)
+	if err != nil {
+		return err
+	}
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if m.config.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", m.config.Username, m.config.Password, host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(from); err != nil {
+		return err
+	}
+	if err := c.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
@@ -0,0 +1,105 @@
+package mail
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSMTPServer accepts a single connection and records the commands and the
+// data it receives, answering every command positively.
+func fakeSMTPServer(t *testing.T) (string, <-chan []string) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: unexpected err %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var lines []string
+		r := bufio.NewReader(conn)
+		reply := func(s string) { conn.Write([]byte(s + "\r\n")) }
+		reply("220 localhost ESMTP")
+		inData := false
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				break
+			}
+			line = strings.TrimRight(line, "\r\n")
+			lines = append(lines, line)
+			switch {
+			case inData && line == ".":
+				inData = false
+				reply("250 queued")
+			case inData:
+			case strings.HasPrefix(line, "EHLO"):
+				reply("250 localhost")
+			case line == "DATA":
+				inData = true
+				reply("354 go ahead")
+			case line == "QUIT":
+				reply("221 bye")
+				received <- lines
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+		received <- lines
+	}()
+	return l.Addr().String(), received
+}
+
+func TestSMTPMailer(t *testing.T) {
+	addr, received := fakeSMTPServer(t)
+	m := NewSMTPMailer(SMTPConfig{Addr: addr, From: "MS Project <noreply@domain.com>", Timeout: 5 * time.Second})
+
+	msg := Message{To: "harry@hogwarts.edu", Subject: "test_subject", Body: "test_body\n.hidden"}
+	if err := m.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send: unexpected err %v", err)
+	}
+
+	lines := strings.Join(<-received, "\n")
+	for _, want := range []string{"MAIL FROM:<noreply@domain.com>", "RCPT TO:<harry@hogwarts.edu>",
+		"From: MS Project <noreply@domain.com>", "Subject: test_subject", "test_body\n..hidden\n.\nQUIT"} {
+		if !strings.Contains(lines, want) {
+			t.Errorf("Send: want %q sent, got\n%s", want, lines)
+		}
+	}
+}
+
+func TestSMTPMailer_errors(t *testing.T) {
+	m := NewSMTPMailer(SMTPConfig{Addr: "127.0.0.1:0", From: "noreply@domain.com"})
+	if err := m.Send(context.Background(), Message{To: "not an address"}); err == nil || !strings.Contains(err.Error(), "invalid recipient") {
+		t.Errorf("Send: want an invalid recipient, got %v", err)
+	}
+
+	// A server which never answers is given up on at the deadline
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: unexpected err %v", err)
+	}
+	defer l.Close()
+	go func() {
+		if conn, err := l.Accept(); err == nil {
+			defer conn.Close()
+			time.Sleep(5 * time.Second)
+		}
+	}()
+	m = NewSMTPMailer(SMTPConfig{Addr: l.Addr().String(), From: "noreply@domain.com", Timeout: 100 * time.Millisecond})
+	start := time.Now()
+	if err := m.Send(context.Background(), Message{To: "harry@hogwarts.edu"}); err == nil || time.Since(start) > 2*time.Second {
+		t.Errorf("Send: want a timeout, got %v after %v", err, time.Since(start))
+	}
+}
@@ -0,0 +1,178 @@
+package persistence
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultHistory is the number of events a Broadcaster keeps to resume from
+const DefaultHistory = 1024
+
+// Broadcaster is a MovieWatcher of the events published to it, for the
+// backends without change streams of their own. It keeps the latest events,
+// so that a watcher reconnecting with the resume token of its last event
+// misses none, unless it was gone for longer than the history lasts.
+type Broadcaster struct {
+	mu sync.Mutex
+	// epoch tells apart the resume tokens of earlier processes, whose
+	// events are gone
+	epoch    string
+	seq      uint64
+	history  []MovieEvent
+	size     int
+	watchers map[*broadcastStream]struct{}
+}
+
+// NewBroadcaster returns a Broadcaster which keeps the given number of events.
+func NewBroadcaster(history int) *Broadcaster {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return &Broadcaster{
+		epoch:    hex.EncodeToString(b),
+		size:     history,
+		watchers: map[*broadcastStream]struct{}{},
+	}
+}
+
+// Publish sends an event of the given type about mv to every watcher. It
+// never blocks, so backends may publish while holding their locks, which
+// keeps the events in the order of the writes.
+func (b *Broadcaster) Publish(typ EventType, mv Movie) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.seq++
+	ev := MovieEvent{
+		Type:        typ,
+		Movie:       mv,
+		Time:        time.Now(),
+		ResumeToken: b.epoch + "." + strconv.FormatUint(b.seq, 10),
+	}
+	b.history = append(b.history, ev)
+	if len(b.history) > b.size {
+		b.history = b.history[len(b.history)-b.size:]
+	}
+	for w := range b.watchers {
+		w.push(ev)
+	}
+}
+
+func (b *Broadcaster) WatchMovies(ctx context.Context, resumeToken string) (MovieStream, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	w := &broadcastStream{
+		broadcaster: b,
+		signal:      make(chan struct{}, 1),
+	}
+	if resumeToken != "" {
+		missed, err := b.after(resumeToken)
+		if err != nil {
+			return nil, err
+		}
+		w.queue = missed
+	}
+	b.watchers[w] = struct{}{}
+	return w, nil
+}
+
+// after returns the events of the history after the one with the given token.
+func (b *Broadcaster) after(resumeToken string) ([]MovieEvent, error) {
+	parts := strings.SplitN(resumeToken, ".", 2)
+	if len(parts) != 2 || parts[0] != b.epoch {
+		return nil, InvalidResumeToken(errors.New("the token is not from this server"))
+	}
+	seq, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil || seq > b.seq {
+		return nil, InvalidResumeToken(fmt.Errorf("malformed token %q", resumeToken))
+	}
+
+	// The history holds the events b.seq-len(history)+1 up to b.seq
+	first := b.seq - uint64(len(b.history)) + 1
+	if seq+1 < first {
+		return nil, InvalidResumeToken(errors.New("the events after the token are gone"))
+	}
+	return append([]MovieEvent(nil), b.history[seq+1-first:]...), nil
+}
+
+// broadcastStream queues the events of a Broadcaster for one watcher. A
+// watcher which falls behind by more than the history is dropped, as it could
+// not resume either.
+type broadcastStream struct {
+	broadcaster *Broadcaster
+	signal      chan struct{}
+
+	mu     sync.Mutex
+	queue  []MovieEvent
+	lagged bool
+	closed bool
+}
+
+// push queues ev, the caller holds the lock of the broadcaster.
+func (w *broadcastStream) push(ev MovieEvent) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.lagged {
+		return
+	}
+	if len(w.queue) >= w.broadcaster.size {
+		w.lagged = true
+		w.queue = nil
+	} else {
+		w.queue = append(w.queue, ev)
+	}
+	select {
+	case w.signal <- struct{}{}:
+	default:
+	}
+}
+
+func (w *broadcastStream) Next(ctx context.Context) (MovieEvent, error) {
+	for {
+		w.mu.Lock()
+		switch {
+		case w.closed:
+			w.mu.Unlock()
+			return MovieEvent{}, Unavailable(errors.New("the watch is closed"))
+		case w.lagged:
+			w.mu.Unlock()
+			return MovieEvent{}, Unavailable(errors.New("the watcher fell behind the events"))
+		case len(w.queue) > 0:
+			ev := w.queue[0]
+			w.queue = w.queue[1:]
+			w.mu.Unlock()
+			return ev, nil
+		}
+		w.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return MovieEvent{}, ctx.Err()
+		case <-w.signal:
+		}
+	}
+}
+
+func (w *broadcastStream) Close() error {
+	w.broadcaster.mu.Lock()
+	delete(w.broadcaster.watchers, w)
+	w.broadcaster.mu.Unlock()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+	w.queue = nil
+	return nil
+}
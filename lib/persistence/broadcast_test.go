@@ -0,0 +1,92 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBroadcaster(t *testing.T) {
+	ctx := context.Background()
+	b := NewBroadcaster(3)
+
+	w, err := b.WatchMovies(ctx, "")
+	if err != nil {
+		t.Fatalf("WatchMovies: unexpected err %v", err)
+	}
+	defer w.Close()
+
+	b.Publish(MovieCreated, Movie{Id: "id_0"})
+	b.Publish(MovieUpdated, Movie{Id: "id_0"})
+	first, err := w.Next(ctx)
+	if err != nil || first.Type != MovieCreated || first.Movie.Id != "id_0" || first.ResumeToken == "" {
+		t.Fatalf("Next: want the created event, got %v, %v", first, err)
+	}
+	if ev, err := w.Next(ctx); err != nil || ev.Type != MovieUpdated {
+		t.Errorf("Next: want the updated event, got %v, %v", ev, err)
+	}
+
+	// A watcher resuming after the first event gets the ones it missed
+	b.Publish(MovieDeleted, Movie{Id: "id_0"})
+	resumed, err := b.WatchMovies(ctx, first.ResumeToken)
+	if err != nil {
+		t.Fatalf("WatchMovies: unexpected err %v", err)
+	}
+	defer resumed.Close()
+	for _, expected := range []EventType{MovieUpdated, MovieDeleted} {
+		if ev, err := resumed.Next(ctx); err != nil || ev.Type != expected {
+			t.Errorf("Next: want event %v, got %v, %v", expected, ev, err)
+		}
+	}
+
+	// The history keeps 3 events, those after the first are gone after 5
+	b.Publish(MovieUndeleted, Movie{Id: "id_0"})
+	b.Publish(MovieUpdated, Movie{Id: "id_0"})
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"expired", first.ResumeToken},
+		{"other_server", "0badcafe.1"},
+		{"malformed", "not_a_token"},
+		{"future", b.epoch + ".99"},
+	}
+	for _, tcase := range tests {
+		t.Run(tcase.name, func(t *testing.T) {
+			if _, err := b.WatchMovies(ctx, tcase.token); !errors.Is(err, ErrInvalidResumeToken) {
+				t.Errorf("WatchMovies: want ErrInvalidResumeToken, got %v", err)
+			}
+		})
+	}
+
+	timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	for i := 0; i < 3; i++ {
+		w.Next(ctx) // the deleted, undeleted and updated events
+	}
+	if _, err := w.Next(timeout); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Next: want DeadlineExceeded without events, got %v", err)
+	}
+}
+
+func TestBroadcaster_lagging(t *testing.T) {
+	ctx := context.Background()
+	b := NewBroadcaster(2)
+
+	w, _ := b.WatchMovies(ctx, "")
+	for i := 0; i < 3; i++ {
+		b.Publish(MovieCreated, Movie{})
+	}
+	if _, err := w.Next(ctx); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("Next: want ErrUnavailable for a watcher fallen behind, got %v", err)
+	}
+
+	w.Close()
+	if _, err := w.Next(ctx); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("Next: want ErrUnavailable once closed, got %v", err)
+	}
+	if len(b.watchers) != 0 {
+		t.Errorf("Close: want the watcher removed, got %v", b.watchers)
+	}
+}
@@ -0,0 +1,386 @@
+package persistence
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AkashGit21/ms-project/lib/filter"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"golang.org/x/sync/singleflight"
+)
+
+// CacheOptions tune a CachingHandler.
+type CacheOptions struct {
+	// Size is the most entries kept, the least recently used go first
+	Size int
+	// TTL is how long an entry is served before the database is asked
+	// again. Entries of a TTL <= 0 are kept until evicted or invalidated.
+	TTL time.Duration
+	// NegativeTTL is how long a user or movie found missing is reported
+	// missing without asking the database, 0 disables it
+	NegativeTTL time.Duration
+}
+
+// CacheStats counts the lookups of a CachingHandler since it was created.
+type CacheStats struct {
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+	// Shared counts the misses which got the result of the same lookup of
+	// another caller, rather than asking the database themselves
+	Shared    uint64 `json:"shared"`
+	Evictions uint64 `json:"evictions"`
+	Entries   int    `json:"entries"`
+}
+
+// The namespaces of the cache keys, each invalidated by the writes to its records
+const (
+	userNamespace  = "user"
+	movieNamespace = "movie"
+)
+
+// CachingHandler serves the reads of the wrapped DatabaseHandler from memory.
+// Every write through it invalidates the record written and every list, count
+// and search of its kind. The writes of other processes are seen once the
+// entries expire.
+type CachingHandler struct {
+	handler DatabaseHandler
+	opts    CacheOptions
+	now     func() time.Time
+	group   singleflight.Group
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+	// generations count the writes of each namespace. The lists, counts
+	// and searches are keyed by the generation they were read in, and a
+	// lookup stores nothing if a write happened meanwhile.
+	generations map[string]uint64
+	stats       CacheStats
+}
+
+type cacheEntry struct {
+	key     string
+	value   interface{}
+	err     error
+	expires time.Time
+}
+
+// NewCachingHandler returns a CachingHandler of handler.
+func NewCachingHandler(handler DatabaseHandler, opts CacheOptions) *CachingHandler {
+	return &CachingHandler{
+		handler:     handler,
+		opts:        opts,
+		now:         time.Now,
+		entries:     map[string]*list.Element{},
+		lru:         list.New(),
+		generations: map[string]uint64{},
+	}
+}
+
+// Stats returns the statistics of the cache, for monitoring.
+func (ch *CachingHandler) Stats() CacheStats {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	stats := ch.stats
+	stats.Entries = ch.lru.Len()
+	return stats
+}
+
+// load returns the entry of key, or else the result of fetch, which is cached
+// unless it failed for another reason than ErrNotFound. The callers missing
+// the same key at once share a single fetch, which is retried by the callers
+// still waiting if the one it ran for gives up.
+func (ch *CachingHandler) load(ctx context.Context, namespace, key string, fetch func(context.Context) (interface{}, error)) (interface{}, error) {
+	ch.mu.Lock()
+	if elem, ok := ch.entries[key]; ok {
+		entry := elem.Value.(*cacheEntry)
+		if entry.expires.IsZero() || ch.now().Before(entry.expires) {
+			ch.lru.MoveToFront(elem)
+			ch.stats.Hits++
+			ch.mu.Unlock()
+			return entry.value, entry.err
+		}
+		ch.remove(elem)
+	}
+	ch.stats.Misses++
+	generation := ch.generations[namespace]
+	ch.mu.Unlock()
+
+	// The generation keeps the callers after a write from getting the
+	// result of a fetch which started before
+	for {
+		fetched := false
+		value, err, shared := ch.group.Do(fmt.Sprintf("%s#%d", key, generation), func() (interface{}, error) {
+			fetched = true
+			value, err := fetch(ctx)
+			ch.store(namespace, generation, key, value, err)
+			return value, err
+		})
+		if !shared || fetched {
+			return value, err
+		}
+		ch.mu.Lock()
+		ch.stats.Shared++
+		ch.mu.Unlock()
+
+		// A shared fetch runs with the context of the caller which started it,
+		// the others fetch again if it ended that context but not theirs
+		if !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) || ctx.Err() != nil {
+			return value, err
+		}
+	}
+}
+
+// store caches the result of a fetch started in the given generation.
+func (ch *CachingHandler) store(namespace string, generation uint64, key string, value interface{}, err error) {
+	ttl := ch.opts.TTL
+	if err != nil {
+		if !errors.Is(err, ErrNotFound) || ch.opts.NegativeTTL <= 0 {
+			return
+		}
+		ttl = ch.opts.NegativeTTL
+	}
+
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	if ch.generations[namespace] != generation {
+		return
+	}
+	entry := &cacheEntry{key: key, value: value, err: err}
+	if ttl > 0 {
+		entry.expires = ch.now().Add(ttl)
+	}
+	if elem, ok := ch.entries[key]; ok {
+		elem.Value = entry
+		ch.lru.MoveToFront(elem)
+		return
+	}
+	ch.entries[key] = ch.lru.PushFront(entry)
+	for ch.lru.Len() > ch.opts.Size {
+		ch.remove(ch.lru.Back())
+		ch.stats.Evictions++
+	}
+}
+
+// invalidate drops the entries of the given keys, and every list, count and
+// search of the namespace.
+func (ch *CachingHandler) invalidate(namespace string, keys ...string) {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	ch.generations[namespace]++
+	for _, key := range keys {
+		if elem, ok := ch.entries[key]; ok {
+			ch.remove(elem)
+		}
+	}
+}
+
+// invalidateAll drops every entry of the namespace.
+func (ch *CachingHandler) invalidateAll(namespace string) {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	ch.generations[namespace]++
+	for key, elem := range ch.entries {
+		if strings.HasPrefix(key, namespace+":") {
+			ch.remove(elem)
+		}
+	}
+}
+
+func (ch *CachingHandler) remove(elem *list.Element) {
+	ch.lru.Remove(elem)
+	delete(ch.entries, elem.Value.(*cacheEntry).key)
+}
+
+// generationKey is the key of a list, count or search of the namespace, valid
+// until the next write to it.
+func (ch *CachingHandler) generationKey(namespace, kind string, args ...interface{}) string {
+	ch.mu.Lock()
+	generation := ch.generations[namespace]
+	ch.mu.Unlock()
+
+	key := fmt.Sprintf("%s:%s:%d", namespace, kind, generation)
+	for _, arg := range args {
+		key += fmt.Sprintf("|%v", arg)
+	}
+	return key
+}
+
+func userKey(uname string) string { return userNamespace + ":username:" + uname }
+func movieKey(id string) string   { return movieNamespace + ":id:" + id }
+
+func (ch *CachingHandler) AddUser(ctx context.Context, u User) ([]byte, error) {
+	defer ch.invalidate(userNamespace, userKey(u.Username))
+	return ch.handler.AddUser(ctx, u)
+}
+
+func (ch *CachingHandler) FindByUsername(ctx context.Context, uname string) (User, error) {
+	value, err := ch.load(ctx, userNamespace, userKey(uname), func(ctx context.Context) (interface{}, error) {
+		return ch.handler.FindByUsername(ctx, uname)
+	})
+	if err != nil {
+		return User{}, err
+	}
+	return CopyUser(value.(User)), nil
+}
+
+func (ch *CachingHandler) FindAllUsers(ctx context.Context, showDeleted bool, order Order, after Cursor, pgSize int32) ([]User, error) {
+	key := ch.generationKey(userNamespace, "list", showDeleted, order, after, pgSize)
+	value, err := ch.load(ctx, userNamespace, key, func(ctx context.Context) (interface{}, error) {
+		return ch.handler.FindAllUsers(ctx, showDeleted, order, after, pgSize)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	users := value.([]User)
+	results := make([]User, len(users))
+	for i, u := range users {
+		results[i] = CopyUser(u)
+	}
+	return results, nil
+}
+
+func (ch *CachingHandler) UpdateByUsername(ctx context.Context, uname string, etag string, u User) ([]byte, error) {
+	defer ch.invalidate(userNamespace, userKey(uname))
+	return ch.handler.UpdateByUsername(ctx, uname, etag, u)
+}
+
+func (ch *CachingHandler) SetUserDeleteTime(ctx context.Context, uname string, etag string, deleteTime *timestamp.Timestamp) error {
+	defer ch.invalidate(userNamespace, userKey(uname))
+	return ch.handler.SetUserDeleteTime(ctx, uname, etag, deleteTime)
+}
+
+func (ch *CachingHandler) SetUserEmailVerifyTime(ctx context.Context, uname string, etag string, verifyTime *timestamp.Timestamp) error {
+	defer ch.invalidate(userNamespace, userKey(uname))
+	return ch.handler.SetUserEmailVerifyTime(ctx, uname, etag, verifyTime)
+}
+
+func (ch *CachingHandler) PurgeUsers(ctx context.Context, deletedBefore time.Time) (int, error) {
+	defer ch.invalidateAll(userNamespace)
+	return ch.handler.PurgeUsers(ctx, deletedBefore)
+}
+
+func (ch *CachingHandler) RemoveByUsername(ctx context.Context, uname string) error {
+	defer ch.invalidate(userNamespace, userKey(uname))
+	return ch.handler.RemoveByUsername(ctx, uname)
+}
+
+func (ch *CachingHandler) CountUsers(ctx context.Context) (int, error) {
+	value, err := ch.load(ctx, userNamespace, ch.generationKey(userNamespace, "count"), func(ctx context.Context) (interface{}, error) {
+		return ch.handler.CountUsers(ctx)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return value.(int), nil
+}
+
+// Authenticate always asks the database, so that a changed password takes
+// effect at once.
+func (ch *CachingHandler) Authenticate(ctx context.Context, uname string, password string) (bool, error) {
+	return ch.handler.Authenticate(ctx, uname, password)
+}
+
+func (ch *CachingHandler) AddMovie(ctx context.Context, mv Movie) ([]byte, error) {
+	defer ch.invalidate(movieNamespace, movieKey(mv.Id))
+	return ch.handler.AddMovie(ctx, mv)
+}
+
+func (ch *CachingHandler) FindMovieByID(ctx context.Context, id string) (Movie, error) {
+	value, err := ch.load(ctx, movieNamespace, movieKey(id), func(ctx context.Context) (interface{}, error) {
+		return ch.handler.FindMovieByID(ctx, id)
+	})
+	if err != nil {
+		return Movie{}, err
+	}
+	return CopyMovie(value.(Movie)), nil
+}
+
+// FindMovieByName is not cached, since the writes only invalidate the movies
+// by their ID.
+func (ch *CachingHandler) FindMovieByName(ctx context.Context, name string) (Movie, error) {
+	return ch.handler.FindMovieByName(ctx, name)
+}
+
+func (ch *CachingHandler) FindAllMovies(ctx context.Context, expr filter.Expr, showDeleted bool, order Order, after Cursor, pgSize int32) ([]Movie, error) {
+	key := ch.generationKey(movieNamespace, "list", expr, showDeleted, order, after, pgSize)
+	value, err := ch.load(ctx, movieNamespace, key, func(ctx context.Context) (interface{}, error) {
+		return ch.handler.FindAllMovies(ctx, expr, showDeleted, order, after, pgSize)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	movies := value.([]Movie)
+	results := make([]Movie, len(movies))
+	for i, mv := range movies {
+		results[i] = CopyMovie(mv)
+	}
+	return results, nil
+}
+
+func (ch *CachingHandler) UpdateMovieByID(ctx context.Context, id string, etag string, mv Movie) ([]byte, error) {
+	defer ch.invalidate(movieNamespace, movieKey(id))
+	return ch.handler.UpdateMovieByID(ctx, id, etag, mv)
+}
+
+func (ch *CachingHandler) PatchMovieByID(ctx context.Context, id string, etag string, mv Movie, fields []string) ([]byte, error) {
+	defer ch.invalidate(movieNamespace, movieKey(id))
+	return ch.handler.PatchMovieByID(ctx, id, etag, mv, fields)
+}
+
+func (ch *CachingHandler) SetMovieDeleteTime(ctx context.Context, id string, etag string, deleteTime *timestamp.Timestamp) error {
+	defer ch.invalidate(movieNamespace, movieKey(id))
+	return ch.handler.SetMovieDeleteTime(ctx, id, etag, deleteTime)
+}
+
+func (ch *CachingHandler) PurgeMovies(ctx context.Context, deletedBefore time.Time) (int, error) {
+	defer ch.invalidateAll(movieNamespace)
+	return ch.handler.PurgeMovies(ctx, deletedBefore)
+}
+
+func (ch *CachingHandler) RemoveMovieByID(ctx context.Context, id string) error {
+	defer ch.invalidate(movieNamespace, movieKey(id))
+	return ch.handler.RemoveMovieByID(ctx, id)
+}
+
+func (ch *CachingHandler) CountMovieRecords(ctx context.Context) (int, error) {
+	value, err := ch.load(ctx, movieNamespace, ch.generationKey(movieNamespace, "count"), func(ctx context.Context) (interface{}, error) {
+		return ch.handler.CountMovieRecords(ctx)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return value.(int), nil
+}
+
+func (ch *CachingHandler) SearchMovies(ctx context.Context, query string, offset, limit int32) ([]SearchHit, error) {
+	key := ch.generationKey(movieNamespace, "search", query, offset, limit)
+	value, err := ch.load(ctx, movieNamespace, key, func(ctx context.Context) (interface{}, error) {
+		return ch.handler.SearchMovies(ctx, query, offset, limit)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	hits := value.([]SearchHit)
+	results := make([]SearchHit, len(hits))
+	for i, hit := range hits {
+		results[i] = SearchHit{Movie: CopyMovie(hit.Movie), Score: hit.Score}
+	}
+	return results, nil
+}
+
+func (ch *CachingHandler) WatchMovies(ctx context.Context, resumeToken string) (MovieStream, error) {
+	return ch.handler.WatchMovies(ctx, resumeToken)
+}
@@ -0,0 +1,229 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AkashGit21/ms-project/lib/filter"
+)
+
+// countingHandler holds movies in a map and counts the reads which reach it.
+// The methods of DatabaseHandler it lacks are not called by the tests.
+type countingHandler struct {
+	DatabaseHandler
+
+	mu     sync.Mutex
+	movies map[string]Movie
+	reads  int
+	// release, if not nil, holds the reads until it is closed or their
+	// context is done
+	release chan struct{}
+}
+
+func (h *countingHandler) read(ctx context.Context) error {
+	if h.release != nil {
+		select {
+		case <-h.release:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	h.mu.Lock()
+	h.reads++
+	h.mu.Unlock()
+	return nil
+}
+
+func (h *countingHandler) FindMovieByID(ctx context.Context, id string) (Movie, error) {
+	if err := h.read(ctx); err != nil {
+		return Movie{}, err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	mv, ok := h.movies[id]
+	if !ok {
+		return Movie{}, NotFound("movie", "id", id)
+	}
+	return mv, nil
+}
+
+func (h *countingHandler) FindAllMovies(ctx context.Context, expr filter.Expr, showDeleted bool, order Order, after Cursor, pgSize int32) ([]Movie, error) {
+	if err := h.read(ctx); err != nil {
+		return nil, err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var results []Movie
+	for _, mv := range h.movies {
+		results = append(results, mv)
+	}
+	return results, nil
+}
+
+func (h *countingHandler) AddMovie(ctx context.Context, mv Movie) ([]byte, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.movies[mv.Id] = mv
+	return []byte(mv.Id), nil
+}
+
+func (h *countingHandler) UpdateMovieByID(ctx context.Context, id string, etag string, mv Movie) ([]byte, error) {
+	return h.AddMovie(ctx, mv)
+}
+
+func newTestCache(opts CacheOptions) (*CachingHandler, *countingHandler) {
+	handler := &countingHandler{movies: map[string]Movie{"id_0": {Id: "id_0", Name: "test_movie", Cast: []string{"test_actor"}}}}
+	return NewCachingHandler(handler, opts), handler
+}
+
+func TestCachingHandler(t *testing.T) {
+	ctx := context.Background()
+	cache, handler := newTestCache(CacheOptions{Size: 10, TTL: time.Minute, NegativeTTL: time.Minute})
+
+	for i := 0; i < 2; i++ {
+		mv, err := cache.FindMovieByID(ctx, "id_0")
+		if err != nil || mv.Name != "test_movie" {
+			t.Fatalf("FindMovieByID: want test_movie, got %+v, %v", mv, err)
+		}
+		// Changing the result must not change the cached movie
+		mv.Cast[0] = "changed"
+	}
+	if mv, _ := cache.FindMovieByID(ctx, "id_0"); mv.Cast[0] != "test_actor" {
+		t.Errorf("FindMovieByID: want the cached movie unchanged, got %v", mv.Cast)
+	}
+	if handler.reads != 1 {
+		t.Errorf("FindMovieByID: want 1 read of the database, got %d", handler.reads)
+	}
+
+	// Missing movies are cached too, until added
+	for i := 0; i < 2; i++ {
+		if _, err := cache.FindMovieByID(ctx, "id_1"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("FindMovieByID: want %v, got %v", ErrNotFound, err)
+		}
+	}
+	cache.AddMovie(ctx, Movie{Id: "id_1", Name: "test_movie_1"})
+	if mv, err := cache.FindMovieByID(ctx, "id_1"); err != nil || mv.Name != "test_movie_1" {
+		t.Errorf("FindMovieByID: want the added movie, got %+v, %v", mv, err)
+	}
+	if handler.reads != 3 {
+		t.Errorf("FindMovieByID: want 3 reads of the database, got %d", handler.reads)
+	}
+
+	// A write invalidates the lists
+	if got, _ := cache.FindAllMovies(ctx, nil, false, nil, Cursor{}, 0); len(got) != 2 {
+		t.Errorf("FindAllMovies: want 2 movies, got %d", len(got))
+	}
+	cache.FindAllMovies(ctx, nil, false, nil, Cursor{}, 0)
+	cache.UpdateMovieByID(ctx, "id_1", "", Movie{Id: "id_1", Name: "test_movie_2"})
+	if got, _ := cache.FindAllMovies(ctx, nil, false, nil, Cursor{}, 0); len(got) != 2 || handler.reads != 5 {
+		t.Errorf("FindAllMovies: want the list read again after a write, got %d reads", handler.reads)
+	}
+	if mv, _ := cache.FindMovieByID(ctx, "id_1"); mv.Name != "test_movie_2" {
+		t.Errorf("FindMovieByID: want the updated movie, got %+v", mv)
+	}
+
+	want := CacheStats{Hits: 4, Misses: 6, Entries: 4}
+	if got := cache.Stats(); got != want {
+		t.Errorf("Stats: want %+v, got %+v", want, got)
+	}
+}
+
+func TestCachingHandler_expiry(t *testing.T) {
+	ctx := context.Background()
+	cache, handler := newTestCache(CacheOptions{Size: 1, TTL: time.Minute})
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	cache.FindMovieByID(ctx, "id_0")
+	now = now.Add(2 * time.Minute)
+	cache.FindMovieByID(ctx, "id_0")
+	if handler.reads != 2 {
+		t.Errorf("FindMovieByID: want an expired movie read again, got %d reads", handler.reads)
+	}
+
+	// Without a negative TTL missing movies are not cached
+	cache.FindMovieByID(ctx, "id_1")
+	cache.FindMovieByID(ctx, "id_1")
+	if handler.reads != 4 {
+		t.Errorf("FindMovieByID: want missing movies read again, got %d reads", handler.reads)
+	}
+
+	// The size of 1 evicts the movie for the list, then the list for the movie
+	cache.FindAllMovies(ctx, nil, false, nil, Cursor{}, 0)
+	cache.FindMovieByID(ctx, "id_0")
+	if stats := cache.Stats(); stats.Evictions != 2 || stats.Entries != 1 {
+		t.Errorf("Stats: want 2 evictions and 1 entry, got %+v", stats)
+	}
+}
+
+func TestCachingHandler_singleflight(t *testing.T) {
+	ctx := context.Background()
+	cache, handler := newTestCache(CacheOptions{Size: 10, TTL: time.Minute})
+	handler.release = make(chan struct{})
+
+	const callers = 5
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := cache.FindMovieByID(ctx, "id_0"); err != nil {
+				t.Errorf("FindMovieByID: unexpected err %v", err)
+			}
+		}()
+	}
+	for cache.Stats().Misses < callers {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(handler.release)
+	wg.Wait()
+
+	if handler.reads != 1 {
+		t.Errorf("FindMovieByID: want 1 read shared by the callers, got %d", handler.reads)
+	}
+	if stats := cache.Stats(); stats.Shared != callers-1 {
+		t.Errorf("Stats: want %d shared, got %+v", callers-1, stats)
+	}
+}
+
+func TestCachingHandler_singleflightCanceled(t *testing.T) {
+	cache, handler := newTestCache(CacheOptions{Size: 10, TTL: time.Minute})
+	handler.release = make(chan struct{})
+
+	// The first caller starts the fetch, and gives up while the second waits
+	// for it
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := cache.FindMovieByID(ctx, "id_0")
+		first <- err
+	}()
+	for cache.Stats().Misses < 1 {
+		time.Sleep(time.Millisecond)
+	}
+	second := make(chan error, 1)
+	go func() {
+		_, err := cache.FindMovieByID(context.Background(), "id_0")
+		second <- err
+	}()
+	for cache.Stats().Misses < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Errorf("FindMovieByID: want %v for the canceled caller, got %v", context.Canceled, err)
+	}
+	close(handler.release)
+	if err := <-second; err != nil {
+		t.Errorf("FindMovieByID: unexpected err %v for the caller still waiting", err)
+	}
+	if _, err := cache.FindMovieByID(context.Background(), "id_0"); err != nil || handler.reads != 1 {
+		t.Errorf("FindMovieByID: want the movie fetched again cached, got %d reads, %v", handler.reads, err)
+	}
+}
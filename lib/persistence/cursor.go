@@ -0,0 +1,79 @@
+package persistence
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Cursor is the position of a record in a list sorted by an Order. Records
+// which are equal in every sort field are sorted by their unique key, i.e.
+// the ID of a movie or the username of a user. Since the key never changes, a
+// page which starts after a cursor neither skips nor repeats records when
+// others are added or removed meanwhile.
+type Cursor struct {
+	// Values holds the sort fields of the record, in the order of the list.
+	// They are strings, or a time.Time which is zero if the record has none.
+	Values []interface{}
+	// Key is the ID of a movie or the username of a user
+	Key string
+}
+
+// IsZero reports whether c is the position before the first record.
+func (c Cursor) IsZero() bool {
+	return len(c.Values) == 0 && c.Key == ""
+}
+
+// CheckCursor returns an error unless c is a position in a list sorted by o,
+// i.e. zero or holding a value of the type of every sort field. The cursors of
+// page tokens are checked, since they come from the clients.
+func (o Order) CheckCursor(c Cursor) error {
+	if c.IsZero() {
+		return nil
+	}
+	if len(c.Values) != len(o) {
+		return fmt.Errorf("cursor has %d values for %d sort fields", len(c.Values), len(o))
+	}
+	for i, sf := range o {
+		_, isTime := c.Values[i].(time.Time)
+		if _, isString := c.Values[i].(string); isTime != timeSortFields[sf.Field] || isTime == isString {
+			return fmt.Errorf("cursor has a %T value for sort field `%s`", c.Values[i], sf.Field)
+		}
+	}
+	return nil
+}
+
+// Less reports whether the record at a comes before the record at b in a list
+// sorted by o. Missing timestamps come before every other time.
+func (o Order) Less(a, b Cursor) bool {
+	for i, sf := range o {
+		if i >= len(a.Values) || i >= len(b.Values) {
+			break
+		}
+		c := compare(a.Values[i], b.Values[i])
+		if sf.Desc {
+			c = -c
+		}
+		if c != 0 {
+			return c < 0
+		}
+	}
+	return a.Key < b.Key
+}
+
+func compare(a, b interface{}) int {
+	switch a := a.(type) {
+	case string:
+		b, _ := b.(string)
+		return strings.Compare(a, b)
+	case time.Time:
+		b, _ := b.(time.Time)
+		switch {
+		case a.Before(b):
+			return -1
+		case a.After(b):
+			return 1
+		}
+	}
+	return 0
+}
@@ -1,8 +1,13 @@
 package dblayer
 
 import (
+	"fmt"
+
 	"github.com/AkashGit21/ms-project/lib/persistence"
+	"github.com/AkashGit21/ms-project/lib/persistence/memlayer"
 	"github.com/AkashGit21/ms-project/lib/persistence/mongolayer"
+	"github.com/AkashGit21/ms-project/lib/persistence/postgres"
+	"github.com/AkashGit21/ms-project/lib/persistence/sqlite"
 )
 
 type DBTYPE string
@@ -11,13 +16,31 @@ const (
 	MONGODB    DBTYPE = "mongodb"
 	DOCUMENTDB DBTYPE = "documentdb"
 	DYNAMODB   DBTYPE = "dynamodb"
+	MEMORY     DBTYPE = "memory"
+	POSTGRES   DBTYPE = "postgres"
+	SQLITE     DBTYPE = "sqlite"
 )
 
+// MongoDBConnectionDefault is the connection string of MongoDB used when none
+// is given. The other backends have their own default, e.g. sqlite.DefaultFile.
+const MongoDBConnectionDefault = "mongodb://127.0.0.1"
+
+// NewPersistenceLayer connects to the database of the given type. An empty
+// connection string picks the default one of the backend.
 func NewPersistenceLayer(options DBTYPE, connection string) (persistence.DatabaseHandler, error) {
 
 	switch options {
 	case MONGODB:
+		if connection == "" {
+			connection = MongoDBConnectionDefault
+		}
 		return mongolayer.NewMongoDBLayer(connection)
+	case MEMORY:
+		return memlayer.NewMemoryLayer()
+	case POSTGRES:
+		return postgres.NewPostgresLayer(connection)
+	case SQLITE:
+		return sqlite.NewSQLiteLayer(connection)
 	}
-	return nil, nil
+	return nil, fmt.Errorf("unsupported database type %q", options)
 }
@@ -0,0 +1,113 @@
+package persistence
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Every DatabaseHandler reports failures with these errors, usually wrapped in
+// an *Error. Callers test for them with errors.Is.
+var (
+	// ErrNotFound means that no record matches the given key
+	ErrNotFound = errors.New("not found")
+	// ErrAlreadyExists means that a unique key of the record is already taken
+	ErrAlreadyExists = errors.New("already exists")
+	// ErrConflict means that a concurrent operation interfered, the operation may be retried
+	ErrConflict = errors.New("conflict")
+	// ErrEtagMismatch means that the record changed since the caller read it
+	ErrEtagMismatch = errors.New("does not match the etag")
+	// ErrInvalidResumeToken means that a watch cannot resume from the given token
+	ErrInvalidResumeToken = errors.New("invalid resume token")
+	// ErrUnavailable means that the database could not be reached
+	ErrUnavailable = errors.New("database unavailable")
+)
+
+// Error is a persistence error along with the record it is about.
+type Error struct {
+	// Kind is one of the sentinel errors above
+	Kind error
+	// Resource is the type of the record, i.e. "user" or "movie"
+	Resource string
+	// Field and Value identify the record, e.g. "username" and "harry_potter"
+	Field string
+	Value string
+	// ID is the key of the record already holding Value, for an
+	// ErrAlreadyExists about a field other than the key, if known
+	ID string
+	// Err is the underlying error of the database driver, if any
+	Err error
+}
+
+func (e *Error) Error() string {
+	msg := e.Kind.Error()
+	if e.Field != "" {
+		msg = fmt.Sprintf("%s with %s `%s` %s", e.Resource, e.Field, e.Value, msg)
+		if e.ID != "" {
+			msg += fmt.Sprintf(" as `%s`", e.ID)
+		}
+	} else if e.Resource != "" {
+		msg = fmt.Sprintf("%s: %s", e.Resource, msg)
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+// Is reports whether target is the kind of e.
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
+
+// Unwrap returns the underlying error, so that e.g. context.DeadlineExceeded
+// can still be detected.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// NotFound returns an ErrNotFound for the resource whose field equals value.
+func NotFound(resource, field, value string) error {
+	return &Error{Kind: ErrNotFound, Resource: resource, Field: field, Value: value}
+}
+
+// AlreadyExists returns an ErrAlreadyExists for the resource whose field equals value.
+func AlreadyExists(resource, field, value string) error {
+	return &Error{Kind: ErrAlreadyExists, Resource: resource, Field: field, Value: value}
+}
+
+// AlreadyExistsAs returns an ErrAlreadyExists for the resource whose field
+// equals value, held by the record with the given ID.
+func AlreadyExistsAs(resource, field, value, id string) error {
+	return &Error{Kind: ErrAlreadyExists, Resource: resource, Field: field, Value: value, ID: id}
+}
+
+// EtagMismatch returns an ErrEtagMismatch for the resource whose field equals value.
+func EtagMismatch(resource, field, value string) error {
+	return &Error{Kind: ErrEtagMismatch, Resource: resource, Field: field, Value: value}
+}
+
+// InvalidResumeToken returns an ErrInvalidResumeToken caused by err.
+func InvalidResumeToken(err error) error {
+	return &Error{Kind: ErrInvalidResumeToken, Err: err}
+}
+
+// Conflict returns an ErrConflict caused by err.
+func Conflict(resource string, err error) error {
+	return &Error{Kind: ErrConflict, Resource: resource, Err: err}
+}
+
+// Unavailable returns an ErrUnavailable caused by err.
+func Unavailable(err error) error {
+	return &Error{Kind: ErrUnavailable, Err: err}
+}
+
+// TakenValue returns the value of the field of resource which err reports as
+// already taken, if err is such an ErrAlreadyExists. The backends use it to
+// complete the error with the ID of the record holding the value.
+func TakenValue(err error, resource, field string) (string, bool) {
+	var perr *Error
+	if !errors.As(err, &perr) || perr.Kind != ErrAlreadyExists || perr.Resource != resource || perr.Field != field {
+		return "", false
+	}
+	return perr.Value, true
+}
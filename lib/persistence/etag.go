@@ -0,0 +1,21 @@
+package persistence
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// NewEtag returns a random etag for a record being written. Etags are random
+// rather than counted, so that a record removed and created again never
+// matches the etags of its predecessor.
+func NewEtag() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// EtagMatches reports whether a record stored with the given etag may be
+// written by a caller who read it with etag. An empty etag always matches.
+func EtagMatches(stored, etag string) bool {
+	return etag == "" || etag == stored
+}
@@ -0,0 +1,68 @@
+package persistence
+
+import (
+	"time"
+
+	"github.com/AkashGit21/ms-project/lib/filter"
+	"github.com/golang/protobuf/ptypes/timestamp"
+)
+
+// MovieFilterSchema declares the fields of a Movie which ListMovies filters
+// may restrict. Every DatabaseHandler supports all of them.
+var MovieFilterSchema = filter.Schema{
+	"name":        {Type: filter.String},
+	"summary":     {Type: filter.String},
+	"director":    {Type: filter.String},
+	"active":      {Type: filter.Bool},
+	"cast":        {Type: filter.List},
+	"writers":     {Type: filter.List},
+	"tags":        {Type: filter.List, Enum: tagNames()},
+	"create_time": {Type: filter.Timestamp},
+	"update_time": {Type: filter.Timestamp},
+}
+
+// tagNames returns the names of the tags in the order of their values.
+func tagNames() []string {
+	names := make([]string, len(Tag_name))
+	for value, name := range Tag_name {
+		names[value] = name
+	}
+	return names
+}
+
+// MovieField returns the value of a field of MovieFilterSchema for filter.Match.
+func MovieField(mv Movie, field string) interface{} {
+	switch field {
+	case "name":
+		return mv.Name
+	case "summary":
+		return mv.Summary
+	case "director":
+		return mv.Director
+	case "active":
+		return mv.Active
+	case "cast":
+		return mv.Cast
+	case "writers":
+		return mv.Writers
+	case "tags":
+		var names []string
+		for _, t := range mv.Tags {
+			names = append(names, Tag_name[int32(t)])
+		}
+		return names
+	case "create_time":
+		return asTime(mv.CreateTime)
+	case "update_time":
+		return asTime(mv.UpdateTime)
+	}
+	return nil
+}
+
+// asTime returns the zero time for a missing timestamp.
+func asTime(ts *timestamp.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
@@ -0,0 +1,679 @@
+package memlayer
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/AkashGit21/ms-project/lib/events"
+	"github.com/AkashGit21/ms-project/lib/filter"
+	"github.com/AkashGit21/ms-project/lib/persistence"
+	"github.com/AkashGit21/ms-project/lib/search"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// MemoryLayer is a DatabaseHandler which keeps every record in process memory.
+// It needs no external database, which makes it suitable for unit tests and
+// local demos. Lists are sorted on every call, which suits small data sets only.
+type MemoryLayer struct {
+	mu sync.RWMutex
+
+	users     map[string]*userRecord
+	userOrder []string
+	emails    map[string]string
+
+	movies     map[string]*persistence.Movie
+	movieOrder []string
+	// movieNames maps the persistence.MovieNameKey of the names to the movie IDs
+	movieNames map[string]string
+	movieIndex *search.Index
+	// movieEvents are published under mu, in the order of the writes
+	movieEvents *persistence.Broadcaster
+
+	// outbox holds the domain events not acknowledged yet, see events.Outbox
+	outbox []events.Event
+}
+
+type userRecord struct {
+	id   string
+	user persistence.User
+}
+
+func NewMemoryLayer() (persistence.DatabaseHandler, error) {
+	return &MemoryLayer{
+		users:       map[string]*userRecord{},
+		emails:      map[string]string{},
+		movies:      map[string]*persistence.Movie{},
+		movieNames:  map[string]string{},
+		movieIndex:  search.NewIndex(persistence.MovieSearchWeights),
+		movieEvents: persistence.NewBroadcaster(persistence.DefaultHistory),
+	}, nil
+}
+
+func (memLayer *MemoryLayer) AddUser(ctx context.Context, u persistence.User) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	memLayer.mu.Lock()
+	defer memLayer.mu.Unlock()
+
+	if _, ok := memLayer.users[u.Username]; ok {
+		return nil, persistence.AlreadyExists("user", "username", u.Username)
+	}
+	if _, ok := memLayer.emails[u.Email]; ok && u.Email != "" {
+		return nil, persistence.AlreadyExists("user", "email", u.Email)
+	}
+
+	if u.Etag == "" {
+		u.Etag = persistence.NewEtag()
+	}
+	ev, err := persistence.UserDomainEvent(events.UserCreated, u)
+	if err != nil {
+		return nil, err
+	}
+	rec := &userRecord{
+		id:   uuid.New().String(),
+		user: persistence.CopyUser(u),
+	}
+	memLayer.users[u.Username] = rec
+	memLayer.userOrder = append(memLayer.userOrder, u.Username)
+	if u.Email != "" {
+		memLayer.emails[u.Email] = u.Username
+	}
+	memLayer.outbox = append(memLayer.outbox, ev)
+
+	return json.Marshal(rec.id)
+}
+
+func (memLayer *MemoryLayer) FindByUsername(ctx context.Context, uname string) (persistence.User, error) {
+	if err := ctx.Err(); err != nil {
+		return persistence.User{}, err
+	}
+
+	memLayer.mu.RLock()
+	defer memLayer.mu.RUnlock()
+
+	rec, ok := memLayer.users[uname]
+	if !ok {
+		return persistence.User{}, persistence.NotFound("user", "username", uname)
+	}
+	return persistence.CopyUser(rec.user), nil
+}
+
+func (memLayer *MemoryLayer) FindAllUsers(ctx context.Context, showDeleted bool, order persistence.Order, after persistence.Cursor, pgSize int32) ([]persistence.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	memLayer.mu.RLock()
+	defer memLayer.mu.RUnlock()
+
+	var results []persistence.User
+	for _, uname := range memLayer.userOrder {
+		if memLayer.users[uname].user.DeleteTime != nil && !showDeleted {
+			continue
+		}
+		u := persistence.CopyUser(memLayer.users[uname].user)
+		u.Password = ""
+		if order.Less(after, order.UserCursor(u)) {
+			results = append(results, u)
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return order.Less(order.UserCursor(results[i]), order.UserCursor(results[j]))
+	})
+	if pgSize > 0 && len(results) > int(pgSize) {
+		results = results[:pgSize]
+	}
+	return results, nil
+}
+
+// UpdateByUsername also moves the user to its new email, which must not be
+// taken by another user.
+func (memLayer *MemoryLayer) UpdateByUsername(ctx context.Context, uname string, etag string, u persistence.User) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	memLayer.mu.Lock()
+	defer memLayer.mu.Unlock()
+
+	rec, ok := memLayer.users[uname]
+	if !ok {
+		return nil, persistence.NotFound("user", "username", uname)
+	}
+	if !persistence.EtagMatches(rec.user.Etag, etag) {
+		return nil, persistence.EtagMismatch("user", "username", uname)
+	}
+	if owner, ok := memLayer.emails[u.Email]; ok && u.Email != "" && owner != uname {
+		return nil, persistence.AlreadyExists("user", "email", u.Email)
+	}
+
+	updated := persistence.CopyUser(u)
+	updated.Username = rec.user.Username
+	updated.Active = rec.user.Active
+	updated.CreateTime = rec.user.CreateTime
+	updated.DeleteTime = rec.user.DeleteTime
+	updated.EmailVerifyTime = nil
+	if updated.Email == rec.user.Email {
+		updated.EmailVerifyTime = rec.user.EmailVerifyTime
+	}
+	updated.Etag = persistence.NewEtag()
+	if updated.UpdateTime == nil {
+		updated.UpdateTime = rec.user.UpdateTime
+	}
+	ev, err := persistence.UserDomainEvent(events.UserUpdated, updated)
+	if err != nil {
+		return nil, err
+	}
+
+	delete(memLayer.emails, rec.user.Email)
+	if updated.Email != "" {
+		memLayer.emails[updated.Email] = uname
+	}
+	rec.user = updated
+	memLayer.outbox = append(memLayer.outbox, ev)
+
+	return []byte(uname), nil
+}
+
+func (memLayer *MemoryLayer) SetUserDeleteTime(ctx context.Context, uname string, etag string, deleteTime *timestamp.Timestamp) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	memLayer.mu.Lock()
+	defer memLayer.mu.Unlock()
+
+	rec, ok := memLayer.users[uname]
+	if !ok {
+		return persistence.NotFound("user", "username", uname)
+	}
+	if !persistence.EtagMatches(rec.user.Etag, etag) {
+		return persistence.EtagMismatch("user", "username", uname)
+	}
+
+	updated := persistence.CopyUser(rec.user)
+	updated.DeleteTime = persistence.CopyTimestamp(deleteTime)
+	updated.Etag = persistence.NewEtag()
+	typ := events.UserDeleted
+	if deleteTime == nil {
+		typ = events.UserUndeleted
+	}
+	ev, err := persistence.UserDomainEvent(typ, updated)
+	if err != nil {
+		return err
+	}
+	rec.user = updated
+	memLayer.outbox = append(memLayer.outbox, ev)
+	return nil
+}
+
+func (memLayer *MemoryLayer) SetUserEmailVerifyTime(ctx context.Context, uname string, etag string, verifyTime *timestamp.Timestamp) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	memLayer.mu.Lock()
+	defer memLayer.mu.Unlock()
+
+	rec, ok := memLayer.users[uname]
+	if !ok {
+		return persistence.NotFound("user", "username", uname)
+	}
+	if !persistence.EtagMatches(rec.user.Etag, etag) {
+		return persistence.EtagMismatch("user", "username", uname)
+	}
+
+	updated := persistence.CopyUser(rec.user)
+	updated.EmailVerifyTime = persistence.CopyTimestamp(verifyTime)
+	updated.Etag = persistence.NewEtag()
+	ev, err := persistence.UserDomainEvent(events.UserEmailVerified, updated)
+	if err != nil {
+		return err
+	}
+	rec.user = updated
+	memLayer.outbox = append(memLayer.outbox, ev)
+	return nil
+}
+
+func (memLayer *MemoryLayer) PurgeUsers(ctx context.Context, deletedBefore time.Time) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	memLayer.mu.Lock()
+	defer memLayer.mu.Unlock()
+
+	purged := 0
+	for _, uname := range append([]string(nil), memLayer.userOrder...) {
+		rec := memLayer.users[uname]
+		if !deletedEarlier(rec.user.DeleteTime, deletedBefore) {
+			continue
+		}
+		delete(memLayer.users, uname)
+		delete(memLayer.emails, rec.user.Email)
+		memLayer.userOrder = remove(memLayer.userOrder, uname)
+		purged++
+	}
+	return purged, nil
+}
+
+func (memLayer *MemoryLayer) RemoveByUsername(ctx context.Context, uname string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	memLayer.mu.Lock()
+	defer memLayer.mu.Unlock()
+
+	rec, ok := memLayer.users[uname]
+	if !ok {
+		return persistence.NotFound("user", "username", uname)
+	}
+	ev, err := events.New(events.UserDeleted, uname, nil)
+	if err != nil {
+		return err
+	}
+
+	delete(memLayer.users, uname)
+	delete(memLayer.emails, rec.user.Email)
+	memLayer.userOrder = remove(memLayer.userOrder, uname)
+	memLayer.outbox = append(memLayer.outbox, ev)
+	return nil
+}
+
+func (memLayer *MemoryLayer) CountUsers(ctx context.Context) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	memLayer.mu.RLock()
+	defer memLayer.mu.RUnlock()
+
+	return len(memLayer.userOrder), nil
+}
+
+func (memLayer *MemoryLayer) Authenticate(ctx context.Context, uname string, password string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	memLayer.mu.RLock()
+	rec, ok := memLayer.users[uname]
+	var hash string
+	if ok {
+		hash = rec.user.Password
+	}
+	memLayer.mu.RUnlock()
+
+	if !ok {
+		return false, persistence.NotFound("user", "username", uname)
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil, nil
+}
+
+func (memLayer *MemoryLayer) AddMovie(ctx context.Context, mv persistence.Movie) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	memLayer.mu.Lock()
+	defer memLayer.mu.Unlock()
+
+	if mv.Id == "" {
+		mv.Id = uuid.New().String()
+	}
+	if mv.Etag == "" {
+		mv.Etag = persistence.NewEtag()
+	}
+	if _, ok := memLayer.movies[mv.Id]; ok {
+		return nil, persistence.AlreadyExists("movie", "id", mv.Id)
+	}
+	if owner, ok := memLayer.movieNames[persistence.MovieNameKey(mv.Name)]; ok {
+		return nil, persistence.AlreadyExistsAs("movie", "name", mv.Name, owner)
+	}
+	ev, err := persistence.MovieDomainEvent(events.MovieCreated, mv)
+	if err != nil {
+		return nil, err
+	}
+
+	stored := persistence.CopyMovie(mv)
+	memLayer.movies[mv.Id] = &stored
+	memLayer.movieOrder = append(memLayer.movieOrder, mv.Id)
+	memLayer.movieNames[persistence.MovieNameKey(mv.Name)] = mv.Id
+	if mv.DeleteTime == nil {
+		memLayer.movieIndex.Add(mv.Id, persistence.MovieDocument(mv))
+	}
+	memLayer.movieEvents.Publish(persistence.MovieCreated, persistence.CopyMovie(stored))
+	memLayer.outbox = append(memLayer.outbox, ev)
+
+	return json.Marshal(mv.Id)
+}
+
+func (memLayer *MemoryLayer) FindMovieByID(ctx context.Context, id string) (persistence.Movie, error) {
+	if err := ctx.Err(); err != nil {
+		return persistence.Movie{}, err
+	}
+
+	memLayer.mu.RLock()
+	defer memLayer.mu.RUnlock()
+
+	mv, ok := memLayer.movies[id]
+	if !ok {
+		return persistence.Movie{}, persistence.NotFound("movie", "id", id)
+	}
+	return persistence.CopyMovie(*mv), nil
+}
+
+func (memLayer *MemoryLayer) FindMovieByName(ctx context.Context, name string) (persistence.Movie, error) {
+	if err := ctx.Err(); err != nil {
+		return persistence.Movie{}, err
+	}
+
+	memLayer.mu.RLock()
+	defer memLayer.mu.RUnlock()
+
+	id, ok := memLayer.movieNames[persistence.MovieNameKey(name)]
+	if !ok {
+		return persistence.Movie{}, persistence.NotFound("movie", "name", name)
+	}
+	return persistence.CopyMovie(*memLayer.movies[id]), nil
+}
+
+func (memLayer *MemoryLayer) FindAllMovies(ctx context.Context, expr filter.Expr, showDeleted bool, order persistence.Order, after persistence.Cursor, pgSize int32) ([]persistence.Movie, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	memLayer.mu.RLock()
+	defer memLayer.mu.RUnlock()
+
+	var results []persistence.Movie
+	for _, id := range memLayer.movieOrder {
+		mv := memLayer.movies[id]
+		if mv.DeleteTime != nil && !showDeleted {
+			continue
+		}
+		if !filter.Match(expr, func(field string) interface{} { return persistence.MovieField(*mv, field) }) {
+			continue
+		}
+		if order.Less(after, order.MovieCursor(*mv)) {
+			results = append(results, persistence.CopyMovie(*mv))
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return order.Less(order.MovieCursor(results[i]), order.MovieCursor(results[j]))
+	})
+	if pgSize > 0 && len(results) > int(pgSize) {
+		results = results[:pgSize]
+	}
+	return results, nil
+}
+
+// UpdateMovieByID replaces the descriptive fields of the movie with given ID.
+// The ID, status, creation and deletion time of the stored movie are left untouched.
+func (memLayer *MemoryLayer) UpdateMovieByID(ctx context.Context, id string, etag string, mv persistence.Movie) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	memLayer.mu.Lock()
+	defer memLayer.mu.Unlock()
+
+	stored, ok := memLayer.movies[id]
+	if !ok {
+		return nil, persistence.NotFound("movie", "id", id)
+	}
+	if !persistence.EtagMatches(stored.Etag, etag) {
+		return nil, persistence.EtagMismatch("movie", "id", id)
+	}
+	if owner, ok := memLayer.movieNames[persistence.MovieNameKey(mv.Name)]; ok && owner != id {
+		return nil, persistence.AlreadyExistsAs("movie", "name", mv.Name, owner)
+	}
+
+	updated := persistence.CopyMovie(mv)
+	updated.Id = stored.Id
+	updated.Active = stored.Active
+	updated.CreateTime = stored.CreateTime
+	updated.DeleteTime = stored.DeleteTime
+	updated.Etag = persistence.NewEtag()
+	if updated.UpdateTime == nil {
+		updated.UpdateTime = stored.UpdateTime
+	}
+	ev, err := persistence.MovieDomainEvent(events.MovieUpdated, updated)
+	if err != nil {
+		return nil, err
+	}
+
+	delete(memLayer.movieNames, persistence.MovieNameKey(stored.Name))
+	memLayer.movieNames[persistence.MovieNameKey(updated.Name)] = id
+	memLayer.movies[id] = &updated
+	if updated.DeleteTime == nil {
+		memLayer.movieIndex.Add(id, persistence.MovieDocument(updated))
+	}
+	memLayer.movieEvents.Publish(persistence.MovieUpdated, persistence.CopyMovie(updated))
+	memLayer.outbox = append(memLayer.outbox, ev)
+
+	return []byte(id), nil
+}
+
+func (memLayer *MemoryLayer) PatchMovieByID(ctx context.Context, id string, etag string, mv persistence.Movie, fields []string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	memLayer.mu.Lock()
+	defer memLayer.mu.Unlock()
+
+	stored, ok := memLayer.movies[id]
+	if !ok {
+		return nil, persistence.NotFound("movie", "id", id)
+	}
+	if !persistence.EtagMatches(stored.Etag, etag) {
+		return nil, persistence.EtagMismatch("movie", "id", id)
+	}
+	updated, err := persistence.PatchMovie(persistence.CopyMovie(*stored), persistence.CopyMovie(mv), fields)
+	if err != nil {
+		return nil, err
+	}
+	if owner, ok := memLayer.movieNames[persistence.MovieNameKey(updated.Name)]; ok && owner != id {
+		return nil, persistence.AlreadyExistsAs("movie", "name", updated.Name, owner)
+	}
+	updated.Etag = persistence.NewEtag()
+	ev, err := persistence.MovieDomainEvent(events.MovieUpdated, updated)
+	if err != nil {
+		return nil, err
+	}
+
+	delete(memLayer.movieNames, persistence.MovieNameKey(stored.Name))
+	memLayer.movieNames[persistence.MovieNameKey(updated.Name)] = id
+	memLayer.movies[id] = &updated
+	if updated.DeleteTime == nil {
+		memLayer.movieIndex.Add(id, persistence.MovieDocument(updated))
+	}
+	memLayer.movieEvents.Publish(persistence.MovieUpdated, persistence.CopyMovie(updated))
+	memLayer.outbox = append(memLayer.outbox, ev)
+
+	return []byte(id), nil
+}
+
+// SetMovieDeleteTime also takes a deleted movie out of the search index, and
+// puts it back once restored.
+func (memLayer *MemoryLayer) SetMovieDeleteTime(ctx context.Context, id string, etag string, deleteTime *timestamp.Timestamp) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	memLayer.mu.Lock()
+	defer memLayer.mu.Unlock()
+
+	mv, ok := memLayer.movies[id]
+	if !ok {
+		return persistence.NotFound("movie", "id", id)
+	}
+	if !persistence.EtagMatches(mv.Etag, etag) {
+		return persistence.EtagMismatch("movie", "id", id)
+	}
+
+	updated := persistence.CopyMovie(*mv)
+	updated.DeleteTime = persistence.CopyTimestamp(deleteTime)
+	updated.Etag = persistence.NewEtag()
+	typ := events.MovieDeleted
+	if deleteTime == nil {
+		typ = events.MovieUndeleted
+	}
+	ev, err := persistence.MovieDomainEvent(typ, updated)
+	if err != nil {
+		return err
+	}
+	*mv = updated
+	memLayer.outbox = append(memLayer.outbox, ev)
+
+	if deleteTime == nil {
+		memLayer.movieIndex.Add(id, persistence.MovieDocument(*mv))
+		memLayer.movieEvents.Publish(persistence.MovieUndeleted, persistence.CopyMovie(*mv))
+	} else {
+		memLayer.movieIndex.Remove(id)
+		memLayer.movieEvents.Publish(persistence.MovieDeleted, persistence.CopyMovie(*mv))
+	}
+	return nil
+}
+
+func (memLayer *MemoryLayer) PurgeMovies(ctx context.Context, deletedBefore time.Time) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	memLayer.mu.Lock()
+	defer memLayer.mu.Unlock()
+
+	purged := 0
+	for _, id := range append([]string(nil), memLayer.movieOrder...) {
+		mv := memLayer.movies[id]
+		if !deletedEarlier(mv.DeleteTime, deletedBefore) {
+			continue
+		}
+		delete(memLayer.movies, id)
+		delete(memLayer.movieNames, persistence.MovieNameKey(mv.Name))
+		memLayer.movieOrder = remove(memLayer.movieOrder, id)
+		memLayer.movieIndex.Remove(id)
+		purged++
+	}
+	return purged, nil
+}
+
+func (memLayer *MemoryLayer) RemoveMovieByID(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	memLayer.mu.Lock()
+	defer memLayer.mu.Unlock()
+
+	mv, ok := memLayer.movies[id]
+	if !ok {
+		return persistence.NotFound("movie", "id", id)
+	}
+	ev, err := events.New(events.MovieDeleted, id, nil)
+	if err != nil {
+		return err
+	}
+
+	delete(memLayer.movies, id)
+	delete(memLayer.movieNames, persistence.MovieNameKey(mv.Name))
+	memLayer.movieOrder = remove(memLayer.movieOrder, id)
+	memLayer.movieIndex.Remove(id)
+	memLayer.movieEvents.Publish(persistence.MovieDeleted, persistence.Movie{Id: id})
+	memLayer.outbox = append(memLayer.outbox, ev)
+	return nil
+}
+
+func (memLayer *MemoryLayer) CountMovieRecords(ctx context.Context) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	memLayer.mu.RLock()
+	defer memLayer.mu.RUnlock()
+
+	return len(memLayer.movieOrder), nil
+}
+
+func (memLayer *MemoryLayer) SearchMovies(ctx context.Context, query string, offset, limit int32) ([]persistence.SearchHit, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	memLayer.mu.RLock()
+	defer memLayer.mu.RUnlock()
+
+	var results []persistence.SearchHit
+	for _, hit := range search.Page(memLayer.movieIndex.Search(query), int(offset), int(limit)) {
+		results = append(results, persistence.SearchHit{Movie: persistence.CopyMovie(*memLayer.movies[hit.ID]), Score: hit.Score})
+	}
+	return results, nil
+}
+
+func (memLayer *MemoryLayer) WatchMovies(ctx context.Context, resumeToken string) (persistence.MovieStream, error) {
+	return memLayer.movieEvents.WatchMovies(ctx, resumeToken)
+}
+
+func (memLayer *MemoryLayer) PendingEvents(ctx context.Context, limit int) ([]events.Event, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	memLayer.mu.RLock()
+	defer memLayer.mu.RUnlock()
+
+	pending := memLayer.outbox
+	if limit > 0 && len(pending) > limit {
+		pending = pending[:limit]
+	}
+	return append([]events.Event(nil), pending...), nil
+}
+
+func (memLayer *MemoryLayer) AckEvents(ctx context.Context, ids []string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	memLayer.mu.Lock()
+	defer memLayer.mu.Unlock()
+
+	acked := map[string]bool{}
+	for _, id := range ids {
+		acked[id] = true
+	}
+	var pending []events.Event
+	for _, ev := range memLayer.outbox {
+		if !acked[ev.ID] {
+			pending = append(pending, ev)
+		}
+	}
+	memLayer.outbox = pending
+	return nil
+}
+
+// deletedEarlier reports whether a record with the given delete time was
+// deleted before t.
+func deletedEarlier(deleteTime *timestamp.Timestamp, t time.Time) bool {
+	return deleteTime != nil && deleteTime.AsTime().Before(t)
+}
+
+func remove(keys []string, key string) []string {
+	for i, k := range keys {
+		if k == key {
+			return append(keys[:i], keys[i+1:]...)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,781 @@
+package memlayer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AkashGit21/ms-project/lib/events"
+	"github.com/AkashGit21/ms-project/lib/filter"
+	"github.com/AkashGit21/ms-project/lib/persistence"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func newTestLayer(t *testing.T) *MemoryLayer {
+	dbhandler, err := NewMemoryLayer()
+	if err != nil {
+		t.Fatalf("NewMemoryLayer: unexpected err %v", err)
+	}
+	return dbhandler.(*MemoryLayer)
+}
+
+func TestUsers(t *testing.T) {
+	ctx := context.Background()
+	memLayer := newTestLayer(t)
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("test_pwd"), bcrypt.MinCost)
+	user := persistence.User{
+		Username: "test_username",
+		Email:    "test_email@domain.com",
+		Password: string(hash),
+		Active:   true,
+	}
+	if _, err := memLayer.AddUser(ctx, user); err != nil {
+		t.Fatalf("AddUser: unexpected err %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		user    persistence.User
+		wantErr bool
+	}{
+		{"duplicate_username", persistence.User{Username: "test_username", Email: "other@domain.com"}, true},
+		{"duplicate_email", persistence.User{Username: "test_username2", Email: "test_email@domain.com"}, true},
+		{"added_user", persistence.User{Username: "test_username2", Email: "other@domain.com"}, false},
+	}
+	for _, tcase := range tests {
+		t.Run(tcase.name, func(t *testing.T) {
+			_, err := memLayer.AddUser(ctx, tcase.user)
+			if (err != nil) != tcase.wantErr {
+				t.Errorf("AddUser: want err %v, got %v", tcase.wantErr, err)
+			}
+		})
+	}
+
+	if got, _ := memLayer.CountUsers(ctx); got != 2 {
+		t.Errorf("CountUsers: want 2, got %d", got)
+	}
+	if ok, err := memLayer.Authenticate(ctx, "test_username", "test_pwd"); !ok || err != nil {
+		t.Errorf("Authenticate: want true for correct password, got %v, %v", ok, err)
+	}
+	if ok, _ := memLayer.Authenticate(ctx, "test_username", "bad_pwd"); ok {
+		t.Error("Authenticate: want false for wrong password")
+	}
+
+	users, err := memLayer.FindAllUsers(ctx, false, persistence.DefaultOrder, persistence.Cursor{Values: []interface{}{time.Time{}}, Key: "test_username"}, 12)
+	if err != nil || len(users) != 1 || users[0].Username != "test_username2" {
+		t.Errorf("FindAllUsers: unexpected result %v, %v", users, err)
+	}
+	if users[0].Password != "" {
+		t.Error("FindAllUsers: password hash should not be returned")
+	}
+
+	if err := memLayer.RemoveByUsername(ctx, "test_username"); err != nil {
+		t.Fatalf("RemoveByUsername: unexpected err %v", err)
+	}
+	if _, err := memLayer.FindByUsername(ctx, "test_username"); !errors.Is(err, persistence.ErrNotFound) {
+		t.Errorf("FindByUsername: want ErrNotFound for removed user, got %v", err)
+	}
+	if _, err := memLayer.AddUser(ctx, user); err != nil {
+		t.Errorf("AddUser: email of removed user should be reusable, got %v", err)
+	}
+}
+
+func TestUpdateByUsername(t *testing.T) {
+	ctx := context.Background()
+	memLayer := newTestLayer(t)
+
+	lastName := "test_last"
+	user := persistence.User{Username: "test_username", Email: "test_email@domain.com", Password: "test_hash",
+		Role: persistence.Role_NORMAL, Active: true, LastName: &lastName, CreateTime: &timestamp.Timestamp{Seconds: 1}}
+	if _, err := memLayer.AddUser(ctx, user); err != nil {
+		t.Fatalf("AddUser: unexpected err %v", err)
+	}
+	if _, err := memLayer.AddUser(ctx, persistence.User{Username: "test_username2", Email: "other@domain.com"}); err != nil {
+		t.Fatalf("AddUser: unexpected err %v", err)
+	}
+	stored, _ := memLayer.FindByUsername(ctx, "test_username")
+
+	nickname := "test_nick"
+	update := persistence.User{Username: "ignored", Email: "new_email@domain.com", Password: "new_hash",
+		Role: persistence.Role_ADMIN, FirstName: "test_first", Nickname: &nickname, UpdateTime: &timestamp.Timestamp{Seconds: 2}}
+	if _, err := memLayer.UpdateByUsername(ctx, "test_username", stored.Etag, update); err != nil {
+		t.Fatalf("UpdateByUsername: unexpected err %v", err)
+	}
+	found, err := memLayer.FindByUsername(ctx, "test_username")
+	if err != nil || found.Email != update.Email || found.Password != update.Password || found.Role != update.Role ||
+		found.FirstName != update.FirstName || found.Nickname == nil || *found.Nickname != nickname || found.LastName != nil {
+		t.Errorf("FindByUsername: want the fields of the update, got %+v, %v", found, err)
+	}
+	// The identity, status and creation time are kept
+	if !found.Active || found.CreateTime.GetSeconds() != 1 || found.UpdateTime.GetSeconds() != 2 || found.Etag == stored.Etag {
+		t.Errorf("FindByUsername: want the status and creation time kept with a new etag, got %+v", found)
+	}
+
+	tests := []struct {
+		name    string
+		uname   string
+		etag    string
+		user    persistence.User
+		wantErr error
+	}{
+		{"stale_etag", "test_username", stored.Etag, update, persistence.ErrEtagMismatch},
+		{"missing_user", "missing_user", "", update, persistence.ErrNotFound},
+		{"duplicate_email", "test_username", "", persistence.User{Email: "other@domain.com"}, persistence.ErrAlreadyExists},
+	}
+	for _, tcase := range tests {
+		t.Run(tcase.name, func(t *testing.T) {
+			if _, err := memLayer.UpdateByUsername(ctx, tcase.uname, tcase.etag, tcase.user); !errors.Is(err, tcase.wantErr) {
+				t.Errorf("UpdateByUsername: want %v, got %v", tcase.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestSetUserEmailVerifyTime(t *testing.T) {
+	ctx := context.Background()
+	memLayer := newTestLayer(t)
+
+	if _, err := memLayer.AddUser(ctx, persistence.User{Username: "test_username", Email: "test_email@domain.com"}); err != nil {
+		t.Fatalf("AddUser: unexpected err %v", err)
+	}
+	stored, _ := memLayer.FindByUsername(ctx, "test_username")
+	if stored.EmailVerifyTime != nil {
+		t.Errorf("FindByUsername: want the email not verified, got %v", stored.EmailVerifyTime)
+	}
+
+	if err := memLayer.SetUserEmailVerifyTime(ctx, "test_username", stored.Etag, &timestamp.Timestamp{Seconds: 2}); err != nil {
+		t.Fatalf("SetUserEmailVerifyTime: unexpected err %v", err)
+	}
+	if err := memLayer.SetUserEmailVerifyTime(ctx, "test_username", stored.Etag, nil); !errors.Is(err, persistence.ErrEtagMismatch) {
+		t.Errorf("SetUserEmailVerifyTime: want %v, got %v", persistence.ErrEtagMismatch, err)
+	}
+	if err := memLayer.SetUserEmailVerifyTime(ctx, "missing_user", "", nil); !errors.Is(err, persistence.ErrNotFound) {
+		t.Errorf("SetUserEmailVerifyTime: want %v, got %v", persistence.ErrNotFound, err)
+	}
+	verified, _ := memLayer.FindByUsername(ctx, "test_username")
+	if verified.EmailVerifyTime.GetSeconds() != 2 || verified.Etag == stored.Etag {
+		t.Errorf("FindByUsername: want the email verified with a new etag, got %+v", verified)
+	}
+
+	// The verification is kept along with the email, and cleared with it
+	if _, err := memLayer.UpdateByUsername(ctx, "test_username", "", persistence.User{Email: verified.Email, FirstName: "test_first"}); err != nil {
+		t.Fatalf("UpdateByUsername: unexpected err %v", err)
+	}
+	if found, _ := memLayer.FindByUsername(ctx, "test_username"); found.EmailVerifyTime.GetSeconds() != 2 {
+		t.Errorf("UpdateByUsername: want the verification kept, got %v", found.EmailVerifyTime)
+	}
+	if _, err := memLayer.UpdateByUsername(ctx, "test_username", "", persistence.User{Email: "new_email@domain.com"}); err != nil {
+		t.Fatalf("UpdateByUsername: unexpected err %v", err)
+	}
+	if found, _ := memLayer.FindByUsername(ctx, "test_username"); found.EmailVerifyTime != nil {
+		t.Errorf("UpdateByUsername: want the verification cleared, got %v", found.EmailVerifyTime)
+	}
+}
+
+func TestMovies(t *testing.T) {
+	ctx := context.Background()
+	memLayer := newTestLayer(t)
+
+	for i := 0; i < 5; i++ {
+		mv := persistence.Movie{
+			Id:   fmt.Sprintf("id_%d", i),
+			Name: fmt.Sprintf("test_movie_%d", i),
+			Cast: []string{"test_cast"},
+		}
+		if _, err := memLayer.AddMovie(ctx, mv); err != nil {
+			t.Fatalf("AddMovie: unexpected err %v", err)
+		}
+	}
+	if _, err := memLayer.AddMovie(ctx, persistence.Movie{Name: "test_movie_0"}); err == nil {
+		t.Error("AddMovie: want error for duplicate name")
+	}
+
+	movies, err := memLayer.FindAllMovies(ctx, nil, false, persistence.DefaultOrder, persistence.Cursor{Values: []interface{}{time.Time{}}, Key: "id_2"}, 12)
+	if err != nil || len(movies) != 2 || movies[0].Id != "id_3" {
+		t.Errorf("FindAllMovies: unexpected result %v, %v", movies, err)
+	}
+
+	// Modifying a returned movie must not modify the stored one
+	found, _ := memLayer.FindMovieByID(ctx, "id_1")
+	found.Cast[0] = "changed"
+	if found, _ = memLayer.FindMovieByID(ctx, "id_1"); found.Cast[0] != "test_cast" {
+		t.Errorf("FindMovieByID: stored movie was modified through returned copy")
+	}
+
+	if _, err := memLayer.UpdateMovieByID(ctx, "id_1", "", persistence.Movie{Name: "test_movie_2"}); err == nil {
+		t.Error("UpdateMovieByID: want error for duplicate name")
+	}
+	if _, err := memLayer.UpdateMovieByID(ctx, "id_1", "", persistence.Movie{Name: "test_movie_renamed"}); err != nil {
+		t.Errorf("UpdateMovieByID: unexpected err %v", err)
+	}
+	if found, _ = memLayer.FindMovieByID(ctx, "id_1"); found.Name != "test_movie_renamed" || found.Id != "id_1" {
+		t.Errorf("UpdateMovieByID: unexpected stored movie %v", found)
+	}
+
+	if err := memLayer.RemoveMovieByID(ctx, "id_1"); err != nil {
+		t.Errorf("RemoveMovieByID: unexpected err %v", err)
+	}
+	if err := memLayer.RemoveMovieByID(ctx, "id_1"); err == nil {
+		t.Error("RemoveMovieByID: want error for missing movie")
+	}
+	if got, _ := memLayer.CountMovieRecords(ctx); got != 4 {
+		t.Errorf("CountMovieRecords: want 4, got %d", got)
+	}
+}
+
+func TestFindAllMovies_cursor(t *testing.T) {
+	ctx := context.Background()
+	memLayer := newTestLayer(t)
+
+	// Inserted out of order, and two movies share a creation time
+	for _, i := range []int{3, 1, 4, 0, 2} {
+		mv := persistence.Movie{
+			Id:         fmt.Sprintf("id_%d", i),
+			Name:       fmt.Sprintf("test_movie_%d", i),
+			CreateTime: &timestamp.Timestamp{Seconds: int64(i / 2)},
+		}
+		if _, err := memLayer.AddMovie(ctx, mv); err != nil {
+			t.Fatalf("AddMovie: unexpected err %v", err)
+		}
+	}
+
+	var got []string
+	after := persistence.Cursor{}
+	for {
+		movies, err := memLayer.FindAllMovies(ctx, nil, false, persistence.DefaultOrder, after, 2)
+		if err != nil {
+			t.Fatalf("FindAllMovies: unexpected err %v", err)
+		}
+		if len(movies) == 0 {
+			break
+		}
+		for _, mv := range movies {
+			got = append(got, mv.Id)
+		}
+		last := movies[len(movies)-1]
+		after = persistence.DefaultOrder.MovieCursor(last)
+
+		// Removing the last movie of the page must not shift the next page
+		if err := memLayer.RemoveMovieByID(ctx, last.Id); err != nil {
+			t.Fatalf("RemoveMovieByID: unexpected err %v", err)
+		}
+	}
+
+	want := []string{"id_0", "id_1", "id_2", "id_3", "id_4"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("FindAllMovies: want %v, got %v", want, got)
+	}
+}
+
+func TestConcurrentAccess(t *testing.T) {
+	ctx := context.Background()
+	memLayer := newTestLayer(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id := fmt.Sprintf("id_%d", i)
+			memLayer.AddMovie(ctx, persistence.Movie{Id: id, Name: id})
+			memLayer.FindAllMovies(ctx, nil, false, persistence.DefaultOrder, persistence.Cursor{}, 12)
+			memLayer.FindMovieByID(ctx, id)
+			memLayer.CountMovieRecords(ctx)
+		}(i)
+	}
+	wg.Wait()
+
+	if got, _ := memLayer.CountMovieRecords(ctx); got != 20 {
+		t.Errorf("CountMovieRecords: want 20, got %d", got)
+	}
+}
+
+func TestPatchMovieByID(t *testing.T) {
+	ctx := context.Background()
+	memLayer := newTestLayer(t)
+
+	mv := persistence.Movie{Id: "id_0", Name: "Inception", Summary: "test_summary", Cast: []string{"test_actor"},
+		Tags: []persistence.Tag{persistence.Tag_Action}, Director: "test_director"}
+	if _, err := memLayer.AddMovie(ctx, mv); err != nil {
+		t.Fatalf("AddMovie: unexpected err %v", err)
+	}
+	if _, err := memLayer.AddMovie(ctx, persistence.Movie{Id: "id_1", Name: "Interstellar"}); err != nil {
+		t.Fatalf("AddMovie: unexpected err %v", err)
+	}
+	stored, _ := memLayer.FindMovieByID(ctx, "id_0")
+
+	// Only the given fields change, even to empty values
+	patch := persistence.Movie{Name: "ignored", Summary: "test_summary2", Tags: []persistence.Tag{persistence.Tag_Comedy},
+		UpdateTime: &timestamp.Timestamp{Seconds: 2}}
+	if _, err := memLayer.PatchMovieByID(ctx, "id_0", stored.Etag, patch, []string{"summary", "tags", "cast"}); err != nil {
+		t.Fatalf("PatchMovieByID: unexpected err %v", err)
+	}
+	found, err := memLayer.FindMovieByID(ctx, "id_0")
+	if err != nil || found.Name != mv.Name || found.Summary != patch.Summary || len(found.Cast) != 0 ||
+		len(found.Tags) != 1 || found.Tags[0] != persistence.Tag_Comedy || found.Director != mv.Director ||
+		found.UpdateTime.GetSeconds() != 2 || found.Etag == stored.Etag {
+		t.Errorf("FindMovieByID: want the given fields patched, got %+v, %v", found, err)
+	}
+
+	tests := []struct {
+		name    string
+		id      string
+		etag    string
+		fields  []string
+		wantErr error
+	}{
+		{"stale_etag", "id_0", stored.Etag, []string{"summary"}, persistence.ErrEtagMismatch},
+		{"missing_movie", "missing", "", []string{"summary"}, persistence.ErrNotFound},
+		{"duplicate_name", "id_0", "", []string{"name"}, persistence.ErrAlreadyExists},
+	}
+	for _, tcase := range tests {
+		t.Run(tcase.name, func(t *testing.T) {
+			patch := persistence.Movie{Name: "Interstellar", Summary: "test_summary3"}
+			if _, err := memLayer.PatchMovieByID(ctx, tcase.id, tcase.etag, patch, tcase.fields); !errors.Is(err, tcase.wantErr) {
+				t.Errorf("PatchMovieByID: want %v, got %v", tcase.wantErr, err)
+			}
+		})
+	}
+	if _, err := memLayer.PatchMovieByID(ctx, "id_0", "", patch, []string{"active"}); err == nil {
+		t.Error("PatchMovieByID: want an error for an unknown field")
+	}
+}
+
+func TestMovieNameUniqueness(t *testing.T) {
+	ctx := context.Background()
+	memLayer := newTestLayer(t)
+
+	for _, mv := range []persistence.Movie{{Id: "id_0", Name: "The Dark Knight"}, {Id: "id_1", Name: "Inception"}} {
+		if _, err := memLayer.AddMovie(ctx, mv); err != nil {
+			t.Fatalf("AddMovie: unexpected err %v", err)
+		}
+	}
+
+	// Names differing by case only are taken, by the movie given in the error
+	wantTaken := func(op string, err error) {
+		var perr *persistence.Error
+		if !errors.Is(err, persistence.ErrAlreadyExists) || !errors.As(err, &perr) || perr.Field != "name" || perr.ID != "id_0" {
+			t.Errorf("%s: want the name taken by id_0, got %v", op, err)
+		}
+	}
+	_, err := memLayer.AddMovie(ctx, persistence.Movie{Id: "id_2", Name: "THE DARK KNIGHT"})
+	wantTaken("AddMovie", err)
+	_, err = memLayer.UpdateMovieByID(ctx, "id_1", "", persistence.Movie{Name: "the dark knight"})
+	wantTaken("UpdateMovieByID", err)
+	_, err = memLayer.PatchMovieByID(ctx, "id_1", "", persistence.Movie{Name: "The Dark knight"}, []string{"name"})
+	wantTaken("PatchMovieByID", err)
+	if mv, err := memLayer.FindMovieByName(ctx, "the DARK knight"); err != nil || mv.Id != "id_0" {
+		t.Errorf("FindMovieByName: want id_0, got %v, %v", mv, err)
+	}
+
+	// A movie may change the case of its own name
+	if _, err := memLayer.PatchMovieByID(ctx, "id_0", "", persistence.Movie{Name: "the dark knight"}, []string{"name"}); err != nil {
+		t.Errorf("PatchMovieByID: unexpected err %v", err)
+	}
+	// Removing the movie frees its name
+	if err := memLayer.RemoveMovieByID(ctx, "id_0"); err != nil {
+		t.Fatalf("RemoveMovieByID: unexpected err %v", err)
+	}
+	if _, err := memLayer.FindMovieByName(ctx, "The Dark Knight"); !errors.Is(err, persistence.ErrNotFound) {
+		t.Errorf("FindMovieByName: want ErrNotFound for a freed name, got %v", err)
+	}
+	if _, err := memLayer.AddMovie(ctx, persistence.Movie{Id: "id_2", Name: "The Dark Knight"}); err != nil {
+		t.Errorf("AddMovie: want the name freed, got %v", err)
+	}
+}
+
+func TestFindAllMovies_filter(t *testing.T) {
+	ctx := context.Background()
+	memLayer := newTestLayer(t)
+
+	movies := []persistence.Movie{
+		{Id: "id_0", Name: "Inception", Director: "Nolan", Cast: []string{"DiCaprio"},
+			Tags: []persistence.Tag{persistence.Tag_Action}, Active: true, CreateTime: &timestamp.Timestamp{Seconds: 1609459200}},
+		{Id: "id_1", Name: "The Prestige", Director: "Nolan", Cast: []string{"Bale", "Jackman"},
+			Tags: []persistence.Tag{persistence.Tag_Fantasy}, Active: true, CreateTime: &timestamp.Timestamp{Seconds: 1625097600}},
+		{Id: "id_2", Name: "Up", Director: "Docter", Cast: []string{"Asner"},
+			Tags: []persistence.Tag{persistence.Tag_Adventure, persistence.Tag_Comedy}, CreateTime: &timestamp.Timestamp{Seconds: 1640995200}},
+		{Id: "id_3", Name: "Draft"},
+	}
+	for _, mv := range movies {
+		if _, err := memLayer.AddMovie(ctx, mv); err != nil {
+			t.Fatalf("AddMovie: unexpected err %v", err)
+		}
+	}
+
+	tests := []struct {
+		filter   string
+		expected string
+	}{
+		{``, "[id_3 id_0 id_1 id_2]"},
+		{`director = "Nolan"`, "[id_0 id_1]"},
+		{`director = "Nolan" AND tags:Action`, "[id_0]"},
+		{`cast:Bale OR tags:Comedy`, "[id_1 id_2]"},
+		{`NOT director = "Nolan"`, "[id_3 id_2]"},
+		{`active = true AND create_time > "2021-01-01"`, "[id_1]"},
+		{`create_time >= "2021-07-01" AND create_time < "2022-01-01T00:00:01Z"`, "[id_1 id_2]"},
+		{`create_time != "2021-07-01"`, "[id_0 id_2]"},
+	}
+	for _, tcase := range tests {
+		t.Run(tcase.filter, func(t *testing.T) {
+			expr, err := filter.Parse(tcase.filter, persistence.MovieFilterSchema)
+			if err != nil {
+				t.Fatalf("Parse: unexpected err %v", err)
+			}
+			found, err := memLayer.FindAllMovies(ctx, expr, false, persistence.DefaultOrder, persistence.Cursor{}, 0)
+			if err != nil {
+				t.Fatalf("FindAllMovies: unexpected err %v", err)
+			}
+			var ids []string
+			for _, mv := range found {
+				ids = append(ids, mv.Id)
+			}
+			if fmt.Sprint(ids) != tcase.expected {
+				t.Errorf("FindAllMovies: want %v, got %v", tcase.expected, ids)
+			}
+		})
+	}
+
+	// The filter applies before the page size
+	expr, _ := filter.Parse(`director = "Nolan"`, persistence.MovieFilterSchema)
+	found, err := memLayer.FindAllMovies(ctx, expr, false, persistence.DefaultOrder, persistence.Cursor{Values: []interface{}{time.Time{}}, Key: "id_3"}, 1)
+	if err != nil || len(found) != 1 || found[0].Id != "id_0" {
+		t.Errorf("FindAllMovies: unexpected page %v, %v", found, err)
+	}
+}
+
+func TestFindAllMovies_order(t *testing.T) {
+	ctx := context.Background()
+	memLayer := newTestLayer(t)
+
+	movies := []persistence.Movie{
+		{Id: "id_0", Name: "Inception", Director: "Nolan", CreateTime: &timestamp.Timestamp{Seconds: 1609459200}},
+		{Id: "id_1", Name: "The Prestige", Director: "Nolan", CreateTime: &timestamp.Timestamp{Seconds: 1625097600}},
+		{Id: "id_2", Name: "Up", Director: "Docter", CreateTime: &timestamp.Timestamp{Seconds: 1640995200}},
+		{Id: "id_3", Name: "Draft"},
+	}
+	for _, mv := range movies {
+		if _, err := memLayer.AddMovie(ctx, mv); err != nil {
+			t.Fatalf("AddMovie: unexpected err %v", err)
+		}
+	}
+
+	tests := []struct {
+		orderBy  string
+		expected string
+	}{
+		{"", "[id_3 id_0 id_1 id_2]"},
+		{"name desc", "[id_2 id_1 id_0 id_3]"},
+		{"director desc, create_time", "[id_0 id_1 id_2 id_3]"},
+		{"create_time desc", "[id_2 id_1 id_0 id_3]"},
+		{"update_time desc, name", "[id_3 id_0 id_1 id_2]"},
+	}
+	for _, tcase := range tests {
+		t.Run(tcase.orderBy, func(t *testing.T) {
+			order, err := persistence.ParseOrder(tcase.orderBy, persistence.MovieSortFields)
+			if err != nil {
+				t.Fatalf("ParseOrder: unexpected err %v", err)
+			}
+
+			// Page by page, so that every cursor is used
+			var ids []string
+			after := persistence.Cursor{}
+			for len(ids) <= len(movies) {
+				found, err := memLayer.FindAllMovies(ctx, nil, false, order, after, 1)
+				if err != nil {
+					t.Fatalf("FindAllMovies: unexpected err %v", err)
+				}
+				if len(found) == 0 {
+					break
+				}
+				ids = append(ids, found[0].Id)
+				after = order.MovieCursor(found[0])
+			}
+			if fmt.Sprint(ids) != tcase.expected {
+				t.Errorf("FindAllMovies: want %v, got %v", tcase.expected, ids)
+			}
+		})
+	}
+}
+
+func TestSearchMovies(t *testing.T) {
+	ctx := context.Background()
+	memLayer := newTestLayer(t)
+
+	movies := []persistence.Movie{
+		{Id: "id_0", Name: "Inception", Summary: "A thief who steals secrets through dream-sharing", Director: "Nolan"},
+		{Id: "id_1", Name: "The Prestige", Summary: "Two rival magicians", Director: "Nolan", Cast: []string{"Christian Bale"}},
+		{Id: "id_2", Name: "Dreamgirls", Summary: "A trio of singers", Writers: []string{"Bill Condon"}},
+		{Id: "id_3", Name: "Dream House", Summary: "A family moves into a house"},
+	}
+	for _, mv := range movies {
+		if _, err := memLayer.AddMovie(ctx, mv); err != nil {
+			t.Fatalf("AddMovie: unexpected err %v", err)
+		}
+	}
+
+	tests := []struct {
+		query    string
+		offset   int32
+		limit    int32
+		expected string
+	}{
+		{"dream", 0, 0, "[id_3 id_0]"},
+		{"NOLAN bale", 0, 0, "[id_1 id_0]"},
+		{"condon", 0, 0, "[id_2]"},
+		{"dream nolan", 1, 2, "[id_0 id_1]"},
+		{"matrix", 0, 0, "[]"},
+		{"the", 0, 0, "[]"},
+	}
+	for _, tcase := range tests {
+		t.Run(tcase.query, func(t *testing.T) {
+			hits, err := memLayer.SearchMovies(ctx, tcase.query, tcase.offset, tcase.limit)
+			if err != nil {
+				t.Fatalf("SearchMovies: unexpected err %v", err)
+			}
+			ids := []string{}
+			for _, hit := range hits {
+				ids = append(ids, hit.Movie.Id)
+			}
+			if fmt.Sprint(ids) != tcase.expected {
+				t.Errorf("SearchMovies: want %v, got %v", tcase.expected, ids)
+			}
+		})
+	}
+
+	// The index follows the updates and removals
+	if _, err := memLayer.UpdateMovieByID(ctx, "id_3", "", persistence.Movie{Name: "House"}); err != nil {
+		t.Fatalf("UpdateMovieByID: unexpected err %v", err)
+	}
+	if err := memLayer.RemoveMovieByID(ctx, "id_0"); err != nil {
+		t.Fatalf("RemoveMovieByID: unexpected err %v", err)
+	}
+	if hits, _ := memLayer.SearchMovies(ctx, "dream", 0, 0); len(hits) != 0 {
+		t.Errorf("SearchMovies: want no hits after update and removal, got %v", hits)
+	}
+}
+
+func TestSoftDelete(t *testing.T) {
+	ctx := context.Background()
+	memLayer := newTestLayer(t)
+
+	for _, mv := range []persistence.Movie{{Id: "id_0", Name: "Inception"}, {Id: "id_1", Name: "Interstellar"}} {
+		if _, err := memLayer.AddMovie(ctx, mv); err != nil {
+			t.Fatalf("AddMovie: unexpected err %v", err)
+		}
+	}
+	if _, err := memLayer.AddUser(ctx, persistence.User{Username: "test_username", Email: "test_email@domain.in"}); err != nil {
+		t.Fatalf("AddUser: unexpected err %v", err)
+	}
+
+	deleted := &timestamp.Timestamp{Seconds: 1609459200}
+	if err := memLayer.SetMovieDeleteTime(ctx, "id_0", "", deleted); err != nil {
+		t.Fatalf("SetMovieDeleteTime: unexpected err %v", err)
+	}
+	if err := memLayer.SetUserDeleteTime(ctx, "test_username", "", deleted); err != nil {
+		t.Fatalf("SetUserDeleteTime: unexpected err %v", err)
+	}
+	if err := memLayer.SetMovieDeleteTime(ctx, "id_9", "", deleted); !errors.Is(err, persistence.ErrNotFound) {
+		t.Errorf("SetMovieDeleteTime: want ErrNotFound for missing movie, got %v", err)
+	}
+
+	tests := []struct {
+		showDeleted bool
+		movies      string
+		users       int
+	}{
+		{false, "[id_1]", 0},
+		{true, "[id_0 id_1]", 1},
+	}
+	for _, tcase := range tests {
+		t.Run(fmt.Sprint("show_deleted_", tcase.showDeleted), func(t *testing.T) {
+			movies, err := memLayer.FindAllMovies(ctx, nil, tcase.showDeleted, persistence.DefaultOrder, persistence.Cursor{}, 0)
+			if err != nil {
+				t.Fatalf("FindAllMovies: unexpected err %v", err)
+			}
+			ids := []string{}
+			for _, mv := range movies {
+				ids = append(ids, mv.Id)
+			}
+			if fmt.Sprint(ids) != tcase.movies {
+				t.Errorf("FindAllMovies: want %v, got %v", tcase.movies, ids)
+			}
+
+			users, err := memLayer.FindAllUsers(ctx, tcase.showDeleted, persistence.DefaultOrder, persistence.Cursor{}, 0)
+			if err != nil || len(users) != tcase.users {
+				t.Errorf("FindAllUsers: want %d users, got %v, %v", tcase.users, users, err)
+			}
+		})
+	}
+
+	if mv, err := memLayer.FindMovieByID(ctx, "id_0"); err != nil || mv.DeleteTime.GetSeconds() != deleted.GetSeconds() {
+		t.Errorf("FindMovieByID: want the delete time, got %v, %v", mv, err)
+	}
+	if hits, _ := memLayer.SearchMovies(ctx, "inception", 0, 0); len(hits) != 0 {
+		t.Errorf("SearchMovies: want no deleted movie, got %v", hits)
+	}
+
+	// A restored movie is found again
+	if err := memLayer.SetMovieDeleteTime(ctx, "id_0", "", nil); err != nil {
+		t.Fatalf("SetMovieDeleteTime: unexpected err %v", err)
+	}
+	if hits, _ := memLayer.SearchMovies(ctx, "inception", 0, 0); len(hits) != 1 {
+		t.Errorf("SearchMovies: want the restored movie, got %v", hits)
+	}
+
+	// Only the records deleted before the given time are purged
+	if err := memLayer.SetMovieDeleteTime(ctx, "id_0", "", deleted); err != nil {
+		t.Fatalf("SetMovieDeleteTime: unexpected err %v", err)
+	}
+	if n, err := memLayer.PurgeMovies(ctx, time.Unix(1609459200, 0)); err != nil || n != 0 {
+		t.Errorf("PurgeMovies: want 0 movies purged, got %d, %v", n, err)
+	}
+	if n, err := memLayer.PurgeMovies(ctx, time.Unix(1609459201, 0)); err != nil || n != 1 {
+		t.Errorf("PurgeMovies: want 1 movie purged, got %d, %v", n, err)
+	}
+	if n, err := memLayer.PurgeUsers(ctx, time.Unix(1609459201, 0)); err != nil || n != 1 {
+		t.Errorf("PurgeUsers: want 1 user purged, got %d, %v", n, err)
+	}
+	if _, err := memLayer.FindMovieByID(ctx, "id_0"); !errors.Is(err, persistence.ErrNotFound) {
+		t.Errorf("FindMovieByID: want ErrNotFound for purged movie, got %v", err)
+	}
+	if _, err := memLayer.FindMovieByID(ctx, "id_1"); err != nil {
+		t.Errorf("FindMovieByID: unexpected err %v", err)
+	}
+}
+
+func TestEtag(t *testing.T) {
+	ctx := context.Background()
+	memLayer := newTestLayer(t)
+
+	if _, err := memLayer.AddMovie(ctx, persistence.Movie{Id: "id_0", Name: "Inception"}); err != nil {
+		t.Fatalf("AddMovie: unexpected err %v", err)
+	}
+	if _, err := memLayer.AddUser(ctx, persistence.User{Username: "test_username"}); err != nil {
+		t.Fatalf("AddUser: unexpected err %v", err)
+	}
+	mv, _ := memLayer.FindMovieByID(ctx, "id_0")
+	u, _ := memLayer.FindByUsername(ctx, "test_username")
+	if mv.Etag == "" || u.Etag == "" {
+		t.Fatalf("want etags on the added records, got %q and %q", mv.Etag, u.Etag)
+	}
+
+	if _, err := memLayer.UpdateMovieByID(ctx, "id_0", mv.Etag, persistence.Movie{Name: "Inception 2"}); err != nil {
+		t.Fatalf("UpdateMovieByID: unexpected err %v", err)
+	}
+	// The etag read before the update is now stale
+	if _, err := memLayer.UpdateMovieByID(ctx, "id_0", mv.Etag, persistence.Movie{Name: "Inception 3"}); !errors.Is(err, persistence.ErrEtagMismatch) {
+		t.Errorf("UpdateMovieByID: want ErrEtagMismatch, got %v", err)
+	}
+	if err := memLayer.SetMovieDeleteTime(ctx, "id_0", mv.Etag, &timestamp.Timestamp{}); !errors.Is(err, persistence.ErrEtagMismatch) {
+		t.Errorf("SetMovieDeleteTime: want ErrEtagMismatch, got %v", err)
+	}
+	if found, _ := memLayer.FindMovieByID(ctx, "id_0"); found.Name != "Inception 2" || found.DeleteTime != nil || found.Etag == mv.Etag {
+		t.Errorf("FindMovieByID: want only the first update with a new etag, got %v", found)
+	}
+
+	if err := memLayer.SetUserDeleteTime(ctx, "test_username", "stale", &timestamp.Timestamp{}); !errors.Is(err, persistence.ErrEtagMismatch) {
+		t.Errorf("SetUserDeleteTime: want ErrEtagMismatch, got %v", err)
+	}
+	if err := memLayer.SetUserDeleteTime(ctx, "test_username", u.Etag, &timestamp.Timestamp{}); err != nil {
+		t.Errorf("SetUserDeleteTime: unexpected err %v", err)
+	}
+}
+
+func TestWatchMovies(t *testing.T) {
+	ctx := context.Background()
+	memLayer := newTestLayer(t)
+
+	stream, err := memLayer.WatchMovies(ctx, "")
+	if err != nil {
+		t.Fatalf("WatchMovies: unexpected err %v", err)
+	}
+	defer stream.Close()
+
+	if _, err := memLayer.AddMovie(ctx, persistence.Movie{Id: "id_0", Name: "Inception"}); err != nil {
+		t.Fatalf("AddMovie: unexpected err %v", err)
+	}
+	if _, err := memLayer.UpdateMovieByID(ctx, "id_0", "", persistence.Movie{Name: "Inception 2"}); err != nil {
+		t.Fatalf("UpdateMovieByID: unexpected err %v", err)
+	}
+	if err := memLayer.SetMovieDeleteTime(ctx, "id_0", "", &timestamp.Timestamp{Seconds: 1}); err != nil {
+		t.Fatalf("SetMovieDeleteTime: unexpected err %v", err)
+	}
+	if err := memLayer.SetMovieDeleteTime(ctx, "id_0", "", nil); err != nil {
+		t.Fatalf("SetMovieDeleteTime: unexpected err %v", err)
+	}
+	if err := memLayer.RemoveMovieByID(ctx, "id_0"); err != nil {
+		t.Fatalf("RemoveMovieByID: unexpected err %v", err)
+	}
+
+	tests := []struct {
+		typ  persistence.EventType
+		name string
+	}{
+		{persistence.MovieCreated, "Inception"},
+		{persistence.MovieUpdated, "Inception 2"},
+		{persistence.MovieDeleted, "Inception 2"},
+		{persistence.MovieUndeleted, "Inception 2"},
+		{persistence.MovieDeleted, ""},
+	}
+	for _, tcase := range tests {
+		ev, err := stream.Next(ctx)
+		if err != nil || ev.Type != tcase.typ || ev.Movie.Id != "id_0" || ev.Movie.Name != tcase.name {
+			t.Errorf("Next: want event %v of %q, got %v, %v", tcase.typ, tcase.name, ev, err)
+		}
+	}
+}
+
+func TestOutbox(t *testing.T) {
+	ctx := context.Background()
+	memLayer := newTestLayer(t)
+
+	memLayer.AddUser(ctx, persistence.User{Username: "test_username", Password: "test_hash"})
+	memLayer.AddMovie(ctx, persistence.Movie{Id: "id_0", Name: "test_movie"})
+	memLayer.UpdateMovieByID(ctx, "id_0", "", persistence.Movie{Name: "test_movie_2"})
+	memLayer.SetMovieDeleteTime(ctx, "id_0", "", &timestamp.Timestamp{Seconds: 1})
+	memLayer.SetMovieDeleteTime(ctx, "id_0", "", nil)
+	memLayer.RemoveMovieByID(ctx, "id_0")
+	// Failed writes record no events
+	memLayer.UpdateMovieByID(ctx, "id_0", "", persistence.Movie{Name: "test_movie_3"})
+
+	pending, err := memLayer.PendingEvents(ctx, 0)
+	if err != nil {
+		t.Fatalf("PendingEvents: unexpected err %v", err)
+	}
+	want := []events.Type{events.UserCreated, events.MovieCreated, events.MovieUpdated,
+		events.MovieDeleted, events.MovieUndeleted, events.MovieDeleted}
+	if len(pending) != len(want) {
+		t.Fatalf("PendingEvents: want %d events, got %d", len(want), len(pending))
+	}
+	for i, ev := range pending {
+		if ev.Type != want[i] {
+			t.Errorf("PendingEvents: want event %d of type %s, got %s", i, want[i], ev.Type)
+		}
+	}
+
+	var u persistence.User
+	if err := json.Unmarshal(pending[0].Data, &u); err != nil || u.Username != "test_username" || u.Password != "" {
+		t.Errorf("UserCreated: want the user without password, got %s", pending[0].Data)
+	}
+	var mv persistence.Movie
+	if err := json.Unmarshal(pending[2].Data, &mv); err != nil || mv.Id != "id_0" || mv.Name != "test_movie_2" {
+		t.Errorf("MovieUpdated: want the updated movie, got %s", pending[2].Data)
+	}
+	if pending[5].Subject != "id_0" || pending[5].Data != nil {
+		t.Errorf("MovieDeleted: want the ID only of a removed movie, got %+v", pending[5])
+	}
+
+	if err := memLayer.AckEvents(ctx, []string{pending[0].ID, pending[1].ID}); err != nil {
+		t.Fatalf("AckEvents: unexpected err %v", err)
+	}
+	if got, _ := memLayer.PendingEvents(ctx, 2); len(got) != 2 || got[0].ID != pending[2].ID {
+		t.Errorf("PendingEvents: want the events after the acknowledged ones, got %+v", got)
+	}
+}
@@ -1,6 +1,9 @@
 package persistence
 
-import "github.com/golang/protobuf/ptypes/timestamp"
+import (
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/timestamp"
+)
 
 // The roles available for users
 type Role int32
@@ -66,31 +69,37 @@ var (
 type User struct {
 
 	// Required. The username of the user. Must be unique and length should be between 6 to 30 characters.
-	Username string `bson:"username,omitempty"`
+	Username string `json:"username,omitempty" bson:"username,omitempty"`
 	// Required. The email address of the user. Must be unique
-	Email string `bson:"email,omitempty"`
+	Email string `json:"email,omitempty" bson:"email,omitempty"`
 	// Required. The encoded password of the user
-	Password string `bson:"password,omitempty"`
+	Password string `json:"password,omitempty" bson:"password,omitempty"`
 	// Role of the user ,i.e. Guest, NORMAL, SUBSCRIBED, ADMIN. Default role is Guest.
-	Role Role `bson:"role,omitempty"`
+	Role Role `json:"role,omitempty" bson:"role,omitempty"`
 	// Status of the user - Active/Inactive
-	Active bool `bson:"Active,omitempty"`
+	Active bool `json:"Active,omitempty" bson:"Active,omitempty"`
 	// The first name of user. For example: 'Harry'
-	FirstName string `bson:"first_name,omitempty"`
+	FirstName string `json:"first_name,omitempty" bson:"first_name,omitempty"`
 	// The last name of user. For example: 'Potter'
-	LastName *string `bson:"last_name,omitempty"`
+	LastName *string `json:"last_name,omitempty" bson:"last_name,omitempty"`
 	// Output only. The timestamp at which the user was created.
-	CreateTime *timestamp.Timestamp `bson:"create_time,omitempty"`
+	CreateTime *timestamp.Timestamp `json:"create_time,omitempty" bson:"create_time,omitempty"`
 	// Output only. The latest timestamp at which the user was updated.
-	UpdateTime *timestamp.Timestamp `bson:"update_time,omitempty"`
+	UpdateTime *timestamp.Timestamp `json:"update_time,omitempty" bson:"update_time,omitempty"`
 	// The age of the user in years.
-	Age *int32 `bson:"age,omitempty"`
+	Age *int32 `json:"age,omitempty" bson:"age,omitempty"`
 	// The height of the user in feet.
-	HeightInCms *float64 `bson:"height_in_cms,omitempty"`
+	HeightInCms *float64 `json:"height_in_cms,omitempty" bson:"height_in_cms,omitempty"`
 	// The nickname of the user.
-	Nickname *string `bson:"nickname,omitempty"`
+	Nickname *string `json:"nickname,omitempty" bson:"nickname,omitempty"`
 	// Enables the receiving of notifications. The default is false if unset.
-	EnableNotifications *bool `bson:"enable_notifications,omitempty"`
+	EnableNotifications *bool `json:"enable_notifications,omitempty" bson:"enable_notifications,omitempty"`
+	// Output only. The timestamp at which the user was deleted, nil unless deleted.
+	DeleteTime *timestamp.Timestamp `json:"delete_time,omitempty" bson:"delete_time,omitempty"`
+	// Output only. The timestamp at which the email of the user was verified, nil until it is.
+	EmailVerifyTime *timestamp.Timestamp `json:"email_verify_time,omitempty" bson:"email_verify_time,omitempty"`
+	// Output only. Changes on every write of the user, see NewEtag.
+	Etag string `json:"etag,omitempty" bson:"etag,omitempty"`
 }
 
 // For Movies service
@@ -116,4 +125,60 @@ type Movie struct {
 	CreateTime *timestamp.Timestamp `json:"create_time,omitempty"`
 	// Output only. The latest timestamp at which the user was updated.
 	UpdateTime *timestamp.Timestamp `json:"update_time,omitempty"`
+	// Output only. The timestamp at which the movie was deleted, nil unless deleted.
+	DeleteTime *timestamp.Timestamp `json:"delete_time,omitempty"`
+	// Output only. Changes on every write of the movie, see NewEtag.
+	Etag string `json:"etag,omitempty"`
+}
+
+// CopyUser returns a deep copy of u, so that callers can never alias stored
+// or cached data.
+func CopyUser(u User) User {
+	u.LastName = copyString(u.LastName)
+	u.Nickname = copyString(u.Nickname)
+	u.CreateTime = CopyTimestamp(u.CreateTime)
+	u.UpdateTime = CopyTimestamp(u.UpdateTime)
+	u.DeleteTime = CopyTimestamp(u.DeleteTime)
+	u.EmailVerifyTime = CopyTimestamp(u.EmailVerifyTime)
+	if u.Age != nil {
+		age := *u.Age
+		u.Age = &age
+	}
+	if u.HeightInCms != nil {
+		height := *u.HeightInCms
+		u.HeightInCms = &height
+	}
+	if u.EnableNotifications != nil {
+		enabled := *u.EnableNotifications
+		u.EnableNotifications = &enabled
+	}
+	return u
+}
+
+// CopyMovie returns a deep copy of mv, so that callers can never alias stored
+// or cached data.
+func CopyMovie(mv Movie) Movie {
+	mv.Cast = append([]string(nil), mv.Cast...)
+	mv.Writers = append([]string(nil), mv.Writers...)
+	mv.Tags = append([]Tag(nil), mv.Tags...)
+	mv.CreateTime = CopyTimestamp(mv.CreateTime)
+	mv.UpdateTime = CopyTimestamp(mv.UpdateTime)
+	mv.DeleteTime = CopyTimestamp(mv.DeleteTime)
+	return mv
+}
+
+// CopyTimestamp returns a copy of ts, or nil if ts is nil.
+func CopyTimestamp(ts *timestamp.Timestamp) *timestamp.Timestamp {
+	if ts == nil {
+		return nil
+	}
+	return proto.Clone(ts).(*timestamp.Timestamp)
+}
+
+func copyString(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	v := *s
+	return &v
 }
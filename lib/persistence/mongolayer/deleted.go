@@ -0,0 +1,146 @@
+package mongolayer
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/AkashGit21/ms-project/lib/events"
+	"github.com/AkashGit21/ms-project/lib/persistence"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// The keys of the delete times, which are missing unless the record is deleted
+const (
+	userDeleteField  = "delete_time"
+	movieDeleteField = "deletetime"
+)
+
+// excludeDeleted restricts query to the records which are not deleted. A
+// condition on null matches the missing keys as well.
+func excludeDeleted(query bson.M, deleteField string) bson.M {
+	if len(query) == 0 {
+		return bson.M{deleteField: nil}
+	}
+	return bson.M{"$and": bson.A{query, bson.M{deleteField: nil}}}
+}
+
+// deletedBefore selects the records deleted before t. Comparisons with a
+// document never match null, hence the records which are not deleted.
+func deletedBefore(deleteField string, t time.Time) bson.M {
+	ts := bson.D{{Key: "seconds", Value: t.Unix()}, {Key: "nanos", Value: int32(t.Nanosecond())}}
+	return bson.M{deleteField: bson.M{"$lt": ts}}
+}
+
+// deleteTimeUpdate sets the delete time, or removes it if nil.
+func deleteTimeUpdate(deleteField string, deleteTime *timestamp.Timestamp) bson.M {
+	if deleteTime == nil {
+		return bson.M{"$unset": bson.M{deleteField: ""}}
+	}
+	return bson.M{"$set": bson.M{deleteField: deleteTime}}
+}
+
+func (mgoLayer *MongoDBLayer) SetUserDeleteTime(ctx context.Context, uname string, etag string, deleteTime *timestamp.Timestamp) error {
+	typ := events.UserDeleted
+	if deleteTime == nil {
+		typ = events.UserUndeleted
+	}
+	return mgoLayer.updateRecord(ctx, USERS, "user", bson.M{userKeyField: uname}, etag, deleteTimeUpdate(userDeleteField, deleteTime), typ,
+		persistence.NotFound("user", "username", uname), persistence.EtagMismatch("user", "username", uname))
+}
+
+func (mgoLayer *MongoDBLayer) SetMovieDeleteTime(ctx context.Context, id string, etag string, deleteTime *timestamp.Timestamp) error {
+	typ := events.MovieDeleted
+	if deleteTime == nil {
+		typ = events.MovieUndeleted
+	}
+	return mgoLayer.updateRecord(ctx, MOVIES, "movie", bson.M{movieKeyField: id}, etag, deleteTimeUpdate(movieDeleteField, deleteTime), typ,
+		persistence.NotFound("movie", "id", id), persistence.EtagMismatch("movie", "id", id))
+}
+
+func (mgoLayer *MongoDBLayer) PurgeUsers(ctx context.Context, t time.Time) (int, error) {
+	return mgoLayer.purge(ctx, USERS, deletedBefore(userDeleteField, t), "user")
+}
+
+func (mgoLayer *MongoDBLayer) PurgeMovies(ctx context.Context, t time.Time) (int, error) {
+	return mgoLayer.purge(ctx, MOVIES, deletedBefore(movieDeleteField, t), "movie")
+}
+
+// updateRecord applies update to the record of the collection with the given
+// key and etag, and records an event of type typ. It returns notFound if there
+// is no such record, and mismatch if its etag differs. Other errors are about
+// the given resource, e.g. "user".
+func (mgoLayer *MongoDBLayer) updateRecord(ctx context.Context, collection, resource string, key bson.M, etag string, update bson.M, typ events.Type, notFound, mismatch error) error {
+	cli := mgoLayer.client
+
+	opts := options.Session().SetDefaultReadConcern(mgoLayer.readConcern)
+
+	sess, err := cli.StartSession(opts)
+	if err != nil {
+		return persistence.Unavailable(err)
+	}
+	defer sess.EndSession(ctx)
+
+	// Call WithSession to start a transaction within the new session.
+	err = mongo.WithSession(
+		ctx,
+		sess,
+		func(sessCtx mongo.SessionContext) error {
+			if err := sess.StartTransaction(); err != nil {
+				return err
+			}
+
+			coll := cli.Database(mgoLayer.database).Collection(collection)
+			res, err := coll.UpdateOne(sessCtx, matchEtag(key, etag), withNewEtag(update))
+			if err != nil {
+				log.Println(err)
+				return err
+			}
+			if res.MatchedCount == 0 {
+				return notMatched(sessCtx, coll, key, notFound, mismatch)
+			}
+			if err := mgoLayer.addChangeEvent(sessCtx, collection, key, typ); err != nil {
+				return err
+			}
+			return sess.CommitTransaction(sessCtx)
+		})
+
+	return toPersistenceError(err, resource, nil)
+}
+
+// purge removes the records of the collection selected by filter.
+func (mgoLayer *MongoDBLayer) purge(ctx context.Context, collection string, filter bson.M, resource string) (int, error) {
+	cli := mgoLayer.client
+
+	opts := options.Session().SetDefaultReadConcern(mgoLayer.readConcern)
+
+	sess, err := cli.StartSession(opts)
+	if err != nil {
+		return 0, persistence.Unavailable(err)
+	}
+	defer sess.EndSession(ctx)
+
+	var count int64
+	// Call WithSession to start a transaction within the new session.
+	err = mongo.WithSession(
+		ctx,
+		sess,
+		func(sessCtx mongo.SessionContext) error {
+			if err := sess.StartTransaction(); err != nil {
+				return err
+			}
+
+			res, err := cli.Database(mgoLayer.database).Collection(collection).DeleteMany(sessCtx, filter)
+			if err != nil {
+				log.Println(err)
+				return err
+			}
+			count = res.DeletedCount
+			return sess.CommitTransaction(sessCtx)
+		})
+
+	return int(count), toPersistenceError(err, resource, nil)
+}
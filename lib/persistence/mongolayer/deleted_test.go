@@ -0,0 +1,49 @@
+package mongolayer
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestDeletedQueries(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    bson.M
+		expected string
+	}{
+		{
+			name:     "no_query",
+			query:    excludeDeleted(bson.M{}, movieDeleteField),
+			expected: `{"deletetime":null}`,
+		},
+		{
+			name:     "query",
+			query:    excludeDeleted(bson.M{"director": "Nolan"}, movieDeleteField),
+			expected: `{"$and":[{"director":"Nolan"},{"deletetime":null}]}`,
+		},
+		{
+			name:     "deleted_before",
+			query:    deletedBefore(userDeleteField, time.Unix(1609459200, 5)),
+			expected: `{"delete_time":{"$lt":{"seconds":1609459200,"nanos":5}}}`,
+		},
+		{
+			name:     "undelete",
+			query:    deleteTimeUpdate(userDeleteField, nil),
+			expected: `{"$unset":{"delete_time":""}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := bson.MarshalExtJSON(tt.query, false, false)
+			if err != nil {
+				t.Fatalf("MarshalExtJSON: unexpected err %v", err)
+			}
+			if string(got) != tt.expected {
+				t.Errorf("\n\texpected: %v \n\tactual: %s", tt.expected, got)
+			}
+		})
+	}
+}
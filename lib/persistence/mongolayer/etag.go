@@ -0,0 +1,54 @@
+package mongolayer
+
+import (
+	"github.com/AkashGit21/ms-project/lib/persistence"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// etagField is the key of the etag, the same for users and movies
+const etagField = "etag"
+
+// matchEtag restricts the filter of a write to the record read with etag. An
+// empty etag matches any record.
+func matchEtag(filter bson.M, etag string) bson.M {
+	if etag == "" {
+		return filter
+	}
+	matched := bson.M{etagField: etag}
+	for k, v := range filter {
+		matched[k] = v
+	}
+	return matched
+}
+
+// withNewEtag adds a new etag to the fields set by update.
+func withNewEtag(update bson.M) bson.M {
+	set, _ := update["$set"].(bson.M)
+	withEtag := bson.M{etagField: persistence.NewEtag()}
+	for k, v := range set {
+		withEtag[k] = v
+	}
+
+	result := bson.M{"$set": withEtag}
+	for k, v := range update {
+		if k != "$set" {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+// notMatched tells apart the two causes of a write whose filter matched no
+// record: notFound if no record has the given key, mismatch if its etag
+// changed since the caller read it.
+func notMatched(sessCtx mongo.SessionContext, coll *mongo.Collection, key bson.M, notFound, mismatch error) error {
+	n, err := coll.CountDocuments(sessCtx, key)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return mismatch
+	}
+	return notFound
+}
@@ -0,0 +1,83 @@
+package mongolayer
+
+import (
+	"testing"
+
+	"github.com/AkashGit21/ms-project/lib/persistence"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestMatchEtag(t *testing.T) {
+	if filter := matchEtag(movieKey("id_0"), ""); len(filter) != 1 {
+		t.Errorf("matchEtag: want the key only without etag, got %v", filter)
+	}
+	filter := matchEtag(movieKey("id_0"), "e1")
+	if filter[movieKeyField] != "id_0" || filter[etagField] != "e1" {
+		t.Errorf("matchEtag: want the key and the etag, got %v", filter)
+	}
+}
+
+func TestWithNewEtag(t *testing.T) {
+	update := withNewEtag(deleteTimeUpdate(movieDeleteField, nil))
+	if _, ok := update["$unset"]; !ok {
+		t.Errorf("withNewEtag: want the $unset kept, got %v", update)
+	}
+	if etag, _ := update["$set"].(bson.M)[etagField].(string); etag == "" {
+		t.Errorf("withNewEtag: want a new etag set, got %v", update)
+	}
+
+	update = withNewEtag(movieUpdate(persistence.Movie{Name: "Inception", UpdateTime: &timestamp.Timestamp{Seconds: 1}}))
+	set := update["$set"].(bson.M)
+	if set["name"] != "Inception" || set["updatetime"] == nil || set[etagField] == "" {
+		t.Errorf("withNewEtag: want the fields of the movie and a new etag set, got %v", update)
+	}
+	if _, ok := withNewEtag(movieUpdate(persistence.Movie{}))["$set"].(bson.M)["updatetime"]; ok {
+		t.Error("movieUpdate: want no update time set when missing")
+	}
+}
+
+func TestUserUpdate(t *testing.T) {
+	nickname := "harry"
+	update := userUpdate(persistence.User{Email: "harry@hogwarts.edu", Nickname: &nickname})
+	set := update["$set"].(bson.M)
+	if set["email"] != "harry@hogwarts.edu" || set["nickname"] != &nickname || set["update_time"] != nil {
+		t.Errorf("userUpdate: want the fields of the user set, got %v", update)
+	}
+	unset, _ := update["$unset"].(bson.M)
+	if _, ok := unset["last_name"]; !ok {
+		t.Errorf("userUpdate: want the missing optional fields unset, got %v", update)
+	}
+	if _, ok := unset["nickname"]; ok {
+		t.Errorf("userUpdate: want the given optional fields kept, got %v", update)
+	}
+}
+
+func TestUserDocument(t *testing.T) {
+	doc, err := userDocument(persistence.User{Username: "harry_potter", Email: "harry@hogwarts.edu"})
+	if err != nil {
+		t.Fatalf("userDocument: unexpected err %v", err)
+	}
+	if v, ok := doc[userVerifyField]; !ok || v != nil || doc["username"] != "harry_potter" {
+		t.Errorf("userDocument: want a null verification time for an unverified email, got %v", doc)
+	}
+
+	doc, _ = userDocument(persistence.User{Username: "harry_potter", EmailVerifyTime: &timestamp.Timestamp{Seconds: 1}})
+	if verified, ok := doc[userVerifyField].(bson.M); !ok || verified["seconds"] != int64(1) {
+		t.Errorf("userDocument: want the verification time kept, got %v", doc)
+	}
+}
+
+func TestMoviePatch(t *testing.T) {
+	update, err := moviePatch(persistence.Movie{Name: "Inception", Summary: "ignored"}, []string{"name", "cast"})
+	if err != nil {
+		t.Fatalf("moviePatch: unexpected err %v", err)
+	}
+	set := update["$set"].(bson.M)
+	if len(set) != 2 || set["name"] != "Inception" {
+		t.Errorf("moviePatch: want the given fields only, got %v", update)
+	}
+	if _, err := moviePatch(persistence.Movie{}, []string{"active"}); err == nil {
+		t.Error("moviePatch: want an error for an unknown field")
+	}
+}
@@ -0,0 +1,104 @@
+package mongolayer
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/AkashGit21/ms-project/lib/filter"
+	"github.com/AkashGit21/ms-project/lib/persistence"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// movieFilterFields maps the fields of persistence.MovieFilterSchema to keys.
+var movieFilterFields = map[string]string{
+	"name":        "name",
+	"summary":     "summary",
+	"director":    "director",
+	"active":      "active",
+	"cast":        "cast",
+	"writers":     "writers",
+	"tags":        "tags",
+	"create_time": movieTimeField,
+	"update_time": "updatetime",
+}
+
+// operators maps the comparators of a filter to query operators.
+var operators = map[filter.Operator]string{
+	filter.Equals:        "$eq",
+	filter.NotEquals:     "$ne",
+	filter.Less:          "$lt",
+	filter.LessEquals:    "$lte",
+	filter.Greater:       "$gt",
+	filter.GreaterEquals: "$gte",
+}
+
+// moviesQuery selects the movies matching expr after the cursor in a list sorted by order.
+func moviesQuery(expr filter.Expr, order persistence.Order, after persistence.Cursor) (bson.M, error) {
+	page, err := pageFilter(movieSortFields, movieKeyField, order, after)
+	if err != nil {
+		return nil, err
+	}
+	if expr == nil {
+		return page, nil
+	}
+	query, err := toQuery(expr, movieFilterFields)
+	if err != nil {
+		return nil, err
+	}
+	return bson.M{"$and": bson.A{page, query}}, nil
+}
+
+// toQuery translates expr into a query document on the given keys.
+func toQuery(expr filter.Expr, fields map[string]string) (bson.M, error) {
+	switch e := expr.(type) {
+	case filter.And:
+		return joinQueries("$and", e.Left, e.Right, fields)
+	case filter.Or:
+		return joinQueries("$or", e.Left, e.Right, fields)
+	case filter.Not:
+		query, err := toQuery(e.Expr, fields)
+		if err != nil {
+			return nil, err
+		}
+		return bson.M{"$nor": bson.A{query}}, nil
+	case filter.Restriction:
+		key, ok := fields[e.Field]
+		if !ok {
+			return nil, fmt.Errorf("field %q cannot be filtered", e.Field)
+		}
+
+		switch v := e.Value.(type) {
+		case time.Time:
+			// Timestamps are stored as documents, see pageFilter
+			ts := bson.D{{Key: "seconds", Value: v.Unix()}, {Key: "nanos", Value: int32(v.Nanosecond())}}
+			cond := bson.D{{Key: operators[e.Op], Value: ts}}
+			if e.Op == filter.NotEquals {
+				// Like the other backends, records without the timestamp never match
+				cond = append(cond, bson.E{Key: "$type", Value: "object"})
+			}
+			return bson.M{key: cond}, nil
+		case string:
+			if e.Op == filter.Has {
+				// A value matches an array which contains it
+				if e.Field == "tags" {
+					return bson.M{key: persistence.Tag_value[v]}, nil
+				}
+				return bson.M{key: v}, nil
+			}
+		}
+		return bson.M{key: bson.M{operators[e.Op]: e.Value}}, nil
+	}
+	return nil, fmt.Errorf("unsupported filter %v", expr)
+}
+
+func joinQueries(op string, left, right filter.Expr, fields map[string]string) (bson.M, error) {
+	l, err := toQuery(left, fields)
+	if err != nil {
+		return nil, err
+	}
+	r, err := toQuery(right, fields)
+	if err != nil {
+		return nil, err
+	}
+	return bson.M{op: bson.A{l, r}}, nil
+}
@@ -0,0 +1,32 @@
+package mongolayer
+
+import (
+	"testing"
+
+	"github.com/AkashGit21/ms-project/lib/filter"
+	"github.com/AkashGit21/ms-project/lib/persistence"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestMoviesQuery(t *testing.T) {
+	expr, err := filter.Parse(`director = "Nolan" AND (tags:Action OR NOT cast:Bale) AND create_time != "2021-01-01"`,
+		persistence.MovieFilterSchema)
+	if err != nil {
+		t.Fatalf("Parse: unexpected err %v", err)
+	}
+
+	query, err := moviesQuery(expr, persistence.DefaultOrder, persistence.Cursor{})
+	if err != nil {
+		t.Fatalf("moviesQuery: unexpected err %v", err)
+	}
+
+	got, err := bson.MarshalExtJSON(query, false, false)
+	if err != nil {
+		t.Fatalf("MarshalExtJSON: unexpected err %v", err)
+	}
+	want := `{"$and":[{},{"$and":[{"$and":[{"director":{"$eq":"Nolan"}},{"$or":[{"tags":1},{"$nor":[{"cast":"Bale"}]}]}]},` +
+		`{"createtime":{"$ne":{"seconds":1609459200,"nanos":0},"$type":"object"}}]}]}`
+	if string(got) != want {
+		t.Errorf("moviesQuery:\n\texpected: %v \n\tactual: %s", want, got)
+	}
+}
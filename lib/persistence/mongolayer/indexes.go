@@ -0,0 +1,208 @@
+package mongolayer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/AkashGit21/ms-project/lib/persistence"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Error codes of the server when an index cannot be created as declared
+const (
+	indexOptionsConflict  = 85
+	indexKeySpecsConflict = 86
+	duplicateKey          = 11000
+)
+
+// caseInsensitive is the collation of the case-insensitive indexes, which the
+// queries must also use to be served by them.
+var caseInsensitive = &options.Collation{Locale: "en", Strength: 2}
+
+// indexSpec declares an index which MongoDBLayer relies upon.
+type indexSpec struct {
+	collection string
+	name       string
+	keys       bson.D
+	unique     bool
+	// caseInsensitive compares strings with a collation of strength 2, so that
+	// "Inception" and "inception" are the same key
+	caseInsensitive bool
+	// weights of the fields of a text index, whose keys are listed as
+	// {_fts: "text", _ftsx: 1} by the server
+	weights bson.D
+}
+
+// indexes are created at startup. Movies are stored without bson tags, so
+// their keys are the lower-cased field names of persistence.Movie. The last
+// index of each collection supports the sort order of its lists.
+var indexes = []indexSpec{
+	{collection: USERS, name: "username_unique", keys: bson.D{{Key: "username", Value: 1}}, unique: true},
+	{collection: USERS, name: "email_unique", keys: bson.D{{Key: "email", Value: 1}}, unique: true},
+	{collection: USERS, name: "create_time_username", keys: bson.D{{Key: userTimeField, Value: 1}, {Key: userKeyField, Value: 1}}},
+	{collection: MOVIES, name: "name_unique_ci", keys: bson.D{{Key: "name", Value: 1}}, unique: true, caseInsensitive: true},
+	{collection: MOVIES, name: "createtime_id", keys: bson.D{{Key: movieTimeField, Value: 1}, {Key: movieKeyField, Value: 1}}},
+	{collection: MOVIES, name: "movies_text", keys: textKeys(movieSearchWeights), weights: movieSearchWeights},
+}
+
+// movieSearchWeights are the persistence.MovieSearchWeights of the text index,
+// whose keys are the field names of the search.Document of a movie.
+var movieSearchWeights = textWeights(persistence.MovieSearchWeights)
+
+func textWeights(weights map[string]float64) bson.D {
+	var d bson.D
+	for field, w := range weights {
+		d = append(d, bson.E{Key: field, Value: int32(w)})
+	}
+	sort.Slice(d, func(i, j int) bool { return d[i].Key < d[j].Key })
+	return d
+}
+
+func textKeys(weights bson.D) bson.D {
+	var keys bson.D
+	for _, w := range weights {
+		keys = append(keys, bson.E{Key: w.Key, Value: "text"})
+	}
+	return keys
+}
+
+func (spec indexSpec) model() mongo.IndexModel {
+	opts := options.Index().SetName(spec.name)
+	if spec.unique {
+		opts.SetUnique(true)
+	}
+	if spec.caseInsensitive {
+		opts.SetCollation(caseInsensitive)
+	}
+	if spec.weights != nil {
+		opts.SetWeights(spec.weights)
+	}
+	return mongo.IndexModel{Keys: spec.keys, Options: opts}
+}
+
+// indexInfo is an index as listed by the server.
+type indexInfo struct {
+	Name      string `bson:"name"`
+	Key       bson.D `bson:"key"`
+	Unique    bool   `bson:"unique"`
+	Weights   bson.M `bson:"weights"`
+	Collation *struct {
+		Locale   string `bson:"locale"`
+		Strength int    `bson:"strength"`
+	} `bson:"collation"`
+}
+
+// ensureIndexes creates the declared indexes which do not exist yet. An index
+// which cannot be created as declared, e.g. because an index of the same name
+// differs or existing records violate it, is reported by IndexDrift instead of
+// failing the startup.
+func (mgoLayer *MongoDBLayer) ensureIndexes(ctx context.Context) error {
+	db := mgoLayer.client.Database(mgoLayer.database)
+	for _, spec := range indexes {
+		_, err := db.Collection(spec.collection).Indexes().CreateOne(ctx, spec.model())
+		var serverErr mongo.ServerError
+		if errors.As(err, &serverErr) && (serverErr.HasErrorCode(indexOptionsConflict) ||
+			serverErr.HasErrorCode(indexKeySpecsConflict) || serverErr.HasErrorCode(duplicateKey)) {
+			log.Printf("[WARN] Could not create index %s.%s: %v", spec.collection, spec.name, err)
+			continue
+		}
+		if err != nil {
+			return toPersistenceError(err, spec.collection, nil)
+		}
+	}
+	return nil
+}
+
+// IndexDrift compares the indexes of the database with the declared ones. It
+// describes every declared index which is missing or differs, and every index
+// which is not declared.
+func (mgoLayer *MongoDBLayer) IndexDrift(ctx context.Context) ([]string, error) {
+	db := mgoLayer.client.Database(mgoLayer.database)
+
+	existing := map[string][]indexInfo{}
+	for _, coll := range []string{USERS, MOVIES} {
+		cur, err := db.Collection(coll).Indexes().List(ctx)
+		if err != nil {
+			return nil, toPersistenceError(err, coll, nil)
+		}
+		var infos []indexInfo
+		if err := cur.All(ctx, &infos); err != nil {
+			return nil, toPersistenceError(err, coll, nil)
+		}
+		existing[coll] = infos
+	}
+	return indexDrift(indexes, existing), nil
+}
+
+// indexDrift compares the declared indexes with the existing indexes of each collection.
+func indexDrift(declared []indexSpec, existing map[string][]indexInfo) []string {
+	var drift []string
+
+	known := map[string]bool{}
+	for _, spec := range declared {
+		known[spec.collection+"."+spec.name] = true
+
+		var found *indexInfo
+		for i := range existing[spec.collection] {
+			if existing[spec.collection][i].Name == spec.name {
+				found = &existing[spec.collection][i]
+				break
+			}
+		}
+		switch {
+		case found == nil:
+			drift = append(drift, fmt.Sprintf("%s: index %s is missing", spec.collection, spec.name))
+		case spec.weights != nil && !sameWeights(found.Weights, spec.weights):
+			drift = append(drift, fmt.Sprintf("%s: index %s has weights %v, want %v", spec.collection, spec.name, found.Weights, spec.weights))
+		case spec.weights == nil && !sameKeys(found.Key, spec.keys):
+			drift = append(drift, fmt.Sprintf("%s: index %s has keys %v, want %v", spec.collection, spec.name, found.Key, spec.keys))
+		case found.Unique != spec.unique:
+			drift = append(drift, fmt.Sprintf("%s: index %s has unique %v, want %v", spec.collection, spec.name, found.Unique, spec.unique))
+		case spec.caseInsensitive && (found.Collation == nil || found.Collation.Strength != 2):
+			drift = append(drift, fmt.Sprintf("%s: index %s is not case-insensitive", spec.collection, spec.name))
+		}
+	}
+
+	for _, coll := range []string{USERS, MOVIES} {
+		for _, info := range existing[coll] {
+			// The index on _id always exists
+			if info.Name == "_id_" || known[coll+"."+info.Name] {
+				continue
+			}
+			drift = append(drift, fmt.Sprintf("%s: index %s is not declared", coll, info.Name))
+		}
+	}
+	return drift
+}
+
+// sameKeys reports whether both index keys have the same fields in the same
+// order and direction. The server may return the directions as any number type.
+func sameKeys(a, b bson.D) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Key != b[i].Key || fmt.Sprint(a[i].Value) != fmt.Sprint(b[i].Value) {
+			return false
+		}
+	}
+	return true
+}
+
+// sameWeights reports whether the text index has the declared fields and weights.
+func sameWeights(actual bson.M, declared bson.D) bool {
+	if len(actual) != len(declared) {
+		return false
+	}
+	for _, w := range declared {
+		if v, ok := actual[w.Key]; !ok || fmt.Sprint(v) != fmt.Sprint(w.Value) {
+			return false
+		}
+	}
+	return true
+}
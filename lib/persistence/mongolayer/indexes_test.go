@@ -0,0 +1,98 @@
+package mongolayer
+
+import (
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestIndexDrift(t *testing.T) {
+	declared := []indexSpec{
+		{collection: USERS, name: "username_unique", keys: bson.D{{Key: "username", Value: 1}}, unique: true},
+		{collection: MOVIES, name: "name_unique_ci", keys: bson.D{{Key: "name", Value: 1}}, unique: true, caseInsensitive: true},
+		{collection: MOVIES, name: "createtime_id", keys: bson.D{{Key: "createtime", Value: 1}, {Key: "_id", Value: 1}}},
+	}
+	idIndex := indexInfo{Name: "_id_", Key: bson.D{{Key: "_id", Value: int32(1)}}}
+	nameIndex := indexInfo{Name: "name_unique_ci", Key: bson.D{{Key: "name", Value: int32(1)}}, Unique: true}
+	nameIndex.Collation = &struct {
+		Locale   string `bson:"locale"`
+		Strength int    `bson:"strength"`
+	}{"en", 2}
+
+	tests := []struct {
+		name     string
+		existing map[string][]indexInfo
+		expected []string
+	}{
+		{
+			name: "no_drift",
+			existing: map[string][]indexInfo{
+				USERS: {idIndex, {Name: "username_unique", Key: bson.D{{Key: "username", Value: int32(1)}}, Unique: true}},
+				MOVIES: {idIndex, nameIndex,
+					{Name: "createtime_id", Key: bson.D{{Key: "createtime", Value: 1.0}, {Key: "_id", Value: int64(1)}}}},
+			},
+		},
+		{
+			name: "drift",
+			existing: map[string][]indexInfo{
+				USERS: {idIndex, {Name: "username_unique", Key: bson.D{{Key: "username", Value: int32(1)}}}},
+				MOVIES: {idIndex,
+					{Name: "name_unique_ci", Key: bson.D{{Key: "name", Value: int32(1)}}, Unique: true},
+					{Name: "createtime_id", Key: bson.D{{Key: "_id", Value: 1}, {Key: "createtime", Value: 1}}},
+					{Name: "director_1", Key: bson.D{{Key: "director", Value: 1}}}},
+			},
+			expected: []string{
+				"users: index username_unique has unique false, want true",
+				"movies: index name_unique_ci is not case-insensitive",
+				"movies: index createtime_id has keys [{_id 1} {createtime 1}], want [{createtime 1} {_id 1}]",
+				"movies: index director_1 is not declared",
+			},
+		},
+		{
+			name:     "missing",
+			existing: map[string][]indexInfo{USERS: {idIndex}, MOVIES: {idIndex, nameIndex}},
+			expected: []string{
+				"users: index username_unique is missing",
+				"movies: index createtime_id is missing",
+			},
+		},
+	}
+	for _, tcase := range tests {
+		t.Run(tcase.name, func(t *testing.T) {
+			got := indexDrift(declared, tcase.existing)
+			if !reflect.DeepEqual(got, tcase.expected) {
+				t.Errorf("indexDrift:\nwant %q\ngot  %q", tcase.expected, got)
+			}
+		})
+	}
+}
+
+func TestIndexDrift_text(t *testing.T) {
+	weights := bson.D{{Key: "name", Value: int32(10)}, {Key: "summary", Value: int32(1)}}
+	declared := []indexSpec{{collection: MOVIES, name: "movies_text", keys: textKeys(weights), weights: weights}}
+	textKey := bson.D{{Key: "_fts", Value: "text"}, {Key: "_ftsx", Value: int32(1)}}
+
+	tests := []struct {
+		name     string
+		weights  bson.M
+		expected []string
+	}{
+		{"no_drift", bson.M{"name": int32(10), "summary": int32(1)}, nil},
+		{"other_weight", bson.M{"name": int32(5), "summary": int32(1)}, []string{
+			"movies: index movies_text has weights map[name:5 summary:1], want [{name 10} {summary 1}]",
+		}},
+		{"missing_field", bson.M{"name": int32(10)}, []string{
+			"movies: index movies_text has weights map[name:10], want [{name 10} {summary 1}]",
+		}},
+	}
+	for _, tcase := range tests {
+		t.Run(tcase.name, func(t *testing.T) {
+			existing := map[string][]indexInfo{MOVIES: {{Name: "movies_text", Key: textKey, Weights: tcase.weights}}}
+			got := indexDrift(declared, existing)
+			if !reflect.DeepEqual(got, tcase.expected) {
+				t.Errorf("indexDrift:\nwant %q\ngot  %q", tcase.expected, got)
+			}
+		})
+	}
+}
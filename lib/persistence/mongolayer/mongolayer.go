@@ -2,86 +2,329 @@ package mongolayer
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
-	identitypb "github.com/AkashGit21/ms-project/internal/grpc/identity"
-	moviepb "github.com/AkashGit21/ms-project/internal/grpc/movie"
+	"github.com/AkashGit21/ms-project/lib/events"
+	"github.com/AkashGit21/ms-project/lib/filter"
 	"github.com/AkashGit21/ms-project/lib/persistence"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/connstring"
 	"golang.org/x/crypto/bcrypt"
 	"gopkg.in/mgo.v2/bson"
 )
 
 const (
 	DATABASE = "msZone"
-	USERNAME = "db_username"
-	PASSWORD = "db_password"
-	CLUSTER  = "db_cluster"
 	USERS    = "users"
 	MOVIES   = "movies"
+
+	// Error code of the server for concurrent writes to the same document
+	writeConflict = 112
 )
 
 type MongoDBLayer struct {
-	client *mongo.Client
+	client      *mongo.Client
+	database    string
+	readConcern *readconcern.ReadConcern
+}
+
+// Options tune the connection to MongoDB. Zero values keep the setting of the
+// connection string, or else the default of the driver.
+type Options struct {
+	// Database overrides the database given in the path of the connection string
+	Database string
+	// MinPoolSize and MaxPoolSize bound the number of connections per server
+	MinPoolSize uint64
+	MaxPoolSize uint64
+	// TLSCAFile is a PEM file of the certificate authorities to trust. Setting
+	// it or TLSCertificateKeyFile enables TLS.
+	TLSCAFile string
+	// TLSCertificateKeyFile is a PEM file with the client certificate and key
+	TLSCertificateKeyFile string
+	// ReadConcern is a read concern level, e.g. "majority" or "local"
+	ReadConcern string
+	// WriteConcern is either "majority" or the number of acknowledging nodes
+	WriteConcern string
+	// ConnectTimeout, ServerSelectionTimeout and SocketTimeout bound the
+	// respective phases of each operation
+	ConnectTimeout         time.Duration
+	ServerSelectionTimeout time.Duration
+	SocketTimeout          time.Duration
 }
 
+// NewMongoDBLayer connects to the MongoDB deployment of the connection string,
+// e.g. mongodb://127.0.0.1:27017/msZone or mongodb+srv://cluster.example.net.
+// The options are read from the environment, see optionsFromEnv.
 func NewMongoDBLayer(connection string) (persistence.DatabaseHandler, error) {
-	var dbUsername, dbPassword, dbCluster string
-	if dbUsername = os.Getenv("DB_USERNAME"); dbUsername == "" {
-		dbUsername = USERNAME
-	}
-	if dbPassword = os.Getenv("DB_PASSWORD"); dbPassword == "" {
-		dbPassword = PASSWORD
-	}
-	if dbCluster = os.Getenv("DB_CLUSTER"); dbCluster == "" {
-		dbCluster = CLUSTER
+	opts, err := optionsFromEnv()
+	if err != nil {
+		return nil, err
 	}
+	return NewMongoDBLayerWithOptions(connection, opts)
+}
 
-	uri, err := getConnectionURI(DATABASE, dbUsername, dbPassword, dbCluster)
+// NewMongoDBLayerWithOptions connects to the MongoDB deployment of the
+// connection string using the given options.
+func NewMongoDBLayerWithOptions(connection string, opts Options) (persistence.DatabaseHandler, error) {
+	clientOpts, database, err := clientOptions(connection, opts)
 	if err != nil {
-		fmt.Println(err)
 		return nil, err
 	}
-	fmt.Println("MONGODB URL:", uri)
-	c, err := getNewClient(uri)
+
+	c, err := mongo.NewClient(clientOpts)
 	if err != nil {
-		log.Fatal(err)
 		return nil, err
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	err = c.Connect(ctx)
+	if err = c.Connect(ctx); err != nil {
+		return nil, persistence.Unavailable(err)
+	}
+	if err = c.Ping(ctx, nil); err != nil {
+		c.Disconnect(context.Background())
+		return nil, persistence.Unavailable(err)
+	}
+	// Never log the connection string itself, it may contain credentials
+	log.Printf("[INFO] Connected to MongoDB at %s, database %q", strings.Join(clientOpts.Hosts, ","), database)
+
+	mgoLayer := &MongoDBLayer{
+		client:      c,
+		database:    database,
+		readConcern: clientOpts.ReadConcern,
+	}
+
+	// Uniqueness of usernames, emails and movie names is enforced by the indexes
+	if err = mgoLayer.ensureIndexes(ctx); err != nil {
+		c.Disconnect(context.Background())
+		return nil, err
+	}
+	if err = mgoLayer.ensureOutbox(ctx); err != nil {
+		c.Disconnect(context.Background())
+		return nil, err
+	}
+	if err = mgoLayer.backfillEmailVerifyTime(ctx); err != nil {
+		c.Disconnect(context.Background())
+		return nil, err
+	}
+	drift, err := mgoLayer.IndexDrift(ctx)
+	if err != nil {
+		c.Disconnect(context.Background())
+		return nil, err
+	}
+	for _, d := range drift {
+		log.Println("[WARN] Index drift:", d)
+	}
+
+	return mgoLayer, nil
+}
+
+// clientOptions builds the options of the driver from the connection string
+// and opts. It also returns the name of the database to use.
+func clientOptions(connection string, opts Options) (*options.ClientOptions, string, error) {
+	if connection == "" {
+		// Fall back to the Atlas cluster of the environment, as before
+		cluster := os.Getenv("DB_CLUSTER")
+		if cluster == "" {
+			return nil, "", fmt.Errorf("no MongoDB connection string given")
+		}
+		connection = fmt.Sprintf("mongodb+srv://%s/%s?retryWrites=true&w=majority", cluster, DATABASE)
+	}
+	if !strings.HasPrefix(connection, "mongodb://") && !strings.HasPrefix(connection, "mongodb+srv://") {
+		return nil, "", fmt.Errorf("invalid MongoDB connection string: must start with mongodb:// or mongodb+srv://")
+	}
+
+	cs, err := connstring.Parse(connection)
 	if err != nil {
-		log.Fatal(err)
+		// The error of the driver may quote the connection string
+		return nil, "", fmt.Errorf("invalid MongoDB connection string")
+	}
+
+	clientOpts := options.Client().ApplyURI(connection)
+
+	// Credentials of the environment are only used if the URI has none
+	if cs.Username == "" {
+		if user := os.Getenv("DB_USERNAME"); user != "" {
+			clientOpts.SetAuth(options.Credential{
+				Username: user,
+				Password: os.Getenv("DB_PASSWORD"),
+			})
+		}
+	}
+
+	database := DATABASE
+	if cs.Database != "" {
+		database = cs.Database
+	}
+	if opts.Database != "" {
+		database = opts.Database
+	}
+
+	if opts.MaxPoolSize > 0 && opts.MinPoolSize > opts.MaxPoolSize {
+		return nil, "", fmt.Errorf("min pool size %d exceeds max pool size %d", opts.MinPoolSize, opts.MaxPoolSize)
+	}
+	if opts.MinPoolSize > 0 {
+		clientOpts.SetMinPoolSize(opts.MinPoolSize)
+	}
+	if opts.MaxPoolSize > 0 {
+		clientOpts.SetMaxPoolSize(opts.MaxPoolSize)
 	}
-	fmt.Println("Successfully connected!")
+	if opts.ConnectTimeout > 0 {
+		clientOpts.SetConnectTimeout(opts.ConnectTimeout)
+	}
+	if opts.ServerSelectionTimeout > 0 {
+		clientOpts.SetServerSelectionTimeout(opts.ServerSelectionTimeout)
+	}
+	if opts.SocketTimeout > 0 {
+		clientOpts.SetSocketTimeout(opts.SocketTimeout)
+	}
+
+	if opts.ReadConcern != "" {
+		clientOpts.SetReadConcern(readconcern.New(readconcern.Level(opts.ReadConcern)))
+	} else if clientOpts.ReadConcern == nil {
+		clientOpts.SetReadConcern(readconcern.Majority())
+	}
+
+	if opts.WriteConcern != "" {
+		wc, err := writeConcern(opts.WriteConcern)
+		if err != nil {
+			return nil, "", err
+		}
+		clientOpts.SetWriteConcern(wc)
+	} else if clientOpts.WriteConcern == nil {
+		clientOpts.SetWriteConcern(writeconcern.New(writeconcern.WMajority()))
+	}
+
+	if opts.TLSCAFile != "" || opts.TLSCertificateKeyFile != "" {
+		tlsConfig, err := tlsConfig(opts.TLSCAFile, opts.TLSCertificateKeyFile)
+		if err != nil {
+			return nil, "", err
+		}
+		clientOpts.SetTLSConfig(tlsConfig)
+	}
+
+	if err := clientOpts.Validate(); err != nil {
+		return nil, "", err
+	}
+	return clientOpts, database, nil
+}
+
+// writeConcern parses "majority" or the number of nodes which must acknowledge a write.
+func writeConcern(w string) (*writeconcern.WriteConcern, error) {
+	if w == "majority" {
+		return writeconcern.New(writeconcern.WMajority()), nil
+	}
+	n, err := strconv.Atoi(w)
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("invalid write concern %q: must be \"majority\" or a number", w)
+	}
+	return writeconcern.New(writeconcern.W(n)), nil
+}
+
+// tlsConfig trusts the certificate authorities of caFile, if given, and
+// presents the client certificate of certKeyFile, if given.
+func tlsConfig(caFile, certKeyFile string) (*tls.Config, error) {
+	config := &tls.Config{}
+	if caFile != "" {
+		pem, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading TLS CA file: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in TLS CA file %s", caFile)
+		}
+		config.RootCAs = pool
+	}
+	if certKeyFile != "" {
+		// The file holds both the certificate and its private key
+		cert, err := tls.LoadX509KeyPair(certKeyFile, certKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading TLS certificate key file: %v", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
 
-	return &MongoDBLayer{
-		client: c,
-	}, err
+// optionsFromEnv reads the options from the environment variables DB_NAME,
+// DB_MIN_POOL_SIZE, DB_MAX_POOL_SIZE, DB_TLS_CA_FILE, DB_TLS_CERT_KEY_FILE,
+// DB_READ_CONCERN, DB_WRITE_CONCERN, DB_CONNECT_TIMEOUT,
+// DB_SERVER_SELECTION_TIMEOUT and DB_SOCKET_TIMEOUT.
+func optionsFromEnv() (Options, error) {
+	opts := Options{
+		Database:              os.Getenv("DB_NAME"),
+		TLSCAFile:             os.Getenv("DB_TLS_CA_FILE"),
+		TLSCertificateKeyFile: os.Getenv("DB_TLS_CERT_KEY_FILE"),
+		ReadConcern:           os.Getenv("DB_READ_CONCERN"),
+		WriteConcern:          os.Getenv("DB_WRITE_CONCERN"),
+	}
+
+	sizes := map[string]*uint64{
+		"DB_MIN_POOL_SIZE": &opts.MinPoolSize,
+		"DB_MAX_POOL_SIZE": &opts.MaxPoolSize,
+	}
+	for env, size := range sizes {
+		if v := os.Getenv(env); v != "" {
+			n, err := strconv.ParseUint(v, 10, 64)
+			if err != nil {
+				return Options{}, fmt.Errorf("invalid %s %q: %v", env, v, err)
+			}
+			*size = n
+		}
+	}
+
+	timeouts := map[string]*time.Duration{
+		"DB_CONNECT_TIMEOUT":          &opts.ConnectTimeout,
+		"DB_SERVER_SELECTION_TIMEOUT": &opts.ServerSelectionTimeout,
+		"DB_SOCKET_TIMEOUT":           &opts.SocketTimeout,
+	}
+	for env, timeout := range timeouts {
+		if v := os.Getenv(env); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return Options{}, fmt.Errorf("invalid %s %q: %v", env, v, err)
+			}
+			*timeout = d
+		}
+	}
+	return opts, nil
 }
 
-func (mgoLayer *MongoDBLayer) AddUser(u persistence.User) ([]byte, error) {
+func (mgoLayer *MongoDBLayer) AddUser(ctx context.Context, u persistence.User) ([]byte, error) {
 	cli := mgoLayer.client
+	if u.Etag == "" {
+		u.Etag = persistence.NewEtag()
+	}
+	ev, err := persistence.UserDomainEvent(events.UserCreated, u)
+	if err != nil {
+		return nil, err
+	}
 
-	opts := options.Session().SetDefaultReadConcern(readconcern.Majority())
+	opts := options.Session().SetDefaultReadConcern(mgoLayer.readConcern)
 	sess, err := cli.StartSession(opts)
 	if err != nil {
-		log.Fatal(err)
+		return nil, persistence.Unavailable(err)
 	}
-	defer sess.EndSession(context.TODO())
+	defer sess.EndSession(ctx)
 	var id []byte
 	// Call WithSession to start a transaction within the new session.
 	err = mongo.WithSession(
-		context.TODO(),
+		ctx,
 		sess,
 		func(sessCtx mongo.SessionContext) error {
 			// Use sessCtx as the Context parameter for InsertOne and FindOne so
@@ -91,36 +334,44 @@ func (mgoLayer *MongoDBLayer) AddUser(u persistence.User) ([]byte, error) {
 				return err
 			}
 
-			usersCollection := cli.Database(DATABASE).Collection(USERS)
-			res, err := usersCollection.InsertOne(sessCtx, u)
+			doc, err := userDocument(u)
+			if err != nil {
+				return err
+			}
+			usersCollection := cli.Database(mgoLayer.database).Collection(USERS)
+			res, err := usersCollection.InsertOne(sessCtx, doc)
 			if err != nil {
 				log.Println(err)
+				return toPersistenceError(err, "user", map[string]string{"username": u.Username, "email": u.Email})
+			}
+			// The event is committed with the user, or neither is
+			if err := mgoLayer.addEvent(sessCtx, ev); err != nil {
 				return err
 			}
 
 			id, _ = json.Marshal(res.InsertedID)
 
-			return sess.CommitTransaction(context.Background())
+			return sess.CommitTransaction(sessCtx)
 		})
 
-	return id, err
+	return id, toPersistenceError(err, "user", nil)
 }
 
-func (mgoLayer *MongoDBLayer) FindByUsername(uname string) (persistence.User, error) {
+func (mgoLayer *MongoDBLayer) FindByUsername(ctx context.Context, uname string) (persistence.User, error) {
 	cli := mgoLayer.client
 
-	opts := options.Session().SetDefaultReadConcern(readconcern.Majority())
+	opts := options.Session().SetDefaultReadConcern(mgoLayer.readConcern)
 
 	sess, err := cli.StartSession(opts)
 	if err != nil {
-		log.Fatal(err)
+		return persistence.User{}, persistence.Unavailable(err)
 	}
-	defer sess.EndSession(context.TODO())
+	defer sess.EndSession(ctx)
 
 	var result persistence.User
 	// Call WithSession to start a transaction within the new session.
 	err = mongo.WithSession(
-		context.TODO(),
+		ctx,
 		sess,
 		func(sessCtx mongo.SessionContext) error {
 			// Use sessCtx as the Context parameter for InsertOne and FindOne so
@@ -132,37 +383,48 @@ func (mgoLayer *MongoDBLayer) FindByUsername(uname string) (persistence.User, er
 
 			newId := bson.M{"username": uname}
 			// newId := bson.ObjectIdHex(id)
-			usersCollection := cli.Database(DATABASE).Collection(USERS)
+			usersCollection := cli.Database(mgoLayer.database).Collection(USERS)
 			err = usersCollection.FindOne(
 				sessCtx,
 				newId,
 			).Decode(&result)
+			if err == mongo.ErrNoDocuments {
+				return persistence.NotFound("user", "username", uname)
+			}
 			if err != nil {
 				log.Println(err)
 				return err
 			}
 
-			return sess.CommitTransaction(context.Background())
+			return sess.CommitTransaction(sessCtx)
 		})
 
-	return result, err
+	return result, toPersistenceError(err, "user", nil)
 }
 
-func (mgoLayer *MongoDBLayer) FindAllUsers(offset int, pgSize int32) ([]*identitypb.User, error) {
+func (mgoLayer *MongoDBLayer) FindAllUsers(ctx context.Context, showDeleted bool, order persistence.Order, after persistence.Cursor, pgSize int32) ([]persistence.User, error) {
+	filter, err := pageFilter(userSortFields, userKeyField, order, after)
+	if err != nil {
+		return nil, err
+	}
+	if !showDeleted {
+		filter = excludeDeleted(filter, userDeleteField)
+	}
+
 	cli := mgoLayer.client
 
-	opts := options.Session().SetDefaultReadConcern(readconcern.Majority())
+	opts := options.Session().SetDefaultReadConcern(mgoLayer.readConcern)
 
 	sess, err := cli.StartSession(opts)
 	if err != nil {
-		log.Fatal(err)
+		return nil, persistence.Unavailable(err)
 	}
-	defer sess.EndSession(context.TODO())
+	defer sess.EndSession(ctx)
 
-	var results []*identitypb.User
+	var results []persistence.User
 	// Call WithSession to start a transaction within the new session.
 	err = mongo.WithSession(
-		context.TODO(),
+		ctx,
 		sess,
 		func(sessCtx mongo.SessionContext) error {
 			// Use sessCtx as the Context parameter for InsertOne and FindOne so
@@ -172,39 +434,46 @@ func (mgoLayer *MongoDBLayer) FindAllUsers(offset int, pgSize int32) ([]*identit
 				return err
 			}
 
-			filter := bson.M{}
-			opts := options.Find().SetSort(bson.M{}).SetSkip(int64(offset)).SetLimit(int64(pgSize))
+			opts := pageOptions(userSortFields, userKeyField, order, pgSize)
 
-			usersCollection := cli.Database(DATABASE).Collection(USERS)
+			usersCollection := cli.Database(mgoLayer.database).Collection(USERS)
 			cur, err := usersCollection.Find(sessCtx, filter, opts)
-			// defer cur.Close(ctx)
+			if err != nil {
+				log.Println(err)
+				return err
+			}
 
-			if err = cur.All(sessCtx, &results); err != nil {
+			var records []persistence.User
+			if err = cur.All(sessCtx, &records); err != nil {
 				log.Println(err)
 				return err
 			}
-			return sess.CommitTransaction(context.Background())
+			for _, rec := range records {
+				// Lists leave out the password hashes
+				rec.Password = ""
+				results = append(results, rec)
+			}
+			return sess.CommitTransaction(sessCtx)
 		})
 
-	// fmt.Println("ID0:", results[0].Username)
-	return results, err
+	return results, toPersistenceError(err, "user", nil)
 }
 
-func (mgoLayer *MongoDBLayer) CountUsers() int {
+func (mgoLayer *MongoDBLayer) CountUsers(ctx context.Context) (int, error) {
 	cli := mgoLayer.client
 
-	opts := options.Session().SetDefaultReadConcern(readconcern.Majority())
+	opts := options.Session().SetDefaultReadConcern(mgoLayer.readConcern)
 
 	sess, err := cli.StartSession(opts)
 	if err != nil {
-		log.Fatal(err)
+		return 0, persistence.Unavailable(err)
 	}
-	defer sess.EndSession(context.TODO())
+	defer sess.EndSession(ctx)
 
 	var count int64
 	// Call WithSession to start a transaction within the new session.
 	err = mongo.WithSession(
-		context.TODO(),
+		ctx,
 		sess,
 		func(sessCtx mongo.SessionContext) error {
 			// Use sessCtx as the Context parameter for InsertOne and FindOne so
@@ -216,7 +485,7 @@ func (mgoLayer *MongoDBLayer) CountUsers() int {
 
 			filter := bson.M{}
 
-			usersCollection := cli.Database(DATABASE).Collection(USERS)
+			usersCollection := cli.Database(mgoLayer.database).Collection(USERS)
 			count, err = usersCollection.CountDocuments(sessCtx, filter)
 			// defer cur.Close(ctx)
 
@@ -224,28 +493,28 @@ func (mgoLayer *MongoDBLayer) CountUsers() int {
 				log.Println(err)
 				return err
 			}
-			return sess.CommitTransaction(context.Background())
+			return sess.CommitTransaction(sessCtx)
 		})
 
 	// fmt.Println("ID0:", results[0].Username)
-	return int(count)
+	return int(count), toPersistenceError(err, "user", nil)
 }
 
-func (mgoLayer *MongoDBLayer) RemoveByUsername(uname string) error {
+func (mgoLayer *MongoDBLayer) RemoveByUsername(ctx context.Context, uname string) error {
 	cli := mgoLayer.client
 
-	opts := options.Session().SetDefaultReadConcern(readconcern.Majority())
+	opts := options.Session().SetDefaultReadConcern(mgoLayer.readConcern)
 
 	sess, err := cli.StartSession(opts)
 	if err != nil {
-		log.Fatal(err)
+		return persistence.Unavailable(err)
 	}
-	defer sess.EndSession(context.TODO())
+	defer sess.EndSession(ctx)
 	// var count []byte
 
 	// Call WithSession to start a transaction within the new session.
 	err = mongo.WithSession(
-		context.TODO(),
+		ctx,
 		sess,
 		func(sessCtx mongo.SessionContext) error {
 			// Use sessCtx as the Context parameter for InsertOne and FindOne so
@@ -257,36 +526,44 @@ func (mgoLayer *MongoDBLayer) RemoveByUsername(uname string) error {
 
 			newId := bson.M{"username": uname}
 			// newId := bson.ObjectIdHex(id)
-			usersCollection := cli.Database(DATABASE).Collection(USERS)
-			_, err := usersCollection.DeleteOne(sessCtx, newId)
+			usersCollection := cli.Database(mgoLayer.database).Collection(USERS)
+			res, err := usersCollection.DeleteOne(sessCtx, newId)
 			if err != nil {
 				log.Println(err)
 				return err
 			}
+			if res.DeletedCount == 0 {
+				return persistence.NotFound("user", "username", uname)
+			}
+			ev, err := events.New(events.UserDeleted, uname, nil)
+			if err != nil {
+				return err
+			}
+			if err := mgoLayer.addEvent(sessCtx, ev); err != nil {
+				return err
+			}
 
-			// count, _ = json.Marshal(res.DeletedCount)
-
-			return sess.CommitTransaction(context.Background())
+			return sess.CommitTransaction(sessCtx)
 		})
 
-	return nil
+	return toPersistenceError(err, "user", nil)
 }
 
-func (mgoLayer *MongoDBLayer) Authenticate(uname string, password string) bool {
+func (mgoLayer *MongoDBLayer) Authenticate(ctx context.Context, uname string, password string) (bool, error) {
 	cli := mgoLayer.client
 
-	opts := options.Session().SetDefaultReadConcern(readconcern.Majority())
+	opts := options.Session().SetDefaultReadConcern(mgoLayer.readConcern)
 
 	sess, err := cli.StartSession(opts)
 	if err != nil {
-		log.Fatal(err)
+		return false, persistence.Unavailable(err)
 	}
-	defer sess.EndSession(context.TODO())
+	defer sess.EndSession(ctx)
 
 	var result persistence.User
 	// Call WithSession to start a transaction within the new session.
 	err = mongo.WithSession(
-		context.TODO(),
+		ctx,
 		sess,
 		func(sessCtx mongo.SessionContext) error {
 			// Use sessCtx as the Context parameter for InsertOne and FindOne so
@@ -302,40 +579,49 @@ func (mgoLayer *MongoDBLayer) Authenticate(uname string, password string) bool {
 
 			opts := options.FindOne().SetProjection(bson.M{"username": true, "password": true})
 
-			usersCollection := cli.Database(DATABASE).Collection(USERS)
-			usersCollection.FindOne(sessCtx, filter, opts).Decode(&result)
+			usersCollection := cli.Database(mgoLayer.database).Collection(USERS)
+			err := usersCollection.FindOne(sessCtx, filter, opts).Decode(&result)
+			if err == mongo.ErrNoDocuments {
+				return persistence.NotFound("user", "username", uname)
+			}
 			if err != nil {
 				return err
 			}
 
-			return sess.CommitTransaction(context.Background())
+			return sess.CommitTransaction(sessCtx)
 		})
-	log.Println("Result: ", result)
-	if result.Username == "" {
-		return false
+	if err != nil {
+		return false, toPersistenceError(err, "user", nil)
 	}
 	err = bcrypt.CompareHashAndPassword([]byte(result.Password), []byte(password))
 	if err != nil {
 		log.Println("Password doesn't match! ", err)
-		return false
+		return false, nil
 	}
 
-	return true
+	return true, nil
 }
 
-func (mgoLayer *MongoDBLayer) AddMovie(mv persistence.Movie) ([]byte, error) {
+func (mgoLayer *MongoDBLayer) AddMovie(ctx context.Context, mv persistence.Movie) ([]byte, error) {
 	cli := mgoLayer.client
+	if mv.Etag == "" {
+		mv.Etag = persistence.NewEtag()
+	}
+	ev, err := persistence.MovieDomainEvent(events.MovieCreated, mv)
+	if err != nil {
+		return nil, err
+	}
 
-	opts := options.Session().SetDefaultReadConcern(readconcern.Majority())
+	opts := options.Session().SetDefaultReadConcern(mgoLayer.readConcern)
 	sess, err := cli.StartSession(opts)
 	if err != nil {
-		log.Fatal(err)
+		return nil, persistence.Unavailable(err)
 	}
-	defer sess.EndSession(context.TODO())
+	defer sess.EndSession(ctx)
 	var id []byte
 	// Call WithSession to start a transaction within the new session.
 	err = mongo.WithSession(
-		context.TODO(),
+		ctx,
 		sess,
 		func(sessCtx mongo.SessionContext) error {
 			// Use sessCtx as the Context parameter for InsertOne and FindOne so
@@ -345,36 +631,39 @@ func (mgoLayer *MongoDBLayer) AddMovie(mv persistence.Movie) ([]byte, error) {
 				return err
 			}
 
-			moviesCollection := cli.Database(DATABASE).Collection(MOVIES)
+			moviesCollection := cli.Database(mgoLayer.database).Collection(MOVIES)
 			res, err := moviesCollection.InsertOne(sessCtx, mv)
 			if err != nil {
 				log.Println(err)
+				return toPersistenceError(err, "movie", map[string]string{"_id": mv.Id, "name": mv.Name})
+			}
+			if err := mgoLayer.addEvent(sessCtx, ev); err != nil {
 				return err
 			}
 
 			id, _ = json.Marshal(res.InsertedID)
 
-			return sess.CommitTransaction(context.Background())
+			return sess.CommitTransaction(sessCtx)
 		})
 
-	return id, err
+	return id, mgoLayer.nameTaken(ctx, toPersistenceError(err, "movie", nil))
 }
 
-func (mgoLayer *MongoDBLayer) FindMovieByID(id string) (persistence.Movie, error) {
+func (mgoLayer *MongoDBLayer) FindMovieByID(ctx context.Context, id string) (persistence.Movie, error) {
 	cli := mgoLayer.client
 
-	opts := options.Session().SetDefaultReadConcern(readconcern.Majority())
+	opts := options.Session().SetDefaultReadConcern(mgoLayer.readConcern)
 
 	sess, err := cli.StartSession(opts)
 	if err != nil {
-		log.Fatal(err)
+		return persistence.Movie{}, persistence.Unavailable(err)
 	}
-	defer sess.EndSession(context.TODO())
+	defer sess.EndSession(ctx)
 
 	var result persistence.Movie
 	// Call WithSession to start a transaction within the new session.
 	err = mongo.WithSession(
-		context.TODO(),
+		ctx,
 		sess,
 		func(sessCtx mongo.SessionContext) error {
 			// Use sessCtx as the Context parameter for InsertOne and FindOne so
@@ -386,37 +675,60 @@ func (mgoLayer *MongoDBLayer) FindMovieByID(id string) (persistence.Movie, error
 
 			filter := bson.M{"_id": id}
 			// newId := bson.ObjectIdHex(id)
-			moviesCollection := cli.Database(DATABASE).Collection(MOVIES)
+			moviesCollection := cli.Database(mgoLayer.database).Collection(MOVIES)
 			err = moviesCollection.FindOne(
 				sessCtx,
 				filter,
 			).Decode(&result)
+			if err == mongo.ErrNoDocuments {
+				return persistence.NotFound("movie", "id", id)
+			}
 			if err != nil {
 				log.Println(err)
 				return err
 			}
 
-			return sess.CommitTransaction(context.Background())
+			return sess.CommitTransaction(sessCtx)
 		})
 
-	return result, err
+	return result, toPersistenceError(err, "movie", nil)
+}
+
+// FindMovieByName uses the case-insensitive unique index of the names, see
+// ensureIndexes.
+func (mgoLayer *MongoDBLayer) FindMovieByName(ctx context.Context, name string) (persistence.Movie, error) {
+	var result persistence.Movie
+	moviesCollection := mgoLayer.client.Database(mgoLayer.database).Collection(MOVIES)
+	err := moviesCollection.FindOne(ctx, bson.M{"name": name}, options.FindOne().SetCollation(caseInsensitive)).Decode(&result)
+	if err == mongo.ErrNoDocuments {
+		return result, persistence.NotFound("movie", "name", name)
+	}
+	return result, toPersistenceError(err, "movie", nil)
 }
 
-func (mgoLayer *MongoDBLayer) FindAllMovies(offset int, pgSize int32) ([]*moviepb.Movie, error) {
+func (mgoLayer *MongoDBLayer) FindAllMovies(ctx context.Context, expr filter.Expr, showDeleted bool, order persistence.Order, after persistence.Cursor, pgSize int32) ([]persistence.Movie, error) {
+	query, err := moviesQuery(expr, order, after)
+	if err != nil {
+		return nil, err
+	}
+	if !showDeleted {
+		query = excludeDeleted(query, movieDeleteField)
+	}
+
 	cli := mgoLayer.client
 
-	opts := options.Session().SetDefaultReadConcern(readconcern.Majority())
+	opts := options.Session().SetDefaultReadConcern(mgoLayer.readConcern)
 
 	sess, err := cli.StartSession(opts)
 	if err != nil {
-		log.Fatal(err)
+		return nil, persistence.Unavailable(err)
 	}
-	defer sess.EndSession(context.TODO())
+	defer sess.EndSession(ctx)
 
-	var results []*moviepb.Movie
+	var results []persistence.Movie
 	// Call WithSession to start a transaction within the new session.
 	err = mongo.WithSession(
-		context.TODO(),
+		ctx,
 		sess,
 		func(sessCtx mongo.SessionContext) error {
 			// Use sessCtx as the Context parameter for InsertOne and FindOne so
@@ -426,84 +738,98 @@ func (mgoLayer *MongoDBLayer) FindAllMovies(offset int, pgSize int32) ([]*moviep
 				return err
 			}
 
-			filter := bson.M{}
-			opts := options.Find().SetSort(bson.M{}).SetSkip(int64(offset)).SetLimit(int64(pgSize))
+			opts := pageOptions(movieSortFields, movieKeyField, order, pgSize)
 
-			moviesCollection := cli.Database(DATABASE).Collection(MOVIES)
-			cur, err := moviesCollection.Find(sessCtx, filter, opts)
-			// defer cur.Close(ctx)
+			moviesCollection := cli.Database(mgoLayer.database).Collection(MOVIES)
+			cur, err := moviesCollection.Find(sessCtx, query, opts)
+			if err != nil {
+				log.Println(err)
+				return err
+			}
 
 			if err = cur.All(sessCtx, &results); err != nil {
 				log.Println(err)
 				return err
 			}
-			return sess.CommitTransaction(context.Background())
+			return sess.CommitTransaction(sessCtx)
 		})
 
-	// fmt.Println("ID0:", results[0].Username)
-	return results, err
+	return results, toPersistenceError(err, "movie", nil)
+}
+
+// UpdateMovieByID replaces the descriptive fields of the movie with given ID.
+// The ID, status, creation and deletion time of the stored movie are left untouched.
+func (mgoLayer *MongoDBLayer) UpdateMovieByID(ctx context.Context, id string, etag string, mv persistence.Movie) ([]byte, error) {
+	return mgoLayer.updateMovie(ctx, id, etag, movieUpdate(mv), mv.Name)
+}
+
+func (mgoLayer *MongoDBLayer) PatchMovieByID(ctx context.Context, id string, etag string, mv persistence.Movie, fields []string) ([]byte, error) {
+	update, err := moviePatch(mv, fields)
+	if err != nil {
+		return nil, err
+	}
+	return mgoLayer.updateMovie(ctx, id, etag, update, mv.Name)
 }
 
-func (mgoLayer *MongoDBLayer) UpdateMovieByID(id string, mv persistence.Movie) ([]byte, error) {
+// UpdateByUsername replaces the fields of the user with the given username.
+// The username, status, creation and deletion time of the stored user are left untouched.
+func (mgoLayer *MongoDBLayer) UpdateByUsername(ctx context.Context, uname string, etag string, u persistence.User) ([]byte, error) {
 	cli := mgoLayer.client
 
-	opts := options.Session().SetDefaultReadConcern(readconcern.Majority())
+	opts := options.Session().SetDefaultReadConcern(mgoLayer.readConcern)
 	sess, err := cli.StartSession(opts)
 	if err != nil {
-		log.Fatal(err)
+		return nil, persistence.Unavailable(err)
 	}
-	defer sess.EndSession(context.TODO())
+	defer sess.EndSession(ctx)
 
-	var result persistence.Movie
 	// Call WithSession to start a transaction within the new session.
 	err = mongo.WithSession(
-		context.TODO(),
+		ctx,
 		sess,
 		func(sessCtx mongo.SessionContext) error {
-			// Use sessCtx as the Context parameter for InsertOne and FindOne so
-			// both operations are run under the new Session.
-
 			if err := sess.StartTransaction(); err != nil {
 				return err
 			}
 
-			filter := bson.M{"_id": id}
-			moviesCollection := cli.Database(DATABASE).Collection(MOVIES)
-			err := moviesCollection.FindOneAndUpdate(
-				sessCtx,
-				filter,
-				mv).Decode(&result)
+			key := userKey(uname)
+			usersCollection := cli.Database(mgoLayer.database).Collection(USERS)
+			res, err := updateUser(sessCtx, usersCollection, key, etag, u)
 			if err != nil {
 				log.Println(err)
-				return err
+				return toPersistenceError(err, "user", map[string]string{"email": u.Email})
 			}
-			// InsertOne(sessCtx, mv)
-			if err != nil {
-				log.Println(err)
+			if res.MatchedCount == 0 {
+				return notMatched(sessCtx, usersCollection, key,
+					persistence.NotFound("user", "username", uname), persistence.EtagMismatch("user", "username", uname))
+			}
+			if err := mgoLayer.addChangeEvent(sessCtx, USERS, key, events.UserUpdated); err != nil {
 				return err
 			}
 
-			return sess.CommitTransaction(context.Background())
+			return sess.CommitTransaction(sessCtx)
 		})
-
-	return []byte(result.Id), err
+	if err != nil {
+		return nil, toPersistenceError(err, "user", nil)
+	}
+	return []byte(uname), nil
 }
 
-func (mgoLayer *MongoDBLayer) CountMovieRecords() int {
+func (mgoLayer *MongoDBLayer) CountMovieRecords(ctx context.Context) (int, error) {
 	cli := mgoLayer.client
 
-	opts := options.Session().SetDefaultReadConcern(readconcern.Majority())
+	opts := options.Session().SetDefaultReadConcern(mgoLayer.readConcern)
 
 	sess, err := cli.StartSession(opts)
 	if err != nil {
-		log.Fatal(err)
+		return 0, persistence.Unavailable(err)
 	}
-	defer sess.EndSession(context.TODO())
+	defer sess.EndSession(ctx)
 
 	var count int64
 	// Call WithSession to start a transaction within the new session.
 	err = mongo.WithSession(
-		context.TODO(),
+		ctx,
 		sess,
 		func(sessCtx mongo.SessionContext) error {
 			// Use sessCtx as the Context parameter for InsertOne and FindOne so
@@ -515,7 +841,7 @@ func (mgoLayer *MongoDBLayer) CountMovieRecords() int {
 
 			filter := bson.M{}
 
-			moviesCollection := cli.Database(DATABASE).Collection(MOVIES)
+			moviesCollection := cli.Database(mgoLayer.database).Collection(MOVIES)
 			count, err = moviesCollection.CountDocuments(sessCtx, filter)
 			// defer cur.Close(ctx)
 
@@ -523,28 +849,28 @@ func (mgoLayer *MongoDBLayer) CountMovieRecords() int {
 				log.Println(err)
 				return err
 			}
-			return sess.CommitTransaction(context.Background())
+			return sess.CommitTransaction(sessCtx)
 		})
 
 	// fmt.Println("ID0:", results[0].Username)
-	return int(count)
+	return int(count), toPersistenceError(err, "movie", nil)
 }
 
-func (mgoLayer *MongoDBLayer) RemoveMovieByID(id string) error {
+func (mgoLayer *MongoDBLayer) RemoveMovieByID(ctx context.Context, id string) error {
 	cli := mgoLayer.client
 
-	opts := options.Session().SetDefaultReadConcern(readconcern.Majority())
+	opts := options.Session().SetDefaultReadConcern(mgoLayer.readConcern)
 
 	sess, err := cli.StartSession(opts)
 	if err != nil {
-		log.Fatal(err)
+		return persistence.Unavailable(err)
 	}
-	defer sess.EndSession(context.TODO())
+	defer sess.EndSession(ctx)
 	// var count []byte
 
 	// Call WithSession to start a transaction within the new session.
 	err = mongo.WithSession(
-		context.TODO(),
+		ctx,
 		sess,
 		func(sessCtx mongo.SessionContext) error {
 			// Use sessCtx as the Context parameter for InsertOne and FindOne so
@@ -556,33 +882,55 @@ func (mgoLayer *MongoDBLayer) RemoveMovieByID(id string) error {
 
 			newId := bson.M{"_id": id}
 			// newId := bson.ObjectIdHex(id)
-			moviesCollection := cli.Database(DATABASE).Collection(MOVIES)
-			_, err := moviesCollection.DeleteOne(sessCtx, newId)
+			moviesCollection := cli.Database(mgoLayer.database).Collection(MOVIES)
+			res, err := moviesCollection.DeleteOne(sessCtx, newId)
 			if err != nil {
 				log.Println(err)
 				return err
 			}
-
-			// count, _ = json.Marshal(res.DeletedCount)
-			return sess.CommitTransaction(context.Background())
+			if res.DeletedCount == 0 {
+				return persistence.NotFound("movie", "id", id)
+			}
+			ev, err := events.New(events.MovieDeleted, id, nil)
+			if err != nil {
+				return err
+			}
+			if err := mgoLayer.addEvent(sessCtx, ev); err != nil {
+				return err
+			}
+			return sess.CommitTransaction(sessCtx)
 		})
 
-	return nil
-}
-
-func getNewClient(connection string) (*mongo.Client, error) {
-	return mongo.NewClient(options.Client().ApplyURI(connection))
+	return toPersistenceError(err, "movie", nil)
 }
 
-func getConnectionURI(db string, user string, pwd string, cluster string) (string, error) {
-	if db == "" || user == "" || pwd == "" || cluster == "" {
-		return "", fmt.Errorf("Missing Parameters. Please retry!")
+// toPersistenceError translates an error of the driver into the errors of the
+// persistence package. values holds the unique fields of the record written, to
+// tell which one is already taken.
+func toPersistenceError(err error, resource string, values map[string]string) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(*persistence.Error); ok {
+		return err
 	}
 
-	fmt.Println("DB: ", db)
-	fmt.Println("User: ", user)
-	fmt.Println("Password: ", pwd)
-	fmt.Println("Cluster: ", cluster)
+	switch {
+	case mongo.IsDuplicateKeyError(err):
+		// The message names the key, e.g. `dup key: { username: "harry_potter" }`
+		for field, value := range values {
+			if strings.Contains(err.Error(), "dup key: { "+field+":") {
+				return persistence.AlreadyExists(resource, strings.TrimPrefix(field, "_"), value)
+			}
+		}
+		return persistence.AlreadyExists(resource, "", "")
+	case mongo.IsTimeout(err), mongo.IsNetworkError(err):
+		return persistence.Unavailable(err)
+	}
 
-	return fmt.Sprintf(`mongodb+srv://%s:%s@%s/%s?retryWrites=true&w=majority`, user, pwd, cluster, db), nil
+	var srvErr mongo.ServerError
+	if errors.As(err, &srvErr) && (srvErr.HasErrorLabel("TransientTransactionError") || srvErr.HasErrorCode(writeConflict)) {
+		return persistence.Conflict(resource, err)
+	}
+	return err
 }
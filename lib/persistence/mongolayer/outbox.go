@@ -0,0 +1,170 @@
+package mongolayer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/AkashGit21/ms-project/lib/events"
+	"github.com/AkashGit21/ms-project/lib/persistence"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// OUTBOX holds the domain events which were not delivered yet
+const OUTBOX = "outbox"
+
+// Error code of the server for a collection which exists already
+const namespaceExists = 48
+
+// outboxRecord is an events.Event as stored in the outbox. The IDs of the
+// events sort in the order they were made, and so do the records by _id.
+type outboxRecord struct {
+	ID      string    `bson:"_id"`
+	Type    string    `bson:"type"`
+	Time    time.Time `bson:"time"`
+	Subject string    `bson:"subject"`
+	Data    string    `bson:"data,omitempty"`
+}
+
+func toOutboxRecord(ev events.Event) outboxRecord {
+	return outboxRecord{
+		ID:      ev.ID,
+		Type:    string(ev.Type),
+		Time:    ev.Time,
+		Subject: ev.Subject,
+		Data:    string(ev.Data),
+	}
+}
+
+func (rec outboxRecord) event() events.Event {
+	ev := events.Event{
+		ID:      rec.ID,
+		Type:    events.Type(rec.Type),
+		Time:    rec.Time.UTC(),
+		Subject: rec.Subject,
+	}
+	if rec.Data != "" {
+		ev.Data = json.RawMessage(rec.Data)
+	}
+	return ev
+}
+
+// ensureOutbox creates the outbox collection, which the servers before
+// MongoDB 4.4 cannot create within the transactions writing to it.
+func (mgoLayer *MongoDBLayer) ensureOutbox(ctx context.Context) error {
+	err := mgoLayer.client.Database(mgoLayer.database).CreateCollection(ctx, OUTBOX)
+	var serverErr mongo.ServerError
+	if errors.As(err, &serverErr) && serverErr.HasErrorCode(namespaceExists) {
+		return nil
+	}
+	return toPersistenceError(err, OUTBOX, nil)
+}
+
+// addEvent records ev in the outbox, within the transaction of sessCtx.
+func (mgoLayer *MongoDBLayer) addEvent(sessCtx mongo.SessionContext, ev events.Event) error {
+	_, err := mgoLayer.client.Database(mgoLayer.database).Collection(OUTBOX).InsertOne(sessCtx, toOutboxRecord(ev))
+	return err
+}
+
+// addChangeEvent records the event of the given type about the user or movie
+// of the collection with the given key, as written by the transaction of sessCtx.
+func (mgoLayer *MongoDBLayer) addChangeEvent(sessCtx mongo.SessionContext, collection string, key bson.M, typ events.Type) error {
+	res := mgoLayer.client.Database(mgoLayer.database).Collection(collection).FindOne(sessCtx, key)
+
+	var ev events.Event
+	var err error
+	if collection == USERS {
+		var u persistence.User
+		if err = res.Decode(&u); err != nil {
+			return err
+		}
+		ev, err = persistence.UserDomainEvent(typ, u)
+	} else {
+		var mv persistence.Movie
+		if err = res.Decode(&mv); err != nil {
+			return err
+		}
+		ev, err = persistence.MovieDomainEvent(typ, mv)
+	}
+	if err != nil {
+		return err
+	}
+	return mgoLayer.addEvent(sessCtx, ev)
+}
+
+func (mgoLayer *MongoDBLayer) PendingEvents(ctx context.Context, limit int) ([]events.Event, error) {
+	cli := mgoLayer.client
+
+	opts := options.Session().SetDefaultReadConcern(mgoLayer.readConcern)
+
+	sess, err := cli.StartSession(opts)
+	if err != nil {
+		return nil, persistence.Unavailable(err)
+	}
+	defer sess.EndSession(ctx)
+
+	var results []events.Event
+	// Call WithSession to start a transaction within the new session.
+	err = mongo.WithSession(
+		ctx,
+		sess,
+		func(sessCtx mongo.SessionContext) error {
+			if err := sess.StartTransaction(); err != nil {
+				return err
+			}
+
+			findOpts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(int64(limit))
+			cur, err := cli.Database(mgoLayer.database).Collection(OUTBOX).Find(sessCtx, bson.M{}, findOpts)
+			if err != nil {
+				log.Println(err)
+				return err
+			}
+
+			var records []outboxRecord
+			if err = cur.All(sessCtx, &records); err != nil {
+				log.Println(err)
+				return err
+			}
+			for _, rec := range records {
+				results = append(results, rec.event())
+			}
+			return sess.CommitTransaction(sessCtx)
+		})
+
+	return results, toPersistenceError(err, OUTBOX, nil)
+}
+
+func (mgoLayer *MongoDBLayer) AckEvents(ctx context.Context, ids []string) error {
+	cli := mgoLayer.client
+
+	opts := options.Session().SetDefaultReadConcern(mgoLayer.readConcern)
+
+	sess, err := cli.StartSession(opts)
+	if err != nil {
+		return persistence.Unavailable(err)
+	}
+	defer sess.EndSession(ctx)
+
+	// Call WithSession to start a transaction within the new session.
+	err = mongo.WithSession(
+		ctx,
+		sess,
+		func(sessCtx mongo.SessionContext) error {
+			if err := sess.StartTransaction(); err != nil {
+				return err
+			}
+
+			_, err := cli.Database(mgoLayer.database).Collection(OUTBOX).DeleteMany(sessCtx, bson.M{"_id": bson.M{"$in": ids}})
+			if err != nil {
+				log.Println(err)
+				return err
+			}
+			return sess.CommitTransaction(sessCtx)
+		})
+
+	return toPersistenceError(err, OUTBOX, nil)
+}
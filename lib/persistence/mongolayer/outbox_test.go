@@ -0,0 +1,35 @@
+package mongolayer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/AkashGit21/ms-project/lib/events"
+	"github.com/AkashGit21/ms-project/lib/persistence"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestOutboxRecord(t *testing.T) {
+	created, _ := persistence.MovieDomainEvent(events.MovieCreated, persistence.Movie{Id: "id_0", Name: "Inception"})
+	removed, _ := events.New(events.MovieDeleted, "id_0", nil)
+
+	for _, ev := range []events.Event{created, removed} {
+		b, err := bson.Marshal(toOutboxRecord(ev))
+		if err != nil {
+			t.Fatalf("Marshal: unexpected err %v", err)
+		}
+		var rec outboxRecord
+		if err := bson.Unmarshal(b, &rec); err != nil {
+			t.Fatalf("Unmarshal: unexpected err %v", err)
+		}
+		// The server keeps milliseconds only
+		got := rec.event()
+		if !got.Time.Equal(ev.Time.Truncate(1e6)) {
+			t.Errorf("event: want time %v, got %v", ev.Time, got.Time)
+		}
+		got.Time = ev.Time
+		if !reflect.DeepEqual(got, ev) {
+			t.Errorf("event: want %+v, got %+v", ev, got)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package mongolayer
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/AkashGit21/ms-project/lib/persistence"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// The fields by which lists are paged, see persistence.Cursor. Movies are
+// stored without bson tags, so their keys are the lower-cased field names.
+const (
+	userTimeField  = "create_time"
+	userKeyField   = "username"
+	movieTimeField = "createtime"
+	movieKeyField  = "_id"
+)
+
+// movieSortFields and userSortFields map the fields of persistence.MovieSortFields
+// and persistence.UserSortFields to keys.
+var (
+	movieSortFields = map[string]string{
+		"name":        "name",
+		"director":    "director",
+		"create_time": movieTimeField,
+		"update_time": "updatetime",
+	}
+	userSortFields = map[string]string{
+		"username":    "username",
+		"email":       "email",
+		"create_time": userTimeField,
+		"update_time": "update_time",
+	}
+)
+
+// pageFilter selects the records after the cursor, in the order of pageOptions.
+// Timestamps are stored as documents of seconds and nanos, which the server
+// compares field by field. Records without a timestamp sort before the others.
+func pageFilter(fields map[string]string, keyField string, order persistence.Order, after persistence.Cursor) (bson.M, error) {
+	if after.IsZero() {
+		return bson.M{}, nil
+	}
+	if len(after.Values) != len(order) {
+		return nil, fmt.Errorf("cursor has %d values for %d sort fields", len(after.Values), len(order))
+	}
+
+	// Built from the last sort field outwards:
+	// {$or: [{a: {$gt: 1}}, {$and: [{a: 1}, {$or: [{b: {$gt: 2}}, ...]}]}]}
+	query := bson.M{keyField: bson.M{"$gt": after.Key}}
+	for i := len(order) - 1; i >= 0; i-- {
+		key := fields[order[i].Field]
+		following, equal := sortBounds(key, order[i].Desc, after.Values[i])
+		query = bson.M{"$and": bson.A{equal, query}}
+		if following != nil {
+			query = bson.M{"$or": bson.A{following, query}}
+		}
+	}
+	return query, nil
+}
+
+// sortBounds returns the conditions on key of the records which follow the
+// value in a list, and of the records which are equal to it. following is nil
+// if no record follows.
+func sortBounds(key string, desc bool, value interface{}) (following, equal bson.M) {
+	op := "$gt"
+	if desc {
+		op = "$lt"
+	}
+
+	t, ok := value.(time.Time)
+	if !ok {
+		return bson.M{key: bson.M{op: value}}, bson.M{key: value}
+	}
+	if t.IsZero() {
+		if desc {
+			return nil, bson.M{key: nil}
+		}
+		return bson.M{key: bson.M{"$type": "object"}}, bson.M{key: nil}
+	}
+
+	ts := bson.D{{Key: "seconds", Value: t.Unix()}, {Key: "nanos", Value: int32(t.Nanosecond())}}
+	// Comparisons with a document never match null
+	following = bson.M{key: bson.M{op: ts}}
+	if desc {
+		following = bson.M{"$or": bson.A{following, bson.M{key: nil}}}
+	}
+	return following, bson.M{key: ts}
+}
+
+// pageOptions sorts by order and then by the unique key, and limits the result
+// to pgSize records, or none if it is 0.
+func pageOptions(fields map[string]string, keyField string, order persistence.Order, pgSize int32) *options.FindOptions {
+	sort := bson.D{}
+	for _, sf := range order {
+		dir := 1
+		if sf.Desc {
+			dir = -1
+		}
+		sort = append(sort, bson.E{Key: fields[sf.Field], Value: dir})
+	}
+	return options.Find().
+		SetSort(append(sort, bson.E{Key: keyField, Value: 1})).
+		SetLimit(int64(pgSize))
+}
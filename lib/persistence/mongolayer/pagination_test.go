@@ -0,0 +1,76 @@
+package mongolayer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AkashGit21/ms-project/lib/persistence"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestPageFilter(t *testing.T) {
+	order, err := persistence.ParseOrder("director desc, create_time", persistence.MovieSortFields)
+	if err != nil {
+		t.Fatalf("ParseOrder: unexpected err %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		order    persistence.Order
+		after    persistence.Cursor
+		expected string
+	}{
+		{
+			name:     "first_page",
+			order:    order,
+			expected: `{}`,
+		},
+		{
+			name:  "time",
+			order: order,
+			after: persistence.Cursor{Values: []interface{}{"Nolan", time.Unix(1609459200, 5)}, Key: "id_1"},
+			expected: `{"$or":[{"director":{"$lt":"Nolan"}},{"$and":[{"director":"Nolan"},` +
+				`{"$or":[{"createtime":{"$gt":{"seconds":1609459200,"nanos":5}}},` +
+				`{"$and":[{"createtime":{"seconds":1609459200,"nanos":5}},{"_id":{"$gt":"id_1"}}]}]}]}]}`,
+		},
+		{
+			name:  "no_time",
+			order: order,
+			after: persistence.Cursor{Values: []interface{}{"Nolan", time.Time{}}, Key: "id_1"},
+			expected: `{"$or":[{"director":{"$lt":"Nolan"}},{"$and":[{"director":"Nolan"},` +
+				`{"$or":[{"createtime":{"$type":"object"}},{"$and":[{"createtime":null},{"_id":{"$gt":"id_1"}}]}]}]}]}`,
+		},
+		{
+			name:  "desc_time",
+			order: persistence.Order{{Field: "update_time", Desc: true}},
+			after: persistence.Cursor{Values: []interface{}{time.Unix(1609459200, 0)}, Key: "id_1"},
+			expected: `{"$or":[{"$or":[{"updatetime":{"$lt":{"seconds":1609459200,"nanos":0}}},{"updatetime":null}]},` +
+				`{"$and":[{"updatetime":{"seconds":1609459200,"nanos":0}},{"_id":{"$gt":"id_1"}}]}]}`,
+		},
+		{
+			name:     "desc_no_time",
+			order:    persistence.Order{{Field: "update_time", Desc: true}},
+			after:    persistence.Cursor{Values: []interface{}{time.Time{}}, Key: "id_1"},
+			expected: `{"$and":[{"updatetime":null},{"_id":{"$gt":"id_1"}}]}`,
+		},
+	}
+	for _, tcase := range tests {
+		t.Run(tcase.name, func(t *testing.T) {
+			query, err := pageFilter(movieSortFields, movieKeyField, tcase.order, tcase.after)
+			if err != nil {
+				t.Fatalf("pageFilter: unexpected err %v", err)
+			}
+			got, err := bson.MarshalExtJSON(query, false, false)
+			if err != nil {
+				t.Fatalf("MarshalExtJSON: unexpected err %v", err)
+			}
+			if string(got) != tcase.expected {
+				t.Errorf("pageFilter:\n\texpected: %v \n\tactual: %s", tcase.expected, got)
+			}
+		})
+	}
+
+	if _, err := pageFilter(movieSortFields, movieKeyField, order, persistence.Cursor{Key: "id_1"}); err == nil {
+		t.Error("pageFilter: want error for cursor of another order")
+	}
+}
@@ -0,0 +1,76 @@
+package mongolayer
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"github.com/AkashGit21/ms-project/lib/persistence"
+	"github.com/AkashGit21/ms-project/lib/search"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// textScore is the relevance of a document matching a $text query.
+var textScore = bson.M{"$meta": "textScore"}
+
+// SearchMovies uses the text index movies_text, which ranks the movies by
+// the weights of the words in movieSearchWeights.
+func (mgoLayer *MongoDBLayer) SearchMovies(ctx context.Context, query string, offset, limit int32) ([]persistence.SearchHit, error) {
+	// Terms are letters and digits only, hence neither phrases nor negations.
+	// The server matches a document containing any of them.
+	terms := search.Tokenize(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+	filter := excludeDeleted(bson.M{"$text": bson.M{"$search": strings.Join(terms, " ")}}, movieDeleteField)
+	findOpts := options.Find().
+		SetProjection(bson.M{"score": textScore}).
+		SetSort(bson.D{{Key: "score", Value: textScore}, {Key: movieKeyField, Value: 1}}).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit))
+
+	cli := mgoLayer.client
+
+	opts := options.Session().SetDefaultReadConcern(mgoLayer.readConcern)
+
+	sess, err := cli.StartSession(opts)
+	if err != nil {
+		return nil, persistence.Unavailable(err)
+	}
+	defer sess.EndSession(ctx)
+
+	var results []persistence.SearchHit
+	// Call WithSession to start a transaction within the new session.
+	err = mongo.WithSession(
+		ctx,
+		sess,
+		func(sessCtx mongo.SessionContext) error {
+			if err := sess.StartTransaction(); err != nil {
+				return err
+			}
+
+			moviesCollection := cli.Database(mgoLayer.database).Collection(MOVIES)
+			cur, err := moviesCollection.Find(sessCtx, filter, findOpts)
+			if err != nil {
+				log.Println(err)
+				return err
+			}
+
+			var records []struct {
+				persistence.Movie `bson:",inline"`
+				Score             float64 `bson:"score"`
+			}
+			if err = cur.All(sessCtx, &records); err != nil {
+				log.Println(err)
+				return err
+			}
+			for _, rec := range records {
+				results = append(results, persistence.SearchHit{Movie: rec.Movie, Score: rec.Score})
+			}
+			return sess.CommitTransaction(sessCtx)
+		})
+
+	return results, toPersistenceError(err, "movie", nil)
+}
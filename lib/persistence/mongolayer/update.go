@@ -0,0 +1,216 @@
+package mongolayer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"reflect"
+
+	"github.com/AkashGit21/ms-project/lib/events"
+	"github.com/AkashGit21/ms-project/lib/persistence"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// movieKey selects the movie with the given ID.
+func movieKey(id string) bson.M {
+	return bson.M{movieKeyField: id}
+}
+
+// userVerifyField is the key of the email verification time of a user. It is
+// null until the email is verified, and missing for the users stored before
+// emails were verified, which backfillEmailVerifyTime trusts as verified.
+const userVerifyField = "email_verify_time"
+
+// userDocument returns u as stored, see userVerifyField.
+func userDocument(u persistence.User) (bson.M, error) {
+	raw, err := bson.Marshal(u)
+	if err != nil {
+		return nil, err
+	}
+	var doc bson.M
+	if err := bson.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	if _, ok := doc[userVerifyField]; !ok {
+		doc[userVerifyField] = nil
+	}
+	return doc, nil
+}
+
+// backfillEmailVerifyTime sets the email verification time of the users stored
+// before emails were verified to their creation time. Later runs find none.
+func (mgoLayer *MongoDBLayer) backfillEmailVerifyTime(ctx context.Context) error {
+	usersCollection := mgoLayer.client.Database(mgoLayer.database).Collection(USERS)
+	notCreated := bson.M{"seconds": int64(0), "nanos": int32(0)}
+	res, err := usersCollection.UpdateMany(ctx, bson.M{userVerifyField: bson.M{"$exists": false}},
+		bson.A{bson.M{"$set": bson.M{userVerifyField: bson.M{"$ifNull": bson.A{"$create_time", notCreated}}}}})
+	if err != nil {
+		return toPersistenceError(err, "user", nil)
+	}
+	if res.ModifiedCount > 0 {
+		log.Printf("[INFO] Trusted the emails of %d existing users as verified", res.ModifiedCount)
+	}
+	return nil
+}
+
+// updateUser applies userUpdate(u) to the user with the given key and etag,
+// within the transaction of ctx. The email verification time is cleared first
+// if the email changes.
+func updateUser(ctx context.Context, usersCollection *mongo.Collection, key bson.M, etag string, u persistence.User) (*mongo.UpdateResult, error) {
+	emailChanged := bson.M{"$and": bson.A{matchEtag(key, etag), bson.M{"email": bson.M{"$ne": u.Email}}}}
+	if _, err := usersCollection.UpdateOne(ctx, emailChanged, bson.M{"$set": bson.M{userVerifyField: nil}}); err != nil {
+		return nil, err
+	}
+	return usersCollection.UpdateOne(ctx, matchEtag(key, etag), withNewEtag(userUpdate(u)))
+}
+
+func (mgoLayer *MongoDBLayer) SetUserEmailVerifyTime(ctx context.Context, uname string, etag string, verifyTime *timestamp.Timestamp) error {
+	// A nil time is stored as null, see userVerifyField
+	update := bson.M{"$set": bson.M{userVerifyField: verifyTime}}
+	return mgoLayer.updateRecord(ctx, USERS, "user", userKey(uname), etag, update, events.UserEmailVerified,
+		persistence.NotFound("user", "username", uname), persistence.EtagMismatch("user", "username", uname))
+}
+
+// movieUpdate sets the descriptive fields of mv, and its update time if any.
+func movieUpdate(mv persistence.Movie) bson.M {
+	set := bson.M{
+		"name":     mv.Name,
+		"summary":  mv.Summary,
+		"cast":     mv.Cast,
+		"tags":     mv.Tags,
+		"director": mv.Director,
+		"writers":  mv.Writers,
+	}
+	if mv.UpdateTime != nil {
+		set["updatetime"] = mv.UpdateTime
+	}
+	return bson.M{"$set": set}
+}
+
+// moviePatch sets the given fields of mv, and its update time if any.
+func moviePatch(mv persistence.Movie, fields []string) (bson.M, error) {
+	update := movieUpdate(mv)
+	all := update["$set"].(bson.M)
+	set := bson.M{}
+	for _, field := range fields {
+		v, ok := all[field]
+		if !ok {
+			return nil, fmt.Errorf("unknown field %q of a movie", field)
+		}
+		set[field] = v
+	}
+	if mv.UpdateTime != nil {
+		set["updatetime"] = mv.UpdateTime
+	}
+	return bson.M{"$set": set}, nil
+}
+
+// userKey selects the user with the given username.
+func userKey(uname string) bson.M {
+	return bson.M{userKeyField: uname}
+}
+
+// userUpdate sets the fields of u, except for its username, status, creation
+// and deletion time, and its update time if any. The optional fields u leaves
+// unset are removed from the stored user.
+func userUpdate(u persistence.User) bson.M {
+	set := bson.M{
+		"email":      u.Email,
+		"password":   u.Password,
+		"role":       u.Role,
+		"first_name": u.FirstName,
+	}
+	unset := bson.M{}
+	optional := map[string]interface{}{
+		"last_name":            u.LastName,
+		"age":                  u.Age,
+		"height_in_cms":        u.HeightInCms,
+		"nickname":             u.Nickname,
+		"enable_notifications": u.EnableNotifications,
+	}
+	for field, v := range optional {
+		if reflect.ValueOf(v).IsNil() {
+			unset[field] = ""
+		} else {
+			set[field] = v
+		}
+	}
+	if u.UpdateTime != nil {
+		set["update_time"] = u.UpdateTime
+	}
+
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+	return update
+}
+
+// updateMovie applies update to the movie with the given ID and etag, and
+// records the change. The name is that of the update, for a duplicate one.
+func (mgoLayer *MongoDBLayer) updateMovie(ctx context.Context, id string, etag string, update bson.M, name string) ([]byte, error) {
+	cli := mgoLayer.client
+
+	opts := options.Session().SetDefaultReadConcern(mgoLayer.readConcern)
+	sess, err := cli.StartSession(opts)
+	if err != nil {
+		return nil, persistence.Unavailable(err)
+	}
+	defer sess.EndSession(ctx)
+
+	// Call WithSession to start a transaction within the new session.
+	err = mongo.WithSession(
+		ctx,
+		sess,
+		func(sessCtx mongo.SessionContext) error {
+			// Use sessCtx as the Context parameter for InsertOne and FindOne so
+			// both operations are run under the new Session.
+
+			if err := sess.StartTransaction(); err != nil {
+				return err
+			}
+
+			key := movieKey(id)
+			moviesCollection := cli.Database(mgoLayer.database).Collection(MOVIES)
+			res, err := moviesCollection.UpdateOne(sessCtx, matchEtag(key, etag), withNewEtag(update))
+			if err != nil {
+				log.Println(err)
+				return toPersistenceError(err, "movie", map[string]string{"name": name})
+			}
+			if res.MatchedCount == 0 {
+				return notMatched(sessCtx, moviesCollection, key,
+					persistence.NotFound("movie", "id", id), persistence.EtagMismatch("movie", "id", id))
+			}
+			if err := mgoLayer.addChangeEvent(sessCtx, MOVIES, key, events.MovieUpdated); err != nil {
+				return err
+			}
+
+			return sess.CommitTransaction(sessCtx)
+		})
+	if err != nil {
+		return nil, mgoLayer.nameTaken(ctx, toPersistenceError(err, "movie", nil))
+	}
+	return []byte(id), nil
+}
+
+// nameTaken completes an error reporting a movie name already taken with the
+// ID of the movie holding it, any other error being returned as is.
+func (mgoLayer *MongoDBLayer) nameTaken(ctx context.Context, err error) error {
+	name, ok := persistence.TakenValue(err, "movie", "name")
+	if !ok {
+		return err
+	}
+	var holder struct {
+		Id string `bson:"_id"`
+	}
+	opts := options.FindOne().SetCollation(caseInsensitive).SetProjection(bson.M{movieKeyField: 1})
+	moviesCollection := mgoLayer.client.Database(mgoLayer.database).Collection(MOVIES)
+	if err := moviesCollection.FindOne(ctx, bson.M{"name": name}, opts).Decode(&holder); err != nil {
+		// The movie holding the name may have been removed since
+		return persistence.AlreadyExists("movie", "name", name)
+	}
+	return persistence.AlreadyExistsAs("movie", "name", name, holder.Id)
+}
@@ -0,0 +1,143 @@
+package mongolayer
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"github.com/AkashGit21/ms-project/lib/persistence"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Error codes of the server for resume tokens it cannot resume from
+const (
+	changeStreamFatalError  = 280
+	changeStreamHistoryLost = 286
+)
+
+// WatchMovies streams the change stream of the movies, which MongoDB offers on
+// replica sets only. Its resume tokens are those of MongoDB in base64, so that
+// a watcher may resume on any server of the deployment.
+func (mgoLayer *MongoDBLayer) WatchMovies(ctx context.Context, resumeToken string) (persistence.MovieStream, error) {
+	opts := options.ChangeStream().SetFullDocument(options.UpdateLookup)
+	if resumeToken != "" {
+		token, err := base64.RawURLEncoding.DecodeString(resumeToken)
+		if err == nil {
+			err = bson.Raw(token).Validate()
+		}
+		if err != nil {
+			return nil, persistence.InvalidResumeToken(err)
+		}
+		opts.SetResumeAfter(bson.Raw(token))
+	}
+
+	moviesCollection := mgoLayer.client.Database(mgoLayer.database).Collection(MOVIES)
+	stream, err := moviesCollection.Watch(ctx, mongo.Pipeline{}, opts)
+	if err != nil {
+		return nil, toWatchError(err)
+	}
+	return &changeStream{stream}, nil
+}
+
+// changeEvent is the part of a change event of MongoDB needed for a MovieEvent.
+type changeEvent struct {
+	OperationType string              `bson:"operationType"`
+	FullDocument  *persistence.Movie  `bson:"fullDocument"`
+	DocumentKey   documentKey         `bson:"documentKey"`
+	ClusterTime   primitive.Timestamp `bson:"clusterTime"`
+	Update        updateDescription   `bson:"updateDescription"`
+}
+
+type documentKey struct {
+	ID string `bson:"_id"`
+}
+
+type updateDescription struct {
+	UpdatedFields bson.M   `bson:"updatedFields"`
+	RemovedFields []string `bson:"removedFields"`
+}
+
+// changeStream is a MovieStream of a change stream of the movies.
+type changeStream struct {
+	stream *mongo.ChangeStream
+}
+
+func (cs *changeStream) Next(ctx context.Context) (persistence.MovieEvent, error) {
+	for cs.stream.Next(ctx) {
+		var change changeEvent
+		if err := cs.stream.Decode(&change); err != nil {
+			return persistence.MovieEvent{}, err
+		}
+		ev, ok := toMovieEvent(change)
+		if !ok {
+			continue
+		}
+		ev.ResumeToken = base64.RawURLEncoding.EncodeToString(cs.stream.ResumeToken())
+		return ev, nil
+	}
+
+	err := cs.stream.Err()
+	if err == nil {
+		err = ctx.Err()
+	}
+	if err == nil {
+		// The stream is invalidated, e.g. because the collection was dropped
+		err = persistence.Unavailable(errors.New("the change stream of the movies was closed"))
+	}
+	return persistence.MovieEvent{}, toWatchError(err)
+}
+
+func (cs *changeStream) Close() error {
+	return cs.stream.Close(context.Background())
+}
+
+// toMovieEvent returns the MovieEvent of change, or false for the changes
+// which are not about a single movie, like the drop of the collection.
+func toMovieEvent(change changeEvent) (persistence.MovieEvent, bool) {
+	ev := persistence.MovieEvent{
+		Movie: persistence.Movie{Id: change.DocumentKey.ID},
+		Time:  time.Unix(int64(change.ClusterTime.T), 0),
+	}
+	if change.FullDocument != nil {
+		ev.Movie = *change.FullDocument
+	}
+
+	switch change.OperationType {
+	case "insert":
+		ev.Type = persistence.MovieCreated
+	case "replace":
+		ev.Type = persistence.MovieUpdated
+	case "update":
+		// Deletes and undeletes are updates of the delete time
+		ev.Type = persistence.MovieUpdated
+		if _, ok := change.Update.UpdatedFields[movieDeleteField]; ok {
+			ev.Type = persistence.MovieDeleted
+		}
+		for _, field := range change.Update.RemovedFields {
+			if field == movieDeleteField {
+				ev.Type = persistence.MovieUndeleted
+			}
+		}
+	case "delete":
+		// Purged, or removed for good; the document is gone
+		ev.Type = persistence.MovieDeleted
+		ev.Movie = persistence.Movie{Id: change.DocumentKey.ID}
+	default:
+		return persistence.MovieEvent{}, false
+	}
+	return ev, true
+}
+
+// toWatchError reports the resume tokens the server cannot resume from as
+// such, and the other errors like toPersistenceError does.
+func toWatchError(err error) error {
+	var srvErr mongo.ServerError
+	if errors.As(err, &srvErr) && (srvErr.HasErrorCode(changeStreamHistoryLost) || srvErr.HasErrorCode(changeStreamFatalError)) {
+		return persistence.InvalidResumeToken(err)
+	}
+	return toPersistenceError(err, "movie", nil)
+}
@@ -0,0 +1,76 @@
+package mongolayer
+
+import (
+	"testing"
+
+	"github.com/AkashGit21/ms-project/lib/persistence"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestToMovieEvent(t *testing.T) {
+	tests := []struct {
+		name     string
+		change   bson.M
+		expected persistence.EventType
+		movie    string
+	}{
+		{
+			name:     "insert",
+			change:   bson.M{"operationType": "insert", "documentKey": bson.M{"_id": "id_0"}, "fullDocument": bson.M{"_id": "id_0", "name": "Inception"}},
+			expected: persistence.MovieCreated,
+			movie:    "Inception",
+		},
+		{
+			name: "update",
+			change: bson.M{"operationType": "update", "documentKey": bson.M{"_id": "id_0"}, "fullDocument": bson.M{"_id": "id_0", "name": "Inception 2"},
+				"updateDescription": bson.M{"updatedFields": bson.M{"name": "Inception 2"}, "removedFields": bson.A{}}},
+			expected: persistence.MovieUpdated,
+			movie:    "Inception 2",
+		},
+		{
+			name: "delete_time_set",
+			change: bson.M{"operationType": "update", "documentKey": bson.M{"_id": "id_0"}, "fullDocument": bson.M{"_id": "id_0", "name": "Inception"},
+				"updateDescription": bson.M{"updatedFields": bson.M{movieDeleteField: bson.M{"seconds": 1}, etagField: "e1"}}},
+			expected: persistence.MovieDeleted,
+			movie:    "Inception",
+		},
+		{
+			name: "delete_time_removed",
+			change: bson.M{"operationType": "update", "documentKey": bson.M{"_id": "id_0"}, "fullDocument": bson.M{"_id": "id_0", "name": "Inception"},
+				"updateDescription": bson.M{"updatedFields": bson.M{etagField: "e1"}, "removedFields": bson.A{movieDeleteField}}},
+			expected: persistence.MovieUndeleted,
+			movie:    "Inception",
+		},
+		{
+			name:     "purged",
+			change:   bson.M{"operationType": "delete", "documentKey": bson.M{"_id": "id_0"}},
+			expected: persistence.MovieDeleted,
+		},
+		{
+			name:     "dropped",
+			change:   bson.M{"operationType": "drop"},
+			expected: persistence.EventUnspecified,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, err := bson.Marshal(tt.change)
+			if err != nil {
+				t.Fatalf("Marshal: unexpected err %v", err)
+			}
+			var change changeEvent
+			if err := bson.Unmarshal(raw, &change); err != nil {
+				t.Fatalf("Unmarshal: unexpected err %v", err)
+			}
+
+			ev, ok := toMovieEvent(change)
+			if ok != (tt.expected != persistence.EventUnspecified) || ev.Type != tt.expected {
+				t.Fatalf("toMovieEvent: want event %v, got %v, %v", tt.expected, ev, ok)
+			}
+			if ok && (ev.Movie.Id != "id_0" || ev.Movie.Name != tt.movie) {
+				t.Errorf("toMovieEvent: want movie id_0 %q, got %v", tt.movie, ev.Movie)
+			}
+		})
+	}
+}
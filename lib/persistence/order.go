@@ -0,0 +1,119 @@
+package persistence
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SortField is a field by which a list is sorted.
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// Order is the sort order of a list, following https://google.aip.dev/132#ordering.
+type Order []SortField
+
+// DefaultOrder sorts the records from the oldest to the newest.
+var DefaultOrder = Order{{Field: "create_time"}}
+
+// The fields by which ListMovies and ListUsers may be sorted. All of them are
+// strings or timestamps.
+var (
+	MovieSortFields = []string{"name", "director", "create_time", "update_time"}
+	UserSortFields  = []string{"username", "email", "create_time", "update_time"}
+
+	// timeSortFields are the sort fields which are timestamps
+	timeSortFields = map[string]bool{"create_time": true, "update_time": true}
+)
+
+// ParseOrder parses an order_by like "name desc, create_time", allowing only
+// the given fields. An empty order_by returns DefaultOrder.
+func ParseOrder(orderBy string, fields []string) (Order, error) {
+	if strings.TrimSpace(orderBy) == "" {
+		return DefaultOrder, nil
+	}
+
+	var order Order
+	seen := map[string]bool{}
+	for _, part := range strings.Split(orderBy, ",") {
+		words := strings.Fields(part)
+		if len(words) == 0 || len(words) > 2 {
+			return nil, fmt.Errorf("`%s` should be a field name, optionally followed by `desc`", strings.TrimSpace(part))
+		}
+
+		sf := SortField{Field: words[0]}
+		if len(words) == 2 {
+			if words[1] != "desc" && words[1] != "asc" {
+				return nil, fmt.Errorf("unknown direction `%s` of field `%s`, want `asc` or `desc`", words[1], words[0])
+			}
+			sf.Desc = words[1] == "desc"
+		}
+		if !contains(fields, sf.Field) {
+			return nil, fmt.Errorf("cannot sort by `%s`, sortable fields are %s", sf.Field, strings.Join(fields, ", "))
+		}
+		if seen[sf.Field] {
+			return nil, fmt.Errorf("field `%s` is given twice", sf.Field)
+		}
+		seen[sf.Field] = true
+		order = append(order, sf)
+	}
+	return order, nil
+}
+
+// String returns the canonical order_by of o.
+func (o Order) String() string {
+	parts := make([]string, len(o))
+	for i, sf := range o {
+		parts[i] = sf.Field
+		if sf.Desc {
+			parts[i] += " desc"
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// MovieCursor returns the position of mv in a list sorted by o.
+func (o Order) MovieCursor(mv Movie) Cursor {
+	c := Cursor{Key: mv.Id}
+	for _, sf := range o {
+		switch sf.Field {
+		case "name":
+			c.Values = append(c.Values, mv.Name)
+		case "director":
+			c.Values = append(c.Values, mv.Director)
+		case "create_time":
+			c.Values = append(c.Values, asTime(mv.CreateTime))
+		case "update_time":
+			c.Values = append(c.Values, asTime(mv.UpdateTime))
+		}
+	}
+	return c
+}
+
+// UserCursor returns the position of u in a list sorted by o.
+func (o Order) UserCursor(u User) Cursor {
+	c := Cursor{Key: u.Username}
+	for _, sf := range o {
+		switch sf.Field {
+		case "username":
+			c.Values = append(c.Values, u.Username)
+		case "email":
+			c.Values = append(c.Values, u.Email)
+		case "create_time":
+			c.Values = append(c.Values, asTime(u.CreateTime))
+		case "update_time":
+			c.Values = append(c.Values, asTime(u.UpdateTime))
+		}
+	}
+	return c
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package persistence_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AkashGit21/ms-project/lib/persistence"
+)
+
+func TestParseOrder(t *testing.T) {
+	tests := []struct {
+		name        string
+		orderBy     string
+		expected    string
+		expectedErr bool
+	}{
+		{"empty", "", "create_time", false},
+		{"blank", "  ", "create_time", false},
+		{"single_field", "name", "name", false},
+		{"desc", "name desc", "name desc", false},
+		{"canonical", "  director   desc , create_time asc", "director desc, create_time", false},
+		{"uppercase_direction", "name DESC", "", true},
+		{"several_fields", "director desc,create_time asc, name", "director desc, create_time, name", false},
+		{"unknown_field", "summary", "", true},
+		{"unknown_direction", "name descending", "", true},
+		{"too_many_words", "name desc asc", "", true},
+		{"empty_part", "name,", "", true},
+		{"duplicate_field", "name, name desc", "", true},
+	}
+	for _, tcase := range tests {
+		t.Run(tcase.name, func(t *testing.T) {
+			order, err := persistence.ParseOrder(tcase.orderBy, persistence.MovieSortFields)
+			if (err != nil) != tcase.expectedErr {
+				t.Fatalf("ParseOrder: want err %v, got %v", tcase.expectedErr, err)
+			}
+			if err == nil && order.String() != tcase.expected {
+				t.Errorf("ParseOrder: want %q, got %q", tcase.expected, order.String())
+			}
+		})
+	}
+}
+
+func TestOrder_Less(t *testing.T) {
+	order, _ := persistence.ParseOrder("director desc, create_time", persistence.MovieSortFields)
+	jan, feb := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	// In the order of the list
+	cursors := []persistence.Cursor{
+		{Values: []interface{}{"Nolan", time.Time{}}, Key: "id_3"},
+		{Values: []interface{}{"Nolan", jan}, Key: "id_1"},
+		{Values: []interface{}{"Nolan", jan}, Key: "id_2"},
+		{Values: []interface{}{"Nolan", feb}, Key: "id_0"},
+		{Values: []interface{}{"Docter", jan}, Key: "id_4"},
+	}
+	for i := range cursors {
+		for j := range cursors {
+			if got := order.Less(cursors[i], cursors[j]); got != (i < j) {
+				t.Errorf("Less(%v, %v): want %v, got %v", cursors[i], cursors[j], i < j, got)
+			}
+		}
+	}
+	if !order.Less(persistence.Cursor{}, cursors[0]) {
+		t.Error("Less: want the zero cursor before every record")
+	}
+}
+
+func TestOrder_CheckCursor(t *testing.T) {
+	order, _ := persistence.ParseOrder("director desc, create_time", persistence.MovieSortFields)
+
+	tests := []struct {
+		name    string
+		cursor  persistence.Cursor
+		wantErr bool
+	}{
+		{"zero", persistence.Cursor{}, false},
+		{"valid", persistence.Cursor{Values: []interface{}{"Nolan", time.Time{}}, Key: "id_0"}, false},
+		{"missing_value", persistence.Cursor{Values: []interface{}{"Nolan"}, Key: "id_0"}, true},
+		{"extra_value", persistence.Cursor{Values: []interface{}{"Nolan", time.Time{}, "Bale"}, Key: "id_0"}, true},
+		{"no_values", persistence.Cursor{Key: "id_0"}, true},
+		{"swapped_types", persistence.Cursor{Values: []interface{}{time.Time{}, "Nolan"}, Key: "id_0"}, true},
+		{"other_type", persistence.Cursor{Values: []interface{}{"Nolan", 1}, Key: "id_0"}, true},
+	}
+	for _, tcase := range tests {
+		t.Run(tcase.name, func(t *testing.T) {
+			if err := order.CheckCursor(tcase.cursor); (err != nil) != tcase.wantErr {
+				t.Errorf("CheckCursor: want err %v, got %v", tcase.wantErr, err)
+			}
+		})
+	}
+}
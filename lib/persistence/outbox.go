@@ -0,0 +1,15 @@
+package persistence
+
+import "github.com/AkashGit21/ms-project/lib/events"
+
+// UserDomainEvent returns the event of the given type about u. The password
+// hash never leaves the database with an event.
+func UserDomainEvent(typ events.Type, u User) (events.Event, error) {
+	u.Password = ""
+	return events.New(typ, u.Username, u)
+}
+
+// MovieDomainEvent returns the event of the given type about mv.
+func MovieDomainEvent(typ events.Type, mv Movie) (events.Event, error) {
+	return events.New(typ, mv.Id, mv)
+}
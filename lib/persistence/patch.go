@@ -0,0 +1,52 @@
+package persistence
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MoviePatchFields are the fields of a movie which PatchMovieByID may set,
+// named like those of MovieFilterSchema.
+var MoviePatchFields = []string{"name", "summary", "cast", "tags", "director", "writers"}
+
+// PatchMovie returns mv with the given fields set from patch, and with the
+// update time of patch if it has one. It fails for a field which is not one
+// of MoviePatchFields.
+func PatchMovie(mv Movie, patch Movie, fields []string) (Movie, error) {
+	for _, field := range fields {
+		switch field {
+		case "name":
+			mv.Name = patch.Name
+		case "summary":
+			mv.Summary = patch.Summary
+		case "cast":
+			mv.Cast = patch.Cast
+		case "tags":
+			mv.Tags = patch.Tags
+		case "director":
+			mv.Director = patch.Director
+		case "writers":
+			mv.Writers = patch.Writers
+		default:
+			return mv, fmt.Errorf("unknown field %q of a movie", field)
+		}
+	}
+	if patch.UpdateTime != nil {
+		mv.UpdateTime = patch.UpdateTime
+	}
+	return mv, nil
+}
+
+// NormalizeMovieName trims the spaces around a movie name and collapses those
+// within it, so that names differing only by their spacing are alike.
+func NormalizeMovieName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// MovieNameKey is the key under which movie names are unique: the normalized
+// name, lower-cased. PostgreSQL and MongoDB compare the names with lower() and
+// a case-insensitive collation, which agree with it for the normalized names.
+// SQLite stores the key itself, since its NOCASE collation folds ASCII only.
+func MovieNameKey(name string) string {
+	return strings.ToLower(NormalizeMovieName(name))
+}
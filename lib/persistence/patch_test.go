@@ -0,0 +1,41 @@
+package persistence
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+)
+
+func TestPatchMovie(t *testing.T) {
+	mv := Movie{Id: "id_0", Name: "Inception", Summary: "test_summary", Cast: []string{"test_actor"},
+		UpdateTime: &timestamp.Timestamp{Seconds: 1}}
+	patch := Movie{Id: "ignored", Name: "ignored", Summary: "test_summary2"}
+
+	got, err := PatchMovie(mv, patch, []string{"summary", "cast"})
+	if err != nil {
+		t.Fatalf("PatchMovie: unexpected err %v", err)
+	}
+	if got.Id != mv.Id || got.Name != mv.Name || got.Summary != patch.Summary || got.Cast != nil || got.UpdateTime.GetSeconds() != 1 {
+		t.Errorf("PatchMovie: want the given fields patched only, got %+v", got)
+	}
+
+	patch.UpdateTime = &timestamp.Timestamp{Seconds: 2}
+	if got, _ := PatchMovie(mv, patch, nil); got.UpdateTime.GetSeconds() != 2 {
+		t.Errorf("PatchMovie: want the update time of the patch, got %v", got.UpdateTime)
+	}
+	if _, err := PatchMovie(mv, patch, []string{"id"}); err == nil {
+		t.Error("PatchMovie: want an error for an unknown field")
+	}
+}
+
+func TestMovieNameKey(t *testing.T) {
+	if got := NormalizeMovieName("  The \t Dark   Knight "); got != "The Dark Knight" {
+		t.Errorf("NormalizeMovieName: want the spaces collapsed, got %q", got)
+	}
+	if MovieNameKey("The Dark Knight") != MovieNameKey(" the dark  KNIGHT") {
+		t.Error("MovieNameKey: want the names differing by case and spacing alike")
+	}
+	if MovieNameKey("The Dark Knight") == MovieNameKey("The Dark Knight Rises") {
+		t.Error("MovieNameKey: want different names apart")
+	}
+}
@@ -1,25 +1,87 @@
 package persistence
 
 import (
-	identitypb "github.com/AkashGit21/ms-project/internal/grpc/identity"
-	moviepb "github.com/AkashGit21/ms-project/internal/grpc/movie"
+	"context"
+	"time"
+
+	"github.com/AkashGit21/ms-project/lib/filter"
+	"github.com/golang/protobuf/ptypes/timestamp"
 )
 
+// The repositories are implemented by every storage backend. Each method
+// takes the context of the calling request, so that its deadline and
+// cancellation reach the database. Failures are reported with the errors of
+// errors.go. The repositories speak the models of this package only, the
+// services convert them to and from the types of their APIs.
+//
+// The FindAll methods return the records after the given Cursor in a list
+// sorted by the given Order, at most as many as the page size, or all of them
+// if it is 0.
+//
+// Records are deleted softly by setting their delete time, which a nil time
+// clears again. The FindAll methods skip deleted records unless asked to show
+// them, and SearchMovies always skips them. The Purge methods remove for good
+// the records deleted before the given time, and return how many they removed.
+//
+// Every write stores a new etag in the record. The writes to an existing record
+// take the etag the caller read it with, and fail with ErrEtagMismatch if the
+// record changed since. An empty etag writes unconditionally.
+//
+// The backends which implement events.Outbox also record the domain events of
+// their writes, atomically with the writes themselves.
+
+// UserRepository stores the users by their username. FindAllUsers leaves out
+// the password hashes of the users. UpdateByUsername replaces the fields of a
+// user, except for its username, status, creation and deletion time, and keeps
+// its update time unless given a new one. It also keeps the time at which the
+// email of the user was verified, which SetUserEmailVerifyTime sets, unless
+// the email changes.
+type UserRepository interface {
+	AddUser(context.Context, User) ([]byte, error)
+	FindByUsername(context.Context, string) (User, error)
+	FindAllUsers(context.Context, bool, Order, Cursor, int32) ([]User, error)
+	UpdateByUsername(context.Context, string, string, User) ([]byte, error)
+	SetUserDeleteTime(context.Context, string, string, *timestamp.Timestamp) error
+	SetUserEmailVerifyTime(context.Context, string, string, *timestamp.Timestamp) error
+	PurgeUsers(context.Context, time.Time) (int, error)
+	RemoveByUsername(context.Context, string) error
+	CountUsers(context.Context) (int, error)
+}
+
+// CredentialStore checks the passwords of the users against their hashes.
+type CredentialStore interface {
+	Authenticate(context.Context, string, string) (bool, error)
+}
+
+// MovieRepository stores the movies by their ID. FindAllMovies only returns
+// the movies matching the filter, whose fields are those of MovieFilterSchema.
+// PatchMovieByID sets only the given fields of a movie, see PatchMovie, at
+// once without reading the movie first. Movie names are unique regardless of
+// case, see MovieNameKey, and a name already taken fails with an
+// ErrAlreadyExists giving the ID of the movie holding it. FindMovieByName
+// finds the movie holding a name in the same way, deleted or not.
+// The changes of the movies are streamed to the watchers of MovieWatcher.
+type MovieRepository interface {
+	AddMovie(context.Context, Movie) ([]byte, error)
+	FindMovieByID(context.Context, string) (Movie, error)
+	FindMovieByName(context.Context, string) (Movie, error)
+	FindAllMovies(context.Context, filter.Expr, bool, Order, Cursor, int32) ([]Movie, error)
+	UpdateMovieByID(context.Context, string, string, Movie) ([]byte, error)
+	PatchMovieByID(context.Context, string, string, Movie, []string) ([]byte, error)
+	SetMovieDeleteTime(context.Context, string, string, *timestamp.Timestamp) error
+	PurgeMovies(context.Context, time.Time) (int, error)
+	RemoveMovieByID(context.Context, string) error
+	CountMovieRecords(context.Context) (int, error)
+
+	SearchIndex
+	MovieWatcher
+}
+
+// DatabaseHandler is a storage backend holding all the repositories.
 type DatabaseHandler interface {
-	AddUser(User) ([]byte, error)
-	FindByUsername(string) (User, error)
-	FindAllUsers(int, int32) ([]*identitypb.User, error)
-	RemoveByUsername(string) error
-	CountUsers() int
-
-	Authenticate(string, string) bool
-
-	AddMovie(Movie) ([]byte, error)
-	FindMovieByID(string) (Movie, error)
-	FindAllMovies(int, int32) ([]*moviepb.Movie, error)
-	UpdateMovieByID(string, Movie) ([]byte, error)
-	RemoveMovieByID(string) error
-	CountMovieRecords() int
+	UserRepository
+	CredentialStore
+	MovieRepository
 
 	// AddEvent(Event) ([]byte, error)
 	// AddBookingForUser([]byte, Booking) error
@@ -0,0 +1,72 @@
+package postgres
+
+import (
+	"fmt"
+
+	"github.com/AkashGit21/ms-project/lib/filter"
+	"github.com/AkashGit21/ms-project/lib/persistence"
+)
+
+// movieFilterColumns maps the fields of persistence.MovieFilterSchema to columns.
+var movieFilterColumns = map[string]string{
+	"name":        "name",
+	"summary":     "summary",
+	"director":    "director",
+	"active":      "active",
+	"cast":        "cast_members",
+	"writers":     "writers",
+	"tags":        "tags",
+	"create_time": "create_time",
+	"update_time": "update_time",
+}
+
+// whereFilter translates expr into an SQL condition on the given columns,
+// appending its parameters to args.
+func whereFilter(expr filter.Expr, columns map[string]string, args *[]interface{}) (string, error) {
+	switch e := expr.(type) {
+	case filter.And:
+		return joinFilters("AND", e.Left, e.Right, columns, args)
+	case filter.Or:
+		return joinFilters("OR", e.Left, e.Right, columns, args)
+	case filter.Not:
+		cond, err := whereFilter(e.Expr, columns, args)
+		if err != nil {
+			return "", err
+		}
+		return "NOT " + cond, nil
+	case filter.Restriction:
+		column, ok := columns[e.Field]
+		if !ok {
+			return "", fmt.Errorf("field %q cannot be filtered", e.Field)
+		}
+
+		value := e.Value
+		if e.Field == "tags" {
+			value = int64(persistence.Tag_value[e.Value.(string)])
+		}
+		*args = append(*args, value)
+		param := fmt.Sprintf("$%d", len(*args))
+
+		switch e.Op {
+		case filter.Has:
+			return "(" + param + " = ANY(" + column + "))", nil
+		case filter.NotEquals:
+			return "(" + column + " <> " + param + ")", nil
+		default:
+			return "(" + column + " " + string(e.Op) + " " + param + ")", nil
+		}
+	}
+	return "", fmt.Errorf("unsupported filter %v", expr)
+}
+
+func joinFilters(op string, left, right filter.Expr, columns map[string]string, args *[]interface{}) (string, error) {
+	l, err := whereFilter(left, columns, args)
+	if err != nil {
+		return "", err
+	}
+	r, err := whereFilter(right, columns, args)
+	if err != nil {
+		return "", err
+	}
+	return "(" + l + " " + op + " " + r + ")", nil
+}
@@ -0,0 +1,83 @@
+package postgres
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/AkashGit21/ms-project/lib/filter"
+	"github.com/AkashGit21/ms-project/lib/persistence"
+)
+
+func TestPageQuery_filter(t *testing.T) {
+	expr, err := filter.Parse(`director = "Nolan" AND (tags:Action OR NOT cast:Bale) AND create_time > "2021-01-01"`,
+		persistence.MovieFilterSchema)
+	if err != nil {
+		t.Fatalf("Parse: unexpected err %v", err)
+	}
+
+	query, args, err := pageQuery(`SELECT id FROM movies`, "id", movieSortColumns, movieFilterColumns, expr,
+		false, persistence.DefaultOrder, persistence.Cursor{Values: []interface{}{time.Time{}}, Key: "id_1"}, 12)
+	if err != nil {
+		t.Fatalf("pageQuery: unexpected err %v", err)
+	}
+
+	want := `SELECT id FROM movies WHERE delete_time IS NULL AND (((director = $2) AND (($3 = ANY(tags)) OR NOT ($4 = ANY(cast_members)))) AND (create_time > $5))` +
+		` AND (COALESCE(create_time, '-infinity'), id) > (COALESCE($6::timestamptz, '-infinity'), $7)` +
+		` ORDER BY COALESCE(create_time, '-infinity'), id LIMIT $1`
+	if query != want {
+		t.Errorf("pageQuery:\n\texpected: %v \n\tactual: %v", want, query)
+	}
+	wantArgs := "[{12 true} Nolan 1 Bale 2021-01-01 00:00:00 +0000 UTC {0001-01-01 00:00:00 +0000 UTC false} id_1]"
+	if fmt.Sprint(args) != wantArgs {
+		t.Errorf("pageQuery:\n\texpected args: %v \n\tactual args: %v", wantArgs, args)
+	}
+}
+
+func TestPageQuery_order(t *testing.T) {
+	order, err := persistence.ParseOrder("director desc, create_time", persistence.MovieSortFields)
+	if err != nil {
+		t.Fatalf("ParseOrder: unexpected err %v", err)
+	}
+
+	after := persistence.Cursor{Values: []interface{}{"Nolan", time.Unix(1609459200, 0).UTC()}, Key: "id_1"}
+	query, args, err := pageQuery(`SELECT id FROM movies`, "id", movieSortColumns, movieFilterColumns, nil,
+		true, order, after, 0)
+	if err != nil {
+		t.Fatalf("pageQuery: unexpected err %v", err)
+	}
+
+	want := `SELECT id FROM movies WHERE (director < $2 OR (director = $2 AND ` +
+		`(COALESCE(create_time, '-infinity') > COALESCE($3::timestamptz, '-infinity') OR ` +
+		`(COALESCE(create_time, '-infinity') = COALESCE($3::timestamptz, '-infinity') AND id > $4))))` +
+		` ORDER BY director DESC, COALESCE(create_time, '-infinity'), id LIMIT $1`
+	if query != want {
+		t.Errorf("pageQuery:\n\texpected: %v \n\tactual: %v", want, query)
+	}
+	wantArgs := "[{0 false} Nolan {2021-01-01 00:00:00 +0000 UTC true} id_1]"
+	if fmt.Sprint(args) != wantArgs {
+		t.Errorf("pageQuery:\n\texpected args: %v \n\tactual args: %v", wantArgs, args)
+	}
+
+	if _, _, err := pageQuery(`SELECT id FROM movies`, "id", movieSortColumns, movieFilterColumns, nil,
+		true, order, persistence.Cursor{Key: "id_1"}, 0); err == nil {
+		t.Error("pageQuery: want error for cursor of another order")
+	}
+}
+
+func TestMoviePatch(t *testing.T) {
+	mv := persistence.Movie{Name: "Inception", Cast: []string{"Bale"}}
+	set, args, err := moviePatch(mv, []string{"name", "cast"}, []interface{}{"id_0", ""})
+	if err != nil {
+		t.Fatalf("moviePatch: unexpected err %v", err)
+	}
+	if want := "name = $3, cast_members = $4, "; set != want {
+		t.Errorf("moviePatch:\n\texpected: %v \n\tactual: %v", want, set)
+	}
+	if len(args) != 4 || args[2] != "Inception" {
+		t.Errorf("moviePatch: want the values after the given args, got %v", args)
+	}
+	if _, _, err := moviePatch(mv, []string{"active"}, nil); err == nil {
+		t.Error("moviePatch: want an error for an unknown field")
+	}
+}
@@ -0,0 +1,128 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationLockID is the key of the advisory lock which serialises migrations,
+// so that several replicas starting at once do not race each other.
+const migrationLockID = 727_140_001
+
+// migration is a single versioned schema change. The version is the numeric
+// prefix of the file name, e.g. 0002 for `0002_create_movies.sql`.
+type migration struct {
+	version int
+	name    string
+	sql     string
+}
+
+// loadMigrations reads the embedded migrations sorted by version.
+func loadMigrations(fsys fs.FS) ([]migration, error) {
+	files, err := fs.Glob(fsys, "migrations/*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	var migrations []migration
+	seen := map[int]string{}
+	for _, file := range files {
+		name := path.Base(file)
+		prefix := strings.SplitN(name, "_", 2)[0]
+		version, err := strconv.Atoi(prefix)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %q: file name should start with a positive version number", name)
+		}
+		if other, ok := seen[version]; ok {
+			return nil, fmt.Errorf("migrations %q and %q share version %d", other, name, version)
+		}
+		seen[version] = name
+
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, migration{version, name, string(content)})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].version < migrations[j].version
+	})
+	return migrations, nil
+}
+
+// migrate applies every embedded migration newer than the current schema version.
+func migrate(ctx context.Context, db *sql.DB) error {
+	migrations, err := loadMigrations(migrationFiles)
+	if err != nil {
+		return err
+	}
+	return applyMigrations(ctx, db, migrations)
+}
+
+// applyMigrations applies the migrations newer than the current schema version.
+// Each migration runs in its own transaction together with its bookkeeping row.
+func applyMigrations(ctx context.Context, db *sql.DB, migrations []migration) error {
+	// Advisory locks belong to a session, hence pin a single connection
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockID); err != nil {
+		return fmt.Errorf("unable to acquire migration lock: %v", err)
+	}
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockID)
+
+	_, err = conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		name       TEXT NOT NULL,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`)
+	if err != nil {
+		return err
+	}
+
+	var current int
+	err = conn.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, m.sql); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %q failed: %v", m.name, err)
+		}
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, m.version, m.name); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+		log.Printf("Applied migration %s", m.name)
+	}
+	return nil
+}
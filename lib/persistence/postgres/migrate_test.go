@@ -0,0 +1,61 @@
+package postgres
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := loadMigrations(migrationFiles)
+	if err != nil {
+		t.Fatalf("loadMigrations: unexpected err %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("loadMigrations: want embedded migrations, got none")
+	}
+	for i, m := range migrations {
+		if m.version != i+1 {
+			t.Errorf("loadMigrations: want version %d at position %d, got %d (%s)", i+1, i, m.version, m.name)
+		}
+	}
+}
+
+func TestLoadMigrations_sorted(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/0010_third.sql":  {Data: []byte("SELECT 3;")},
+		"migrations/0002_second.sql": {Data: []byte("SELECT 2;")},
+		"migrations/0001_first.sql":  {Data: []byte("SELECT 1;")},
+	}
+	migrations, err := loadMigrations(fsys)
+	if err != nil {
+		t.Fatalf("loadMigrations: unexpected err %v", err)
+	}
+
+	want := []int{1, 2, 10}
+	for i, m := range migrations {
+		if m.version != want[i] {
+			t.Errorf("loadMigrations: want version %d, got %d", want[i], m.version)
+		}
+	}
+}
+
+func TestLoadMigrations_invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+	}{
+		{"no_version", fstest.MapFS{"migrations/create_users.sql": {}}},
+		{"zero_version", fstest.MapFS{"migrations/0000_create_users.sql": {}}},
+		{"duplicate_version", fstest.MapFS{
+			"migrations/0001_create_users.sql":  {},
+			"migrations/0001_create_movies.sql": {},
+		}},
+	}
+	for _, tcase := range tests {
+		t.Run(tcase.name, func(t *testing.T) {
+			if _, err := loadMigrations(tcase.fsys); err == nil {
+				t.Error("loadMigrations: want error, got nil")
+			}
+		})
+	}
+}
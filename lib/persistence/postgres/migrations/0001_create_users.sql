@@ -0,0 +1,23 @@
+-- Users of the identity service. Username and email are unique per user.
+CREATE TABLE users (
+    seq                  BIGSERIAL,
+    id                   TEXT PRIMARY KEY,
+    username             TEXT NOT NULL,
+    email                TEXT NOT NULL,
+    password             TEXT NOT NULL,
+    role                 INTEGER NOT NULL DEFAULT 0,
+    active               BOOLEAN NOT NULL DEFAULT FALSE,
+    first_name           TEXT NOT NULL DEFAULT '',
+    last_name            TEXT,
+    create_time          TIMESTAMPTZ,
+    update_time          TIMESTAMPTZ,
+    age                  INTEGER,
+    height_in_cms        DOUBLE PRECISION,
+    nickname             TEXT,
+    enable_notifications BOOLEAN,
+
+    CONSTRAINT users_username_key UNIQUE (username),
+    CONSTRAINT users_email_key UNIQUE (email)
+);
+
+CREATE INDEX users_seq_idx ON users (seq);
@@ -0,0 +1,18 @@
+-- Movies of the catalogue. Movie names are unique.
+CREATE TABLE movies (
+    seq          BIGSERIAL,
+    id           TEXT PRIMARY KEY,
+    name         TEXT NOT NULL,
+    summary      TEXT NOT NULL DEFAULT '',
+    cast_members TEXT[] NOT NULL DEFAULT '{}',
+    tags         INTEGER[] NOT NULL DEFAULT '{}',
+    director     TEXT NOT NULL DEFAULT '',
+    writers      TEXT[] NOT NULL DEFAULT '{}',
+    active       BOOLEAN NOT NULL DEFAULT FALSE,
+    create_time  TIMESTAMPTZ,
+    update_time  TIMESTAMPTZ,
+
+    CONSTRAINT movies_name_key UNIQUE (name)
+);
+
+CREATE INDEX movies_seq_idx ON movies (seq);
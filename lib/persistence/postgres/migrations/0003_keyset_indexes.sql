@@ -0,0 +1,7 @@
+-- Lists are paged by creation time and unique key, see persistence.Cursor.
+-- Records without creation time come first.
+CREATE INDEX users_create_time_username_idx ON users ((COALESCE(create_time, '-infinity')), username);
+CREATE INDEX movies_create_time_id_idx ON movies ((COALESCE(create_time, '-infinity')), id);
+
+DROP INDEX users_seq_idx;
+DROP INDEX movies_seq_idx;
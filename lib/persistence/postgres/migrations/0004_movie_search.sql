@@ -0,0 +1,13 @@
+-- Full-text search of movies, see SearchMovies. The words of the name weigh
+-- the most, then those of the director and cast, the writers and the summary.
+-- Unlike array_to_string, the function is declared immutable so that it can
+-- be indexed.
+CREATE FUNCTION movie_search_vector(name TEXT, director TEXT, cast_members TEXT[], writers TEXT[], summary TEXT)
+RETURNS tsvector LANGUAGE sql IMMUTABLE AS $$
+    SELECT setweight(to_tsvector('english', name), 'A') ||
+           setweight(to_tsvector('english', director || ' ' || array_to_string(cast_members, ' ')), 'B') ||
+           setweight(to_tsvector('english', array_to_string(writers, ' ')), 'C') ||
+           setweight(to_tsvector('english', summary), 'D')
+$$;
+
+CREATE INDEX movies_search_idx ON movies USING GIN (movie_search_vector(name, director, cast_members, writers, summary));
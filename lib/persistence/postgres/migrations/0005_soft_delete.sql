@@ -0,0 +1,7 @@
+-- Deleted records are kept until purged, see persistence.DatabaseHandler.
+ALTER TABLE users ADD COLUMN delete_time TIMESTAMPTZ;
+ALTER TABLE movies ADD COLUMN delete_time TIMESTAMPTZ;
+
+-- The purger looks for the records deleted before its retention period
+CREATE INDEX users_delete_time_idx ON users (delete_time) WHERE delete_time IS NOT NULL;
+CREATE INDEX movies_delete_time_idx ON movies (delete_time) WHERE delete_time IS NOT NULL;
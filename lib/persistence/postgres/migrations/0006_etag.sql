@@ -0,0 +1,6 @@
+-- Writes are checked against the etag the caller read, see persistence.DatabaseHandler.
+ALTER TABLE users ADD COLUMN etag TEXT NOT NULL DEFAULT '';
+ALTER TABLE movies ADD COLUMN etag TEXT NOT NULL DEFAULT '';
+
+UPDATE users SET etag = substr(md5(random()::text), 1, 16);
+UPDATE movies SET etag = substr(md5(random()::text), 1, 16);
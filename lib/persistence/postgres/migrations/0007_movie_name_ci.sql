@@ -0,0 +1,4 @@
+-- Movie names are unique regardless of case, see persistence.MovieNameKey.
+-- This fails if some movies already have names differing by case only,
+-- which must then be renamed first.
+CREATE UNIQUE INDEX movies_name_lower_key ON movies (lower(name));
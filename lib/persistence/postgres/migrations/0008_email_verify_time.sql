@@ -0,0 +1,5 @@
+-- The time at which the email of a user was verified, see persistence.UserRepository.
+ALTER TABLE users ADD COLUMN email_verify_time TIMESTAMPTZ;
+
+-- The users created before emails were verified are trusted as verified
+UPDATE users SET email_verify_time = COALESCE(create_time, now());
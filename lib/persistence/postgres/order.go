@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AkashGit21/ms-project/lib/persistence"
+)
+
+// movieSortColumns and userSortColumns map the fields of persistence.MovieSortFields
+// and persistence.UserSortFields to the expressions lists are sorted by. Missing
+// timestamps sort before every other time, like in the other backends.
+var (
+	movieSortColumns = map[string]string{
+		"name":        "name",
+		"director":    "director",
+		"create_time": "COALESCE(create_time, '-infinity')",
+		"update_time": "COALESCE(update_time, '-infinity')",
+	}
+	userSortColumns = map[string]string{
+		"username":    "username",
+		"email":       "email",
+		"create_time": "COALESCE(create_time, '-infinity')",
+		"update_time": "COALESCE(update_time, '-infinity')",
+	}
+)
+
+// orderBy returns the ORDER BY clause of a list sorted by order, and then by the key column.
+func orderBy(key string, columns map[string]string, order persistence.Order) string {
+	var exprs []string
+	for _, sf := range order {
+		expr := columns[sf.Field]
+		if sf.Desc {
+			expr += " DESC"
+		}
+		exprs = append(exprs, expr)
+	}
+	return strings.Join(append(exprs, key), ", ")
+}
+
+// afterCursor returns the condition matching the records after the cursor in
+// a list sorted by order and then by the key column, appending its parameters
+// to args. Ascending orders are compared as a row, which the indexes support.
+func afterCursor(key string, columns map[string]string, order persistence.Order,
+	after persistence.Cursor, args *[]interface{}) (string, error) {
+	if len(after.Values) != len(order) {
+		return "", fmt.Errorf("cursor has %d values for %d sort fields", len(after.Values), len(order))
+	}
+
+	ascending := true
+	for _, sf := range order {
+		ascending = ascending && !sf.Desc
+	}
+	if ascending {
+		var exprs, params []string
+		for i, sf := range order {
+			exprs = append(exprs, columns[sf.Field])
+			params = append(params, cursorParam(after.Values[i], args))
+		}
+		exprs = append(exprs, key)
+		params = append(params, cursorParam(after.Key, args))
+		return fmt.Sprintf("(%s) > (%s)", strings.Join(exprs, ", "), strings.Join(params, ", ")), nil
+	}
+
+	// (a < $1 OR (a = $1 AND (b > $2 OR (b = $2 AND key > $3))))
+	var sb strings.Builder
+	for i, sf := range order {
+		op := ">"
+		if sf.Desc {
+			op = "<"
+		}
+		expr, param := columns[sf.Field], cursorParam(after.Values[i], args)
+		fmt.Fprintf(&sb, "(%s %s %s OR (%s = %s AND ", expr, op, param, expr, param)
+	}
+	fmt.Fprintf(&sb, "%s > %s", key, cursorParam(after.Key, args))
+	sb.WriteString(strings.Repeat("))", len(order)))
+	return sb.String(), nil
+}
+
+// cursorParam appends a value of a cursor to args and returns its placeholder.
+func cursorParam(value interface{}, args *[]interface{}) string {
+	if t, ok := value.(time.Time); ok {
+		*args = append(*args, sql.NullTime{Time: t, Valid: !t.IsZero()})
+		return fmt.Sprintf("COALESCE($%d::timestamptz, '-infinity')", len(*args))
+	}
+	*args = append(*args, value)
+	return fmt.Sprintf("$%d", len(*args))
+}
@@ -0,0 +1,723 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/AkashGit21/ms-project/lib/filter"
+	"github.com/AkashGit21/ms-project/lib/persistence"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testConnection returns the connection to the database given by POSTGRES_TEST_URL,
+// after dropping everything in its public schema. The tests are skipped without it.
+func testConnection(t *testing.T) string {
+	connection := os.Getenv("POSTGRES_TEST_URL")
+	if connection == "" {
+		t.Skip("POSTGRES_TEST_URL is not set")
+	}
+
+	db, err := sql.Open("postgres", connection)
+	if err != nil {
+		t.Fatalf("Open: unexpected err %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(`DROP SCHEMA public CASCADE; CREATE SCHEMA public`); err != nil {
+		t.Fatalf("Exec: unexpected err resetting the schema %v", err)
+	}
+	return connection
+}
+
+func newTestLayer(t *testing.T) *PostgresLayer {
+	dbhandler, err := NewPostgresLayer(testConnection(t))
+	if err != nil {
+		t.Fatalf("NewPostgresLayer: unexpected err %v", err)
+	}
+	pgLayer := dbhandler.(*PostgresLayer)
+	t.Cleanup(func() { pgLayer.db.Close() })
+	return pgLayer
+}
+
+func TestMigrate_reopen(t *testing.T) {
+	ctx := context.Background()
+	pgLayer := newTestLayer(t)
+
+	if _, err := pgLayer.AddMovie(ctx, persistence.Movie{Name: "test_movie"}); err != nil {
+		t.Fatalf("AddMovie: unexpected err %v", err)
+	}
+
+	// Connecting again must neither fail nor lose data
+	dbhandler, err := NewPostgresLayer(os.Getenv("POSTGRES_TEST_URL"))
+	if err != nil {
+		t.Fatalf("NewPostgresLayer: unexpected err on reopen %v", err)
+	}
+	defer dbhandler.(*PostgresLayer).db.Close()
+	if got, _ := dbhandler.CountMovieRecords(ctx); got != 1 {
+		t.Errorf("CountMovieRecords: want 1, got %d", got)
+	}
+	if hits, err := dbhandler.SearchMovies(ctx, "test_movie", 0, 0); err != nil || len(hits) != 1 {
+		t.Errorf("SearchMovies: want 1 hit after reopen, got %v, %v", hits, err)
+	}
+}
+
+func TestMigrate_existingData(t *testing.T) {
+	ctx := context.Background()
+	connection := testConnection(t)
+
+	db, err := sql.Open("postgres", connection)
+	if err != nil {
+		t.Fatalf("Open: unexpected err %v", err)
+	}
+	defer db.Close()
+
+	// The schema and data as they were before the last migrations
+	migrations, err := loadMigrations(migrationFiles)
+	if err != nil {
+		t.Fatalf("loadMigrations: unexpected err %v", err)
+	}
+	if err := applyMigrations(ctx, db, migrations[:6]); err != nil {
+		t.Fatalf("applyMigrations: unexpected err %v", err)
+	}
+	_, err = db.ExecContext(ctx, `INSERT INTO users (id, username, email, password, create_time) VALUES
+		('id_0', 'test_username', 'test_email@domain.com', '', '2021-01-01T00:00:00Z'),
+		('id_1', 'test_username2', 'other@domain.com', '', NULL)`)
+	if err != nil {
+		t.Fatalf("ExecContext: unexpected err %v", err)
+	}
+	if _, err := db.ExecContext(ctx, `INSERT INTO movies (id, name) VALUES ('id_0', 'Inception'), ('id_1', 'INCEPTION')`); err != nil {
+		t.Fatalf("ExecContext: unexpected err %v", err)
+	}
+
+	// Names differing by case only fail 0007, which leaves the schema as it was
+	if err := migrate(ctx, db); err == nil {
+		t.Fatal("migrate: want an error for names differing by case only")
+	}
+	var version int
+	if err := db.QueryRowContext(ctx, `SELECT MAX(version) FROM schema_migrations`).Scan(&version); err != nil || version != 6 {
+		t.Errorf("schema_migrations: want version 6, got %d, %v", version, err)
+	}
+
+	if _, err := db.ExecContext(ctx, `UPDATE movies SET name = 'Inception 2' WHERE id = 'id_1'`); err != nil {
+		t.Fatalf("ExecContext: unexpected err %v", err)
+	}
+	dbhandler, err := NewPostgresLayer(connection)
+	if err != nil {
+		t.Fatalf("NewPostgresLayer: unexpected err %v", err)
+	}
+	defer dbhandler.(*PostgresLayer).db.Close()
+
+	// The existing users are trusted as verified, from their creation time if any
+	found, err := dbhandler.FindByUsername(ctx, "test_username")
+	if err != nil || found.EmailVerifyTime.GetSeconds() != 1609459200 {
+		t.Errorf("FindByUsername: want the email verified at creation, got %v, %v", found.EmailVerifyTime, err)
+	}
+	if found, err := dbhandler.FindByUsername(ctx, "test_username2"); err != nil || found.EmailVerifyTime == nil {
+		t.Errorf("FindByUsername: want the email verified, got %v, %v", found.EmailVerifyTime, err)
+	}
+	if _, err := dbhandler.AddMovie(ctx, persistence.Movie{Id: "id_2", Name: "inception"}); !errors.Is(err, persistence.ErrAlreadyExists) {
+		t.Errorf("AddMovie: want ErrAlreadyExists for a name differing by case only, got %v", err)
+	}
+}
+
+func TestUsers(t *testing.T) {
+	ctx := context.Background()
+	pgLayer := newTestLayer(t)
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("test_pwd"), bcrypt.MinCost)
+	lastName := "test_last"
+	user := persistence.User{
+		Username:   "test_username",
+		Email:      "test_email@domain.com",
+		Password:   string(hash),
+		Role:       persistence.Role_ADMIN,
+		Active:     true,
+		LastName:   &lastName,
+		CreateTime: ptypes.TimestampNow(),
+	}
+	if _, err := pgLayer.AddUser(ctx, user); err != nil {
+		t.Fatalf("AddUser: unexpected err %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		user    persistence.User
+		wantErr bool
+	}{
+		{"duplicate_username", persistence.User{Username: "test_username", Email: "other@domain.com"}, true},
+		{"duplicate_email", persistence.User{Username: "test_username2", Email: "test_email@domain.com"}, true},
+		{"added_user", persistence.User{Username: "test_username2", Email: "other@domain.com"}, false},
+	}
+	for _, tcase := range tests {
+		t.Run(tcase.name, func(t *testing.T) {
+			_, err := pgLayer.AddUser(ctx, tcase.user)
+			if (err != nil) != tcase.wantErr {
+				t.Errorf("AddUser: want err %v, got %v", tcase.wantErr, err)
+			}
+		})
+	}
+
+	found, err := pgLayer.FindByUsername(ctx, "test_username")
+	if err != nil {
+		t.Fatalf("FindByUsername: unexpected err %v", err)
+	}
+	if found.Role != user.Role || *found.LastName != lastName || !found.CreateTime.AsTime().Equal(user.CreateTime.AsTime()) {
+		t.Errorf("FindByUsername: want %v, got %v", user, found)
+	}
+
+	if got, _ := pgLayer.CountUsers(ctx); got != 2 {
+		t.Errorf("CountUsers: want 2, got %d", got)
+	}
+	if ok, err := pgLayer.Authenticate(ctx, "test_username", "test_pwd"); !ok || err != nil {
+		t.Errorf("Authenticate: want true for correct password, got %v, %v", ok, err)
+	}
+	if ok, _ := pgLayer.Authenticate(ctx, "test_username", "bad_pwd"); ok {
+		t.Error("Authenticate: want false for wrong password")
+	}
+	if _, err := pgLayer.Authenticate(ctx, "missing_user", "test_pwd"); !errors.Is(err, persistence.ErrNotFound) {
+		t.Errorf("Authenticate: want ErrNotFound for missing user, got %v", err)
+	}
+
+	// Users without creation time come first
+	users, err := pgLayer.FindAllUsers(ctx, false, persistence.DefaultOrder, persistence.Cursor{Values: []interface{}{time.Time{}}, Key: "test_username2"}, 12)
+	if err != nil || len(users) != 1 || users[0].Username != "test_username" {
+		t.Errorf("FindAllUsers: unexpected result %v, %v", users, err)
+	}
+
+	if err := pgLayer.RemoveByUsername(ctx, "test_username"); err != nil {
+		t.Errorf("RemoveByUsername: unexpected err %v", err)
+	}
+	if err := pgLayer.RemoveByUsername(ctx, "test_username"); !errors.Is(err, persistence.ErrNotFound) {
+		t.Errorf("RemoveByUsername: want ErrNotFound for missing user, got %v", err)
+	}
+}
+
+func TestUpdateByUsername(t *testing.T) {
+	ctx := context.Background()
+	pgLayer := newTestLayer(t)
+
+	lastName := "test_last"
+	user := persistence.User{Username: "test_username", Email: "test_email@domain.com", Password: "test_hash",
+		Role: persistence.Role_NORMAL, Active: true, LastName: &lastName, CreateTime: &timestamp.Timestamp{Seconds: 1}}
+	if _, err := pgLayer.AddUser(ctx, user); err != nil {
+		t.Fatalf("AddUser: unexpected err %v", err)
+	}
+	if _, err := pgLayer.AddUser(ctx, persistence.User{Username: "test_username2", Email: "other@domain.com"}); err != nil {
+		t.Fatalf("AddUser: unexpected err %v", err)
+	}
+	stored, _ := pgLayer.FindByUsername(ctx, "test_username")
+
+	nickname := "test_nick"
+	update := persistence.User{Username: "ignored", Email: "new_email@domain.com", Password: "new_hash",
+		Role: persistence.Role_ADMIN, FirstName: "test_first", Nickname: &nickname, UpdateTime: &timestamp.Timestamp{Seconds: 2}}
+	if _, err := pgLayer.UpdateByUsername(ctx, "test_username", stored.Etag, update); err != nil {
+		t.Fatalf("UpdateByUsername: unexpected err %v", err)
+	}
+	found, err := pgLayer.FindByUsername(ctx, "test_username")
+	if err != nil || found.Email != update.Email || found.Password != update.Password || found.Role != update.Role ||
+		found.FirstName != update.FirstName || found.Nickname == nil || *found.Nickname != nickname || found.LastName != nil {
+		t.Errorf("FindByUsername: want the fields of the update, got %+v, %v", found, err)
+	}
+	// The identity, status and creation time are kept
+	if !found.Active || found.CreateTime.GetSeconds() != 1 || found.UpdateTime.GetSeconds() != 2 || found.Etag == stored.Etag {
+		t.Errorf("FindByUsername: want the status and creation time kept with a new etag, got %+v", found)
+	}
+
+	tests := []struct {
+		name    string
+		uname   string
+		etag    string
+		user    persistence.User
+		wantErr error
+	}{
+		{"stale_etag", "test_username", stored.Etag, update, persistence.ErrEtagMismatch},
+		{"missing_user", "missing_user", "", update, persistence.ErrNotFound},
+		{"duplicate_email", "test_username", "", persistence.User{Email: "other@domain.com"}, persistence.ErrAlreadyExists},
+	}
+	for _, tcase := range tests {
+		t.Run(tcase.name, func(t *testing.T) {
+			if _, err := pgLayer.UpdateByUsername(ctx, tcase.uname, tcase.etag, tcase.user); !errors.Is(err, tcase.wantErr) {
+				t.Errorf("UpdateByUsername: want %v, got %v", tcase.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestSetUserEmailVerifyTime(t *testing.T) {
+	ctx := context.Background()
+	pgLayer := newTestLayer(t)
+
+	if _, err := pgLayer.AddUser(ctx, persistence.User{Username: "test_username", Email: "test_email@domain.com"}); err != nil {
+		t.Fatalf("AddUser: unexpected err %v", err)
+	}
+	stored, _ := pgLayer.FindByUsername(ctx, "test_username")
+	if stored.EmailVerifyTime != nil {
+		t.Errorf("FindByUsername: want the email not verified, got %v", stored.EmailVerifyTime)
+	}
+
+	if err := pgLayer.SetUserEmailVerifyTime(ctx, "test_username", stored.Etag, &timestamp.Timestamp{Seconds: 2}); err != nil {
+		t.Fatalf("SetUserEmailVerifyTime: unexpected err %v", err)
+	}
+	if err := pgLayer.SetUserEmailVerifyTime(ctx, "test_username", stored.Etag, nil); !errors.Is(err, persistence.ErrEtagMismatch) {
+		t.Errorf("SetUserEmailVerifyTime: want %v, got %v", persistence.ErrEtagMismatch, err)
+	}
+	if err := pgLayer.SetUserEmailVerifyTime(ctx, "missing_user", "", nil); !errors.Is(err, persistence.ErrNotFound) {
+		t.Errorf("SetUserEmailVerifyTime: want %v, got %v", persistence.ErrNotFound, err)
+	}
+	verified, _ := pgLayer.FindByUsername(ctx, "test_username")
+	if verified.EmailVerifyTime.GetSeconds() != 2 || verified.Etag == stored.Etag {
+		t.Errorf("FindByUsername: want the email verified with a new etag, got %+v", verified)
+	}
+
+	// The verification is kept along with the email, and cleared with it
+	if _, err := pgLayer.UpdateByUsername(ctx, "test_username", "", persistence.User{Email: verified.Email, FirstName: "test_first"}); err != nil {
+		t.Fatalf("UpdateByUsername: unexpected err %v", err)
+	}
+	if found, _ := pgLayer.FindByUsername(ctx, "test_username"); found.EmailVerifyTime.GetSeconds() != 2 {
+		t.Errorf("UpdateByUsername: want the verification kept, got %v", found.EmailVerifyTime)
+	}
+	if _, err := pgLayer.UpdateByUsername(ctx, "test_username", "", persistence.User{Email: "new_email@domain.com"}); err != nil {
+		t.Fatalf("UpdateByUsername: unexpected err %v", err)
+	}
+	if found, _ := pgLayer.FindByUsername(ctx, "test_username"); found.EmailVerifyTime != nil {
+		t.Errorf("UpdateByUsername: want the verification cleared, got %v", found.EmailVerifyTime)
+	}
+}
+
+func TestMovies(t *testing.T) {
+	ctx := context.Background()
+	pgLayer := newTestLayer(t)
+
+	for i := 0; i < 5; i++ {
+		mv := persistence.Movie{
+			Id:      fmt.Sprintf("id_%d", i),
+			Name:    fmt.Sprintf("test_movie_%d", i),
+			Cast:    []string{"test_cast1", "test_cast2"},
+			Tags:    []persistence.Tag{persistence.Tag_Action, persistence.Tag_Comedy},
+			Writers: []string{"test_writer"},
+		}
+		if _, err := pgLayer.AddMovie(ctx, mv); err != nil {
+			t.Fatalf("AddMovie: unexpected err %v", err)
+		}
+	}
+	_, err := pgLayer.AddMovie(ctx, persistence.Movie{Name: "test_movie_0"})
+	var perr *persistence.Error
+	if !errors.As(err, &perr) || perr.Kind != persistence.ErrAlreadyExists || perr.Field != "name" {
+		t.Errorf("AddMovie: want ErrAlreadyExists on name for duplicate name, got %v", err)
+	}
+
+	movies, err := pgLayer.FindAllMovies(ctx, nil, false, persistence.DefaultOrder, persistence.Cursor{Values: []interface{}{time.Time{}}, Key: "id_2"}, 12)
+	if err != nil || len(movies) != 2 || movies[0].Id != "id_3" {
+		t.Errorf("FindAllMovies: unexpected result %v, %v", movies, err)
+	}
+
+	found, err := pgLayer.FindMovieByID(ctx, "id_1")
+	if err != nil {
+		t.Fatalf("FindMovieByID: unexpected err %v", err)
+	}
+	wantTags := []persistence.Tag{persistence.Tag_Action, persistence.Tag_Comedy}
+	if !reflect.DeepEqual(found.Tags, wantTags) || !reflect.DeepEqual(found.Cast, []string{"test_cast1", "test_cast2"}) {
+		t.Errorf("FindMovieByID: unexpected movie %v", found)
+	}
+
+	if _, err := pgLayer.UpdateMovieByID(ctx, "id_1", "", persistence.Movie{Name: "test_movie_2"}); !errors.Is(err, persistence.ErrAlreadyExists) {
+		t.Errorf("UpdateMovieByID: want ErrAlreadyExists for duplicate name, got %v", err)
+	}
+	if _, err := pgLayer.UpdateMovieByID(ctx, "missing", "", persistence.Movie{Name: "test_movie"}); !errors.Is(err, persistence.ErrNotFound) {
+		t.Errorf("UpdateMovieByID: want ErrNotFound for missing movie, got %v", err)
+	}
+	if _, err := pgLayer.UpdateMovieByID(ctx, "id_1", "", persistence.Movie{Name: "test_movie_renamed"}); err != nil {
+		t.Errorf("UpdateMovieByID: unexpected err %v", err)
+	}
+	if found, _ = pgLayer.FindMovieByID(ctx, "id_1"); found.Name != "test_movie_renamed" || found.Cast != nil {
+		t.Errorf("UpdateMovieByID: unexpected stored movie %v", found)
+	}
+
+	if err := pgLayer.RemoveMovieByID(ctx, "id_1"); err != nil {
+		t.Errorf("RemoveMovieByID: unexpected err %v", err)
+	}
+	if got, _ := pgLayer.CountMovieRecords(ctx); got != 4 {
+		t.Errorf("CountMovieRecords: want 4, got %d", got)
+	}
+}
+
+func TestPatchMovieByID(t *testing.T) {
+	ctx := context.Background()
+	pgLayer := newTestLayer(t)
+
+	mv := persistence.Movie{Id: "id_0", Name: "Inception", Summary: "test_summary", Cast: []string{"test_actor"},
+		Tags: []persistence.Tag{persistence.Tag_Action}, Director: "test_director"}
+	if _, err := pgLayer.AddMovie(ctx, mv); err != nil {
+		t.Fatalf("AddMovie: unexpected err %v", err)
+	}
+	if _, err := pgLayer.AddMovie(ctx, persistence.Movie{Id: "id_1", Name: "Interstellar"}); err != nil {
+		t.Fatalf("AddMovie: unexpected err %v", err)
+	}
+	stored, _ := pgLayer.FindMovieByID(ctx, "id_0")
+
+	// Only the given fields change, even to empty values
+	patch := persistence.Movie{Name: "ignored", Summary: "test_summary2", Tags: []persistence.Tag{persistence.Tag_Comedy},
+		UpdateTime: &timestamp.Timestamp{Seconds: 2}}
+	if _, err := pgLayer.PatchMovieByID(ctx, "id_0", stored.Etag, patch, []string{"summary", "tags", "cast"}); err != nil {
+		t.Fatalf("PatchMovieByID: unexpected err %v", err)
+	}
+	found, err := pgLayer.FindMovieByID(ctx, "id_0")
+	if err != nil || found.Name != mv.Name || found.Summary != patch.Summary || len(found.Cast) != 0 ||
+		len(found.Tags) != 1 || found.Tags[0] != persistence.Tag_Comedy || found.Director != mv.Director ||
+		found.UpdateTime.GetSeconds() != 2 || found.Etag == stored.Etag {
+		t.Errorf("FindMovieByID: want the given fields patched, got %+v, %v", found, err)
+	}
+
+	tests := []struct {
+		name    string
+		id      string
+		etag    string
+		fields  []string
+		wantErr error
+	}{
+		{"stale_etag", "id_0", stored.Etag, []string{"summary"}, persistence.ErrEtagMismatch},
+		{"missing_movie", "missing", "", []string{"summary"}, persistence.ErrNotFound},
+		{"duplicate_name", "id_0", "", []string{"name"}, persistence.ErrAlreadyExists},
+	}
+	for _, tcase := range tests {
+		t.Run(tcase.name, func(t *testing.T) {
+			patch := persistence.Movie{Name: "Interstellar", Summary: "test_summary3"}
+			if _, err := pgLayer.PatchMovieByID(ctx, tcase.id, tcase.etag, patch, tcase.fields); !errors.Is(err, tcase.wantErr) {
+				t.Errorf("PatchMovieByID: want %v, got %v", tcase.wantErr, err)
+			}
+		})
+	}
+	if _, err := pgLayer.PatchMovieByID(ctx, "id_0", "", patch, []string{"active"}); err == nil {
+		t.Error("PatchMovieByID: want an error for an unknown field")
+	}
+}
+
+func TestMovieNameUniqueness(t *testing.T) {
+	ctx := context.Background()
+	pgLayer := newTestLayer(t)
+
+	for _, mv := range []persistence.Movie{{Id: "id_0", Name: "The Dark Knight"}, {Id: "id_1", Name: "Inception"}} {
+		if _, err := pgLayer.AddMovie(ctx, mv); err != nil {
+			t.Fatalf("AddMovie: unexpected err %v", err)
+		}
+	}
+
+	// Names differing by case only are taken, by the movie given in the error
+	wantTaken := func(op string, err error) {
+		var perr *persistence.Error
+		if !errors.Is(err, persistence.ErrAlreadyExists) || !errors.As(err, &perr) || perr.Field != "name" || perr.ID != "id_0" {
+			t.Errorf("%s: want the name taken by id_0, got %v", op, err)
+		}
+	}
+	_, err := pgLayer.AddMovie(ctx, persistence.Movie{Id: "id_2", Name: "THE DARK KNIGHT"})
+	wantTaken("AddMovie", err)
+	_, err = pgLayer.UpdateMovieByID(ctx, "id_1", "", persistence.Movie{Name: "the dark knight"})
+	wantTaken("UpdateMovieByID", err)
+	_, err = pgLayer.PatchMovieByID(ctx, "id_1", "", persistence.Movie{Name: "The Dark knight"}, []string{"name"})
+	wantTaken("PatchMovieByID", err)
+	if mv, err := pgLayer.FindMovieByName(ctx, "the DARK knight"); err != nil || mv.Id != "id_0" {
+		t.Errorf("FindMovieByName: want id_0, got %v, %v", mv, err)
+	}
+
+	// A movie may change the case of its own name
+	if _, err := pgLayer.PatchMovieByID(ctx, "id_0", "", persistence.Movie{Name: "the dark knight"}, []string{"name"}); err != nil {
+		t.Errorf("PatchMovieByID: unexpected err %v", err)
+	}
+	// Removing the movie frees its name
+	if err := pgLayer.RemoveMovieByID(ctx, "id_0"); err != nil {
+		t.Fatalf("RemoveMovieByID: unexpected err %v", err)
+	}
+	if _, err := pgLayer.FindMovieByName(ctx, "The Dark Knight"); !errors.Is(err, persistence.ErrNotFound) {
+		t.Errorf("FindMovieByName: want ErrNotFound for a freed name, got %v", err)
+	}
+	if _, err := pgLayer.AddMovie(ctx, persistence.Movie{Id: "id_2", Name: "The Dark Knight"}); err != nil {
+		t.Errorf("AddMovie: want the name freed, got %v", err)
+	}
+}
+
+func TestFindAllMovies_filter(t *testing.T) {
+	ctx := context.Background()
+	pgLayer := newTestLayer(t)
+
+	movies := []persistence.Movie{
+		{Id: "id_0", Name: "Inception", Director: "Nolan", Cast: []string{"DiCaprio"},
+			Tags: []persistence.Tag{persistence.Tag_Action}, Active: true, CreateTime: &timestamp.Timestamp{Seconds: 1609459200}},
+		{Id: "id_1", Name: "The Prestige", Director: "Nolan", Cast: []string{"Bale", "Jackman"},
+			Tags: []persistence.Tag{persistence.Tag_Fantasy}, Active: true, CreateTime: &timestamp.Timestamp{Seconds: 1625097600}},
+		{Id: "id_2", Name: "Up", Director: "Docter", Cast: []string{"Asner"},
+			Tags: []persistence.Tag{persistence.Tag_Adventure, persistence.Tag_Comedy}, CreateTime: &timestamp.Timestamp{Seconds: 1640995200}},
+		{Id: "id_3", Name: "Draft"},
+	}
+	for _, mv := range movies {
+		if _, err := pgLayer.AddMovie(ctx, mv); err != nil {
+			t.Fatalf("AddMovie: unexpected err %v", err)
+		}
+	}
+
+	tests := []struct {
+		filter   string
+		expected string
+	}{
+		{``, "[id_3 id_0 id_1 id_2]"},
+		{`director = "Nolan"`, "[id_0 id_1]"},
+		{`director = "Nolan" AND tags:Action`, "[id_0]"},
+		{`cast:Bale OR tags:Comedy`, "[id_1 id_2]"},
+		{`NOT director = "Nolan"`, "[id_3 id_2]"},
+		{`active = true AND create_time > "2021-01-01"`, "[id_1]"},
+		{`create_time >= "2021-07-01" AND create_time < "2022-01-01T00:00:01Z"`, "[id_1 id_2]"},
+		{`create_time != "2021-07-01"`, "[id_0 id_2]"},
+	}
+	for _, tcase := range tests {
+		t.Run(tcase.filter, func(t *testing.T) {
+			expr, err := filter.Parse(tcase.filter, persistence.MovieFilterSchema)
+			if err != nil {
+				t.Fatalf("Parse: unexpected err %v", err)
+			}
+			found, err := pgLayer.FindAllMovies(ctx, expr, false, persistence.DefaultOrder, persistence.Cursor{}, 0)
+			if err != nil {
+				t.Fatalf("FindAllMovies: unexpected err %v", err)
+			}
+			var ids []string
+			for _, mv := range found {
+				ids = append(ids, mv.Id)
+			}
+			if fmt.Sprint(ids) != tcase.expected {
+				t.Errorf("FindAllMovies: want %v, got %v", tcase.expected, ids)
+			}
+		})
+	}
+
+	// The filter applies before the page size
+	expr, _ := filter.Parse(`director = "Nolan"`, persistence.MovieFilterSchema)
+	found, err := pgLayer.FindAllMovies(ctx, expr, false, persistence.DefaultOrder, persistence.Cursor{Values: []interface{}{time.Time{}}, Key: "id_3"}, 1)
+	if err != nil || len(found) != 1 || found[0].Id != "id_0" {
+		t.Errorf("FindAllMovies: unexpected page %v, %v", found, err)
+	}
+}
+
+func TestFindAllMovies_order(t *testing.T) {
+	ctx := context.Background()
+	pgLayer := newTestLayer(t)
+
+	movies := []persistence.Movie{
+		{Id: "id_0", Name: "Inception", Director: "Nolan", CreateTime: &timestamp.Timestamp{Seconds: 1609459200}},
+		{Id: "id_1", Name: "The Prestige", Director: "Nolan", CreateTime: &timestamp.Timestamp{Seconds: 1625097600}},
+		{Id: "id_2", Name: "Up", Director: "Docter", CreateTime: &timestamp.Timestamp{Seconds: 1640995200}},
+		{Id: "id_3", Name: "Draft"},
+	}
+	for _, mv := range movies {
+		if _, err := pgLayer.AddMovie(ctx, mv); err != nil {
+			t.Fatalf("AddMovie: unexpected err %v", err)
+		}
+	}
+
+	tests := []struct {
+		orderBy  string
+		expected string
+	}{
+		{"", "[id_3 id_0 id_1 id_2]"},
+		{"name desc", "[id_2 id_1 id_0 id_3]"},
+		{"director desc, create_time", "[id_0 id_1 id_2 id_3]"},
+		{"create_time desc", "[id_2 id_1 id_0 id_3]"},
+		{"update_time desc, name", "[id_3 id_0 id_1 id_2]"},
+	}
+	for _, tcase := range tests {
+		t.Run(tcase.orderBy, func(t *testing.T) {
+			order, err := persistence.ParseOrder(tcase.orderBy, persistence.MovieSortFields)
+			if err != nil {
+				t.Fatalf("ParseOrder: unexpected err %v", err)
+			}
+
+			// Page by page, so that every cursor is used
+			var ids []string
+			after := persistence.Cursor{}
+			for len(ids) <= len(movies) {
+				found, err := pgLayer.FindAllMovies(ctx, nil, false, order, after, 1)
+				if err != nil {
+					t.Fatalf("FindAllMovies: unexpected err %v", err)
+				}
+				if len(found) == 0 {
+					break
+				}
+				ids = append(ids, found[0].Id)
+				after = order.MovieCursor(found[0])
+			}
+			if fmt.Sprint(ids) != tcase.expected {
+				t.Errorf("FindAllMovies: want %v, got %v", tcase.expected, ids)
+			}
+		})
+	}
+}
+
+func TestSoftDelete(t *testing.T) {
+	ctx := context.Background()
+	pgLayer := newTestLayer(t)
+
+	for _, mv := range []persistence.Movie{{Id: "id_0", Name: "Inception"}, {Id: "id_1", Name: "Interstellar"}} {
+		if _, err := pgLayer.AddMovie(ctx, mv); err != nil {
+			t.Fatalf("AddMovie: unexpected err %v", err)
+		}
+	}
+	if _, err := pgLayer.AddUser(ctx, persistence.User{Username: "test_username", Email: "test_email@domain.in"}); err != nil {
+		t.Fatalf("AddUser: unexpected err %v", err)
+	}
+
+	deleted := &timestamp.Timestamp{Seconds: 1609459200}
+	if err := pgLayer.SetMovieDeleteTime(ctx, "id_0", "", deleted); err != nil {
+		t.Fatalf("SetMovieDeleteTime: unexpected err %v", err)
+	}
+	if err := pgLayer.SetUserDeleteTime(ctx, "test_username", "", deleted); err != nil {
+		t.Fatalf("SetUserDeleteTime: unexpected err %v", err)
+	}
+	if err := pgLayer.SetMovieDeleteTime(ctx, "id_9", "", deleted); !errors.Is(err, persistence.ErrNotFound) {
+		t.Errorf("SetMovieDeleteTime: want ErrNotFound for missing movie, got %v", err)
+	}
+
+	tests := []struct {
+		showDeleted bool
+		movies      string
+		users       int
+	}{
+		{false, "[id_1]", 0},
+		{true, "[id_0 id_1]", 1},
+	}
+	for _, tcase := range tests {
+		t.Run(fmt.Sprint("show_deleted_", tcase.showDeleted), func(t *testing.T) {
+			movies, err := pgLayer.FindAllMovies(ctx, nil, tcase.showDeleted, persistence.DefaultOrder, persistence.Cursor{}, 0)
+			if err != nil {
+				t.Fatalf("FindAllMovies: unexpected err %v", err)
+			}
+			ids := []string{}
+			for _, mv := range movies {
+				ids = append(ids, mv.Id)
+			}
+			if fmt.Sprint(ids) != tcase.movies {
+				t.Errorf("FindAllMovies: want %v, got %v", tcase.movies, ids)
+			}
+
+			users, err := pgLayer.FindAllUsers(ctx, tcase.showDeleted, persistence.DefaultOrder, persistence.Cursor{}, 0)
+			if err != nil || len(users) != tcase.users {
+				t.Errorf("FindAllUsers: want %d users, got %v, %v", tcase.users, users, err)
+			}
+		})
+	}
+
+	if mv, err := pgLayer.FindMovieByID(ctx, "id_0"); err != nil || mv.DeleteTime.GetSeconds() != deleted.GetSeconds() {
+		t.Errorf("FindMovieByID: want the delete time, got %v, %v", mv, err)
+	}
+	if hits, _ := pgLayer.SearchMovies(ctx, "inception", 0, 0); len(hits) != 0 {
+		t.Errorf("SearchMovies: want no deleted movie, got %v", hits)
+	}
+
+	// A restored movie is found again
+	if err := pgLayer.SetMovieDeleteTime(ctx, "id_0", "", nil); err != nil {
+		t.Fatalf("SetMovieDeleteTime: unexpected err %v", err)
+	}
+	if hits, _ := pgLayer.SearchMovies(ctx, "inception", 0, 0); len(hits) != 1 {
+		t.Errorf("SearchMovies: want the restored movie, got %v", hits)
+	}
+
+	// Only the records deleted before the given time are purged
+	if err := pgLayer.SetMovieDeleteTime(ctx, "id_0", "", deleted); err != nil {
+		t.Fatalf("SetMovieDeleteTime: unexpected err %v", err)
+	}
+	if n, err := pgLayer.PurgeMovies(ctx, time.Unix(1609459200, 0)); err != nil || n != 0 {
+		t.Errorf("PurgeMovies: want 0 movies purged, got %d, %v", n, err)
+	}
+	if n, err := pgLayer.PurgeMovies(ctx, time.Unix(1609459201, 0)); err != nil || n != 1 {
+		t.Errorf("PurgeMovies: want 1 movie purged, got %d, %v", n, err)
+	}
+	if n, err := pgLayer.PurgeUsers(ctx, time.Unix(1609459201, 0)); err != nil || n != 1 {
+		t.Errorf("PurgeUsers: want 1 user purged, got %d, %v", n, err)
+	}
+	if _, err := pgLayer.FindMovieByID(ctx, "id_0"); !errors.Is(err, persistence.ErrNotFound) {
+		t.Errorf("FindMovieByID: want ErrNotFound for purged movie, got %v", err)
+	}
+	if _, err := pgLayer.FindMovieByID(ctx, "id_1"); err != nil {
+		t.Errorf("FindMovieByID: unexpected err %v", err)
+	}
+}
+
+func TestEtag(t *testing.T) {
+	ctx := context.Background()
+	pgLayer := newTestLayer(t)
+
+	if _, err := pgLayer.AddMovie(ctx, persistence.Movie{Id: "id_0", Name: "Inception"}); err != nil {
+		t.Fatalf("AddMovie: unexpected err %v", err)
+	}
+	if _, err := pgLayer.AddUser(ctx, persistence.User{Username: "test_username", Email: "test_email@domain.in"}); err != nil {
+		t.Fatalf("AddUser: unexpected err %v", err)
+	}
+	mv, _ := pgLayer.FindMovieByID(ctx, "id_0")
+	u, _ := pgLayer.FindByUsername(ctx, "test_username")
+	if mv.Etag == "" || u.Etag == "" {
+		t.Fatalf("want etags on the added records, got %q and %q", mv.Etag, u.Etag)
+	}
+
+	if _, err := pgLayer.UpdateMovieByID(ctx, "id_0", mv.Etag, persistence.Movie{Name: "Inception 2"}); err != nil {
+		t.Fatalf("UpdateMovieByID: unexpected err %v", err)
+	}
+	// The etag read before the update is now stale
+	if _, err := pgLayer.UpdateMovieByID(ctx, "id_0", mv.Etag, persistence.Movie{Name: "Inception 3"}); !errors.Is(err, persistence.ErrEtagMismatch) {
+		t.Errorf("UpdateMovieByID: want ErrEtagMismatch, got %v", err)
+	}
+	if _, err := pgLayer.UpdateMovieByID(ctx, "missing", mv.Etag, persistence.Movie{Name: "Inception 3"}); !errors.Is(err, persistence.ErrNotFound) {
+		t.Errorf("UpdateMovieByID: want ErrNotFound, got %v", err)
+	}
+	if err := pgLayer.SetMovieDeleteTime(ctx, "id_0", mv.Etag, &timestamp.Timestamp{}); !errors.Is(err, persistence.ErrEtagMismatch) {
+		t.Errorf("SetMovieDeleteTime: want ErrEtagMismatch, got %v", err)
+	}
+	if found, _ := pgLayer.FindMovieByID(ctx, "id_0"); found.Name != "Inception 2" || found.DeleteTime != nil || found.Etag == mv.Etag {
+		t.Errorf("FindMovieByID: want only the first update with a new etag, got %v", found)
+	}
+
+	if err := pgLayer.SetUserDeleteTime(ctx, "test_username", "stale", &timestamp.Timestamp{}); !errors.Is(err, persistence.ErrEtagMismatch) {
+		t.Errorf("SetUserDeleteTime: want ErrEtagMismatch, got %v", err)
+	}
+	if err := pgLayer.SetUserDeleteTime(ctx, "test_username", u.Etag, &timestamp.Timestamp{}); err != nil {
+		t.Errorf("SetUserDeleteTime: unexpected err %v", err)
+	}
+}
+
+func TestWatchMovies(t *testing.T) {
+	ctx := context.Background()
+	pgLayer := newTestLayer(t)
+
+	stream, err := pgLayer.WatchMovies(ctx, "")
+	if err != nil {
+		t.Fatalf("WatchMovies: unexpected err %v", err)
+	}
+	defer stream.Close()
+
+	if _, err := pgLayer.AddMovie(ctx, persistence.Movie{Id: "id_0", Name: "Inception"}); err != nil {
+		t.Fatalf("AddMovie: unexpected err %v", err)
+	}
+	if _, err := pgLayer.UpdateMovieByID(ctx, "id_0", "", persistence.Movie{Name: "Inception 2"}); err != nil {
+		t.Fatalf("UpdateMovieByID: unexpected err %v", err)
+	}
+	if err := pgLayer.SetMovieDeleteTime(ctx, "id_0", "", &timestamp.Timestamp{Seconds: 1}); err != nil {
+		t.Fatalf("SetMovieDeleteTime: unexpected err %v", err)
+	}
+
+	tests := []struct {
+		typ  persistence.EventType
+		name string
+	}{
+		{persistence.MovieCreated, "Inception"},
+		{persistence.MovieUpdated, "Inception 2"},
+		{persistence.MovieDeleted, "Inception 2"},
+	}
+	for _, tcase := range tests {
+		ev, err := stream.Next(ctx)
+		if err != nil || ev.Type != tcase.typ || ev.Movie.Id != "id_0" || ev.Movie.Name != tcase.name {
+			t.Errorf("Next: want event %v of %q, got %v, %v", tcase.typ, tcase.name, ev, err)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+
+	"github.com/AkashGit21/ms-project/lib/persistence"
+	"github.com/AkashGit21/ms-project/lib/search"
+)
+
+// searchVector is the indexed expression of migration 0004_movie_search.sql.
+const searchVector = `movie_search_vector(name, director, cast_members, writers, summary)`
+
+// SearchMovies uses the full-text search of Postgres, ranking the movies by
+// the weights of the words in searchVector.
+func (pgLayer *PostgresLayer) SearchMovies(ctx context.Context, query string, offset, pgSize int32) ([]persistence.SearchHit, error) {
+	q, ok := searchQuery(query)
+	if !ok {
+		return nil, nil
+	}
+
+	rows, err := pgLayer.db.QueryContext(ctx,
+		`SELECT `+movieColumns+`, ts_rank(`+searchVector+`, q) AS score
+		FROM movies, to_tsquery('english', $1) q
+		WHERE `+searchVector+` @@ q AND delete_time IS NULL
+		ORDER BY score DESC, id LIMIT $2 OFFSET $3`,
+		q, limit(pgSize), offset,
+	)
+	if err != nil {
+		return nil, toPersistenceError(err, "movie", nil)
+	}
+	defer rows.Close()
+
+	var results []persistence.SearchHit
+	for rows.Next() {
+		var hit persistence.SearchHit
+		mv, err := scanMovie(scoreScanner{rows, &hit.Score})
+		if err != nil {
+			return nil, toPersistenceError(err, "movie", nil)
+		}
+		hit.Movie = mv
+		results = append(results, hit)
+	}
+	return results, toPersistenceError(rows.Err(), "movie", nil)
+}
+
+// searchQuery returns the tsquery matching any word of query, like the other
+// backends do. It reports false if query has no word to search.
+func searchQuery(query string) (string, bool) {
+	// Terms are letters and digits only, hence no tsquery operators
+	terms := search.Tokenize(query)
+	return strings.Join(terms, " | "), len(terms) > 0
+}
+
+// scoreScanner scans the score of a search after the columns of a movie.
+type scoreScanner struct {
+	scanner
+	score *float64
+}
+
+func (s scoreScanner) Scan(dest ...interface{}) error {
+	return s.scanner.Scan(append(dest, s.score)...)
+}
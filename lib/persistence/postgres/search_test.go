@@ -0,0 +1,23 @@
+package postgres
+
+import "testing"
+
+func TestSearchQuery(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+		ok       bool
+	}{
+		{"Nolan dream", "nolan | dream", true},
+		{"dream & !nolan:*", "dream | nolan", true},
+		{"the of", "", false},
+	}
+	for _, tcase := range tests {
+		t.Run(tcase.query, func(t *testing.T) {
+			got, ok := searchQuery(tcase.query)
+			if got != tcase.expected || ok != tcase.ok {
+				t.Errorf("searchQuery: want %q, %v, got %q, %v", tcase.expected, tcase.ok, got, ok)
+			}
+		})
+	}
+}
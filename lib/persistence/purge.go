@@ -0,0 +1,43 @@
+package persistence
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Purge removes for good the movies and users which were deleted longer than
+// retention ago, and returns how many of each it removed.
+func Purge(ctx context.Context, handler DatabaseHandler, retention time.Duration) (movies, users int, err error) {
+	deletedBefore := time.Now().Add(-retention)
+
+	if movies, err = handler.PurgeMovies(ctx, deletedBefore); err != nil {
+		return movies, 0, err
+	}
+	users, err = handler.PurgeUsers(ctx, deletedBefore)
+	return movies, users, err
+}
+
+// RunPurger runs Purge every interval until ctx is done. Failures are logged,
+// the records they left are purged by the next run.
+func RunPurger(ctx context.Context, handler DatabaseHandler, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		movies, users, err := Purge(ctx, handler, retention)
+		if err != nil {
+			log.Printf("[WARN] Could not purge the deleted records: %v", err)
+			continue
+		}
+		if movies > 0 || users > 0 {
+			log.Printf("[DEBUG] Purged %d movies and %d users deleted before the last %v", movies, users, retention)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package persistence_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/AkashGit21/ms-project/lib/persistence"
+	"github.com/AkashGit21/ms-project/lib/persistence/memlayer"
+	"github.com/golang/protobuf/ptypes"
+)
+
+func TestPurge(t *testing.T) {
+	ctx := context.Background()
+	dbhandler, _ := memlayer.NewMemoryLayer()
+
+	for _, mv := range []persistence.Movie{{Id: "id_0", Name: "test_movie_0"}, {Id: "id_1", Name: "test_movie_1"}} {
+		if _, err := dbhandler.AddMovie(ctx, mv); err != nil {
+			t.Fatalf("AddMovie: unexpected err %v", err)
+		}
+	}
+	if _, err := dbhandler.AddUser(ctx, persistence.User{Username: "test_username"}); err != nil {
+		t.Fatalf("AddUser: unexpected err %v", err)
+	}
+
+	// Deleted two days and one hour ago, while the retention is one day
+	old, _ := ptypes.TimestampProto(time.Now().Add(-48 * time.Hour))
+	recent, _ := ptypes.TimestampProto(time.Now().Add(-time.Hour))
+	dbhandler.SetMovieDeleteTime(ctx, "id_0", "", old)
+	dbhandler.SetMovieDeleteTime(ctx, "id_1", "", recent)
+	dbhandler.SetUserDeleteTime(ctx, "test_username", "", old)
+
+	movies, users, err := persistence.Purge(ctx, dbhandler, 24*time.Hour)
+	if err != nil || movies != 1 || users != 1 {
+		t.Errorf("Purge: want 1 movie and 1 user, got %d, %d, %v", movies, users, err)
+	}
+	if got, _ := dbhandler.CountMovieRecords(ctx); got != 1 {
+		t.Errorf("CountMovieRecords: want 1, got %d", got)
+	}
+}
@@ -0,0 +1,45 @@
+package persistence
+
+import (
+	"context"
+	"strings"
+
+	"github.com/AkashGit21/ms-project/lib/search"
+)
+
+// SearchIndex finds movies by the words of their name, summary, cast,
+// director and writers. MongoDB uses a text index, Postgres its full-text
+// search, and the in-process backends a search.Index of MovieDocuments.
+type SearchIndex interface {
+	// SearchMovies returns the movies matching any word of the query, best
+	// matches first. It skips the first offset matches and returns at most
+	// limit of them.
+	SearchMovies(ctx context.Context, query string, offset, limit int32) ([]SearchHit, error)
+}
+
+// SearchHit is a movie matching a search. The higher the score the better
+// the match, but scores are only comparable within the same backend.
+type SearchHit struct {
+	Movie Movie
+	Score float64
+}
+
+// MovieSearchWeights tells how relevant a word is in each searchable field.
+var MovieSearchWeights = map[string]float64{
+	"name":     10,
+	"director": 5,
+	"cast":     5,
+	"writers":  2,
+	"summary":  1,
+}
+
+// MovieDocument returns the searchable fields of mv.
+func MovieDocument(mv Movie) search.Document {
+	return search.Document{
+		"name":     mv.Name,
+		"summary":  mv.Summary,
+		"cast":     strings.Join(mv.Cast, ", "),
+		"director": mv.Director,
+		"writers":  strings.Join(mv.Writers, ", "),
+	}
+}
@@ -0,0 +1,96 @@
+package sqlite
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AkashGit21/ms-project/lib/persistence"
+)
+
+// movieSortColumns and userSortColumns map the fields of persistence.MovieSortFields
+// and persistence.UserSortFields to the expressions lists are sorted by. Missing
+// timestamps sort before every other time, like in the other backends.
+var (
+	movieSortColumns = map[string]string{
+		"name":        "name",
+		"director":    "director",
+		"create_time": "IFNULL(create_time, " + noTime + ")",
+		"update_time": "IFNULL(update_time, " + noTime + ")",
+	}
+	userSortColumns = map[string]string{
+		"username":    "username",
+		"email":       "email",
+		"create_time": "IFNULL(create_time, " + noTime + ")",
+		"update_time": "IFNULL(update_time, " + noTime + ")",
+	}
+)
+
+// orderBy returns the ORDER BY clause of a list sorted by order, and then by the key column.
+func orderBy(key string, columns map[string]string, order persistence.Order) string {
+	var exprs []string
+	for _, sf := range order {
+		expr := columns[sf.Field]
+		if sf.Desc {
+			expr += " DESC"
+		}
+		exprs = append(exprs, expr)
+	}
+	return strings.Join(append(exprs, key), ", ")
+}
+
+// afterCursor returns the condition matching the records after the cursor in
+// a list sorted by order and then by the key column, appending its parameters
+// to args in the order of their placeholders. Ascending orders are compared
+// as a row, which the indexes support.
+func afterCursor(key string, columns map[string]string, order persistence.Order,
+	after persistence.Cursor, args *[]interface{}) (string, error) {
+	if len(after.Values) != len(order) {
+		return "", fmt.Errorf("cursor has %d values for %d sort fields", len(after.Values), len(order))
+	}
+
+	ascending := true
+	for _, sf := range order {
+		ascending = ascending && !sf.Desc
+	}
+	if ascending {
+		var exprs, params []string
+		for i, sf := range order {
+			exprs = append(exprs, columns[sf.Field])
+			params = append(params, cursorParam(after.Values[i], args))
+		}
+		exprs = append(exprs, key)
+		params = append(params, cursorParam(after.Key, args))
+		return fmt.Sprintf("(%s) > (%s)", strings.Join(exprs, ", "), strings.Join(params, ", ")), nil
+	}
+
+	// (a < ? OR (a = ? AND (b > ? OR (b = ? AND key > ?))))
+	var sb strings.Builder
+	for i, sf := range order {
+		op := ">"
+		if sf.Desc {
+			op = "<"
+		}
+		expr := columns[sf.Field]
+		fmt.Fprintf(&sb, "(%s %s %s OR (%s = %s AND ", expr, op, cursorParam(after.Values[i], args),
+			expr, cursorParam(after.Values[i], args))
+	}
+	fmt.Fprintf(&sb, "%s > %s", key, cursorParam(after.Key, args))
+	sb.WriteString(strings.Repeat("))", len(order)))
+	return sb.String(), nil
+}
+
+// cursorParam appends a value of a cursor to args and returns its placeholder.
+// Times are stored as nanoseconds, see toUnixNano.
+func cursorParam(value interface{}, args *[]interface{}) string {
+	if t, ok := value.(time.Time); ok {
+		if t.IsZero() {
+			*args = append(*args, nil)
+		} else {
+			*args = append(*args, t.UnixNano())
+		}
+		return "IFNULL(?, " + noTime + ")"
+	}
+	*args = append(*args, value)
+	return "?"
+}
@@ -0,0 +1,54 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+
+	"github.com/AkashGit21/ms-project/lib/persistence"
+)
+
+// loadIndex indexes every stored movie which is not deleted. The index is kept
+// up to date by the writes of this process only, which is fine since a single
+// server uses the database file.
+func (sqlLayer *SQLiteLayer) loadIndex(ctx context.Context) error {
+	rows, err := sqlLayer.db.QueryContext(ctx, `SELECT `+movieColumns+` FROM movies WHERE delete_time IS NULL`)
+	if err != nil {
+		return toPersistenceError(err, "movie", nil)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		mv, err := scanMovie(rows)
+		if err != nil {
+			return toPersistenceError(err, "movie", nil)
+		}
+		sqlLayer.movieIndex.Add(mv.Id, persistence.MovieDocument(mv))
+	}
+	return toPersistenceError(rows.Err(), "movie", nil)
+}
+
+// SearchMovies pages the hits which are still stored and not deleted, so that
+// a page is only short at the end of the hits.
+func (sqlLayer *SQLiteLayer) SearchMovies(ctx context.Context, query string, offset, limit int32) ([]persistence.SearchHit, error) {
+	var results []persistence.SearchHit
+	skipped := int32(0)
+	for _, hit := range sqlLayer.movieIndex.Search(query) {
+		if limit > 0 && len(results) == int(limit) {
+			break
+		}
+		mv, err := sqlLayer.FindMovieByID(ctx, hit.ID)
+		// Removed or deleted since the search, or by another process
+		if errors.Is(err, persistence.ErrNotFound) || err == nil && mv.DeleteTime != nil {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if skipped < offset {
+			skipped++
+			continue
+		}
+		results = append(results, persistence.SearchHit{Movie: mv, Score: hit.Score})
+	}
+	return results, nil
+}
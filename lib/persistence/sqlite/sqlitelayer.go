@@ -0,0 +1,787 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AkashGit21/ms-project/lib/filter"
+	"github.com/AkashGit21/ms-project/lib/persistence"
+	"github.com/AkashGit21/ms-project/lib/search"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+
+	// Pure Go driver, hence the binary still builds with CGO_ENABLED=0
+	"modernc.org/sqlite"
+)
+
+const (
+	// DefaultFile is used when no database file is given
+	DefaultFile = "ms-project.db"
+
+	// noTime sorts before every creation time, it is the smallest INTEGER
+	noTime = "-9223372036854775808"
+
+	userColumns  = `username, email, password, role, active, first_name, last_name, create_time, update_time, age, height_in_cms, nickname, enable_notifications, delete_time, etag, email_verify_time`
+	movieColumns = `id, name, summary, cast_members, tags, director, writers, active, create_time, update_time, delete_time, etag`
+)
+
+// migrations holds the schema changes, the version of the schema being the
+// number of migrations applied. It is stored in `PRAGMA user_version`.
+// Append new migrations at the end, never edit the applied ones.
+var migrations = []string{
+	`CREATE TABLE users (
+		seq                  INTEGER PRIMARY KEY AUTOINCREMENT,
+		id                   TEXT NOT NULL UNIQUE,
+		username             TEXT NOT NULL UNIQUE,
+		email                TEXT NOT NULL UNIQUE,
+		password             TEXT NOT NULL,
+		role                 INTEGER NOT NULL DEFAULT 0,
+		active               BOOLEAN NOT NULL DEFAULT FALSE,
+		first_name           TEXT NOT NULL DEFAULT '',
+		last_name            TEXT,
+		create_time          INTEGER,
+		update_time          INTEGER,
+		age                  INTEGER,
+		height_in_cms        REAL,
+		nickname             TEXT,
+		enable_notifications BOOLEAN
+	)`,
+	`CREATE TABLE movies (
+		seq          INTEGER PRIMARY KEY AUTOINCREMENT,
+		id           TEXT NOT NULL UNIQUE,
+		name         TEXT NOT NULL UNIQUE,
+		summary      TEXT NOT NULL DEFAULT '',
+		cast_members TEXT NOT NULL DEFAULT '[]',
+		tags         TEXT NOT NULL DEFAULT '[]',
+		director     TEXT NOT NULL DEFAULT '',
+		writers      TEXT NOT NULL DEFAULT '[]',
+		active       BOOLEAN NOT NULL DEFAULT FALSE,
+		create_time  INTEGER,
+		update_time  INTEGER
+	)`,
+	// Lists are paged by creation time and unique key, see persistence.Cursor
+	`CREATE INDEX users_create_time_username_idx ON users (IFNULL(create_time, ` + noTime + `), username);
+	CREATE INDEX movies_create_time_id_idx ON movies (IFNULL(create_time, ` + noTime + `), id)`,
+	// Deleted records are kept until purged, see persistence.DatabaseHandler
+	`ALTER TABLE users ADD COLUMN delete_time INTEGER;
+	ALTER TABLE movies ADD COLUMN delete_time INTEGER`,
+	// Writes are checked against the etag the caller read, see persistence.DatabaseHandler
+	`ALTER TABLE users ADD COLUMN etag TEXT NOT NULL DEFAULT '';
+	ALTER TABLE movies ADD COLUMN etag TEXT NOT NULL DEFAULT '';
+	UPDATE users SET etag = lower(hex(randomblob(8)));
+	UPDATE movies SET etag = lower(hex(randomblob(8)))`,
+	// Movie names are unique regardless of case, see persistence.MovieNameKey
+	`CREATE UNIQUE INDEX movies_name_nocase_idx ON movies (name COLLATE NOCASE)`,
+	// The users created before emails were verified are trusted as verified
+	`ALTER TABLE users ADD COLUMN email_verify_time INTEGER;
+	UPDATE users SET email_verify_time = IFNULL(create_time, 0)`,
+	// NOCASE only folds the ASCII letters, hence movie names are made unique
+	// by their persistence.MovieNameKey, filled in by fillMovieNameKeys
+	`ALTER TABLE movies ADD COLUMN name_key TEXT NOT NULL DEFAULT ''`,
+	// This fails if some movies already have names differing by the case of
+	// other letters only, which must then be renamed first
+	`DROP INDEX movies_name_nocase_idx;
+	CREATE UNIQUE INDEX movies_name_key_idx ON movies (name_key)`,
+}
+
+// migrationFuncs complete the migrations with the given version, in their
+// transaction, with the changes which SQLite cannot make by itself.
+var migrationFuncs = map[int]func(context.Context, *sql.Tx) error{
+	9: fillMovieNameKeys,
+}
+
+// matchEtag is the condition of the writes which check the etag of the record.
+// It takes the etag as its only parameter, an empty etag matches any record.
+const matchEtag = `etag = COALESCE(NULLIF(?, ''), etag)`
+
+// SQLiteLayer is a DatabaseHandler backed by a single SQLite database file.
+// It is meant for edge and demo deployments which run a single server, which
+// is why movies are searched with an index in process memory.
+type SQLiteLayer struct {
+	db *sql.DB
+
+	// writeMu keeps movieIndex and movieEvents in the order of the writes to the movies
+	writeMu     sync.Mutex
+	movieIndex  *search.Index
+	movieEvents *persistence.Broadcaster
+}
+
+// NewSQLiteLayer opens (or creates) the database file given by connection,
+// e.g. `/var/lib/ms-project/ms.db` or `:memory:`, and migrates its schema.
+func NewSQLiteLayer(connection string) (persistence.DatabaseHandler, error) {
+	if connection == "" {
+		connection = DefaultFile
+	}
+
+	db, err := sql.Open("sqlite", connection)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite allows a single writer at a time. Sharing one connection avoids
+	// SQLITE_BUSY errors and keeps `:memory:` databases alive.
+	db.SetMaxOpenConns(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := migrate(ctx, db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	sqlLayer := &SQLiteLayer{
+		db:          db,
+		movieIndex:  search.NewIndex(persistence.MovieSearchWeights),
+		movieEvents: persistence.NewBroadcaster(persistence.DefaultHistory),
+	}
+	if err := sqlLayer.loadIndex(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return sqlLayer, nil
+}
+
+// migrate applies the migrations newer than the stored schema version.
+func migrate(ctx context.Context, db *sql.DB) error {
+	var version int
+	if err := db.QueryRowContext(ctx, `PRAGMA user_version`).Scan(&version); err != nil {
+		return err
+	}
+
+	for ; version < len(migrations); version++ {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, migrations[version]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d failed: %v", version+1, err)
+		}
+		if fn := migrationFuncs[version+1]; fn != nil {
+			if err := fn(ctx, tx); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("migration %d failed: %v", version+1, err)
+			}
+		}
+		// PRAGMA does not accept bound parameters
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`PRAGMA user_version = %d`, version+1)); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fillMovieNameKeys stores the persistence.MovieNameKey of the name of every movie.
+func fillMovieNameKeys(ctx context.Context, tx *sql.Tx) error {
+	rows, err := tx.QueryContext(ctx, `SELECT id, name FROM movies`)
+	if err != nil {
+		return err
+	}
+	keys := map[string]string{}
+	for rows.Next() {
+		var id, name string
+		if err := rows.Scan(&id, &name); err != nil {
+			rows.Close()
+			return err
+		}
+		keys[id] = persistence.MovieNameKey(name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for id, key := range keys {
+		if _, err := tx.ExecContext(ctx, `UPDATE movies SET name_key = ? WHERE id = ?`, key, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (sqlLayer *SQLiteLayer) AddUser(ctx context.Context, u persistence.User) ([]byte, error) {
+	id := uuid.New().String()
+	if u.Etag == "" {
+		u.Etag = persistence.NewEtag()
+	}
+
+	_, err := sqlLayer.db.ExecContext(ctx,
+		`INSERT INTO users (id, `+userColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		id, u.Username, u.Email, u.Password, int32(u.Role), u.Active, u.FirstName, u.LastName,
+		toUnixNano(u.CreateTime), toUnixNano(u.UpdateTime), u.Age, u.HeightInCms, u.Nickname, u.EnableNotifications,
+		toUnixNano(u.DeleteTime), u.Etag, toUnixNano(u.EmailVerifyTime),
+	)
+	if err != nil {
+		return nil, toPersistenceError(err, "user", map[string]string{"id": id, "username": u.Username, "email": u.Email})
+	}
+
+	return json.Marshal(id)
+}
+
+func (sqlLayer *SQLiteLayer) FindByUsername(ctx context.Context, uname string) (persistence.User, error) {
+	row := sqlLayer.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE username = ?`, uname)
+	u, err := scanUser(row)
+	if err == sql.ErrNoRows {
+		return u, persistence.NotFound("user", "username", uname)
+	}
+	return u, toPersistenceError(err, "user", nil)
+}
+
+func (sqlLayer *SQLiteLayer) FindAllUsers(ctx context.Context, showDeleted bool, order persistence.Order, after persistence.Cursor, pgSize int32) ([]persistence.User, error) {
+	query, args, err := pageQuery(`SELECT `+userColumns+` FROM users`, "username", userSortColumns, nil, nil, showDeleted, order, after, pgSize)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := sqlLayer.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, toPersistenceError(err, "user", nil)
+	}
+	defer rows.Close()
+
+	var results []persistence.User
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, toPersistenceError(err, "user", nil)
+		}
+		// Lists leave out the password hashes
+		u.Password = ""
+		results = append(results, u)
+	}
+	return results, toPersistenceError(rows.Err(), "user", nil)
+}
+
+// UpdateByUsername clears the email verification time along with the email,
+// the right-hand sides of the assignments reading the row as it was.
+func (sqlLayer *SQLiteLayer) UpdateByUsername(ctx context.Context, uname string, etag string, u persistence.User) ([]byte, error) {
+	res, err := sqlLayer.db.ExecContext(ctx,
+		`UPDATE users SET email = ?, password = ?, role = ?, first_name = ?, last_name = ?,
+			update_time = COALESCE(?, update_time), age = ?, height_in_cms = ?, nickname = ?,
+			enable_notifications = ?, etag = ?, email_verify_time = CASE WHEN email = ? THEN email_verify_time END
+		WHERE username = ? AND `+matchEtag,
+		u.Email, u.Password, int32(u.Role), u.FirstName, u.LastName,
+		toUnixNano(u.UpdateTime), u.Age, u.HeightInCms, u.Nickname,
+		u.EnableNotifications, persistence.NewEtag(), u.Email, uname, etag,
+	)
+	err = toPersistenceError(err, "user", map[string]string{"email": u.Email})
+	if err := sqlLayer.checkEtag(ctx, checkAffected(res, err, "user", "username", uname), "users"); err != nil {
+		return nil, err
+	}
+	return []byte(uname), nil
+}
+
+func (sqlLayer *SQLiteLayer) SetUserDeleteTime(ctx context.Context, uname string, etag string, deleteTime *timestamp.Timestamp) error {
+	res, err := sqlLayer.db.ExecContext(ctx, `UPDATE users SET delete_time = ?, etag = ? WHERE username = ? AND `+matchEtag,
+		toUnixNano(deleteTime), persistence.NewEtag(), uname, etag)
+	return sqlLayer.checkEtag(ctx, checkAffected(res, err, "user", "username", uname), "users")
+}
+
+func (sqlLayer *SQLiteLayer) SetUserEmailVerifyTime(ctx context.Context, uname string, etag string, verifyTime *timestamp.Timestamp) error {
+	res, err := sqlLayer.db.ExecContext(ctx, `UPDATE users SET email_verify_time = ?, etag = ? WHERE username = ? AND `+matchEtag,
+		toUnixNano(verifyTime), persistence.NewEtag(), uname, etag)
+	return sqlLayer.checkEtag(ctx, checkAffected(res, err, "user", "username", uname), "users")
+}
+
+func (sqlLayer *SQLiteLayer) PurgeUsers(ctx context.Context, deletedBefore time.Time) (int, error) {
+	res, err := sqlLayer.db.ExecContext(ctx, `DELETE FROM users WHERE delete_time < ?`, deletedBefore.UnixNano())
+	return rowsAffected(res, err, "user")
+}
+
+func (sqlLayer *SQLiteLayer) RemoveByUsername(ctx context.Context, uname string) error {
+	res, err := sqlLayer.db.ExecContext(ctx, `DELETE FROM users WHERE username = ?`, uname)
+	return checkAffected(res, err, "user", "username", uname)
+}
+
+func (sqlLayer *SQLiteLayer) CountUsers(ctx context.Context) (int, error) {
+	var count int
+	err := sqlLayer.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM users`).Scan(&count)
+	return count, toPersistenceError(err, "user", nil)
+}
+
+func (sqlLayer *SQLiteLayer) Authenticate(ctx context.Context, uname string, password string) (bool, error) {
+	var hash string
+	err := sqlLayer.db.QueryRowContext(ctx, `SELECT password FROM users WHERE username = ?`, uname).Scan(&hash)
+	if err == sql.ErrNoRows {
+		return false, persistence.NotFound("user", "username", uname)
+	}
+	if err != nil {
+		return false, toPersistenceError(err, "user", nil)
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil, nil
+}
+
+func (sqlLayer *SQLiteLayer) AddMovie(ctx context.Context, mv persistence.Movie) ([]byte, error) {
+	if mv.Id == "" {
+		mv.Id = uuid.New().String()
+	}
+	if mv.Etag == "" {
+		mv.Etag = persistence.NewEtag()
+	}
+
+	sqlLayer.writeMu.Lock()
+	defer sqlLayer.writeMu.Unlock()
+
+	_, err := sqlLayer.db.ExecContext(ctx,
+		`INSERT INTO movies (`+movieColumns+`, name_key) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		mv.Id, mv.Name, mv.Summary, toJSON(mv.Cast), toJSON(mv.Tags), mv.Director,
+		toJSON(mv.Writers), mv.Active, toUnixNano(mv.CreateTime), toUnixNano(mv.UpdateTime),
+		toUnixNano(mv.DeleteTime), mv.Etag, persistence.MovieNameKey(mv.Name),
+	)
+	if err != nil {
+		return nil, sqlLayer.nameTaken(ctx, toPersistenceError(err, "movie", map[string]string{"id": mv.Id, "name": mv.Name}))
+	}
+	if mv.DeleteTime == nil {
+		sqlLayer.movieIndex.Add(mv.Id, persistence.MovieDocument(mv))
+	}
+	sqlLayer.movieEvents.Publish(persistence.MovieCreated, mv)
+
+	return json.Marshal(mv.Id)
+}
+
+func (sqlLayer *SQLiteLayer) FindMovieByID(ctx context.Context, id string) (persistence.Movie, error) {
+	row := sqlLayer.db.QueryRowContext(ctx, `SELECT `+movieColumns+` FROM movies WHERE id = ?`, id)
+	mv, err := scanMovie(row)
+	if err == sql.ErrNoRows {
+		return mv, persistence.NotFound("movie", "id", id)
+	}
+	return mv, toPersistenceError(err, "movie", nil)
+}
+
+func (sqlLayer *SQLiteLayer) FindMovieByName(ctx context.Context, name string) (persistence.Movie, error) {
+	row := sqlLayer.db.QueryRowContext(ctx, `SELECT `+movieColumns+` FROM movies WHERE name_key = ?`, persistence.MovieNameKey(name))
+	mv, err := scanMovie(row)
+	if err == sql.ErrNoRows {
+		return mv, persistence.NotFound("movie", "name", name)
+	}
+	return mv, toPersistenceError(err, "movie", nil)
+}
+
+func (sqlLayer *SQLiteLayer) FindAllMovies(ctx context.Context, expr filter.Expr, showDeleted bool, order persistence.Order, after persistence.Cursor, pgSize int32) ([]persistence.Movie, error) {
+	query, args, err := pageQuery(`SELECT `+movieColumns+` FROM movies`, "id", movieSortColumns, movieFilterColumns, expr, showDeleted, order, after, pgSize)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := sqlLayer.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, toPersistenceError(err, "movie", nil)
+	}
+	defer rows.Close()
+
+	var results []persistence.Movie
+	for rows.Next() {
+		mv, err := scanMovie(rows)
+		if err != nil {
+			return nil, toPersistenceError(err, "movie", nil)
+		}
+
+		results = append(results, mv)
+	}
+	return results, toPersistenceError(rows.Err(), "movie", nil)
+}
+
+// UpdateMovieByID replaces the descriptive fields of the movie with given ID.
+// The ID, status, creation and deletion time of the stored movie are left untouched.
+func (sqlLayer *SQLiteLayer) UpdateMovieByID(ctx context.Context, id string, etag string, mv persistence.Movie) ([]byte, error) {
+	sqlLayer.writeMu.Lock()
+	defer sqlLayer.writeMu.Unlock()
+
+	res, err := sqlLayer.db.ExecContext(ctx,
+		`UPDATE movies SET name = ?, name_key = ?, summary = ?, cast_members = ?, tags = ?, director = ?,
+			writers = ?, update_time = COALESCE(?, update_time), etag = ?
+		WHERE id = ? AND `+matchEtag,
+		mv.Name, persistence.MovieNameKey(mv.Name), mv.Summary, toJSON(mv.Cast), toJSON(mv.Tags), mv.Director,
+		toJSON(mv.Writers), toUnixNano(mv.UpdateTime), persistence.NewEtag(), id, etag,
+	)
+	err = sqlLayer.nameTaken(ctx, toPersistenceError(err, "movie", map[string]string{"name": mv.Name}))
+	if err := sqlLayer.checkEtag(ctx, checkAffected(res, err, "movie", "id", id), "movies"); err != nil {
+		return nil, err
+	}
+	stored, err := sqlLayer.FindMovieByID(ctx, id)
+	if err != nil {
+		log.Println("[WARN] Failed to read the updated movie: ", err)
+		return []byte(id), nil
+	}
+	if stored.DeleteTime == nil {
+		sqlLayer.movieIndex.Add(id, persistence.MovieDocument(stored))
+	}
+	sqlLayer.movieEvents.Publish(persistence.MovieUpdated, stored)
+	return []byte(id), nil
+}
+
+func (sqlLayer *SQLiteLayer) PatchMovieByID(ctx context.Context, id string, etag string, mv persistence.Movie, fields []string) ([]byte, error) {
+	set, args, err := moviePatch(mv, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	sqlLayer.writeMu.Lock()
+	defer sqlLayer.writeMu.Unlock()
+
+	args = append(args, toUnixNano(mv.UpdateTime), persistence.NewEtag(), id, etag)
+	res, err := sqlLayer.db.ExecContext(ctx,
+		`UPDATE movies SET `+set+`update_time = COALESCE(?, update_time), etag = ? WHERE id = ? AND `+matchEtag, args...)
+	err = sqlLayer.nameTaken(ctx, toPersistenceError(err, "movie", map[string]string{"name": mv.Name}))
+	if err := sqlLayer.checkEtag(ctx, checkAffected(res, err, "movie", "id", id), "movies"); err != nil {
+		return nil, err
+	}
+	stored, err := sqlLayer.FindMovieByID(ctx, id)
+	if err != nil {
+		log.Println("[WARN] Failed to read the updated movie: ", err)
+		return []byte(id), nil
+	}
+	if stored.DeleteTime == nil {
+		sqlLayer.movieIndex.Add(id, persistence.MovieDocument(stored))
+	}
+	sqlLayer.movieEvents.Publish(persistence.MovieUpdated, stored)
+	return []byte(id), nil
+}
+
+// nameTaken completes an error reporting a movie name already taken with the
+// ID of the movie holding it, any other error being returned as is.
+func (sqlLayer *SQLiteLayer) nameTaken(ctx context.Context, err error) error {
+	name, ok := persistence.TakenValue(err, "movie", "name")
+	if !ok {
+		return err
+	}
+	var id string
+	if err := sqlLayer.db.QueryRowContext(ctx, `SELECT id FROM movies WHERE name_key = ?`, persistence.MovieNameKey(name)).Scan(&id); err != nil {
+		// The movie holding the name may have been removed since
+		return persistence.AlreadyExists("movie", "name", name)
+	}
+	return persistence.AlreadyExistsAs("movie", "name", name, id)
+}
+
+// SetMovieDeleteTime also takes a deleted movie out of the search index, and
+// puts it back once restored.
+func (sqlLayer *SQLiteLayer) SetMovieDeleteTime(ctx context.Context, id string, etag string, deleteTime *timestamp.Timestamp) error {
+	sqlLayer.writeMu.Lock()
+	defer sqlLayer.writeMu.Unlock()
+
+	res, err := sqlLayer.db.ExecContext(ctx, `UPDATE movies SET delete_time = ?, etag = ? WHERE id = ? AND `+matchEtag,
+		toUnixNano(deleteTime), persistence.NewEtag(), id, etag)
+	if err := sqlLayer.checkEtag(ctx, checkAffected(res, err, "movie", "id", id), "movies"); err != nil {
+		return err
+	}
+	mv, err := sqlLayer.FindMovieByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if deleteTime != nil {
+		sqlLayer.movieIndex.Remove(id)
+		sqlLayer.movieEvents.Publish(persistence.MovieDeleted, mv)
+		return nil
+	}
+	sqlLayer.movieIndex.Add(id, persistence.MovieDocument(mv))
+	sqlLayer.movieEvents.Publish(persistence.MovieUndeleted, mv)
+	return nil
+}
+
+// PurgeMovies needs no change of the search index, which holds no deleted movies.
+func (sqlLayer *SQLiteLayer) PurgeMovies(ctx context.Context, deletedBefore time.Time) (int, error) {
+	res, err := sqlLayer.db.ExecContext(ctx, `DELETE FROM movies WHERE delete_time < ?`, deletedBefore.UnixNano())
+	return rowsAffected(res, err, "movie")
+}
+
+func (sqlLayer *SQLiteLayer) RemoveMovieByID(ctx context.Context, id string) error {
+	sqlLayer.writeMu.Lock()
+	defer sqlLayer.writeMu.Unlock()
+
+	res, err := sqlLayer.db.ExecContext(ctx, `DELETE FROM movies WHERE id = ?`, id)
+	if err := checkAffected(res, err, "movie", "id", id); err != nil {
+		return err
+	}
+	sqlLayer.movieIndex.Remove(id)
+	sqlLayer.movieEvents.Publish(persistence.MovieDeleted, persistence.Movie{Id: id})
+	return nil
+}
+
+func (sqlLayer *SQLiteLayer) WatchMovies(ctx context.Context, resumeToken string) (persistence.MovieStream, error) {
+	return sqlLayer.movieEvents.WatchMovies(ctx, resumeToken)
+}
+
+func (sqlLayer *SQLiteLayer) CountMovieRecords(ctx context.Context) (int, error) {
+	var count int
+	err := sqlLayer.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM movies`).Scan(&count)
+	return count, toPersistenceError(err, "movie", nil)
+}
+
+// scanner is implemented by both *sql.Row and *sql.Rows.
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanUser(row scanner) (persistence.User, error) {
+	var (
+		u                      persistence.User
+		role                   int32
+		lastName, nickname     sql.NullString
+		createTime, updateTime sql.NullInt64
+		deleteTime, verifyTime sql.NullInt64
+		age                    sql.NullInt32
+		height                 sql.NullFloat64
+		notifications          sql.NullBool
+	)
+
+	err := row.Scan(&u.Username, &u.Email, &u.Password, &role, &u.Active, &u.FirstName, &lastName,
+		&createTime, &updateTime, &age, &height, &nickname, &notifications, &deleteTime, &u.Etag, &verifyTime)
+	if err != nil {
+		return persistence.User{}, err
+	}
+
+	u.Role = persistence.Role(role)
+	u.CreateTime = toTimestamp(createTime)
+	u.UpdateTime = toTimestamp(updateTime)
+	u.DeleteTime = toTimestamp(deleteTime)
+	u.EmailVerifyTime = toTimestamp(verifyTime)
+	if lastName.Valid {
+		u.LastName = &lastName.String
+	}
+	if nickname.Valid {
+		u.Nickname = &nickname.String
+	}
+	if age.Valid {
+		u.Age = &age.Int32
+	}
+	if height.Valid {
+		u.HeightInCms = &height.Float64
+	}
+	if notifications.Valid {
+		u.EnableNotifications = &notifications.Bool
+	}
+	return u, nil
+}
+
+func scanMovie(row scanner) (persistence.Movie, error) {
+	var (
+		mv                     persistence.Movie
+		cast, tags, writers    string
+		createTime, updateTime sql.NullInt64
+		deleteTime             sql.NullInt64
+	)
+
+	err := row.Scan(&mv.Id, &mv.Name, &mv.Summary, &cast, &tags, &mv.Director,
+		&writers, &mv.Active, &createTime, &updateTime, &deleteTime, &mv.Etag)
+	if err != nil {
+		return persistence.Movie{}, err
+	}
+
+	if err := fromJSON(cast, &mv.Cast); err != nil {
+		return persistence.Movie{}, err
+	}
+	if err := fromJSON(tags, &mv.Tags); err != nil {
+		return persistence.Movie{}, err
+	}
+	if err := fromJSON(writers, &mv.Writers); err != nil {
+		return persistence.Movie{}, err
+	}
+	mv.CreateTime = toTimestamp(createTime)
+	mv.UpdateTime = toTimestamp(updateTime)
+	mv.DeleteTime = toTimestamp(deleteTime)
+	return mv, nil
+}
+
+// checkAffected turns a statement which matched no rows into a not found error.
+func checkAffected(res sql.Result, err error, resource, field, value string) error {
+	if err != nil {
+		return toPersistenceError(err, resource, nil)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return toPersistenceError(err, resource, nil)
+	}
+	if n == 0 {
+		return persistence.NotFound(resource, field, value)
+	}
+	return nil
+}
+
+// checkEtag tells apart the two causes of a conditional write to table which
+// matched no rows: a missing record is left not found, a record whose etag
+// changed since the caller read it is reported as an etag mismatch.
+func (sqlLayer *SQLiteLayer) checkEtag(ctx context.Context, err error, table string) error {
+	var perr *persistence.Error
+	if !errors.As(err, &perr) || perr.Kind != persistence.ErrNotFound {
+		return err
+	}
+	var count int
+	// The table and column are constants of this package, never user input
+	query := fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE %s = ?`, table, perr.Field)
+	if err := sqlLayer.db.QueryRowContext(ctx, query, perr.Value).Scan(&count); err != nil {
+		return toPersistenceError(err, perr.Resource, nil)
+	}
+	if count > 0 {
+		return persistence.EtagMismatch(perr.Resource, perr.Field, perr.Value)
+	}
+	return err
+}
+
+// rowsAffected returns the number of rows removed or changed by a statement.
+func rowsAffected(res sql.Result, err error, resource string) (int, error) {
+	if err != nil {
+		return 0, toPersistenceError(err, resource, nil)
+	}
+	n, err := res.RowsAffected()
+	return int(n), toPersistenceError(err, resource, nil)
+}
+
+// Result codes of SQLite, see https://www.sqlite.org/rescode.html
+const (
+	sqliteBusy             = 5
+	sqliteLocked           = 6
+	sqliteConstraintPK     = 1555
+	sqliteConstraintUnique = 2067
+)
+
+// moviePatch returns the assignments of the columns of the given fields of mv,
+// each followed by a comma, and their values. The name is set along with its key.
+func moviePatch(mv persistence.Movie, fields []string) (string, []interface{}, error) {
+	var set strings.Builder
+	var args []interface{}
+	for _, field := range fields {
+		var column string
+		var value interface{}
+		switch field {
+		case "name":
+			column, value = "name", mv.Name
+			set.WriteString("name_key = ?, ")
+			args = append(args, persistence.MovieNameKey(mv.Name))
+		case "summary":
+			column, value = "summary", mv.Summary
+		case "cast":
+			column, value = "cast_members", toJSON(mv.Cast)
+		case "tags":
+			column, value = "tags", toJSON(mv.Tags)
+		case "director":
+			column, value = "director", mv.Director
+		case "writers":
+			column, value = "writers", toJSON(mv.Writers)
+		default:
+			return "", nil, fmt.Errorf("unknown field %q of a movie", field)
+		}
+		set.WriteString(column + " = ?, ")
+		args = append(args, value)
+	}
+	return set.String(), args, nil
+}
+
+// toPersistenceError translates an error of the driver into the errors of the
+// persistence package. values holds the unique fields of the record written, to
+// tell which one is already taken.
+func toPersistenceError(err error, resource string, values map[string]string) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(*persistence.Error); ok {
+		return err
+	}
+
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return err
+	}
+	switch sqliteErr.Code() {
+	case sqliteConstraintPK, sqliteConstraintUnique:
+		// The message names the column, e.g. `UNIQUE constraint failed: users.email`
+		field := ""
+		if i := strings.LastIndex(sqliteErr.Error(), "."); i >= 0 {
+			field = strings.Fields(sqliteErr.Error()[i+1:])[0]
+		}
+		// The names are unique by their key
+		if field == "name_key" {
+			field = "name"
+		}
+		return persistence.AlreadyExists(resource, field, values[field])
+	case sqliteBusy, sqliteLocked:
+		return persistence.Conflict(resource, err)
+	}
+	return err
+}
+
+// pageQuery completes the query of a list with the records matching expr
+// after the cursor, sorted by order and then by the unique key column.
+// Deleted records are left out unless showDeleted is set.
+func pageQuery(query string, key string, sortColumns, filterColumns map[string]string, expr filter.Expr,
+	showDeleted bool, order persistence.Order, after persistence.Cursor, pgSize int32) (string, []interface{}, error) {
+	var args []interface{}
+
+	var conds []string
+	if !showDeleted {
+		conds = append(conds, `delete_time IS NULL`)
+	}
+	if expr != nil {
+		cond, err := whereFilter(expr, filterColumns, &args)
+		if err != nil {
+			return "", nil, err
+		}
+		conds = append(conds, cond)
+	}
+	if !after.IsZero() {
+		cond, err := afterCursor(key, sortColumns, order, after, &args)
+		if err != nil {
+			return "", nil, err
+		}
+		conds = append(conds, cond)
+	}
+	if len(conds) > 0 {
+		query += ` WHERE ` + strings.Join(conds, " AND ")
+	}
+	return query + ` ORDER BY ` + orderBy(key, sortColumns, order) + ` LIMIT ?`, append(args, limit(pgSize)), nil
+}
+
+// limit maps a page size of 0 to no limit at all, like Mongo does.
+func limit(pgSize int32) int64 {
+	if pgSize <= 0 {
+		return -1
+	}
+	return int64(pgSize)
+}
+
+// Lists are stored as JSON arrays, since SQLite has no array columns.
+func toJSON(v interface{}) string {
+	b, _ := json.Marshal(v)
+	if string(b) == "null" {
+		return "[]"
+	}
+	return string(b)
+}
+
+func fromJSON(s string, v interface{}) error {
+	if s == "" || s == "[]" {
+		return nil
+	}
+	return json.Unmarshal([]byte(s), v)
+}
+
+// Timestamps are stored as nanoseconds since the Unix epoch, which keeps them
+// both lossless and sortable.
+func toUnixNano(ts *timestamp.Timestamp) sql.NullInt64 {
+	if ts == nil {
+		return sql.NullInt64{}
+	}
+	t, err := ptypes.Timestamp(ts)
+	return sql.NullInt64{Int64: t.UnixNano(), Valid: err == nil}
+}
+
+func toTimestamp(n sql.NullInt64) *timestamp.Timestamp {
+	if !n.Valid {
+		return nil
+	}
+	ts, err := ptypes.TimestampProto(time.Unix(0, n.Int64))
+	if err != nil {
+		return nil
+	}
+	return ts
+}
@@ -0,0 +1,768 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/AkashGit21/ms-project/lib/filter"
+	"github.com/AkashGit21/ms-project/lib/persistence"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func newTestLayer(t *testing.T) *SQLiteLayer {
+	dbhandler, err := NewSQLiteLayer(":memory:")
+	if err != nil {
+		t.Fatalf("NewSQLiteLayer: unexpected err %v", err)
+	}
+	return dbhandler.(*SQLiteLayer)
+}
+
+func TestMigrate_reopen(t *testing.T) {
+	ctx := context.Background()
+	file := filepath.Join(t.TempDir(), "test.db")
+
+	dbhandler, err := NewSQLiteLayer(file)
+	if err != nil {
+		t.Fatalf("NewSQLiteLayer: unexpected err %v", err)
+	}
+	if _, err := dbhandler.AddMovie(ctx, persistence.Movie{Name: "test_movie"}); err != nil {
+		t.Fatalf("AddMovie: unexpected err %v", err)
+	}
+	dbhandler.(*SQLiteLayer).db.Close()
+
+	// Opening the same file again must neither fail nor lose data
+	dbhandler, err = NewSQLiteLayer(file)
+	if err != nil {
+		t.Fatalf("NewSQLiteLayer: unexpected err on reopen %v", err)
+	}
+	if got, _ := dbhandler.CountMovieRecords(ctx); got != 1 {
+		t.Errorf("CountMovieRecords: want 1, got %d", got)
+	}
+	// The search index is rebuilt from the stored movies
+	if hits, err := dbhandler.SearchMovies(ctx, "test_movie", 0, 0); err != nil || len(hits) != 1 {
+		t.Errorf("SearchMovies: want 1 hit after reopen, got %v, %v", hits, err)
+	}
+}
+
+func TestMigrate_nameKeys(t *testing.T) {
+	ctx := context.Background()
+	file := filepath.Join(t.TempDir(), "test.db")
+
+	// A database from before the movie name keys
+	db, err := sql.Open("sqlite", file)
+	if err != nil {
+		t.Fatalf("sql.Open: unexpected err %v", err)
+	}
+	for i, migration := range migrations[:8] {
+		if _, err := db.ExecContext(ctx, migration); err != nil {
+			t.Fatalf("migration %d: unexpected err %v", i+1, err)
+		}
+	}
+	if _, err := db.ExecContext(ctx, `PRAGMA user_version = 8`); err != nil {
+		t.Fatalf("PRAGMA user_version: unexpected err %v", err)
+	}
+	if _, err := db.ExecContext(ctx, `INSERT INTO movies (id, name) VALUES ('id_0', 'Amélie')`); err != nil {
+		t.Fatalf("INSERT: unexpected err %v", err)
+	}
+	db.Close()
+
+	// The keys of the stored names are filled in
+	dbhandler, err := NewSQLiteLayer(file)
+	if err != nil {
+		t.Fatalf("NewSQLiteLayer: unexpected err %v", err)
+	}
+	if mv, err := dbhandler.FindMovieByName(ctx, "AMÉLIE"); err != nil || mv.Id != "id_0" {
+		t.Errorf("FindMovieByName: want id_0, got %v, %v", mv, err)
+	}
+	if _, err := dbhandler.AddMovie(ctx, persistence.Movie{Id: "id_1", Name: "amélie"}); !errors.Is(err, persistence.ErrAlreadyExists) {
+		t.Errorf("AddMovie: want ErrAlreadyExists, got %v", err)
+	}
+}
+
+func TestUsers(t *testing.T) {
+	ctx := context.Background()
+	sqlLayer := newTestLayer(t)
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("test_pwd"), bcrypt.MinCost)
+	lastName := "test_last"
+	user := persistence.User{
+		Username:   "test_username",
+		Email:      "test_email@domain.com",
+		Password:   string(hash),
+		Role:       persistence.Role_ADMIN,
+		Active:     true,
+		LastName:   &lastName,
+		CreateTime: ptypes.TimestampNow(),
+	}
+	if _, err := sqlLayer.AddUser(ctx, user); err != nil {
+		t.Fatalf("AddUser: unexpected err %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		user    persistence.User
+		wantErr bool
+	}{
+		{"duplicate_username", persistence.User{Username: "test_username", Email: "other@domain.com"}, true},
+		{"duplicate_email", persistence.User{Username: "test_username2", Email: "test_email@domain.com"}, true},
+		{"added_user", persistence.User{Username: "test_username2", Email: "other@domain.com"}, false},
+	}
+	for _, tcase := range tests {
+		t.Run(tcase.name, func(t *testing.T) {
+			_, err := sqlLayer.AddUser(ctx, tcase.user)
+			if (err != nil) != tcase.wantErr {
+				t.Errorf("AddUser: want err %v, got %v", tcase.wantErr, err)
+			}
+		})
+	}
+
+	found, err := sqlLayer.FindByUsername(ctx, "test_username")
+	if err != nil {
+		t.Fatalf("FindByUsername: unexpected err %v", err)
+	}
+	if found.Role != user.Role || *found.LastName != lastName || !found.CreateTime.AsTime().Equal(user.CreateTime.AsTime()) {
+		t.Errorf("FindByUsername: want %v, got %v", user, found)
+	}
+
+	if got, _ := sqlLayer.CountUsers(ctx); got != 2 {
+		t.Errorf("CountUsers: want 2, got %d", got)
+	}
+	if ok, err := sqlLayer.Authenticate(ctx, "test_username", "test_pwd"); !ok || err != nil {
+		t.Errorf("Authenticate: want true for correct password, got %v, %v", ok, err)
+	}
+	if ok, _ := sqlLayer.Authenticate(ctx, "test_username", "bad_pwd"); ok {
+		t.Error("Authenticate: want false for wrong password")
+	}
+	if _, err := sqlLayer.Authenticate(ctx, "missing_user", "test_pwd"); !errors.Is(err, persistence.ErrNotFound) {
+		t.Errorf("Authenticate: want ErrNotFound for missing user, got %v", err)
+	}
+
+	// Users without creation time come first
+	users, err := sqlLayer.FindAllUsers(ctx, false, persistence.DefaultOrder, persistence.Cursor{Values: []interface{}{time.Time{}}, Key: "test_username2"}, 12)
+	if err != nil || len(users) != 1 || users[0].Username != "test_username" {
+		t.Errorf("FindAllUsers: unexpected result %v, %v", users, err)
+	}
+
+	if err := sqlLayer.RemoveByUsername(ctx, "test_username"); err != nil {
+		t.Errorf("RemoveByUsername: unexpected err %v", err)
+	}
+	if err := sqlLayer.RemoveByUsername(ctx, "test_username"); !errors.Is(err, persistence.ErrNotFound) {
+		t.Errorf("RemoveByUsername: want ErrNotFound for missing user, got %v", err)
+	}
+}
+
+func TestUpdateByUsername(t *testing.T) {
+	ctx := context.Background()
+	sqlLayer := newTestLayer(t)
+
+	lastName := "test_last"
+	user := persistence.User{Username: "test_username", Email: "test_email@domain.com", Password: "test_hash",
+		Role: persistence.Role_NORMAL, Active: true, LastName: &lastName, CreateTime: &timestamp.Timestamp{Seconds: 1}}
+	if _, err := sqlLayer.AddUser(ctx, user); err != nil {
+		t.Fatalf("AddUser: unexpected err %v", err)
+	}
+	if _, err := sqlLayer.AddUser(ctx, persistence.User{Username: "test_username2", Email: "other@domain.com"}); err != nil {
+		t.Fatalf("AddUser: unexpected err %v", err)
+	}
+	stored, _ := sqlLayer.FindByUsername(ctx, "test_username")
+
+	nickname := "test_nick"
+	update := persistence.User{Username: "ignored", Email: "new_email@domain.com", Password: "new_hash",
+		Role: persistence.Role_ADMIN, FirstName: "test_first", Nickname: &nickname, UpdateTime: &timestamp.Timestamp{Seconds: 2}}
+	if _, err := sqlLayer.UpdateByUsername(ctx, "test_username", stored.Etag, update); err != nil {
+		t.Fatalf("UpdateByUsername: unexpected err %v", err)
+	}
+	found, err := sqlLayer.FindByUsername(ctx, "test_username")
+	if err != nil || found.Email != update.Email || found.Password != update.Password || found.Role != update.Role ||
+		found.FirstName != update.FirstName || found.Nickname == nil || *found.Nickname != nickname || found.LastName != nil {
+		t.Errorf("FindByUsername: want the fields of the update, got %+v, %v", found, err)
+	}
+	// The identity, status and creation time are kept
+	if !found.Active || found.CreateTime.GetSeconds() != 1 || found.UpdateTime.GetSeconds() != 2 || found.Etag == stored.Etag {
+		t.Errorf("FindByUsername: want the status and creation time kept with a new etag, got %+v", found)
+	}
+
+	tests := []struct {
+		name    string
+		uname   string
+		etag    string
+		user    persistence.User
+		wantErr error
+	}{
+		{"stale_etag", "test_username", stored.Etag, update, persistence.ErrEtagMismatch},
+		{"missing_user", "missing_user", "", update, persistence.ErrNotFound},
+		{"duplicate_email", "test_username", "", persistence.User{Email: "other@domain.com"}, persistence.ErrAlreadyExists},
+	}
+	for _, tcase := range tests {
+		t.Run(tcase.name, func(t *testing.T) {
+			if _, err := sqlLayer.UpdateByUsername(ctx, tcase.uname, tcase.etag, tcase.user); !errors.Is(err, tcase.wantErr) {
+				t.Errorf("UpdateByUsername: want %v, got %v", tcase.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestSetUserEmailVerifyTime(t *testing.T) {
+	ctx := context.Background()
+	sqlLayer := newTestLayer(t)
+
+	if _, err := sqlLayer.AddUser(ctx, persistence.User{Username: "test_username", Email: "test_email@domain.com"}); err != nil {
+		t.Fatalf("AddUser: unexpected err %v", err)
+	}
+	stored, _ := sqlLayer.FindByUsername(ctx, "test_username")
+	if stored.EmailVerifyTime != nil {
+		t.Errorf("FindByUsername: want the email not verified, got %v", stored.EmailVerifyTime)
+	}
+
+	if err := sqlLayer.SetUserEmailVerifyTime(ctx, "test_username", stored.Etag, &timestamp.Timestamp{Seconds: 2}); err != nil {
+		t.Fatalf("SetUserEmailVerifyTime: unexpected err %v", err)
+	}
+	if err := sqlLayer.SetUserEmailVerifyTime(ctx, "test_username", stored.Etag, nil); !errors.Is(err, persistence.ErrEtagMismatch) {
+		t.Errorf("SetUserEmailVerifyTime: want %v, got %v", persistence.ErrEtagMismatch, err)
+	}
+	if err := sqlLayer.SetUserEmailVerifyTime(ctx, "missing_user", "", nil); !errors.Is(err, persistence.ErrNotFound) {
+		t.Errorf("SetUserEmailVerifyTime: want %v, got %v", persistence.ErrNotFound, err)
+	}
+	verified, _ := sqlLayer.FindByUsername(ctx, "test_username")
+	if verified.EmailVerifyTime.GetSeconds() != 2 || verified.Etag == stored.Etag {
+		t.Errorf("FindByUsername: want the email verified with a new etag, got %+v", verified)
+	}
+
+	// The verification is kept along with the email, and cleared with it
+	if _, err := sqlLayer.UpdateByUsername(ctx, "test_username", "", persistence.User{Email: verified.Email, FirstName: "test_first"}); err != nil {
+		t.Fatalf("UpdateByUsername: unexpected err %v", err)
+	}
+	if found, _ := sqlLayer.FindByUsername(ctx, "test_username"); found.EmailVerifyTime.GetSeconds() != 2 {
+		t.Errorf("UpdateByUsername: want the verification kept, got %v", found.EmailVerifyTime)
+	}
+	if _, err := sqlLayer.UpdateByUsername(ctx, "test_username", "", persistence.User{Email: "new_email@domain.com"}); err != nil {
+		t.Fatalf("UpdateByUsername: unexpected err %v", err)
+	}
+	if found, _ := sqlLayer.FindByUsername(ctx, "test_username"); found.EmailVerifyTime != nil {
+		t.Errorf("UpdateByUsername: want the verification cleared, got %v", found.EmailVerifyTime)
+	}
+}
+
+func TestMovies(t *testing.T) {
+	ctx := context.Background()
+	sqlLayer := newTestLayer(t)
+
+	for i := 0; i < 5; i++ {
+		mv := persistence.Movie{
+			Id:      fmt.Sprintf("id_%d", i),
+			Name:    fmt.Sprintf("test_movie_%d", i),
+			Cast:    []string{"test_cast1", "test_cast2"},
+			Tags:    []persistence.Tag{persistence.Tag_Action, persistence.Tag_Comedy},
+			Writers: []string{"test_writer"},
+		}
+		if _, err := sqlLayer.AddMovie(ctx, mv); err != nil {
+			t.Fatalf("AddMovie: unexpected err %v", err)
+		}
+	}
+	_, err := sqlLayer.AddMovie(ctx, persistence.Movie{Name: "test_movie_0"})
+	var perr *persistence.Error
+	if !errors.As(err, &perr) || perr.Kind != persistence.ErrAlreadyExists || perr.Field != "name" {
+		t.Errorf("AddMovie: want ErrAlreadyExists on name for duplicate name, got %v", err)
+	}
+
+	movies, err := sqlLayer.FindAllMovies(ctx, nil, false, persistence.DefaultOrder, persistence.Cursor{Values: []interface{}{time.Time{}}, Key: "id_2"}, 12)
+	if err != nil || len(movies) != 2 || movies[0].Id != "id_3" {
+		t.Errorf("FindAllMovies: unexpected result %v, %v", movies, err)
+	}
+
+	found, err := sqlLayer.FindMovieByID(ctx, "id_1")
+	if err != nil {
+		t.Fatalf("FindMovieByID: unexpected err %v", err)
+	}
+	wantTags := []persistence.Tag{persistence.Tag_Action, persistence.Tag_Comedy}
+	if !reflect.DeepEqual(found.Tags, wantTags) || !reflect.DeepEqual(found.Cast, []string{"test_cast1", "test_cast2"}) {
+		t.Errorf("FindMovieByID: unexpected movie %v", found)
+	}
+
+	if _, err := sqlLayer.UpdateMovieByID(ctx, "id_1", "", persistence.Movie{Name: "test_movie_2"}); !errors.Is(err, persistence.ErrAlreadyExists) {
+		t.Errorf("UpdateMovieByID: want ErrAlreadyExists for duplicate name, got %v", err)
+	}
+	if _, err := sqlLayer.UpdateMovieByID(ctx, "missing", "", persistence.Movie{Name: "test_movie"}); !errors.Is(err, persistence.ErrNotFound) {
+		t.Errorf("UpdateMovieByID: want ErrNotFound for missing movie, got %v", err)
+	}
+	if _, err := sqlLayer.UpdateMovieByID(ctx, "id_1", "", persistence.Movie{Name: "test_movie_renamed"}); err != nil {
+		t.Errorf("UpdateMovieByID: unexpected err %v", err)
+	}
+	if found, _ = sqlLayer.FindMovieByID(ctx, "id_1"); found.Name != "test_movie_renamed" || found.Cast != nil {
+		t.Errorf("UpdateMovieByID: unexpected stored movie %v", found)
+	}
+
+	if err := sqlLayer.RemoveMovieByID(ctx, "id_1"); err != nil {
+		t.Errorf("RemoveMovieByID: unexpected err %v", err)
+	}
+	if got, _ := sqlLayer.CountMovieRecords(ctx); got != 4 {
+		t.Errorf("CountMovieRecords: want 4, got %d", got)
+	}
+}
+
+func TestPatchMovieByID(t *testing.T) {
+	ctx := context.Background()
+	sqlLayer := newTestLayer(t)
+
+	mv := persistence.Movie{Id: "id_0", Name: "Inception", Summary: "test_summary", Cast: []string{"test_actor"},
+		Tags: []persistence.Tag{persistence.Tag_Action}, Director: "test_director"}
+	if _, err := sqlLayer.AddMovie(ctx, mv); err != nil {
+		t.Fatalf("AddMovie: unexpected err %v", err)
+	}
+	if _, err := sqlLayer.AddMovie(ctx, persistence.Movie{Id: "id_1", Name: "Interstellar"}); err != nil {
+		t.Fatalf("AddMovie: unexpected err %v", err)
+	}
+	stored, _ := sqlLayer.FindMovieByID(ctx, "id_0")
+
+	// Only the given fields change, even to empty values
+	patch := persistence.Movie{Name: "ignored", Summary: "test_summary2", Tags: []persistence.Tag{persistence.Tag_Comedy},
+		UpdateTime: &timestamp.Timestamp{Seconds: 2}}
+	if _, err := sqlLayer.PatchMovieByID(ctx, "id_0", stored.Etag, patch, []string{"summary", "tags", "cast"}); err != nil {
+		t.Fatalf("PatchMovieByID: unexpected err %v", err)
+	}
+	found, err := sqlLayer.FindMovieByID(ctx, "id_0")
+	if err != nil || found.Name != mv.Name || found.Summary != patch.Summary || len(found.Cast) != 0 ||
+		len(found.Tags) != 1 || found.Tags[0] != persistence.Tag_Comedy || found.Director != mv.Director ||
+		found.UpdateTime.GetSeconds() != 2 || found.Etag == stored.Etag {
+		t.Errorf("FindMovieByID: want the given fields patched, got %+v, %v", found, err)
+	}
+
+	tests := []struct {
+		name    string
+		id      string
+		etag    string
+		fields  []string
+		wantErr error
+	}{
+		{"stale_etag", "id_0", stored.Etag, []string{"summary"}, persistence.ErrEtagMismatch},
+		{"missing_movie", "missing", "", []string{"summary"}, persistence.ErrNotFound},
+		{"duplicate_name", "id_0", "", []string{"name"}, persistence.ErrAlreadyExists},
+	}
+	for _, tcase := range tests {
+		t.Run(tcase.name, func(t *testing.T) {
+			patch := persistence.Movie{Name: "Interstellar", Summary: "test_summary3"}
+			if _, err := sqlLayer.PatchMovieByID(ctx, tcase.id, tcase.etag, patch, tcase.fields); !errors.Is(err, tcase.wantErr) {
+				t.Errorf("PatchMovieByID: want %v, got %v", tcase.wantErr, err)
+			}
+		})
+	}
+	if _, err := sqlLayer.PatchMovieByID(ctx, "id_0", "", patch, []string{"active"}); err == nil {
+		t.Error("PatchMovieByID: want an error for an unknown field")
+	}
+}
+
+func TestMovieNameUniqueness(t *testing.T) {
+	ctx := context.Background()
+	sqlLayer := newTestLayer(t)
+
+	for _, mv := range []persistence.Movie{{Id: "id_0", Name: "The Dark Knight"}, {Id: "id_1", Name: "Inception"}} {
+		if _, err := sqlLayer.AddMovie(ctx, mv); err != nil {
+			t.Fatalf("AddMovie: unexpected err %v", err)
+		}
+	}
+
+	// Names differing by case only are taken, by the movie given in the error
+	wantTaken := func(op string, err error) {
+		var perr *persistence.Error
+		if !errors.Is(err, persistence.ErrAlreadyExists) || !errors.As(err, &perr) || perr.Field != "name" || perr.ID != "id_0" {
+			t.Errorf("%s: want the name taken by id_0, got %v", op, err)
+		}
+	}
+	_, err := sqlLayer.AddMovie(ctx, persistence.Movie{Id: "id_2", Name: "THE DARK KNIGHT"})
+	wantTaken("AddMovie", err)
+	_, err = sqlLayer.UpdateMovieByID(ctx, "id_1", "", persistence.Movie{Name: "the dark knight"})
+	wantTaken("UpdateMovieByID", err)
+	_, err = sqlLayer.PatchMovieByID(ctx, "id_1", "", persistence.Movie{Name: "The Dark knight"}, []string{"name"})
+	wantTaken("PatchMovieByID", err)
+	if mv, err := sqlLayer.FindMovieByName(ctx, "the DARK knight"); err != nil || mv.Id != "id_0" {
+		t.Errorf("FindMovieByName: want id_0, got %v, %v", mv, err)
+	}
+
+	// So are the names differing by the case of non-ASCII letters
+	if _, err := sqlLayer.AddMovie(ctx, persistence.Movie{Id: "id_3", Name: "Amélie"}); err != nil {
+		t.Fatalf("AddMovie: unexpected err %v", err)
+	}
+	_, err = sqlLayer.AddMovie(ctx, persistence.Movie{Id: "id_4", Name: "AMÉLIE"})
+	if !errors.Is(err, persistence.ErrAlreadyExists) {
+		t.Errorf("AddMovie: want ErrAlreadyExists for AMÉLIE, got %v", err)
+	}
+	if mv, err := sqlLayer.FindMovieByName(ctx, "AMÉLIE"); err != nil || mv.Id != "id_3" {
+		t.Errorf("FindMovieByName: want id_3, got %v, %v", mv, err)
+	}
+
+	// A movie may change the case of its own name
+	if _, err := sqlLayer.PatchMovieByID(ctx, "id_0", "", persistence.Movie{Name: "the dark knight"}, []string{"name"}); err != nil {
+		t.Errorf("PatchMovieByID: unexpected err %v", err)
+	}
+	// Removing the movie frees its name
+	if err := sqlLayer.RemoveMovieByID(ctx, "id_0"); err != nil {
+		t.Fatalf("RemoveMovieByID: unexpected err %v", err)
+	}
+	if _, err := sqlLayer.FindMovieByName(ctx, "The Dark Knight"); !errors.Is(err, persistence.ErrNotFound) {
+		t.Errorf("FindMovieByName: want ErrNotFound for a freed name, got %v", err)
+	}
+	if _, err := sqlLayer.AddMovie(ctx, persistence.Movie{Id: "id_2", Name: "The Dark Knight"}); err != nil {
+		t.Errorf("AddMovie: want the name freed, got %v", err)
+	}
+}
+
+func TestFindAllMovies_filter(t *testing.T) {
+	ctx := context.Background()
+	sqlLayer := newTestLayer(t)
+
+	movies := []persistence.Movie{
+		{Id: "id_0", Name: "Inception", Director: "Nolan", Cast: []string{"DiCaprio"},
+			Tags: []persistence.Tag{persistence.Tag_Action}, Active: true, CreateTime: &timestamp.Timestamp{Seconds: 1609459200}},
+		{Id: "id_1", Name: "The Prestige", Director: "Nolan", Cast: []string{"Bale", "Jackman"},
+			Tags: []persistence.Tag{persistence.Tag_Fantasy}, Active: true, CreateTime: &timestamp.Timestamp{Seconds: 1625097600}},
+		{Id: "id_2", Name: "Up", Director: "Docter", Cast: []string{"Asner"},
+			Tags: []persistence.Tag{persistence.Tag_Adventure, persistence.Tag_Comedy}, CreateTime: &timestamp.Timestamp{Seconds: 1640995200}},
+		{Id: "id_3", Name: "Draft"},
+	}
+	for _, mv := range movies {
+		if _, err := sqlLayer.AddMovie(ctx, mv); err != nil {
+			t.Fatalf("AddMovie: unexpected err %v", err)
+		}
+	}
+
+	tests := []struct {
+		filter   string
+		expected string
+	}{
+		{``, "[id_3 id_0 id_1 id_2]"},
+		{`director = "Nolan"`, "[id_0 id_1]"},
+		{`director = "Nolan" AND tags:Action`, "[id_0]"},
+		{`cast:Bale OR tags:Comedy`, "[id_1 id_2]"},
+		{`NOT director = "Nolan"`, "[id_3 id_2]"},
+		{`active = true AND create_time > "2021-01-01"`, "[id_1]"},
+		{`create_time >= "2021-07-01" AND create_time < "2022-01-01T00:00:01Z"`, "[id_1 id_2]"},
+		{`create_time != "2021-07-01"`, "[id_0 id_2]"},
+	}
+	for _, tcase := range tests {
+		t.Run(tcase.filter, func(t *testing.T) {
+			expr, err := filter.Parse(tcase.filter, persistence.MovieFilterSchema)
+			if err != nil {
+				t.Fatalf("Parse: unexpected err %v", err)
+			}
+			found, err := sqlLayer.FindAllMovies(ctx, expr, false, persistence.DefaultOrder, persistence.Cursor{}, 0)
+			if err != nil {
+				t.Fatalf("FindAllMovies: unexpected err %v", err)
+			}
+			var ids []string
+			for _, mv := range found {
+				ids = append(ids, mv.Id)
+			}
+			if fmt.Sprint(ids) != tcase.expected {
+				t.Errorf("FindAllMovies: want %v, got %v", tcase.expected, ids)
+			}
+		})
+	}
+
+	// The filter applies before the page size
+	expr, _ := filter.Parse(`director = "Nolan"`, persistence.MovieFilterSchema)
+	found, err := sqlLayer.FindAllMovies(ctx, expr, false, persistence.DefaultOrder, persistence.Cursor{Values: []interface{}{time.Time{}}, Key: "id_3"}, 1)
+	if err != nil || len(found) != 1 || found[0].Id != "id_0" {
+		t.Errorf("FindAllMovies: unexpected page %v, %v", found, err)
+	}
+}
+
+func TestFindAllMovies_order(t *testing.T) {
+	ctx := context.Background()
+	sqlLayer := newTestLayer(t)
+
+	movies := []persistence.Movie{
+		{Id: "id_0", Name: "Inception", Director: "Nolan", CreateTime: &timestamp.Timestamp{Seconds: 1609459200}},
+		{Id: "id_1", Name: "The Prestige", Director: "Nolan", CreateTime: &timestamp.Timestamp{Seconds: 1625097600}},
+		{Id: "id_2", Name: "Up", Director: "Docter", CreateTime: &timestamp.Timestamp{Seconds: 1640995200}},
+		{Id: "id_3", Name: "Draft"},
+	}
+	for _, mv := range movies {
+		if _, err := sqlLayer.AddMovie(ctx, mv); err != nil {
+			t.Fatalf("AddMovie: unexpected err %v", err)
+		}
+	}
+
+	tests := []struct {
+		orderBy  string
+		expected string
+	}{
+		{"", "[id_3 id_0 id_1 id_2]"},
+		{"name desc", "[id_2 id_1 id_0 id_3]"},
+		{"director desc, create_time", "[id_0 id_1 id_2 id_3]"},
+		{"create_time desc", "[id_2 id_1 id_0 id_3]"},
+		{"update_time desc, name", "[id_3 id_0 id_1 id_2]"},
+	}
+	for _, tcase := range tests {
+		t.Run(tcase.orderBy, func(t *testing.T) {
+			order, err := persistence.ParseOrder(tcase.orderBy, persistence.MovieSortFields)
+			if err != nil {
+				t.Fatalf("ParseOrder: unexpected err %v", err)
+			}
+
+			// Page by page, so that every cursor is used
+			var ids []string
+			after := persistence.Cursor{}
+			for len(ids) <= len(movies) {
+				found, err := sqlLayer.FindAllMovies(ctx, nil, false, order, after, 1)
+				if err != nil {
+					t.Fatalf("FindAllMovies: unexpected err %v", err)
+				}
+				if len(found) == 0 {
+					break
+				}
+				ids = append(ids, found[0].Id)
+				after = order.MovieCursor(found[0])
+			}
+			if fmt.Sprint(ids) != tcase.expected {
+				t.Errorf("FindAllMovies: want %v, got %v", tcase.expected, ids)
+			}
+		})
+	}
+}
+
+func TestSearchMovies(t *testing.T) {
+	ctx := context.Background()
+	sqlLayer := newTestLayer(t)
+
+	movies := []persistence.Movie{
+		{Id: "id_0", Name: "Inception", Summary: "A thief who steals secrets through dream-sharing", Director: "Nolan"},
+		{Id: "id_1", Name: "The Prestige", Summary: "Two rival magicians", Director: "Nolan", Cast: []string{"Christian Bale"}},
+		{Id: "id_2", Name: "Dreamgirls", Summary: "A trio of singers", Writers: []string{"Bill Condon"}},
+		{Id: "id_3", Name: "Dream House", Summary: "A family moves into a house"},
+	}
+	for _, mv := range movies {
+		if _, err := sqlLayer.AddMovie(ctx, mv); err != nil {
+			t.Fatalf("AddMovie: unexpected err %v", err)
+		}
+	}
+
+	tests := []struct {
+		query    string
+		offset   int32
+		limit    int32
+		expected string
+	}{
+		{"dream", 0, 0, "[id_3 id_0]"},
+		{"NOLAN bale", 0, 0, "[id_1 id_0]"},
+		{"condon", 0, 0, "[id_2]"},
+		{"dream nolan", 1, 2, "[id_0 id_1]"},
+		{"matrix", 0, 0, "[]"},
+		{"the", 0, 0, "[]"},
+	}
+	for _, tcase := range tests {
+		t.Run(tcase.query, func(t *testing.T) {
+			hits, err := sqlLayer.SearchMovies(ctx, tcase.query, tcase.offset, tcase.limit)
+			if err != nil {
+				t.Fatalf("SearchMovies: unexpected err %v", err)
+			}
+			ids := []string{}
+			for _, hit := range hits {
+				ids = append(ids, hit.Movie.Id)
+			}
+			if fmt.Sprint(ids) != tcase.expected {
+				t.Errorf("SearchMovies: want %v, got %v", tcase.expected, ids)
+			}
+		})
+	}
+
+	// Movies deleted by another process are skipped before paging, so that
+	// the pages stay full
+	if _, err := sqlLayer.db.ExecContext(ctx, `UPDATE movies SET delete_time = 1 WHERE id = 'id_3'`); err != nil {
+		t.Fatalf("ExecContext: unexpected err %v", err)
+	}
+	hits, err := sqlLayer.SearchMovies(ctx, "dream nolan", 0, 2)
+	if err != nil || len(hits) != 2 || hits[0].Movie.Id != "id_0" || hits[1].Movie.Id != "id_1" {
+		t.Errorf("SearchMovies: want [id_0 id_1] without the deleted movie, got %v, %v", hits, err)
+	}
+	if hits, _ := sqlLayer.SearchMovies(ctx, "dream nolan", 2, 2); len(hits) != 0 {
+		t.Errorf("SearchMovies: want no hits past the end, got %v", hits)
+	}
+	if _, err := sqlLayer.db.ExecContext(ctx, `UPDATE movies SET delete_time = NULL WHERE id = 'id_3'`); err != nil {
+		t.Fatalf("ExecContext: unexpected err %v", err)
+	}
+
+	// The index follows the updates and removals
+	if _, err := sqlLayer.UpdateMovieByID(ctx, "id_3", "", persistence.Movie{Name: "House"}); err != nil {
+		t.Fatalf("UpdateMovieByID: unexpected err %v", err)
+	}
+	if err := sqlLayer.RemoveMovieByID(ctx, "id_0"); err != nil {
+		t.Fatalf("RemoveMovieByID: unexpected err %v", err)
+	}
+	if hits, _ := sqlLayer.SearchMovies(ctx, "dream", 0, 0); len(hits) != 0 {
+		t.Errorf("SearchMovies: want no hits after update and removal, got %v", hits)
+	}
+}
+
+func TestSoftDelete(t *testing.T) {
+	ctx := context.Background()
+	sqlLayer := newTestLayer(t)
+
+	for _, mv := range []persistence.Movie{{Id: "id_0", Name: "Inception"}, {Id: "id_1", Name: "Interstellar"}} {
+		if _, err := sqlLayer.AddMovie(ctx, mv); err != nil {
+			t.Fatalf("AddMovie: unexpected err %v", err)
+		}
+	}
+	if _, err := sqlLayer.AddUser(ctx, persistence.User{Username: "test_username", Email: "test_email@domain.in"}); err != nil {
+		t.Fatalf("AddUser: unexpected err %v", err)
+	}
+
+	deleted := &timestamp.Timestamp{Seconds: 1609459200}
+	if err := sqlLayer.SetMovieDeleteTime(ctx, "id_0", "", deleted); err != nil {
+		t.Fatalf("SetMovieDeleteTime: unexpected err %v", err)
+	}
+	if err := sqlLayer.SetUserDeleteTime(ctx, "test_username", "", deleted); err != nil {
+		t.Fatalf("SetUserDeleteTime: unexpected err %v", err)
+	}
+	if err := sqlLayer.SetMovieDeleteTime(ctx, "id_9", "", deleted); !errors.Is(err, persistence.ErrNotFound) {
+		t.Errorf("SetMovieDeleteTime: want ErrNotFound for missing movie, got %v", err)
+	}
+
+	tests := []struct {
+		showDeleted bool
+		movies      string
+		users       int
+	}{
+		{false, "[id_1]", 0},
+		{true, "[id_0 id_1]", 1},
+	}
+	for _, tcase := range tests {
+		t.Run(fmt.Sprint("show_deleted_", tcase.showDeleted), func(t *testing.T) {
+			movies, err := sqlLayer.FindAllMovies(ctx, nil, tcase.showDeleted, persistence.DefaultOrder, persistence.Cursor{}, 0)
+			if err != nil {
+				t.Fatalf("FindAllMovies: unexpected err %v", err)
+			}
+			ids := []string{}
+			for _, mv := range movies {
+				ids = append(ids, mv.Id)
+			}
+			if fmt.Sprint(ids) != tcase.movies {
+				t.Errorf("FindAllMovies: want %v, got %v", tcase.movies, ids)
+			}
+
+			users, err := sqlLayer.FindAllUsers(ctx, tcase.showDeleted, persistence.DefaultOrder, persistence.Cursor{}, 0)
+			if err != nil || len(users) != tcase.users {
+				t.Errorf("FindAllUsers: want %d users, got %v, %v", tcase.users, users, err)
+			}
+		})
+	}
+
+	if mv, err := sqlLayer.FindMovieByID(ctx, "id_0"); err != nil || mv.DeleteTime.GetSeconds() != deleted.GetSeconds() {
+		t.Errorf("FindMovieByID: want the delete time, got %v, %v", mv, err)
+	}
+	if hits, _ := sqlLayer.SearchMovies(ctx, "inception", 0, 0); len(hits) != 0 {
+		t.Errorf("SearchMovies: want no deleted movie, got %v", hits)
+	}
+
+	// A restored movie is found again
+	if err := sqlLayer.SetMovieDeleteTime(ctx, "id_0", "", nil); err != nil {
+		t.Fatalf("SetMovieDeleteTime: unexpected err %v", err)
+	}
+	if hits, _ := sqlLayer.SearchMovies(ctx, "inception", 0, 0); len(hits) != 1 {
+		t.Errorf("SearchMovies: want the restored movie, got %v", hits)
+	}
+
+	// Only the records deleted before the given time are purged
+	if err := sqlLayer.SetMovieDeleteTime(ctx, "id_0", "", deleted); err != nil {
+		t.Fatalf("SetMovieDeleteTime: unexpected err %v", err)
+	}
+	if n, err := sqlLayer.PurgeMovies(ctx, time.Unix(1609459200, 0)); err != nil || n != 0 {
+		t.Errorf("PurgeMovies: want 0 movies purged, got %d, %v", n, err)
+	}
+	if n, err := sqlLayer.PurgeMovies(ctx, time.Unix(1609459201, 0)); err != nil || n != 1 {
+		t.Errorf("PurgeMovies: want 1 movie purged, got %d, %v", n, err)
+	}
+	if n, err := sqlLayer.PurgeUsers(ctx, time.Unix(1609459201, 0)); err != nil || n != 1 {
+		t.Errorf("PurgeUsers: want 1 user purged, got %d, %v", n, err)
+	}
+	if _, err := sqlLayer.FindMovieByID(ctx, "id_0"); !errors.Is(err, persistence.ErrNotFound) {
+		t.Errorf("FindMovieByID: want ErrNotFound for purged movie, got %v", err)
+	}
+	if _, err := sqlLayer.FindMovieByID(ctx, "id_1"); err != nil {
+		t.Errorf("FindMovieByID: unexpected err %v", err)
+	}
+}
+
+func TestEtag(t *testing.T) {
+	ctx := context.Background()
+	sqlLayer := newTestLayer(t)
+
+	if _, err := sqlLayer.AddMovie(ctx, persistence.Movie{Id: "id_0", Name: "Inception"}); err != nil {
+		t.Fatalf("AddMovie: unexpected err %v", err)
+	}
+	if _, err := sqlLayer.AddUser(ctx, persistence.User{Username: "test_username", Email: "test_email@domain.in"}); err != nil {
+		t.Fatalf("AddUser: unexpected err %v", err)
+	}
+	mv, _ := sqlLayer.FindMovieByID(ctx, "id_0")
+	u, _ := sqlLayer.FindByUsername(ctx, "test_username")
+	if mv.Etag == "" || u.Etag == "" {
+		t.Fatalf("want etags on the added records, got %q and %q", mv.Etag, u.Etag)
+	}
+
+	if _, err := sqlLayer.UpdateMovieByID(ctx, "id_0", mv.Etag, persistence.Movie{Name: "Inception 2"}); err != nil {
+		t.Fatalf("UpdateMovieByID: unexpected err %v", err)
+	}
+	// The etag read before the update is now stale
+	if _, err := sqlLayer.UpdateMovieByID(ctx, "id_0", mv.Etag, persistence.Movie{Name: "Inception 3"}); !errors.Is(err, persistence.ErrEtagMismatch) {
+		t.Errorf("UpdateMovieByID: want ErrEtagMismatch, got %v", err)
+	}
+	if _, err := sqlLayer.UpdateMovieByID(ctx, "missing", mv.Etag, persistence.Movie{Name: "Inception 3"}); !errors.Is(err, persistence.ErrNotFound) {
+		t.Errorf("UpdateMovieByID: want ErrNotFound, got %v", err)
+	}
+	if err := sqlLayer.SetMovieDeleteTime(ctx, "id_0", mv.Etag, &timestamp.Timestamp{}); !errors.Is(err, persistence.ErrEtagMismatch) {
+		t.Errorf("SetMovieDeleteTime: want ErrEtagMismatch, got %v", err)
+	}
+	if found, _ := sqlLayer.FindMovieByID(ctx, "id_0"); found.Name != "Inception 2" || found.DeleteTime != nil || found.Etag == mv.Etag {
+		t.Errorf("FindMovieByID: want only the first update with a new etag, got %v", found)
+	}
+
+	if err := sqlLayer.SetUserDeleteTime(ctx, "test_username", "stale", &timestamp.Timestamp{}); !errors.Is(err, persistence.ErrEtagMismatch) {
+		t.Errorf("SetUserDeleteTime: want ErrEtagMismatch, got %v", err)
+	}
+	if err := sqlLayer.SetUserDeleteTime(ctx, "test_username", u.Etag, &timestamp.Timestamp{}); err != nil {
+		t.Errorf("SetUserDeleteTime: unexpected err %v", err)
+	}
+}
+
+func TestWatchMovies(t *testing.T) {
+	ctx := context.Background()
+	sqlLayer := newTestLayer(t)
+
+	stream, err := sqlLayer.WatchMovies(ctx, "")
+	if err != nil {
+		t.Fatalf("WatchMovies: unexpected err %v", err)
+	}
+	defer stream.Close()
+
+	if _, err := sqlLayer.AddMovie(ctx, persistence.Movie{Id: "id_0", Name: "Inception"}); err != nil {
+		t.Fatalf("AddMovie: unexpected err %v", err)
+	}
+	if _, err := sqlLayer.UpdateMovieByID(ctx, "id_0", "", persistence.Movie{Name: "Inception 2"}); err != nil {
+		t.Fatalf("UpdateMovieByID: unexpected err %v", err)
+	}
+	if err := sqlLayer.SetMovieDeleteTime(ctx, "id_0", "", &timestamp.Timestamp{Seconds: 1}); err != nil {
+		t.Fatalf("SetMovieDeleteTime: unexpected err %v", err)
+	}
+
+	tests := []struct {
+		typ  persistence.EventType
+		name string
+	}{
+		{persistence.MovieCreated, "Inception"},
+		{persistence.MovieUpdated, "Inception 2"},
+		{persistence.MovieDeleted, "Inception 2"},
+	}
+	for _, tcase := range tests {
+		ev, err := stream.Next(ctx)
+		if err != nil || ev.Type != tcase.typ || ev.Movie.Id != "id_0" || ev.Movie.Name != tcase.name {
+			t.Errorf("Next: want event %v of %q, got %v, %v", tcase.typ, tcase.name, ev, err)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/AkashGit21/ms-project/lib/filter"
+	"github.com/golang/protobuf/ptypes/timestamp"
+)
+
+// timeoutHandler bounds every operation of the wrapped DatabaseHandler, so that
+// a hung database cannot block a request forever, even one without a deadline.
+type timeoutHandler struct {
+	handler DatabaseHandler
+	timeout time.Duration
+}
+
+// WithTimeout returns a DatabaseHandler which runs each operation of handler
+// with at most the given timeout. A deadline of the caller which expires
+// earlier still takes precedence. A timeout <= 0 returns handler as it is.
+func WithTimeout(handler DatabaseHandler, timeout time.Duration) DatabaseHandler {
+	if timeout <= 0 || handler == nil {
+		return handler
+	}
+	return &timeoutHandler{
+		handler: handler,
+		timeout: timeout,
+	}
+}
+
+func (th *timeoutHandler) AddUser(ctx context.Context, u User) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, th.timeout)
+	defer cancel()
+	return th.handler.AddUser(ctx, u)
+}
+
+func (th *timeoutHandler) FindByUsername(ctx context.Context, uname string) (User, error) {
+	ctx, cancel := context.WithTimeout(ctx, th.timeout)
+	defer cancel()
+	return th.handler.FindByUsername(ctx, uname)
+}
+
+func (th *timeoutHandler) FindAllUsers(ctx context.Context, showDeleted bool, order Order, after Cursor, pgSize int32) ([]User, error) {
+	ctx, cancel := context.WithTimeout(ctx, th.timeout)
+	defer cancel()
+	return th.handler.FindAllUsers(ctx, showDeleted, order, after, pgSize)
+}
+
+func (th *timeoutHandler) UpdateByUsername(ctx context.Context, uname string, etag string, u User) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, th.timeout)
+	defer cancel()
+	return th.handler.UpdateByUsername(ctx, uname, etag, u)
+}
+
+func (th *timeoutHandler) SetUserDeleteTime(ctx context.Context, uname string, etag string, deleteTime *timestamp.Timestamp) error {
+	ctx, cancel := context.WithTimeout(ctx, th.timeout)
+	defer cancel()
+	return th.handler.SetUserDeleteTime(ctx, uname, etag, deleteTime)
+}
+
+func (th *timeoutHandler) SetUserEmailVerifyTime(ctx context.Context, uname string, etag string, verifyTime *timestamp.Timestamp) error {
+	ctx, cancel := context.WithTimeout(ctx, th.timeout)
+	defer cancel()
+	return th.handler.SetUserEmailVerifyTime(ctx, uname, etag, verifyTime)
+}
+
+func (th *timeoutHandler) PurgeUsers(ctx context.Context, deletedBefore time.Time) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, th.timeout)
+	defer cancel()
+	return th.handler.PurgeUsers(ctx, deletedBefore)
+}
+
+func (th *timeoutHandler) RemoveByUsername(ctx context.Context, uname string) error {
+	ctx, cancel := context.WithTimeout(ctx, th.timeout)
+	defer cancel()
+	return th.handler.RemoveByUsername(ctx, uname)
+}
+
+func (th *timeoutHandler) CountUsers(ctx context.Context) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, th.timeout)
+	defer cancel()
+	return th.handler.CountUsers(ctx)
+}
+
+func (th *timeoutHandler) Authenticate(ctx context.Context, uname string, password string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, th.timeout)
+	defer cancel()
+	return th.handler.Authenticate(ctx, uname, password)
+}
+
+func (th *timeoutHandler) AddMovie(ctx context.Context, mv Movie) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, th.timeout)
+	defer cancel()
+	return th.handler.AddMovie(ctx, mv)
+}
+
+func (th *timeoutHandler) FindMovieByID(ctx context.Context, id string) (Movie, error) {
+	ctx, cancel := context.WithTimeout(ctx, th.timeout)
+	defer cancel()
+	return th.handler.FindMovieByID(ctx, id)
+}
+
+func (th *timeoutHandler) FindMovieByName(ctx context.Context, name string) (Movie, error) {
+	ctx, cancel := context.WithTimeout(ctx, th.timeout)
+	defer cancel()
+	return th.handler.FindMovieByName(ctx, name)
+}
+
+func (th *timeoutHandler) FindAllMovies(ctx context.Context, expr filter.Expr, showDeleted bool, order Order, after Cursor, pgSize int32) ([]Movie, error) {
+	ctx, cancel := context.WithTimeout(ctx, th.timeout)
+	defer cancel()
+	return th.handler.FindAllMovies(ctx, expr, showDeleted, order, after, pgSize)
+}
+
+func (th *timeoutHandler) UpdateMovieByID(ctx context.Context, id string, etag string, mv Movie) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, th.timeout)
+	defer cancel()
+	return th.handler.UpdateMovieByID(ctx, id, etag, mv)
+}
+
+func (th *timeoutHandler) PatchMovieByID(ctx context.Context, id string, etag string, mv Movie, fields []string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, th.timeout)
+	defer cancel()
+	return th.handler.PatchMovieByID(ctx, id, etag, mv, fields)
+}
+
+func (th *timeoutHandler) SetMovieDeleteTime(ctx context.Context, id string, etag string, deleteTime *timestamp.Timestamp) error {
+	ctx, cancel := context.WithTimeout(ctx, th.timeout)
+	defer cancel()
+	return th.handler.SetMovieDeleteTime(ctx, id, etag, deleteTime)
+}
+
+func (th *timeoutHandler) PurgeMovies(ctx context.Context, deletedBefore time.Time) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, th.timeout)
+	defer cancel()
+	return th.handler.PurgeMovies(ctx, deletedBefore)
+}
+
+func (th *timeoutHandler) RemoveMovieByID(ctx context.Context, id string) error {
+	ctx, cancel := context.WithTimeout(ctx, th.timeout)
+	defer cancel()
+	return th.handler.RemoveMovieByID(ctx, id)
+}
+
+func (th *timeoutHandler) CountMovieRecords(ctx context.Context) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, th.timeout)
+	defer cancel()
+	return th.handler.CountMovieRecords(ctx)
+}
+
+func (th *timeoutHandler) SearchMovies(ctx context.Context, query string, offset, limit int32) ([]SearchHit, error) {
+	ctx, cancel := context.WithTimeout(ctx, th.timeout)
+	defer cancel()
+	return th.handler.SearchMovies(ctx, query, offset, limit)
+}
+
+// WatchMovies is not limited by the timeout, which is meant for single
+// operations rather than streams lasting as long as their watcher.
+func (th *timeoutHandler) WatchMovies(ctx context.Context, resumeToken string) (MovieStream, error) {
+	return th.handler.WatchMovies(ctx, resumeToken)
+}
@@ -0,0 +1,36 @@
+package persistence_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/AkashGit21/ms-project/lib/persistence"
+	"github.com/AkashGit21/ms-project/lib/persistence/memlayer"
+)
+
+func TestWithTimeout(t *testing.T) {
+	dbhandler, _ := memlayer.NewMemoryLayer()
+
+	if got := persistence.WithTimeout(dbhandler, 0); got != dbhandler {
+		t.Error("WithTimeout: want the handler itself for a zero timeout")
+	}
+
+	timed := persistence.WithTimeout(dbhandler, time.Second)
+	if _, err := timed.AddMovie(context.Background(), persistence.Movie{Name: "test_movie"}); err != nil {
+		t.Fatalf("AddMovie: unexpected err %v", err)
+	}
+	if got, err := timed.CountMovieRecords(context.Background()); err != nil || got != 1 {
+		t.Errorf("CountMovieRecords: want 1, got %d, %v", got, err)
+	}
+
+	// A cancelled request must not reach the database
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := timed.AddMovie(ctx, persistence.Movie{Name: "test_movie_2"}); err != context.Canceled {
+		t.Errorf("AddMovie: want %v, got %v", context.Canceled, err)
+	}
+	if got, _ := dbhandler.CountMovieRecords(context.Background()); got != 1 {
+		t.Errorf("CountMovieRecords: want 1, got %d", got)
+	}
+}
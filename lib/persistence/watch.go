@@ -0,0 +1,49 @@
+package persistence
+
+import (
+	"context"
+	"time"
+)
+
+// EventType is the kind of change of a MovieEvent. Its values are those of
+// moviepb.MovieEvent_Type.
+type EventType int32
+
+const (
+	EventUnspecified EventType = iota
+	MovieCreated
+	MovieUpdated
+	MovieDeleted
+	MovieUndeleted
+)
+
+// MovieEvent is a change of a movie.
+type MovieEvent struct {
+	Type EventType
+	// Movie is the movie as of the change. Only its Id is known for the
+	// movies removed for good.
+	Movie Movie
+	Time  time.Time
+	// ResumeToken resumes a watch after this event
+	ResumeToken string
+}
+
+// MovieStream is a stream of movie events, see MovieWatcher.
+type MovieStream interface {
+	// Next blocks until the next event. It fails once ctx is done or the
+	// stream broke, after which the caller should watch again from the
+	// resume token of the last event it got.
+	Next(ctx context.Context) (MovieEvent, error)
+	Close() error
+}
+
+// MovieWatcher streams the changes of the movies. MongoDB uses its change
+// streams, the other backends a Broadcaster of the changes made through them,
+// hence by this process only. Purges are not announced, as the purged movies
+// were announced deleted before.
+type MovieWatcher interface {
+	// WatchMovies returns the events after the one with the given resume
+	// token, or the events from now on if the token is empty. It fails with
+	// ErrInvalidResumeToken if the token is malformed or too old to resume.
+	WatchMovies(ctx context.Context, resumeToken string) (MovieStream, error)
+}
@@ -0,0 +1,147 @@
+// Package search implements a small full-text index held in memory, for the
+// storage backends which have no search engine of their own, and the
+// highlighting of the words of a query in the text of a result.
+package search
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// Document holds the text of the fields of a record by field name.
+type Document map[string]string
+
+// Hit is a document matching a query, the higher the score the better.
+type Hit struct {
+	ID    string
+	Score float64
+}
+
+// Index is an inverted index: it maps every term to the documents which
+// contain it. It is safe for concurrent use.
+type Index struct {
+	mu      sync.RWMutex
+	weights map[string]float64
+	// postings holds the frequency of a term in each document, weighted by
+	// the fields which contain it
+	postings map[string]map[string]float64
+	// terms holds the terms of each document, to remove them again
+	terms map[string][]string
+}
+
+// NewIndex returns an empty Index. The weights tell how relevant a term is in
+// each field; fields without a weight are not indexed.
+func NewIndex(weights map[string]float64) *Index {
+	return &Index{
+		weights:  weights,
+		postings: map[string]map[string]float64{},
+		terms:    map[string][]string{},
+	}
+}
+
+// Add indexes the document with the given ID, replacing any previous version.
+func (idx *Index) Add(id string, doc Document) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(id)
+	freqs := map[string]float64{}
+	for field, text := range doc {
+		for _, term := range Tokenize(text) {
+			freqs[term] += idx.weights[field]
+		}
+	}
+	for term, freq := range freqs {
+		if freq == 0 {
+			continue
+		}
+		if idx.postings[term] == nil {
+			idx.postings[term] = map[string]float64{}
+		}
+		idx.postings[term][id] = freq
+		idx.terms[id] = append(idx.terms[id], term)
+	}
+}
+
+// Remove removes the document with the given ID, if indexed.
+func (idx *Index) Remove(id string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.remove(id)
+}
+
+func (idx *Index) remove(id string) {
+	for _, term := range idx.terms[id] {
+		delete(idx.postings[term], id)
+		if len(idx.postings[term]) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+	delete(idx.terms, id)
+}
+
+// Len returns the number of documents indexed.
+func (idx *Index) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.terms)
+}
+
+// Search returns the documents containing any term of the query, best
+// matches first. A document scores the weighted frequency of each term times
+// its inverse document frequency, so that rare terms count more. Documents
+// of the same score are sorted by ID.
+func (idx *Index) Search(query string) []Hit {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	scores := map[string]float64{}
+	for _, term := range unique(Tokenize(query)) {
+		docs := idx.postings[term]
+		idf := math.Log(1 + float64(len(idx.terms))/float64(len(docs)))
+		for id, freq := range docs {
+			scores[id] += freq * idf
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, Hit{ID: id, Score: score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	return hits
+}
+
+func unique(terms []string) []string {
+	seen := map[string]bool{}
+	var result []string
+	for _, term := range terms {
+		if !seen[term] {
+			seen[term] = true
+			result = append(result, term)
+		}
+	}
+	return result
+}
+
+// Page returns the hits after the first offset ones, at most limit of them,
+// or all of them if limit is 0. A negative offset counts as 0.
+func Page(hits []Hit, offset, limit int) []Hit {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(hits) {
+		return nil
+	}
+	hits = hits[offset:]
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits
+}
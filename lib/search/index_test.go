@@ -0,0 +1,75 @@
+package search
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestIndex_Search(t *testing.T) {
+	idx := NewIndex(map[string]float64{"name": 10, "summary": 1})
+	idx.Add("id_0", Document{"name": "Inception", "summary": "A thief who steals secrets through dreams"})
+	idx.Add("id_1", Document{"name": "Dreamgirls", "summary": "A trio of singers"})
+	idx.Add("id_2", Document{"name": "The Dream Team", "summary": "Patients escape from a hospital"})
+	idx.Add("id_3", Document{"name": "Up", "summary": "An old man flies his house, a dream of his wife", "ignored": "Inception"})
+
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{"name_before_summary", "dream", "[id_2 id_3]"},
+		{"any_term", "inception singers", "[id_0 id_1]"},
+		{"case_insensitive", "INCEPTION", "[id_0]"},
+		{"stop_words", "the of", "[]"},
+		{"no_match", "matrix", "[]"},
+		{"unindexed_field", "ignored", "[]"},
+	}
+	for _, tcase := range tests {
+		t.Run(tcase.name, func(t *testing.T) {
+			var ids []string
+			for _, hit := range idx.Search(tcase.query) {
+				ids = append(ids, hit.ID)
+			}
+			if fmt.Sprint(ids) != tcase.expected {
+				t.Errorf("Search: want %v, got %v", tcase.expected, ids)
+			}
+		})
+	}
+
+	// Replaced and removed documents are not found anymore
+	idx.Add("id_2", Document{"name": "The Team"})
+	idx.Remove("id_3")
+	if hits := idx.Search("dream"); len(hits) != 0 {
+		t.Errorf("Search: want no hits, got %v", hits)
+	}
+	if idx.Len() != 3 {
+		t.Errorf("Len: want 3, got %d", idx.Len())
+	}
+}
+
+func TestPage(t *testing.T) {
+	hits := []Hit{{ID: "id_0"}, {ID: "id_1"}, {ID: "id_2"}}
+
+	tests := []struct {
+		name          string
+		offset, limit int
+		expected      string
+	}{
+		{"all", 0, 0, "[id_0 id_1 id_2]"},
+		{"limit", 0, 2, "[id_0 id_1]"},
+		{"offset", 1, 0, "[id_1 id_2]"},
+		{"past_the_end", 3, 2, "[]"},
+		{"negative_offset", -1, 2, "[id_0 id_1]"},
+	}
+	for _, tcase := range tests {
+		t.Run(tcase.name, func(t *testing.T) {
+			ids := []string{}
+			for _, hit := range Page(hits, tcase.offset, tcase.limit) {
+				ids = append(ids, hit.ID)
+			}
+			if fmt.Sprint(ids) != tcase.expected {
+				t.Errorf("Page: want %v, got %v", tcase.expected, ids)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// stopWords are too common to tell documents apart, hence neither indexed
+// nor highlighted.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "by": true,
+	"for": true, "from": true, "in": true, "is": true, "it": true, "of": true, "on": true, "or": true,
+	"the": true, "to": true, "with": true,
+}
+
+// Tokenize splits text into lower-cased terms: the runs of letters and
+// digits which are not stop words.
+func Tokenize(text string) []string {
+	var terms []string
+	for _, span := range spans(text) {
+		if term := strings.ToLower(text[span[0]:span[1]]); !stopWords[term] {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// spans returns the byte offsets of the runs of letters and digits of text.
+func spans(text string) [][2]int {
+	var result [][2]int
+	start := -1
+	for i, r := range text {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case isWord && start < 0:
+			start = i
+		case !isWord && start >= 0:
+			result = append(result, [2]int{start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		result = append(result, [2]int{start, len(text)})
+	}
+	return result
+}
+
+// Highlight returns a snippet of text of about width characters around the
+// first term of the query it contains, with the terms of the query wrapped in
+// <em> and </em>. A snippet which does not start or end with the text is
+// marked with an ellipsis. Highlight returns "" if text contains no term.
+func Highlight(text string, query string, width int) string {
+	terms := map[string]bool{}
+	for _, term := range Tokenize(query) {
+		terms[term] = true
+	}
+
+	words := spans(text)
+	matches := make([]bool, len(words))
+	first := -1
+	for i, w := range words {
+		matches[i] = terms[strings.ToLower(text[w[0]:w[1]])]
+		if matches[i] && first < 0 {
+			first = i
+		}
+	}
+	if first < 0 {
+		return ""
+	}
+
+	start, end := 0, len(text)
+	if utf8.RuneCountInString(text) > width {
+		// Start a few words before the first match, and end with the last
+		// word which fits
+		from := first - 3
+		if from <= 0 {
+			from = 0
+		} else {
+			start = words[from][0]
+		}
+		end = words[first][1]
+		for _, w := range words[from:] {
+			if utf8.RuneCountInString(text[start:w[1]]) > width {
+				break
+			}
+			if w[1] > end {
+				end = w[1]
+			}
+		}
+	}
+
+	var sb strings.Builder
+	if start > 0 {
+		sb.WriteString("…")
+	}
+	pos := start
+	for i, w := range words {
+		if !matches[i] || w[0] < start || w[1] > end {
+			continue
+		}
+		sb.WriteString(text[pos:w[0]])
+		sb.WriteString("<em>" + text[w[0]:w[1]] + "</em>")
+		pos = w[1]
+	}
+	sb.WriteString(text[pos:end])
+	if end < len(text) {
+		sb.WriteString("…")
+	}
+	return sb.String()
+}
@@ -0,0 +1,49 @@
+package search
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	got := Tokenize("The Lord of the Rings: Return-of the KING (2003)")
+	if want := "[lord rings return king 2003]"; fmt.Sprint(got) != want {
+		t.Errorf("Tokenize: want %v, got %v", want, got)
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		query    string
+		width    int
+		expected string
+	}{
+		{"whole_text", "Christian Bale, Hugh Jackman", "bale", 80, "Christian <em>Bale</em>, Hugh Jackman"},
+		{"every_term", "A dream within a dream", "Dream", 80, "A <em>dream</em> within a <em>dream</em>"},
+		{"no_match", "Christian Bale", "nolan", 80, ""},
+		{"stop_word", "The Prestige", "the", 80, ""},
+		{
+			name:     "cut",
+			text:     "A thief who steals corporate secrets through the use of dream-sharing technology is given the inverse task",
+			query:    "dream",
+			width:    40,
+			expected: "…the use of <em>dream</em>-sharing technology is…",
+		},
+		{
+			name:     "cut_end",
+			text:     "Dreams of a thief who steals corporate secrets",
+			query:    "dreams",
+			width:    20,
+			expected: "<em>Dreams</em> of a thief…",
+		},
+	}
+	for _, tcase := range tests {
+		t.Run(tcase.name, func(t *testing.T) {
+			if got := Highlight(tcase.text, tcase.query, tcase.width); got != tcase.expected {
+				t.Errorf("Highlight:\n\texpected: %v \n\tactual: %v", tcase.expected, got)
+			}
+		})
+	}
+}